@@ -1,9 +1,11 @@
 package config
 
 import (
-	"log"
+	"errors"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +15,7 @@ type Config struct {
 	DB    DBConfig
 	Auth  AuthConfig
 	Minio MinioConfig
+	Mail  MailConfig
 }
 
 type AppConfig struct {
@@ -20,6 +23,12 @@ type AppConfig struct {
 	CORSOrigin     string
 	MinioPublicURL string
 	Env            string
+	FrontendURL    string // база для ссылок в письмах
+	// ProxyHeader — заголовок с IP клиента от reverse proxy (например, X-Real-Ip за Traefik);
+	// пусто — берём адрес TCP-соединения
+	ProxyHeader string
+	// TrashRetention — сколько удалённые вишлисты и подарки лежат в корзине до окончательного удаления
+	TrashRetention time.Duration
 }
 
 type DBConfig struct {
@@ -32,9 +41,33 @@ type DBConfig struct {
 }
 
 type AuthConfig struct {
-	JWTSecret    string
-	BotToken     string
-	CookieDomain string
+	JWTSecret      string
+	BotToken       string
+	CookieDomain   string
+	JWTKeysDir     string // каталог PEM-ключей подписи <kid>.pem; пусто — HS256 с JWTSecret
+	JWTActiveKeyID string // kid ключа, которым подписываются новые токены
+	JWTAllowHS256  bool   // при заданном JWTKeysDir ещё принимать токены HS256 без kid
+	// AdminUsernames получают роль admin при старте — для первого администратора инстанса
+	AdminUsernames []string
+	// AccountDeletionGrace — сколько удалённый аккаунт ждёт окончательной очистки; вход в этот срок отменяет удаление
+	AccountDeletionGrace time.Duration
+	// DataExportTTL — сколько готовый архив с данными пользователя доступен для скачивания
+	DataExportTTL time.Duration
+	// TelegramWebAppMaxAge — сколько initData Telegram Mini App годится для входа после подписи
+	TelegramWebAppMaxAge time.Duration
+	// Параметры Argon2id для новых хешей паролей; 0 — значение по умолчанию.
+	// После изменения старые хеши пересчитываются при следующем входе
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	From         string
 }
 
 type MinioConfig struct {
@@ -54,6 +87,9 @@ func LoadConfig() (*Config, error) {
 			CORSOrigin:     getEnv("CORS_ORIGIN", "https://prosto-namekni.ru"),
 			MinioPublicURL: getEnv("MINIO_PUBLIC_URL", "https://files.prosto-namekni.ru"),
 			Env:            getEnv("APP_ENV", "production"),
+			FrontendURL:    getEnv("FRONTEND_URL", "https://prosto-namekni.ru"),
+			ProxyHeader:    getEnv("PROXY_HEADER", ""),
+			TrashRetention: getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour),
 		},
 		DB: DBConfig{
 			Host:     getEnv("DB_HOST", "postgres"),
@@ -64,9 +100,21 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:    getEnv("JWT_SECRET", ""),
-			BotToken:     getEnv("BOT_TOKEN", ""),
-			CookieDomain: getEnv("COOKIE_DOMAIN", "prosto-namekni.ru"),
+			JWTSecret:      getEnv("JWT_SECRET", ""),
+			BotToken:       getEnv("BOT_TOKEN", ""),
+			CookieDomain:   getEnv("COOKIE_DOMAIN", "prosto-namekni.ru"),
+			JWTKeysDir:     getEnv("JWT_KEYS_DIR", ""),
+			JWTActiveKeyID: getEnv("JWT_ACTIVE_KID", ""),
+			JWTAllowHS256:  getEnvAsBool("JWT_ALLOW_HS256", true),
+			AdminUsernames: getEnvAsList("ADMIN_USERNAMES"),
+
+			AccountDeletionGrace: getEnvAsDuration("ACCOUNT_DELETION_GRACE", 7*24*time.Hour),
+			DataExportTTL:        getEnvAsDuration("DATA_EXPORT_TTL", 48*time.Hour),
+			TelegramWebAppMaxAge: getEnvAsDuration("TELEGRAM_WEBAPP_MAX_AGE", time.Hour),
+
+			Argon2Memory:      uint32(getEnvAsUint("PASSWORD_ARGON2_MEMORY", 32)),
+			Argon2Iterations:  uint32(getEnvAsUint("PASSWORD_ARGON2_ITERATIONS", 32)),
+			Argon2Parallelism: uint8(getEnvAsUint("PASSWORD_ARGON2_PARALLELISM", 8)),
 		},
 		Minio: MinioConfig{
 			Endpoint:     getEnv("MINIO_ENDPOINT", "minio:9000"),
@@ -75,10 +123,19 @@ func LoadConfig() (*Config, error) {
 			RootPassword: getEnv("MINIO_ROOT_PASSWORD", "minio_password"),
 			UseSSL:       getEnvAsBool("MINIO_USE_SSL", false),
 		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUser:     getEnv("SMTP_USER", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "Просто намекни <no-reply@prosto-namekni.ru>"),
+		},
 	}
 
+	// Секрет нужен и с асимметричными ключами: от него считаются ключи mfa-токенов и токенов
+	// доступа к вишлистам с паролем, и пустой сделал бы их подделываемыми
 	if cfg.Auth.JWTSecret == "" {
-		log.Println("WARNING: JWT_SECRET is not set")
+		return nil, errors.New("JWT_SECRET is not set")
 	}
 
 	return cfg, nil
@@ -99,3 +156,32 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if valueStr := getEnv(key, ""); valueStr != "" {
+		if value, err := time.ParseDuration(valueStr); err == nil {
+			return value
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsUint разбирает беззнаковое число размером bits бит; пусто или ошибка — 0
+func getEnvAsUint(key string, bits int) uint64 {
+	value, err := strconv.ParseUint(getEnv(key, ""), 10, bits)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// getEnvAsList разбирает список через запятую, пропуская пустые элементы
+func getEnvAsList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
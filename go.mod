@@ -6,7 +6,6 @@ require (
 	github.com/chai2010/webp v1.4.0
 	github.com/electrofocus/telegram-auth-verifier v1.1.1
 	github.com/gofiber/fiber/v2 v2.52.5
-	github.com/gofiber/jwt/v2 v2.2.7
 	github.com/golang-jwt/jwt/v4 v4.5.1
 	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,9 @@ import (
 	"main/config"
 	"main/internal/controller/restapi"
 	"main/internal/repo/persistent"
+	accessUC "main/internal/usecase/access"
+	accountUC "main/internal/usecase/account"
+	adminUC "main/internal/usecase/admin"
 	parseUC "main/internal/usecase/parse"
 	presentUC "main/internal/usecase/present"
 	templateUC "main/internal/usecase/template"
@@ -21,6 +25,8 @@ import (
 	userUC "main/internal/usecase/user"
 	wishlistUC "main/internal/usecase/wishlist"
 	"main/pkg/hasher"
+	"main/pkg/jwtkeys"
+	"main/pkg/mailer"
 	minioPkg "main/pkg/minio"
 	"main/pkg/postgres"
 )
@@ -33,6 +39,9 @@ func Run(cfg *config.Config) {
 	}
 
 	// AutoMigrate
+	if err := persistent.PrepareUserEmails(db); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
 	if err := db.AutoMigrate(
 		&persistent.UserModel{},
 		&persistent.WishlistModel{},
@@ -41,9 +50,29 @@ func Run(cfg *config.Config) {
 		&persistent.PresentMetaModel{},
 		&persistent.TemplateModel{},
 		&persistent.TemplateLikeModel{},
+		&persistent.SessionModel{},
+		&persistent.UserIdentityModel{},
+		&persistent.UserTokenModel{},
+		&persistent.LoginAttemptModel{},
+		&persistent.AccessTokenModel{},
+		&persistent.DataExportModel{},
+		&persistent.HandleRedirectModel{},
+		&persistent.CollaboratorModel{},
+		&persistent.WishlistInviteModel{},
+		&persistent.WishlistSlugModel{},
+		&persistent.WishlistRevisionModel{},
 	); err != nil {
 		log.Fatalf("automigrate: %v", err)
 	}
+	if err := persistent.MigrateTelegramIdentities(db); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+	if err := persistent.PromoteAdmins(db, cfg.Auth.AdminUsernames); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+	if err := persistent.BackfillEventEnds(db); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
 
 	// MinIO
 	fileStorage, err := minioPkg.New(cfg.Minio, cfg.App.MinioPublicURL)
@@ -59,24 +88,128 @@ func Run(cfg *config.Config) {
 	presentMetaRepo := persistent.NewPresentMetaRepo(db)
 	rateLimitRepo := persistent.NewParseRateLimitRepo(db)
 	templateRepo := persistent.NewTemplateRepo(db)
+	sessionRepo := persistent.NewSessionRepo(db)
+	identityRepo := persistent.NewIdentityRepo(db)
+	userTokenRepo := persistent.NewUserTokenRepo(db)
+	loginAttemptRepo := persistent.NewLoginAttemptRepo(db)
+	accessTokenRepo := persistent.NewAccessTokenRepo(db)
+	statsRepo := persistent.NewStatsRepo(db)
+	fileRefRepo := persistent.NewFileRefRepo(db)
+	dataExportRepo := persistent.NewDataExportRepo(db)
+	handleRedirectRepo := persistent.NewHandleRedirectRepo(db)
+	collaboratorRepo := persistent.NewCollaboratorRepo(db)
+	revisionRepo := persistent.NewRevisionRepo(db)
+	wishlistInviteRepo := persistent.NewWishlistInviteRepo(db)
+
+	// JWT keys
+	jwtKeys, err := jwtkeys.New(cfg.Auth)
+	if err != nil {
+		log.Fatalf("jwt keys: %v", err)
+	}
 
 	// Hasher
-	pwHasher := hasher.New()
+	pwHasher := hasher.NewArgon2id(hasher.Argon2Params{
+		Memory:      cfg.Auth.Argon2Memory,
+		Iterations:  cfg.Auth.Argon2Iterations,
+		Parallelism: cfg.Auth.Argon2Parallelism,
+	})
 
 	// Use Cases
-	userUseCase := userUC.New(userRepo, pwHasher, cfg.Auth.JWTSecret, cfg.Auth.BotToken)
-	wishlistUseCase := wishlistUC.New(wishlistRepo, fileStorage)
-	presentUseCase := presentUC.New(presentRepo, wishlistRepo, fileStorage, presentMetaRepo)
+	accessPolicy := accessUC.New(wishlistRepo, presentRepo, collaboratorRepo, cfg.Auth.JWTSecret)
+	userUseCase, err := userUC.New(userUC.Deps{
+		UserRepo:        userRepo,
+		IdentityRepo:    identityRepo,
+		SessionRepo:     sessionRepo,
+		TokenRepo:       userTokenRepo,
+		AttemptRepo:     loginAttemptRepo,
+		AccessTokenRepo: accessTokenRepo,
+		RedirectRepo:    handleRedirectRepo,
+		WishlistRepo:    wishlistRepo,
+		Hasher:          pwHasher,
+		Mailer:          mailer.New(cfg.Mail),
+		JWTKeys:         jwtKeys,
+		JWTSecret:       cfg.Auth.JWTSecret,
+		BotToken:        cfg.Auth.BotToken,
+		WebAppMaxAge:    cfg.Auth.TelegramWebAppMaxAge,
+		FrontendURL:     cfg.App.FrontendURL,
+		DeletionGrace:   cfg.Auth.AccountDeletionGrace,
+	})
+	if err != nil {
+		log.Fatalf("user use case: %v", err)
+	}
+	wishlistUseCase := wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo:     wishlistRepo,
+		PresentRepo:      presentRepo,
+		PresentMetaRepo:  presentMetaRepo,
+		AttemptRepo:      loginAttemptRepo,
+		UserRepo:         userRepo,
+		RedirectRepo:     handleRedirectRepo,
+		RevisionRepo:     revisionRepo,
+		CollaboratorRepo: collaboratorRepo,
+		InviteRepo:       wishlistInviteRepo,
+		FileStorage:      fileStorage,
+		Policy:           accessPolicy,
+		Hasher:           pwHasher,
+	})
+	presentUseCase := presentUC.New(presentRepo, wishlistRepo, fileStorage, presentMetaRepo, accessPolicy)
 	uploadUseCase := uploadUC.New(fileStorage)
 	httpClient := &http.Client{Timeout: 15 * time.Second}
 	parseUseCase := parseUC.NewParseUseCase(rateLimitRepo, httpClient)
 	templateUseCase := templateUC.New(templateRepo, wishlistRepo)
+	adminUseCase := adminUC.New(userRepo, sessionRepo, wishlistRepo, templateRepo, statsRepo)
+	accountUseCase := accountUC.New(accountUC.Deps{
+		UserRepo:        userRepo,
+		IdentityRepo:    identityRepo,
+		WishlistRepo:    wishlistRepo,
+		PresentRepo:     presentRepo,
+		PresentMetaRepo: presentMetaRepo,
+		TemplateRepo:    templateRepo,
+		FileRefRepo:     fileRefRepo,
+		ExportRepo:      dataExportRepo,
+		FileStorage:     fileStorage,
+		ExportTTL:       cfg.Auth.DataExportTTL,
+		TrashRetention:  cfg.App.TrashRetention,
+	})
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	runPeriodically(jobsCtx, "purge deleted accounts", time.Hour, func(ctx context.Context) error {
+		purged, err := accountUseCase.PurgeDue(ctx, time.Now())
+		if purged > 0 {
+			log.Printf("purged %d deleted accounts", purged)
+		}
+		return err
+	})
+	runPeriodically(jobsCtx, "purge trash", time.Hour, func(ctx context.Context) error {
+		purged, err := accountUseCase.PurgeTrash(ctx, time.Now())
+		if purged > 0 {
+			log.Printf("purged %d wishlists and presents from trash", purged)
+		}
+		return err
+	})
+	runPeriodically(jobsCtx, "archive past events", time.Hour, func(ctx context.Context) error {
+		archived, err := wishlistUseCase.ArchivePastEvents(ctx, time.Now())
+		if archived > 0 {
+			log.Printf("archived %d wishlists after their events", archived)
+		}
+		return err
+	})
+	runPeriodically(jobsCtx, "build data exports", 30*time.Second, func(ctx context.Context) error {
+		_, err := accountUseCase.ProcessExports(ctx, time.Now())
+		return err
+	})
+	runPeriodically(jobsCtx, "purge expired data exports", time.Hour, func(ctx context.Context) error {
+		_, err := accountUseCase.PurgeExpiredExports(ctx, time.Now())
+		return err
+	})
 
 	// HTTP server
 	app := fiber.New(fiber.Config{
-		BodyLimit: 15 * 1024 * 1024, // 15MB — headroom for multipart overhead
+		BodyLimit:   15 * 1024 * 1024, // 15MB — headroom for multipart overhead
+		ProxyHeader: cfg.App.ProxyHeader,
 	})
-	restapi.NewRouter(app, cfg, userUseCase, wishlistUseCase, presentUseCase, uploadUseCase, parseUseCase, templateUseCase)
+	restapi.NewRouter(app, cfg, jwtKeys, userUseCase, wishlistUseCase, presentUseCase, uploadUseCase, parseUseCase, templateUseCase, adminUseCase, accountUseCase)
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -91,6 +224,7 @@ func Run(cfg *config.Config) {
 	log.Printf("Server started on :%s", cfg.App.Port)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 	if err := app.Shutdown(); err != nil {
 		log.Printf("server shutdown error: %v", err)
 	}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runPeriodically запускает job сразу и затем каждые interval, пока не отменён ctx.
+// Ошибки только логируются: следующий запуск повторит работу
+func runPeriodically(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := job(ctx); err != nil {
+				log.Printf("job %s: %v", name, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -1,37 +1,214 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
-	jwtware "github.com/gofiber/jwt/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/pkg/jwtkeys"
 )
 
-// CookieToHeader переносит JWT-токен из cookie в заголовок Authorization
+// scopesKey — ключ Locals со скоупами персонального токена; для JWT-сессий не задан
+const scopesKey = "scopes"
+
+// SessionValidator проверяет, что сессия, выпустившая токен, не отозвана
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID uuid.UUID) error
+}
+
+// Authenticator проверяет JWT-сессии и персональные токены доступа
+type Authenticator interface {
+	SessionValidator
+	AuthenticateAccessToken(ctx context.Context, token string) (entity.AccessToken, error)
+}
+
+// CookieToHeader переносит JWT-токен из cookie в заголовок Authorization.
+// Явно переданный персональный токен cookie не перетирает
 func CookieToHeader() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		token := c.Cookies("token")
-		if token != "" {
+		if token != "" && bearerAccessToken(c) == "" {
 			c.Request().Header.Set("Authorization", "Bearer "+token)
 		}
 		return c.Next()
 	}
 }
 
-// JWTProtected создаёт middleware для проверки JWT
-func JWTProtected(secret string) fiber.Handler {
-	return jwtware.New(jwtware.Config{
-		SigningKey: []byte(secret),
-		ContextKey: "user",
-	})
+// JWTProtected создаёт middleware для проверки JWT и активности его сессии
+// либо персонального токена доступа
+func JWTProtected(keys *jwtkeys.KeySet, auth Authenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if raw := bearerAccessToken(c); raw != "" {
+			return accessTokenAuth(c, auth, raw)
+		}
+
+		raw := bearerJWT(c)
+		if raw == "" {
+			return c.Status(fiber.StatusBadRequest).SendString("Missing or malformed JWT")
+		}
+		token, err := keys.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).SendString("Invalid or expired JWT")
+		}
+		c.Locals("user", token)
+		if err := checkSession(c, auth); err != nil {
+			return c.Status(fiber.StatusUnauthorized).SendString("Invalid or expired JWT")
+		}
+		return c.Next()
+	}
+}
+
+// RequireScopes пропускает запрос по персональному токену, только если у него есть все scopes.
+// JWT-сессии имеют полный доступ
+func RequireScopes(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted, ok := c.Locals(scopesKey).([]string)
+		if !ok {
+			return c.Next()
+		}
+		for _, s := range scopes {
+			if !hasScope(granted, s) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "access token lacks scope " + s})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// SessionOnly закрывает маршрут для персональных токенов (управление аккаунтом и сами токены)
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals(scopesKey).([]string); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not available for access tokens"})
+		}
+		return c.Next()
+	}
+}
+
+// RequireRole пропускает только JWT-сессии пользователей с ролью не ниже min.
+// Роль берётся из claims, поэтому понижение вступает в силу вместе с отзывом сессий;
+// у персональных токенов роли нет
+func RequireRole(min string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !entity.RoleAtLeast(Role(c), min) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "requires role " + min})
+		}
+		return c.Next()
+	}
 }
 
 // JWTOptional parses JWT if present but does not fail on missing/invalid tokens.
 // Use for routes that have optional authentication (public + enriched for logged-in users).
-func JWTOptional(secret string) fiber.Handler {
-	return jwtware.New(jwtware.Config{
-		SigningKey:  []byte(secret),
-		ContextKey:  "user",
-		ErrorHandler: func(c *fiber.Ctx, _ error) error {
+// An explicitly sent personal access token is checked as strictly as in JWTProtected,
+// so the route still needs RequireScopes.
+func JWTOptional(keys *jwtkeys.KeySet, auth Authenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if raw := bearerAccessToken(c); raw != "" {
+			return accessTokenAuth(c, auth, raw)
+		}
+		raw := bearerJWT(c)
+		if raw == "" {
+			return c.Next()
+		}
+		token, err := keys.Parse(raw)
+		if err != nil {
 			return c.Next()
-		},
+		}
+		c.Locals("user", token)
+		if err := checkSession(c, auth); err != nil {
+			c.Locals("user", nil)
+		}
+		return c.Next()
+	}
+}
+
+// accessTokenAuth пропускает запрос с действующим персональным токеном, запомнив его скоупы
+func accessTokenAuth(c *fiber.Ctx, auth Authenticator, raw string) error {
+	token, err := auth.AuthenticateAccessToken(c.Context(), raw)
+	if errors.Is(err, usecase.ErrBanned) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).SendString("Invalid or expired access token")
+	}
+	// Те же claims, что и у JWT, — обработчики получают пользователя через getUserID как обычно
+	c.Locals("user", &jwt.Token{
+		Valid:  true,
+		Claims: jwt.MapClaims{"id": token.UserID.String(), "pat": token.ID.String()},
 	})
+	c.Locals(scopesKey, token.Scopes)
+	return c.Next()
+}
+
+// SessionID возвращает ID сессии из проверенного токена или uuid.Nil
+func SessionID(c *fiber.Ctx) uuid.UUID {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return uuid.Nil
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil
+	}
+	sid, _ := claims["sid"].(string)
+	id, err := uuid.Parse(sid)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// Role возвращает роль из JWT-сессии или "" (в том числе для персональных токенов)
+func Role(c *fiber.Ctx) string {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["pat"] != nil {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
+
+func checkSession(c *fiber.Ctx, sessions SessionValidator) error {
+	sid := SessionID(c)
+	if sid == uuid.Nil {
+		return fiber.ErrUnauthorized
+	}
+	return sessions.ValidateSession(c.Context(), sid)
+}
+
+// bearerJWT возвращает токен из заголовка "Authorization: Bearer <token>" или ""
+func bearerJWT(c *fiber.Ctx) string {
+	auth := c.Get(fiber.HeaderAuthorization)
+	if len(auth) <= len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(auth[len("Bearer "):])
+}
+
+// bearerAccessToken возвращает персональный токен из заголовка Authorization или ""
+func bearerAccessToken(c *fiber.Ctx) string {
+	raw := bearerJWT(c)
+	if !strings.HasPrefix(raw, entity.AccessTokenPrefix) {
+		return ""
+	}
+	return raw
+}
+
+func hasScope(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == scope {
+			return true
+		}
+	}
+	return false
 }
@@ -14,26 +14,30 @@ import (
 	"main/internal/controller/restapi/middleware"
 	v1 "main/internal/controller/restapi/v1"
 	"main/internal/usecase"
+	"main/pkg/jwtkeys"
 )
 
 func NewRouter(
 	app *fiber.App,
 	cfg *config.Config,
+	jwtKeys *jwtkeys.KeySet,
 	userUC usecase.UserUseCase,
 	wishlistUC usecase.WishlistUseCase,
 	presentUC usecase.PresentUseCase,
 	uploadUC usecase.UploadUseCase,
 	parseUC usecase.ParseUseCase,
 	templateUC usecase.TemplateUseCase,
+	adminUC usecase.AdminUseCase,
+	accountUC usecase.AccountUseCase,
 ) {
 	app.Use(logger.New())
 	app.Use(compress.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.App.CORSOrigin,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Custom-Header",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Custom-Header, If-Match",
 		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS, PATCH",
 		AllowCredentials: true,
-		ExposeHeaders:    "Content-Length, X-Knowledge-Base",
+		ExposeHeaders:    "Content-Length, X-Knowledge-Base, ETag",
 		MaxAge:           3600,
 	}))
 	app.Use(recover.New())
@@ -47,5 +51,11 @@ func NewRouter(
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
-	v1.NewRouter(app, cfg.Auth.JWTSecret, cfg.Auth.CookieDomain, cfg.App.Env == "production", userUC, wishlistUC, presentUC, uploadUC, parseUC, templateUC)
+	// Публичные ключи для проверки access-токенов другими сервисами
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(jwtKeys.JWKS())
+	})
+
+	v1.NewRouter(app, jwtKeys, cfg.Auth.CookieDomain, cfg.App.Env == "production", userUC, wishlistUC, presentUC, uploadUC, parseUC, templateUC, adminUC, accountUC)
 }
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"main/internal/controller/restapi/v1/response"
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+type accountHandler struct {
+	uc usecase.AccountUseCase
+}
+
+func newAccountHandler(uc usecase.AccountUseCase) *accountHandler {
+	return &accountHandler{uc: uc}
+}
+
+// requestExport ставит выгрузку в очередь: архив собирается в фоне, клиент опрашивает список выгрузок
+func (h *accountHandler) requestExport(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	export, err := h.uc.RequestExport(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	return c.Status(fiber.StatusAccepted).JSON(response.Data(exportResponse(export)))
+}
+
+func (h *accountHandler) getExports(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	exports, err := h.uc.GetExports(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	result := make([]fiber.Map, len(exports))
+	for i, e := range exports {
+		result[i] = exportResponse(e)
+	}
+	return c.JSON(response.Data(result))
+}
+
+func (h *accountHandler) downloadExport(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	exportID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid export ID"))
+	}
+
+	data, err := h.uc.DownloadExport(c.Context(), userID, exportID)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="wishlist-export-%s.zip"`, exportID))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(data)
+}
+
+// exportResponse — выгрузка со ссылкой на скачивание, пока архив доступен
+func exportResponse(e entity.DataExport) fiber.Map {
+	result := fiber.Map{
+		"id":          e.ID,
+		"status":      e.Status,
+		"size":        e.Size,
+		"createdAt":   e.CreatedAt,
+		"completedAt": e.CompletedAt,
+		"expiresAt":   e.ExpiresAt,
+	}
+	if e.Downloadable(time.Now()) {
+		result["downloadUrl"] = fmt.Sprintf("/api/v1/users/me/exports/%s/download", e.ID)
+	}
+	return result
+}
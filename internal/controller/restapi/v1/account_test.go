@@ -0,0 +1,108 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "main/internal/controller/restapi/v1"
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+func setupAccountApp(am *MockAccountUC) *fiber.App {
+	app := fiber.New()
+	v1.NewRouter(app, testKeys, "", false,
+		&MockUserUC{}, &MockWishlistUC{}, &MockPresentUC{}, &MockUploadUC{}, &MockParseUC{},
+		&MockTemplateUC{}, &MockAdminUC{}, am,
+	)
+	return app
+}
+
+func TestRequestExport_Accepted(t *testing.T) {
+	am := &MockAccountUC{}
+	app := setupAccountApp(am)
+	userID := uuid.New()
+	export := entity.DataExport{ID: uuid.New(), UserID: userID, Status: entity.DataExportPending, CreatedAt: time.Now()}
+	am.On("RequestExport", mock.Anything, userID).Return(export, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/exports", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, export.ID.String(), body.Data["id"])
+	assert.Equal(t, entity.DataExportPending, body.Data["status"])
+	assert.NotContains(t, body.Data, "downloadUrl")
+}
+
+func TestGetExports_DownloadURLOnlyWhileAvailable(t *testing.T) {
+	am := &MockAccountUC{}
+	app := setupAccountApp(am)
+	userID := uuid.New()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	ready := entity.DataExport{ID: uuid.New(), Status: entity.DataExportReady, ObjectID: "a", ExpiresAt: &future}
+	expired := entity.DataExport{ID: uuid.New(), Status: entity.DataExportReady, ObjectID: "b", ExpiresAt: &past}
+	am.On("GetExports", mock.Anything, userID).Return([]entity.DataExport{ready, expired}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/exports", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, "/api/v1/users/me/exports/"+ready.ID.String()+"/download", body.Data[0]["downloadUrl"])
+	assert.NotContains(t, body.Data[1], "downloadUrl")
+	assert.NotContains(t, body.Data[0], "objectId")
+}
+
+func TestDownloadExport(t *testing.T) {
+	am := &MockAccountUC{}
+	app := setupAccountApp(am)
+	userID := uuid.New()
+	exportID := uuid.New()
+	am.On("DownloadExport", mock.Anything, userID, exportID).Return([]byte("PK zip"), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/exports/"+exportID.String()+"/download", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get(fiber.HeaderContentType))
+	assert.Contains(t, resp.Header.Get(fiber.HeaderContentDisposition), "attachment")
+	assert.Equal(t, "no-store", resp.Header.Get(fiber.HeaderCacheControl))
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "PK zip", string(data))
+}
+
+func TestDownloadExport_NotFound(t *testing.T) {
+	am := &MockAccountUC{}
+	app := setupAccountApp(am)
+	am.On("DownloadExport", mock.Anything, mock.Anything, mock.Anything).Return(nil, usecase.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/exports/"+uuid.NewString()+"/download", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(uuid.New()))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
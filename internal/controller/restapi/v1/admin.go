@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"main/internal/controller/restapi/v1/request"
+	"main/internal/controller/restapi/v1/response"
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+type adminHandler struct {
+	uc usecase.AdminUseCase
+}
+
+func newAdminHandler(uc usecase.AdminUseCase) *adminHandler {
+	return &adminHandler{uc: uc}
+}
+
+func (h *adminHandler) listUsers(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+
+	result, err := h.uc.ListUsers(c.Context(), actorID, c.Query("q"), page)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	users := make([]fiber.Map, len(result.Users))
+	for i, u := range result.Users {
+		users[i] = adminUserResponse(u)
+	}
+	return c.JSON(fiber.Map{
+		"data":    users,
+		"total":   result.Total,
+		"hasMore": result.HasMore,
+	})
+}
+
+func (h *adminHandler) ban(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid user ID"))
+	}
+	var req request.BanRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+		}
+	}
+
+	user, err := h.uc.Ban(c.Context(), actorID, id, req.Reason)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(adminUserResponse(user)))
+}
+
+func (h *adminHandler) unban(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid user ID"))
+	}
+
+	user, err := h.uc.Unban(c.Context(), actorID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(adminUserResponse(user)))
+}
+
+func (h *adminHandler) setRole(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid user ID"))
+	}
+	var req request.SetRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	user, err := h.uc.SetRole(c.Context(), actorID, id, req.Role)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(adminUserResponse(user)))
+}
+
+func (h *adminHandler) unpublishTemplate(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid template ID"))
+	}
+
+	template, err := h.uc.UnpublishTemplate(c.Context(), actorID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(template))
+}
+
+func (h *adminHandler) unpublishWishlist(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	wishlist, err := h.uc.UnpublishWishlist(c.Context(), actorID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *adminHandler) publishWishlist(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	wishlist, err := h.uc.PublishWishlist(c.Context(), actorID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *adminHandler) stats(c *fiber.Ctx) error {
+	actorID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+
+	stats, err := h.uc.Stats(c.Context(), actorID)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(stats))
+}
+
+// adminUserResponse — профиль для модераторов; пароль и секрет 2FA наружу не отдаются
+func adminUserResponse(user entity.User) fiber.Map {
+	m := profileResponse(user)
+	m["bannedAt"] = user.BannedAt
+	m["banReason"] = user.BanReason
+	return m
+}
@@ -2,10 +2,16 @@ package v1
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+
+	"main/internal/controller/restapi/v1/response"
+	"main/internal/usecase"
 )
 
 func getUserID(c *fiber.Ctx) (uuid.UUID, error) {
@@ -39,3 +45,117 @@ func getOptionalUserID(c *fiber.Ctx) *uuid.UUID {
 	}
 	return &id
 }
+
+// viewer — пользователь из необязательной авторизации (uuid.Nil для анонима)
+// и токены разблокировки защищённых паролем вишлистов из cookie
+func viewer(c *fiber.Ctx) usecase.Viewer {
+	v := usecase.Viewer{}
+	if id, err := getUserID(c); err == nil {
+		v.UserID = id
+	}
+	c.Request().Header.VisitAllCookie(func(key, value []byte) {
+		if len(v.UnlockTokens) < maxUnlockCookies && strings.HasPrefix(string(key), unlockCookiePrefix) {
+			v.UnlockTokens = append(v.UnlockTokens, string(value))
+		}
+	})
+	return v
+}
+
+// errorStatus маппит типизированные ошибки use case в HTTP-статус, иначе возвращает fallback
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden), errors.Is(err, usecase.ErrBanned), errors.Is(err, usecase.ErrReauthRequired),
+		errors.Is(err, usecase.ErrWrongWishlistPassword):
+		return fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrConflict), errors.Is(err, usecase.ErrLastLoginMethod):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrStale):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, errIfMatchRequired):
+		return fiber.StatusPreconditionRequired
+	case errors.Is(err, usecase.ErrLinkCooldown):
+		return fiber.StatusTooManyRequests
+	}
+	return fallback
+}
+
+var errIfMatchRequired = errors.New("нужен заголовок If-Match с ETag редактируемой версии")
+
+// setETag отдаёт версию вишлиста или подарка; клиент возвращает её в If-Match, когда правит или удаляет
+func setETag(c *fiber.Ctx, version uint) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion читает из If-Match версию, которую правит клиент. Правка без заголовка
+// вслепую затёрла бы чужие изменения, поэтому он обязателен (428)
+func ifMatchVersion(c *fiber.Ctx) (uint, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, errIfMatchRequired
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, errors.New("invalid If-Match")
+	}
+	version, err := strconv.ParseUint(tag, 10, 0)
+	if err != nil {
+		return 0, errors.New("invalid If-Match")
+	}
+	return uint(version), nil
+}
+
+// editError отвечает на ошибку правки. На устаревшую версию — 412 с актуальным состоянием
+// и его ETag, чтобы клиент мог показать чужие изменения без лишнего запроса
+func editError(c *fiber.Ctx, err error, fallback int) error {
+	var staleWishlist *usecase.StaleWishlistError
+	if errors.As(err, &staleWishlist) {
+		setETag(c, staleWishlist.Current.Version)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": err.Error(),
+			"data":  wishlistResponse(staleWishlist.Current),
+		})
+	}
+	var stalePresent *usecase.StalePresentError
+	if errors.As(err, &stalePresent) {
+		setETag(c, stalePresent.Current.Version)
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": err.Error(),
+			"data":  stalePresent.Current,
+		})
+	}
+	return c.Status(errorStatus(err, fallback)).JSON(response.Error(err.Error()))
+}
+
+// viewError отвечает на ошибку просмотра вишлиста: для защищённого паролем — 403 с ID вишлиста,
+// чтобы клиент, открывший короткую ссылку, мог запросить разблокировку
+func viewError(c *fiber.Ctx, err error, fallback int) error {
+	var locked *usecase.WishlistLockedError
+	if errors.As(err, &locked) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":            err.Error(),
+			"passwordRequired": true,
+			"wishlistId":       locked.WishlistID,
+		})
+	}
+	return c.Status(errorStatus(err, fallback)).JSON(response.Error(err.Error()))
+}
+
+func clientInfo(c *fiber.Ctx) usecase.ClientInfo {
+	return usecase.ClientInfo{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+}
+
+// loginError отвечает 429 с Retry-After для заблокированного входа, 403 для забаненного аккаунта
+// и 401 для остальных ошибок
+func loginError(c *fiber.Ctx, err error) error {
+	var lockout *usecase.LockoutError
+	if errors.As(err, &lockout) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(response.Error(err.Error()))
+	}
+	if errors.Is(err, usecase.ErrBanned) {
+		return c.Status(fiber.StatusForbidden).JSON(response.Error(err.Error()))
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+}
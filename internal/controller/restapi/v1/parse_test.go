@@ -19,8 +19,8 @@ import (
 
 func setupParseAppWithUC(pu *MockParseUC) *fiber.App {
 	app := fiber.New()
-	v1.NewRouter(app, testSecret, "localhost", false,
-		&MockUserUC{}, &MockWishlistUC{}, &MockPresentUC{}, &MockUploadUC{}, pu, &MockTemplateUC{})
+	v1.NewRouter(app, testKeys, "localhost", false,
+		&MockUserUC{}, &MockWishlistUC{}, &MockPresentUC{}, &MockUploadUC{}, pu, &MockTemplateUC{}, &MockAdminUC{}, &MockAccountUC{})
 	return app
 }
 
@@ -25,10 +25,11 @@ func (h *presentHandler) getOne(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
 	}
 
-	present, err := h.uc.GetByID(c.Context(), id)
+	present, err := h.uc.GetByID(c.Context(), viewer(c), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(response.Error(err.Error()))
+		return viewError(c, err, fiber.StatusNotFound)
 	}
+	setETag(c, present.Version)
 	return c.JSON(response.Data(present))
 }
 
@@ -38,14 +39,18 @@ func (h *presentHandler) getAll(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
 
-	presents, err := h.uc.GetAllByWishlist(c.Context(), wishlistID)
+	presents, err := h.uc.GetAllByWishlist(c.Context(), viewer(c), wishlistID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+		return viewError(c, err, fiber.StatusInternalServerError)
 	}
 	return c.JSON(response.Data(presents))
 }
 
 func (h *presentHandler) create(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	wishlistID, err := uuid.Parse(c.Params("wishlistId"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
@@ -59,32 +64,45 @@ func (h *presentHandler) create(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("title is required"))
 	}
 
-	present, err := h.uc.Create(c.Context(), wishlistID, input)
+	present, err := h.uc.Create(c.Context(), userID, wishlistID, input)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
 	}
 	return c.Status(fiber.StatusCreated).JSON(response.Data(present))
 }
 
 func (h *presentHandler) update(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
 
 	input, err := h.parsePresentInput(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
 
-	present, err := h.uc.Update(c.Context(), id, input)
+	present, err := h.uc.Update(c.Context(), userID, id, version, input)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+		return editError(c, err, fiber.StatusInternalServerError)
 	}
+	setETag(c, present.Version)
 	return c.JSON(response.Data(present))
 }
 
 func (h *presentHandler) delete(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
@@ -93,21 +111,46 @@ func (h *presentHandler) delete(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
 
-	if err := h.uc.Delete(c.Context(), wishlistID, id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	if err := h.uc.Delete(c.Context(), userID, wishlistID, id, version); err != nil {
+		return editError(c, err, fiber.StatusInternalServerError)
 	}
 	return c.JSON(response.Data(true))
 }
 
+func (h *presentHandler) restore(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
+	}
+	wishlistID, err := uuid.Parse(c.Params("wishlistId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	p, err := h.uc.Restore(c.Context(), userID, wishlistID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(p))
+}
+
 func (h *presentHandler) reserve(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
 	}
 
-	if err := h.uc.Reserve(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+	if err := h.uc.Reserve(c.Context(), viewer(c), id); err != nil {
+		return viewError(c, err, fiber.StatusBadRequest)
 	}
 	return c.JSON(response.Data(true))
 }
@@ -118,8 +161,8 @@ func (h *presentHandler) release(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid present ID"))
 	}
 
-	if err := h.uc.Release(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	if err := h.uc.Release(c.Context(), viewer(c), id); err != nil {
+		return viewError(c, err, fiber.StatusInternalServerError)
 	}
 	return c.JSON(response.Data(true))
 }
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	v1 "main/internal/controller/restapi/v1"
+	"main/internal/entity"
 	"main/internal/usecase"
 )
 
@@ -23,7 +26,7 @@ func setupPresentApp(presentMock usecase.PresentUseCase) *fiber.App {
 	userMock := &MockUserUC{}
 	wishlistMock := &MockWishlistUC{}
 	uploadMock := &MockUploadUC{}
-	v1.NewRouter(app, testSecret, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{})
+	v1.NewRouter(app, testKeys, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{}, &MockAdminUC{}, &MockAccountUC{})
 	return app
 }
 
@@ -32,7 +35,7 @@ func TestReserve_AlreadyReserved(t *testing.T) {
 	app := setupPresentApp(pm)
 
 	pid := uuid.New()
-	pm.On("Reserve", mock.Anything, pid).Return(errors.New("упс... подарок уже был забронирован, пожалуйста перезагрузите страницу"))
+	pm.On("Reserve", mock.Anything, usecase.Viewer{}, pid).Return(errors.New("упс... подарок уже был забронирован, пожалуйста перезагрузите страницу"))
 
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/presents/"+pid.String()+"/reserve", nil)
 	resp, err := app.Test(req)
@@ -49,7 +52,7 @@ func TestReserve_Success(t *testing.T) {
 	app := setupPresentApp(pm)
 
 	pid := uuid.New()
-	pm.On("Reserve", mock.Anything, pid).Return(nil)
+	pm.On("Reserve", mock.Anything, usecase.Viewer{}, pid).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/presents/"+pid.String()+"/reserve", nil)
 	resp, err := app.Test(req)
@@ -87,7 +90,7 @@ func TestDelete_Success(t *testing.T) {
 	pid := uuid.New()
 	wid := uuid.New()
 
-	pm.On("Delete", mock.Anything, wid, pid).Return(nil)
+	pm.On("Delete", mock.Anything, userID, wid, pid, uint(1)).Return(nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -95,6 +98,7 @@ func TestDelete_Success(t *testing.T) {
 		nil,
 	)
 	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
 
 	resp, err := app.Test(req)
 	require.NoError(t, err)
@@ -104,3 +108,61 @@ func TestDelete_Success(t *testing.T) {
 	json.NewDecoder(resp.Body).Decode(&result)
 	assert.Equal(t, true, result["data"])
 }
+
+func TestRestore_Success(t *testing.T) {
+	pm := &MockPresentUC{}
+	app := setupPresentApp(pm)
+
+	userID := uuid.New()
+	pid := uuid.New()
+	wid := uuid.New()
+	pm.On("Restore", mock.Anything, userID, wid, pid).Return(entity.Present{ID: pid, WishlistID: wid}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/presents/"+pid.String()+"/restore", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	pm.AssertExpectations(t)
+}
+
+func TestRestore_WishlistInTrash_NotFound(t *testing.T) {
+	pm := &MockPresentUC{}
+	app := setupPresentApp(pm)
+
+	userID := uuid.New()
+	pid := uuid.New()
+	wid := uuid.New()
+	pm.On("Restore", mock.Anything, userID, wid, pid).Return(entity.Present{}, usecase.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/presents/"+pid.String()+"/restore", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestUpdate_StalePresent(t *testing.T) {
+	pm := &MockPresentUC{}
+	app := setupPresentApp(pm)
+
+	userID, pid := uuid.New(), uuid.New()
+	current := entity.Present{ID: pid, Title: "Чайник", Reserved: true, Version: 2}
+	pm.On("Update", mock.Anything, userID, pid, uint(1), mock.Anything).
+		Return(entity.Present{}, fmt.Errorf("update present: %w", &usecase.StalePresentError{Current: current}))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/presents/"+pid.String(), strings.NewReader("title=Кофеварка"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"1"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get(fiber.HeaderETag))
+
+	var result struct {
+		Data entity.Present `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.True(t, result.Data.Reserved)
+}
@@ -1,5 +1,7 @@
 package request
 
+import "time"
+
 type RegisterRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
@@ -19,3 +21,64 @@ type TelegramAuthRequest struct {
 	AuthDate  int64  `json:"auth_date"`
 	Hash      string `json:"hash"`
 }
+
+// TelegramWebAppAuthRequest — initData из Telegram.WebApp.initData как есть, строкой запроса
+type TelegramWebAppAuthRequest struct {
+	InitData string `json:"initData" validate:"required"`
+}
+
+// SetHandleRequest — пустой handle скрывает публичный профиль
+type SetHandleRequest struct {
+	Handle string `json:"handle"`
+}
+
+type SetPasswordRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type TokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeleteAccountRequest — пароль нужен, если он задан у аккаунта, код — при включённой 2FA
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type CodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFARequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type CreateAccessTokenRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type BanRequest struct {
+	Reason string `json:"reason"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
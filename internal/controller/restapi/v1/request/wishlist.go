@@ -0,0 +1,46 @@
+package request
+
+import "time"
+
+// CloneWishlistRequest — тело необязательно; пустой Title — название оригинала
+type CloneWishlistRequest struct {
+	Title string `json:"title"`
+}
+
+type WishlistProfileRequest struct {
+	Listed bool `json:"listed"`
+}
+
+// WishlistVisibilityRequest — пароль нужен только для visibility=password
+type WishlistVisibilityRequest struct {
+	Visibility string `json:"visibility"`
+	Password   string `json:"password"`
+}
+
+// WishlistSlugRequest — пустой slug убирает адрес, scope: profile (по умолчанию) или global
+type WishlistSlugRequest struct {
+	Slug  string `json:"slug"`
+	Scope string `json:"scope"`
+}
+
+// WishlistEventRequest — time в RFC 3339, null убирает дату; timezone — зона IANA (пусто — UTC);
+// repeat: пусто или yearly
+type WishlistEventRequest struct {
+	Time     time.Time `json:"time"`
+	Timezone string    `json:"timezone"`
+	Repeat   string    `json:"repeat"`
+}
+
+type WishlistUnlockRequest struct {
+	Password string `json:"password"`
+}
+
+// CollaboratorRequest — Handle нужен только при добавлении соавтора
+type CollaboratorRequest struct {
+	Handle string `json:"handle"`
+	Role   string `json:"role"`
+}
+
+type WishlistInviteRequest struct {
+	Role string `json:"role"`
+}
@@ -4,12 +4,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"main/internal/controller/restapi/middleware"
+	"main/internal/entity"
 	"main/internal/usecase"
+	"main/pkg/jwtkeys"
 )
 
 func NewRouter(
 	router fiber.Router,
-	jwtSecret string,
+	jwtKeys *jwtkeys.KeySet,
 	cookieDomain string,
 	secureCookie bool,
 	userUC usecase.UserUseCase,
@@ -18,73 +20,164 @@ func NewRouter(
 	uploadUC usecase.UploadUseCase,
 	parseUC usecase.ParseUseCase,
 	templateUC usecase.TemplateUseCase,
+	adminUC usecase.AdminUseCase,
+	accountUC usecase.AccountUseCase,
 ) {
 	api := router.Group("/api/v1")
 
 	userH := newUserHandler(userUC, uploadUC, cookieDomain, secureCookie)
-	wishlistH := newWishlistHandler(wishlistUC)
+	wishlistH := newWishlistHandler(wishlistUC, cookieDomain, secureCookie)
 	presentH := newPresentHandler(presentUC)
 	uploadH := newUploadHandler(uploadUC)
 	parseH := newParseHandler(parseUC)
 	templateH := newTemplateHandler(templateUC)
+	adminH := newAdminHandler(adminUC)
+	accountH := newAccountHandler(accountUC)
 
 	// Auth (public)
 	auth := api.Group("/auth")
 	auth.Post("/register", userH.register)
 	auth.Post("/login", userH.login)
 	auth.Post("/telegram", userH.authTelegram)
+	auth.Post("/telegram/webapp", userH.authTelegramWebApp)
+	auth.Post("/refresh", userH.refresh)
+	auth.Post("/password/forgot", userH.forgotPassword)
+	auth.Post("/password/reset", userH.resetPassword)
+	auth.Post("/email/verify", userH.verifyEmail)
+	auth.Post("/magic-link", userH.requestMagicLink)
+	auth.Post("/magic-link/verify", userH.loginMagicLink)
+	auth.Post("/mfa", userH.completeMFA)
+	// Logout работает и с истёкшим access-токеном — сессия найдётся по refresh-cookie
+	auth.Post("/logout", middleware.JWTOptional(jwtKeys, userUC), userH.logout)
 
 	// Auth (protected)
 	authProtected := api.Group("/auth")
-	authProtected.Use(middleware.JWTProtected(jwtSecret))
-	authProtected.Get("/me", userH.me)
-	authProtected.Post("/logout", userH.logout)
+	authProtected.Use(middleware.JWTProtected(jwtKeys, userUC))
+	authProtected.Get("/me", middleware.RequireScopes(entity.ScopeProfileRead), userH.me)
+
+	// Публичные маршруты тоже принимают персональные токены — со скоупами, как и защищённые
+	scopes := middleware.RequireScopes
 
 	// Templates (public) — BEFORE protected group
-	api.Get("/templates", middleware.JWTOptional(jwtSecret), templateH.getPublic)
+	api.Get("/templates", middleware.JWTOptional(jwtKeys, userUC), scopes(entity.ScopeTemplatesRead), templateH.getPublic)
+
+	// Wishlists (public) — static routes BEFORE parametric.
+	// Владелец видит свой вишлист, даже если модератор снял его с публикации или сделал приватным;
+	// защищённый паролем вишлист остальные видят после разблокировки
+	optional := middleware.JWTOptional(jwtKeys, userUC)
+	api.Get("/wishlists/s/:shortId", optional, scopes(entity.ScopeWishlistsRead), wishlistH.getByShortID)
+	api.Get("/users/:handle/wishlists/:slug", optional, scopes(entity.ScopeWishlistsRead), wishlistH.getByProfileSlug)
+	api.Get("/wishlists/:id", optional, scopes(entity.ScopeWishlistsRead), wishlistH.getOne)
+	api.Post("/wishlists/:id/unlock", wishlistH.unlock)
+	api.Get("/wishlists/:wishlistId/presents", optional, scopes(entity.ScopePresentsRead), presentH.getAll)
+	api.Put("/presents/:id/reserve", optional, scopes(entity.ScopePresentsWrite), presentH.reserve)
+	api.Put("/presents/:id/release", optional, scopes(entity.ScopePresentsWrite), presentH.release)
 
-	// Wishlists (public) — static routes BEFORE parametric
-	api.Get("/wishlists/s/:shortId", wishlistH.getByShortID)
-	api.Get("/wishlists/:id", wishlistH.getOne)
-	api.Get("/wishlists/:wishlistId/presents", presentH.getAll)
-	api.Put("/presents/:id/reserve", presentH.reserve)
-	api.Put("/presents/:id/release", presentH.release)
+	// Public profiles — GET /users/me пропускается дальше, к защищённой группе
+	api.Get("/users/:handle", userH.getPublicProfile)
 
-	// Protected routes
+	// Protected routes. Каждый маршрут либо требует скоупы персонального токена,
+	// либо закрыт для токенов через sessionOnly
 	protected := api.Group("")
-	protected.Use(middleware.JWTProtected(jwtSecret))
+	protected.Use(middleware.JWTProtected(jwtKeys, userUC))
+	sessionOnly := middleware.SessionOnly()
 
 	// User profile
-	protected.Get("/users/me", userH.getProfile)
-	protected.Patch("/users/me", userH.updateProfile)
+	protected.Get("/users/me", scopes(entity.ScopeProfileRead), userH.getProfile)
+	protected.Patch("/users/me", sessionOnly, userH.updateProfile)
+	protected.Delete("/users/me", sessionOnly, userH.deleteAccount)
+	protected.Put("/users/me/handle", sessionOnly, userH.setHandle)
+	protected.Put("/users/me/password", sessionOnly, userH.setPassword)
+	protected.Post("/users/me/password/change", sessionOnly, userH.changePassword)
+	protected.Post("/users/me/email", sessionOnly, userH.requestEmailVerification)
+	protected.Post("/users/me/2fa/totp", sessionOnly, userH.beginTOTP)
+	protected.Post("/users/me/2fa/totp/confirm", sessionOnly, userH.confirmTOTP)
+	protected.Delete("/users/me/2fa/totp", sessionOnly, userH.disableTOTP)
+	protected.Get("/users/me/identities", sessionOnly, userH.getIdentities)
+	protected.Post("/users/me/identities/telegram", sessionOnly, userH.linkTelegram)
+	protected.Delete("/users/me/identities/:id", sessionOnly, userH.unlinkIdentity)
+
+	// Personal data export — архив собирается в фоне, ссылка на скачивание истекает
+	protected.Post("/users/me/exports", sessionOnly, accountH.requestExport)
+	protected.Get("/users/me/exports", sessionOnly, accountH.getExports)
+	protected.Get("/users/me/exports/:id/download", sessionOnly, accountH.downloadExport)
+
+	// Active sessions
+	protected.Get("/users/me/sessions", sessionOnly, userH.getSessions)
+	protected.Delete("/users/me/sessions", sessionOnly, userH.revokeOtherSessions)
+	protected.Delete("/users/me/sessions/:id", sessionOnly, userH.revokeSession)
+
+	// Personal access tokens
+	protected.Get("/users/me/tokens", sessionOnly, userH.getAccessTokens)
+	protected.Post("/users/me/tokens", sessionOnly, userH.createAccessToken)
+	protected.Delete("/users/me/tokens/:id", sessionOnly, userH.revokeAccessToken)
 
 	// Parse
-	protected.Get("/parse", parseH.parse)
+	protected.Get("/parse", scopes(entity.ScopeParse), parseH.parse)
 
 	// Upload
-	protected.Post("/upload", uploadH.upload)
-	protected.Post("/upload/bulk", uploadH.bulkUpload)
+	protected.Post("/upload", scopes(entity.ScopeUpload), uploadH.upload)
+	protected.Post("/upload/bulk", scopes(entity.ScopeUpload), uploadH.bulkUpload)
 
 	// Wishlists (protected)
-	protected.Get("/wishlists", wishlistH.getAll)
-	protected.Post("/wishlists", wishlistH.create)
-	protected.Post("/wishlists/constructor", wishlistH.createConstructor)
-	protected.Put("/wishlists/:id", wishlistH.update)
-	protected.Put("/wishlists/:id/blocks", wishlistH.updateBlocks)
-	protected.Delete("/wishlists/:id", wishlistH.delete)
+	protected.Get("/wishlists", scopes(entity.ScopeWishlistsRead), wishlistH.getAll)
+	protected.Post("/wishlists", scopes(entity.ScopeWishlistsWrite), wishlistH.create)
+	protected.Post("/wishlists/constructor", scopes(entity.ScopeWishlistsWrite), wishlistH.createConstructor)
+	protected.Put("/wishlists/:id", scopes(entity.ScopeWishlistsWrite), wishlistH.update)
+	protected.Put("/wishlists/:id/blocks", scopes(entity.ScopeWishlistsWrite), wishlistH.updateBlocks)
+	protected.Post("/wishlists/:id/clone", scopes(entity.ScopeWishlistsWrite, entity.ScopePresentsWrite), wishlistH.clone)
+	protected.Put("/wishlists/:id/profile", scopes(entity.ScopeWishlistsWrite), wishlistH.setListedOnProfile)
+	protected.Put("/wishlists/:id/visibility", scopes(entity.ScopeWishlistsWrite), wishlistH.setVisibility)
+	protected.Put("/wishlists/:id/slug", scopes(entity.ScopeWishlistsWrite), wishlistH.setSlug)
+	protected.Put("/wishlists/:id/event", scopes(entity.ScopeWishlistsWrite), wishlistH.setEvent)
+	protected.Delete("/wishlists/:id", scopes(entity.ScopeWishlistsWrite), wishlistH.delete)
+
+	// Revisions — история блоков и настроек; откат сохраняется новой ревизией
+	protected.Get("/wishlists/:id/revisions", scopes(entity.ScopeWishlistsRead), wishlistH.getRevisions)
+	protected.Get("/wishlists/:id/revisions/diff", scopes(entity.ScopeWishlistsRead), wishlistH.diffRevisions)
+	protected.Get("/wishlists/:id/revisions/:number", scopes(entity.ScopeWishlistsRead), wishlistH.getRevision)
+	protected.Post("/wishlists/:id/revisions/:number/restore", scopes(entity.ScopeWishlistsWrite), wishlistH.restoreRevision)
+
+	// Trash — удалённое хранится до окончательной очистки фоновой задачей
+	protected.Get("/trash", scopes(entity.ScopeWishlistsRead, entity.ScopePresentsRead), wishlistH.getTrash)
+	protected.Post("/wishlists/:id/restore", scopes(entity.ScopeWishlistsWrite), wishlistH.restore)
+	protected.Post("/wishlists/:wishlistId/presents/:id/restore", scopes(entity.ScopePresentsWrite), presentH.restore)
+
+	// Collaborators — соавторами управляет владелец, соавтор может выйти сам
+	protected.Get("/wishlists/:id/collaborators", scopes(entity.ScopeWishlistsRead), wishlistH.getCollaborators)
+	protected.Post("/wishlists/:id/collaborators", scopes(entity.ScopeWishlistsWrite), wishlistH.addCollaborator)
+	protected.Put("/wishlists/:id/collaborators/:userId", scopes(entity.ScopeWishlistsWrite), wishlistH.setCollaboratorRole)
+	protected.Delete("/wishlists/:id/collaborators/:userId", scopes(entity.ScopeWishlistsWrite), wishlistH.removeCollaborator)
+	protected.Post("/wishlists/:id/invites", scopes(entity.ScopeWishlistsWrite), wishlistH.createInvite)
+	protected.Post("/wishlists/invites/accept", sessionOnly, wishlistH.acceptInvite)
+	// Приглашение по handle даёт доступ, только когда приглашённый его примет
+	protected.Get("/users/me/collaborations/pending", scopes(entity.ScopeWishlistsRead), wishlistH.getPendingCollaborations)
+	protected.Post("/users/me/collaborations/:wishlistId/accept", sessionOnly, wishlistH.acceptCollaboration)
+	protected.Post("/users/me/collaborations/:wishlistId/decline", sessionOnly, wishlistH.declineCollaboration)
 
 	// Presents (protected)
-	protected.Post("/wishlists/:wishlistId/presents", presentH.create)
-	protected.Get("/presents/:id", presentH.getOne)
-	protected.Put("/presents/:id", presentH.update)
-	protected.Delete("/wishlists/:wishlistId/presents/:id", presentH.delete)
+	protected.Post("/wishlists/:wishlistId/presents", scopes(entity.ScopePresentsWrite), presentH.create)
+	protected.Get("/presents/:id", scopes(entity.ScopePresentsRead), presentH.getOne)
+	protected.Put("/presents/:id", scopes(entity.ScopePresentsWrite), presentH.update)
+	protected.Delete("/wishlists/:wishlistId/presents/:id", scopes(entity.ScopePresentsWrite), presentH.delete)
 
 	// Templates (protected)
-	protected.Get("/templates/my", templateH.getMy)
-	protected.Post("/templates", templateH.create)
-	protected.Patch("/templates/:id", templateH.update)
-	protected.Delete("/templates/:id", templateH.delete)
-	protected.Post("/wishlists/from-template/:id", templateH.createWishlistFromTemplate)
-	protected.Post("/templates/:id/like", templateH.like)
-	protected.Delete("/templates/:id/like", templateH.unlike)
+	protected.Get("/templates/my", scopes(entity.ScopeTemplatesRead), templateH.getMy)
+	protected.Post("/templates", scopes(entity.ScopeTemplatesWrite), templateH.create)
+	protected.Patch("/templates/:id", scopes(entity.ScopeTemplatesWrite), templateH.update)
+	protected.Delete("/templates/:id", scopes(entity.ScopeTemplatesWrite), templateH.delete)
+	protected.Post("/wishlists/from-template/:id", scopes(entity.ScopeTemplatesRead, entity.ScopeWishlistsWrite), templateH.createWishlistFromTemplate)
+	protected.Post("/templates/:id/like", scopes(entity.ScopeTemplatesWrite), templateH.like)
+	protected.Delete("/templates/:id/like", scopes(entity.ScopeTemplatesWrite), templateH.unlike)
+
+	// Admin — только JWT-сессии модераторов; назначать роли могут только администраторы
+	admin := protected.Group("/admin", sessionOnly, middleware.RequireRole(entity.RoleModerator))
+	admin.Get("/users", adminH.listUsers)
+	admin.Post("/users/:id/ban", adminH.ban)
+	admin.Delete("/users/:id/ban", adminH.unban)
+	admin.Put("/users/:id/role", middleware.RequireRole(entity.RoleAdmin), adminH.setRole)
+	admin.Post("/templates/:id/unpublish", adminH.unpublishTemplate)
+	admin.Post("/wishlists/:id/unpublish", adminH.unpublishWishlist)
+	admin.Post("/wishlists/:id/publish", adminH.publishWishlist)
+	admin.Get("/stats", adminH.stats)
 }
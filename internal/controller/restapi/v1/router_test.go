@@ -0,0 +1,480 @@
+package v1_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	v1 "main/internal/controller/restapi/v1"
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// routeCase — один маршрут из v1.NewRouter
+type routeCase struct {
+	method string
+	route  string // шаблон маршрута, как он зарегистрирован в роутере
+	public bool
+}
+
+// allRoutes — полный список маршрутов v1. TestRouter_TableCoversAllRoutes
+// следит, чтобы новые маршруты не оставались без проверки авторизации.
+var allRoutes = []routeCase{
+	{http.MethodPost, "/api/v1/auth/register", true},
+	{http.MethodPost, "/api/v1/auth/login", true},
+	{http.MethodPost, "/api/v1/auth/telegram", true},
+	{http.MethodPost, "/api/v1/auth/telegram/webapp", true},
+	{http.MethodPost, "/api/v1/auth/refresh", true},
+	{http.MethodPost, "/api/v1/auth/password/forgot", true},
+	{http.MethodPost, "/api/v1/auth/password/reset", true},
+	{http.MethodPost, "/api/v1/auth/email/verify", true},
+	{http.MethodPost, "/api/v1/auth/magic-link", true},
+	{http.MethodPost, "/api/v1/auth/magic-link/verify", true},
+	{http.MethodPost, "/api/v1/auth/mfa", true},
+	{http.MethodPost, "/api/v1/auth/logout", true},
+	{http.MethodGet, "/api/v1/auth/me", false},
+	{http.MethodGet, "/api/v1/templates", true},
+	{http.MethodGet, "/api/v1/wishlists/s/:shortId", true},
+	{http.MethodGet, "/api/v1/wishlists/:id", true},
+	{http.MethodPost, "/api/v1/wishlists/:id/unlock", true},
+	{http.MethodGet, "/api/v1/wishlists/:wishlistId/presents", true},
+	{http.MethodPut, "/api/v1/presents/:id/reserve", true},
+	{http.MethodPut, "/api/v1/presents/:id/release", true},
+	{http.MethodGet, "/api/v1/users/:handle/wishlists/:slug", true},
+	{http.MethodGet, "/api/v1/users/:handle", true},
+	{http.MethodGet, "/api/v1/users/me", false},
+	{http.MethodPatch, "/api/v1/users/me", false},
+	{http.MethodDelete, "/api/v1/users/me", false},
+	{http.MethodPut, "/api/v1/users/me/handle", false},
+	{http.MethodPut, "/api/v1/users/me/password", false},
+	{http.MethodPost, "/api/v1/users/me/password/change", false},
+	{http.MethodPost, "/api/v1/users/me/email", false},
+	{http.MethodPost, "/api/v1/users/me/2fa/totp", false},
+	{http.MethodPost, "/api/v1/users/me/2fa/totp/confirm", false},
+	{http.MethodDelete, "/api/v1/users/me/2fa/totp", false},
+	{http.MethodGet, "/api/v1/users/me/identities", false},
+	{http.MethodPost, "/api/v1/users/me/identities/telegram", false},
+	{http.MethodDelete, "/api/v1/users/me/identities/:id", false},
+	{http.MethodPost, "/api/v1/users/me/exports", false},
+	{http.MethodGet, "/api/v1/users/me/exports", false},
+	{http.MethodGet, "/api/v1/users/me/exports/:id/download", false},
+	{http.MethodGet, "/api/v1/users/me/sessions", false},
+	{http.MethodDelete, "/api/v1/users/me/sessions", false},
+	{http.MethodDelete, "/api/v1/users/me/sessions/:id", false},
+	{http.MethodGet, "/api/v1/users/me/tokens", false},
+	{http.MethodPost, "/api/v1/users/me/tokens", false},
+	{http.MethodDelete, "/api/v1/users/me/tokens/:id", false},
+	{http.MethodGet, "/api/v1/parse", false},
+	{http.MethodPost, "/api/v1/upload", false},
+	{http.MethodPost, "/api/v1/upload/bulk", false},
+	{http.MethodGet, "/api/v1/wishlists", false},
+	{http.MethodPost, "/api/v1/wishlists", false},
+	{http.MethodPost, "/api/v1/wishlists/constructor", false},
+	{http.MethodPut, "/api/v1/wishlists/:id", false},
+	{http.MethodPost, "/api/v1/wishlists/:id/clone", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/blocks", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/profile", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/visibility", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/slug", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/event", false},
+	{http.MethodGet, "/api/v1/wishlists/:id/revisions", false},
+	{http.MethodGet, "/api/v1/wishlists/:id/revisions/diff", false},
+	{http.MethodGet, "/api/v1/wishlists/:id/revisions/:number", false},
+	{http.MethodPost, "/api/v1/wishlists/:id/revisions/:number/restore", false},
+	{http.MethodDelete, "/api/v1/wishlists/:id", false},
+	{http.MethodGet, "/api/v1/trash", false},
+	{http.MethodPost, "/api/v1/wishlists/:id/restore", false},
+	{http.MethodPost, "/api/v1/wishlists/:wishlistId/presents/:id/restore", false},
+	{http.MethodGet, "/api/v1/wishlists/:id/collaborators", false},
+	{http.MethodPost, "/api/v1/wishlists/:id/collaborators", false},
+	{http.MethodPut, "/api/v1/wishlists/:id/collaborators/:userId", false},
+	{http.MethodDelete, "/api/v1/wishlists/:id/collaborators/:userId", false},
+	{http.MethodPost, "/api/v1/wishlists/:id/invites", false},
+	{http.MethodPost, "/api/v1/wishlists/invites/accept", false},
+	{http.MethodGet, "/api/v1/users/me/collaborations/pending", false},
+	{http.MethodPost, "/api/v1/users/me/collaborations/:wishlistId/accept", false},
+	{http.MethodPost, "/api/v1/users/me/collaborations/:wishlistId/decline", false},
+	{http.MethodPost, "/api/v1/wishlists/:wishlistId/presents", false},
+	{http.MethodGet, "/api/v1/presents/:id", false},
+	{http.MethodPut, "/api/v1/presents/:id", false},
+	{http.MethodDelete, "/api/v1/wishlists/:wishlistId/presents/:id", false},
+	{http.MethodGet, "/api/v1/templates/my", false},
+	{http.MethodPost, "/api/v1/templates", false},
+	{http.MethodPatch, "/api/v1/templates/:id", false},
+	{http.MethodDelete, "/api/v1/templates/:id", false},
+	{http.MethodPost, "/api/v1/wishlists/from-template/:id", false},
+	{http.MethodPost, "/api/v1/templates/:id/like", false},
+	{http.MethodDelete, "/api/v1/templates/:id/like", false},
+	{http.MethodGet, "/api/v1/admin/users", false},
+	{http.MethodPost, "/api/v1/admin/users/:id/ban", false},
+	{http.MethodDelete, "/api/v1/admin/users/:id/ban", false},
+	{http.MethodPut, "/api/v1/admin/users/:id/role", false},
+	{http.MethodPost, "/api/v1/admin/templates/:id/unpublish", false},
+	{http.MethodPost, "/api/v1/admin/wishlists/:id/unpublish", false},
+	{http.MethodPost, "/api/v1/admin/wishlists/:id/publish", false},
+	{http.MethodGet, "/api/v1/admin/stats", false},
+}
+
+// fillRoute подставляет UUID вместо параметров маршрута
+func fillRoute(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") {
+			parts[i] = uuid.New().String()
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+type routerMocks struct {
+	user     *MockUserUC
+	wishlist *MockWishlistUC
+	present  *MockPresentUC
+	upload   *MockUploadUC
+	parse    *MockParseUC
+	template *MockTemplateUC
+	admin    *MockAdminUC
+	account  *MockAccountUC
+}
+
+func setupRouterApp() (*fiber.App, routerMocks) {
+	m := routerMocks{
+		user:     &MockUserUC{},
+		wishlist: &MockWishlistUC{},
+		present:  &MockPresentUC{},
+		upload:   &MockUploadUC{},
+		parse:    &MockParseUC{},
+		template: &MockTemplateUC{},
+		admin:    &MockAdminUC{},
+		account:  &MockAccountUC{},
+	}
+	app := fiber.New()
+	v1.NewRouter(app, testKeys, "", false, m.user, m.wishlist, m.present, m.upload, m.parse, m.template, m.admin, m.account)
+	return app, m
+}
+
+func TestRouter_TableCoversAllRoutes(t *testing.T) {
+	app, _ := setupRouterApp()
+
+	known := make(map[string]bool, len(allRoutes))
+	for _, rc := range allRoutes {
+		known[rc.method+" "+rc.route] = true
+	}
+
+	for _, r := range app.GetRoutes(true) {
+		if r.Method == http.MethodHead {
+			continue
+		}
+		assert.True(t, known[r.Method+" "+r.Path], "route %s %s is missing from allRoutes", r.Method, r.Path)
+	}
+}
+
+func TestRouter_AuthRequirement(t *testing.T) {
+	for _, rc := range allRoutes {
+		t.Run(rc.method+" "+rc.route, func(t *testing.T) {
+			app, m := setupRouterApp()
+			// Публичные маршруты доходят до use case — отвечаем ошибкой, статус нам не важен
+			m.wishlist.On("GetByShortID", mock.Anything, mock.Anything, mock.Anything).Return(entity.Wishlist{}, usecase.ErrNotFound).Maybe()
+			m.wishlist.On("GetByID", mock.Anything, mock.Anything, mock.Anything).Return(entity.Wishlist{}, usecase.ErrNotFound).Maybe()
+			m.wishlist.On("GetByProfileSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(usecase.ProfileWishlist{}, usecase.ErrNotFound).Maybe()
+			m.present.On("GetAllByWishlist", mock.Anything, mock.Anything, mock.Anything).Return([]entity.Present{}, nil).Maybe()
+			m.present.On("Reserve", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			m.present.On("Release", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			m.wishlist.On("Unlock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(usecase.WishlistUnlock{}, usecase.ErrNotFound).Maybe()
+			m.template.On("GetPublic", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return([]entity.TemplateWithAuthor{}, false, nil).Maybe()
+			m.user.On("Register", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, usecase.ErrForbidden).Maybe()
+			m.user.On("Login", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, usecase.ErrForbidden).Maybe()
+			m.user.On("AuthenticateTelegram", mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, usecase.ErrForbidden).Maybe()
+			m.user.On("Logout", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			m.user.On("RequestPasswordReset", mock.Anything, mock.Anything).Return(nil).Maybe()
+			m.user.On("ResetPassword", mock.Anything, mock.Anything, mock.Anything).Return(usecase.ErrInvalidToken).Maybe()
+			m.user.On("VerifyEmail", mock.Anything, mock.Anything).Return(entity.User{}, usecase.ErrInvalidToken).Maybe()
+			m.user.On("RequestMagicLink", mock.Anything, mock.Anything).Return(nil).Maybe()
+			m.user.On("LoginWithMagicLink", mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, usecase.ErrInvalidToken).Maybe()
+			m.user.On("CompleteMFA", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, usecase.ErrInvalidToken).Maybe()
+			m.user.On("GetPublicProfile", mock.Anything, mock.Anything).Return(usecase.PublicProfile{}, usecase.ErrNotFound).Maybe()
+
+			// Без токена
+			req := httptest.NewRequest(rc.method, fillRoute(rc.route), bytes.NewBufferString(`{"username":"a","password":"b"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+
+			if rc.public {
+				assert.NotEqual(t, "Missing or malformed JWT", string(body))
+				return
+			}
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, "Missing or malformed JWT", string(body))
+
+			// С токеном, подписанным чужим ключом
+			req = httptest.NewRequest(rc.method, fillRoute(rc.route), nil)
+			req.Header.Set("Authorization", "Bearer "+makeTestTokenWithSecret(uuid.New(), "another-secret"))
+			resp, err = app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func TestRouter_AccessTokenWithoutScopesIsForbidden(t *testing.T) {
+	const raw = "pat_noscopes"
+	for _, rc := range allRoutes {
+		if rc.public {
+			continue
+		}
+		t.Run(rc.method+" "+rc.route, func(t *testing.T) {
+			app, m := setupRouterApp()
+			m.user.AccessTokens = map[string]entity.AccessToken{raw: {ID: uuid.New(), UserID: uuid.New()}}
+
+			// Каждый защищённый маршрут должен требовать скоуп или быть sessionOnly
+			req := httptest.NewRequest(rc.method, fillRoute(rc.route), nil)
+			req.Header.Set("Authorization", "Bearer "+raw)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		})
+	}
+}
+
+func TestRouter_AccessTokenScopes(t *testing.T) {
+	app, m := setupRouterApp()
+	userID := uuid.New()
+	m.user.AccessTokens = map[string]entity.AccessToken{
+		"pat_reader": {ID: uuid.New(), UserID: userID, Scopes: []string{entity.ScopeWishlistsRead}},
+	}
+	m.wishlist.On("GetAllByUser", mock.Anything, userID).Return([]entity.Wishlist{}, nil)
+
+	send := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, send(http.MethodGet, "/api/v1/wishlists", "pat_reader"))
+	assert.Equal(t, fiber.StatusForbidden, send(http.MethodPost, "/api/v1/wishlists", "pat_reader"))
+	assert.Equal(t, fiber.StatusUnauthorized, send(http.MethodGet, "/api/v1/wishlists", "pat_unknown"))
+	m.wishlist.AssertExpectations(t)
+}
+
+func TestRouter_AccessTokenOnPublicRoutes(t *testing.T) {
+	app, m := setupRouterApp()
+	userID := uuid.New()
+	wid := uuid.New()
+	m.user.AccessTokens = map[string]entity.AccessToken{
+		"pat_reader": {ID: uuid.New(), UserID: userID, Scopes: []string{entity.ScopeWishlistsRead}},
+	}
+	// Владелец, пришедший с токеном, должен быть узнан — иначе свой приватный вишлист ему не виден
+	m.wishlist.On("GetByID", mock.Anything, mock.MatchedBy(func(v usecase.Viewer) bool { return v.UserID == userID }), wid).
+		Return(entity.Wishlist{ID: wid, UserID: userID}, nil)
+
+	send := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, send("/api/v1/wishlists/"+wid.String(), "pat_reader"))
+	assert.Equal(t, fiber.StatusForbidden, send("/api/v1/wishlists/"+wid.String()+"/presents", "pat_reader"))
+	assert.Equal(t, fiber.StatusUnauthorized, send("/api/v1/wishlists/"+wid.String(), "pat_unknown"))
+	m.wishlist.AssertExpectations(t)
+}
+
+func TestRouter_AdminRequiresRole(t *testing.T) {
+	send := func(app *fiber.App, method, path, token, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	targetPath := "/api/v1/admin/users/" + uuid.New().String() + "/role"
+
+	t.Run("user", func(t *testing.T) {
+		app, m := setupRouterApp()
+		assert.Equal(t, fiber.StatusForbidden, send(app, http.MethodGet, "/api/v1/admin/stats", makeTestTokenWithRole(uuid.New(), entity.RoleUser), ""))
+		assert.Equal(t, fiber.StatusForbidden, send(app, http.MethodGet, "/api/v1/admin/stats", makeTestToken(uuid.New()), ""))
+		m.admin.AssertNotCalled(t, "Stats", mock.Anything, mock.Anything)
+	})
+
+	t.Run("moderator", func(t *testing.T) {
+		app, m := setupRouterApp()
+		modID := uuid.New()
+		m.admin.On("Stats", mock.Anything, modID).Return(entity.InstanceStats{Users: 3}, nil)
+
+		token := makeTestTokenWithRole(modID, entity.RoleModerator)
+		assert.Equal(t, fiber.StatusOK, send(app, http.MethodGet, "/api/v1/admin/stats", token, ""))
+		assert.Equal(t, fiber.StatusForbidden, send(app, http.MethodPut, targetPath, token, `{"role":"admin"}`))
+		m.admin.AssertExpectations(t)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		app, m := setupRouterApp()
+		adminID := uuid.New()
+		m.admin.On("SetRole", mock.Anything, adminID, mock.Anything, entity.RoleModerator).
+			Return(entity.User{ID: uuid.New(), Role: entity.RoleModerator}, nil)
+
+		token := makeTestTokenWithRole(adminID, entity.RoleAdmin)
+		assert.Equal(t, fiber.StatusOK, send(app, http.MethodPut, targetPath, token, `{"role":"moderator"}`))
+		m.admin.AssertExpectations(t)
+	})
+}
+
+func TestRouter_AdminUserResponseHidesSecrets(t *testing.T) {
+	app, m := setupRouterApp()
+	modID := uuid.New()
+	m.admin.On("ListUsers", mock.Anything, modID, "ali", 1).Return(usecase.UserPage{
+		Users: []entity.User{{ID: uuid.New(), Username: "alice", Password: "hash", TOTPSecret: "SECRET", Role: entity.RoleUser}},
+		Total: 1,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?q=ali", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestTokenWithRole(modID, entity.RoleModerator))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"username":"alice"`)
+	assert.NotContains(t, string(body), "hash")
+	assert.NotContains(t, string(body), "SECRET")
+}
+
+func TestRouter_OwnershipErrors(t *testing.T) {
+	type mutatingRoute struct {
+		name   string
+		method string
+		path   func(wid, pid uuid.UUID) string
+		body   string
+		ctype  string
+		expect func(m routerMocks, userID, wid, pid uuid.UUID, err error)
+		okCode int
+	}
+
+	formCType := "application/x-www-form-urlencoded"
+	routes := []mutatingRoute{
+		{
+			name:   "update wishlist",
+			method: http.MethodPut,
+			path:   func(wid, _ uuid.UUID) string { return "/api/v1/wishlists/" + wid.String() },
+			body:   "title=New",
+			ctype:  formCType,
+			expect: func(m routerMocks, userID, wid, _ uuid.UUID, err error) {
+				m.wishlist.On("Update", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{ID: wid}, err)
+			},
+			okCode: fiber.StatusOK,
+		},
+		{
+			name:   "update wishlist blocks",
+			method: http.MethodPut,
+			path:   func(wid, _ uuid.UUID) string { return "/api/v1/wishlists/" + wid.String() + "/blocks" },
+			body:   `[{"type":"text","row":0,"col":0,"colSpan":1}]`,
+			ctype:  "application/json",
+			expect: func(m routerMocks, userID, wid, _ uuid.UUID, err error) {
+				m.wishlist.On("UpdateBlocks", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{ID: wid}, err)
+			},
+			okCode: fiber.StatusOK,
+		},
+		{
+			name:   "delete wishlist",
+			method: http.MethodDelete,
+			path:   func(wid, _ uuid.UUID) string { return "/api/v1/wishlists/" + wid.String() },
+			expect: func(m routerMocks, userID, wid, _ uuid.UUID, err error) {
+				m.wishlist.On("Delete", mock.Anything, userID, wid, uint(3)).Return(err)
+			},
+			okCode: fiber.StatusOK,
+		},
+		{
+			name:   "create present",
+			method: http.MethodPost,
+			path:   func(wid, _ uuid.UUID) string { return "/api/v1/wishlists/" + wid.String() + "/presents" },
+			body:   "title=Gift",
+			ctype:  formCType,
+			expect: func(m routerMocks, userID, wid, _ uuid.UUID, err error) {
+				m.present.On("Create", mock.Anything, userID, wid, mock.Anything).Return(entity.Present{}, err)
+			},
+			okCode: fiber.StatusCreated,
+		},
+		{
+			name:   "update present",
+			method: http.MethodPut,
+			path:   func(_, pid uuid.UUID) string { return "/api/v1/presents/" + pid.String() },
+			body:   "title=Gift",
+			ctype:  formCType,
+			expect: func(m routerMocks, userID, _, pid uuid.UUID, err error) {
+				m.present.On("Update", mock.Anything, userID, pid, uint(3), mock.Anything).Return(entity.Present{ID: pid}, err)
+			},
+			okCode: fiber.StatusOK,
+		},
+		{
+			name:   "delete present",
+			method: http.MethodDelete,
+			path: func(wid, pid uuid.UUID) string {
+				return "/api/v1/wishlists/" + wid.String() + "/presents/" + pid.String()
+			},
+			expect: func(m routerMocks, userID, wid, pid uuid.UUID, err error) {
+				m.present.On("Delete", mock.Anything, userID, wid, pid, uint(3)).Return(err)
+			},
+			okCode: fiber.StatusOK,
+		},
+	}
+
+	outcomes := []struct {
+		name string
+		err  error
+		code int // 0 — код успеха маршрута
+	}{
+		{name: "owner", err: nil},
+		{name: "forbidden", err: usecase.ErrForbidden, code: fiber.StatusForbidden},
+		{name: "not found", err: fmt.Errorf("wishlist %w", usecase.ErrNotFound), code: fiber.StatusNotFound},
+	}
+
+	for _, rt := range routes {
+		for _, oc := range outcomes {
+			t.Run(rt.name+"/"+oc.name, func(t *testing.T) {
+				app, m := setupRouterApp()
+				userID, wid, pid := uuid.New(), uuid.New(), uuid.New()
+				rt.expect(m, userID, wid, pid, oc.err)
+
+				var body io.Reader
+				if rt.body != "" {
+					body = bytes.NewBufferString(rt.body)
+				}
+				req := httptest.NewRequest(rt.method, rt.path(wid, pid), body)
+				req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+				req.Header.Set("If-Match", `"3"`)
+				if rt.ctype != "" {
+					req.Header.Set("Content-Type", rt.ctype)
+				}
+
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				want := oc.code
+				if want == 0 {
+					want = rt.okCode
+				}
+				assert.Equal(t, want, resp.StatusCode)
+				m.wishlist.AssertExpectations(t)
+				m.present.AssertExpectations(t)
+			})
+		}
+	}
+}
@@ -22,9 +22,9 @@ import (
 
 func setupTemplateApp(tm *MockTemplateUC) *fiber.App {
 	app := fiber.New()
-	v1.NewRouter(app, testSecret, "", false,
+	v1.NewRouter(app, testKeys, "", false,
 		&MockUserUC{}, &MockWishlistUC{}, &MockPresentUC{}, &MockUploadUC{}, &MockParseUC{},
-		tm,
+		tm, &MockAdminUC{}, &MockAccountUC{},
 	)
 	return app
 }
@@ -45,7 +45,7 @@ func TestGetPublicTemplates_Success(t *testing.T) {
 			UserDisplayName: "Никита",
 		},
 	}
-	tm.On("GetPublic", mock.Anything, 0, "").Return(templates, "", nil)
+	tm.On("GetPublic", mock.Anything, 0, 1, (*uuid.UUID)(nil)).Return(templates, false, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/templates", nil)
 	resp, err := app.Test(req)
@@ -10,36 +10,67 @@ import (
 
 	"main/internal/entity"
 	"main/internal/usecase"
+	"main/pkg/jwtkeys"
 )
 
 const testSecret = "test-jwt-secret"
 
+var testKeys = jwtkeys.NewHMAC(testSecret)
+
 func makeTestToken(userID uuid.UUID) string {
-	claims := jwt.MapClaims{
+	return makeTestTokenWithSecret(userID, testSecret)
+}
+
+func makeTestTokenWithSecret(userID uuid.UUID, secret string) string {
+	return signTestClaims(jwt.MapClaims{
 		"id":  userID.String(),
+		"sid": uuid.New().String(),
 		"exp": time.Now().Add(time.Hour).Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, _ := token.SignedString([]byte(testSecret))
+	}, secret)
+}
+
+func makeTestTokenWithRole(userID uuid.UUID, role string) string {
+	return signTestClaims(jwt.MapClaims{
+		"id":   userID.String(),
+		"sid":  uuid.New().String(),
+		"role": role,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}, testSecret)
+}
+
+func signTestClaims(claims jwt.MapClaims, secret string) string {
+	signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
 	return signed
 }
 
 // MockUserUC
 
-type MockUserUC struct{ mock.Mock }
+type MockUserUC struct {
+	mock.Mock
+	// SessionErr — результат ValidateSession; вызывается middleware на каждом
+	// защищённом запросе, поэтому не требует явной настройки ожиданий
+	SessionErr error
+	// AccessTokens — персональные токены, которые принимает AuthenticateAccessToken
+	AccessTokens map[string]entity.AccessToken
+}
+
+func (m *MockUserUC) Register(ctx context.Context, username, password string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, username, password, client)
+	return args.Get(0).(usecase.AuthResult), args.Error(1)
+}
 
-func (m *MockUserUC) Register(ctx context.Context, username, password string) (usecase.AuthResult, error) {
-	args := m.Called(ctx, username, password)
+func (m *MockUserUC) Login(ctx context.Context, login, password string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, login, password, client)
 	return args.Get(0).(usecase.AuthResult), args.Error(1)
 }
 
-func (m *MockUserUC) Login(ctx context.Context, username, password string) (usecase.AuthResult, error) {
-	args := m.Called(ctx, username, password)
+func (m *MockUserUC) AuthenticateTelegram(ctx context.Context, input usecase.TelegramAuthInput, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, input, client)
 	return args.Get(0).(usecase.AuthResult), args.Error(1)
 }
 
-func (m *MockUserUC) AuthenticateTelegram(ctx context.Context, input usecase.TelegramAuthInput) (usecase.AuthResult, error) {
-	args := m.Called(ctx, input)
+func (m *MockUserUC) AuthenticateTelegramWebApp(ctx context.Context, initData string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, initData, client)
 	return args.Get(0).(usecase.AuthResult), args.Error(1)
 }
 
@@ -53,11 +84,152 @@ func (m *MockUserUC) GetProfile(ctx context.Context, userID uuid.UUID) (entity.U
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUserUC) SetHandle(ctx context.Context, userID uuid.UUID, handle string) (entity.User, error) {
+	args := m.Called(ctx, userID, handle)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUserUC) GetPublicProfile(ctx context.Context, handle string) (usecase.PublicProfile, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(usecase.PublicProfile), args.Error(1)
+}
+
 func (m *MockUserUC) UpdateProfile(ctx context.Context, userID uuid.UUID, input usecase.UpdateProfileInput) (entity.User, error) {
 	args := m.Called(ctx, userID, input)
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUserUC) Refresh(ctx context.Context, refreshToken string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, refreshToken, client)
+	return args.Get(0).(usecase.AuthResult), args.Error(1)
+}
+
+func (m *MockUserUC) Logout(ctx context.Context, sessionID uuid.UUID, refreshToken string) error {
+	args := m.Called(ctx, sessionID, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) GetIdentities(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserUC) LinkTelegram(ctx context.Context, userID uuid.UUID, input usecase.TelegramAuthInput) (entity.UserIdentity, error) {
+	args := m.Called(ctx, userID, input)
+	return args.Get(0).(entity.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserUC) SetPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) UnlinkIdentity(ctx context.Context, userID, identityID uuid.UUID) error {
+	args := m.Called(ctx, userID, identityID)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string) error {
+	args := m.Called(ctx, userID, sessionID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) RequestEmailVerification(ctx context.Context, userID uuid.UUID, email string) error {
+	args := m.Called(ctx, userID, email)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) VerifyEmail(ctx context.Context, token string) (entity.User, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUserUC) RequestMagicLink(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) LoginWithMagicLink(ctx context.Context, token string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, token, client)
+	return args.Get(0).(usecase.AuthResult), args.Error(1)
+}
+
+func (m *MockUserUC) CompleteMFA(ctx context.Context, mfaToken, code string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	args := m.Called(ctx, mfaToken, code, client)
+	return args.Get(0).(usecase.AuthResult), args.Error(1)
+}
+
+func (m *MockUserUC) BeginTOTPEnrollment(ctx context.Context, userID uuid.UUID) (usecase.TOTPEnrollment, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(usecase.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockUserUC) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserUC) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) ValidateSession(_ context.Context, _ uuid.UUID) error {
+	return m.SessionErr
+}
+
+func (m *MockUserUC) DeleteAccount(ctx context.Context, userID, sessionID uuid.UUID, input usecase.DeleteAccountInput) (time.Time, error) {
+	args := m.Called(ctx, userID, sessionID, input)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockUserUC) GetSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Session), args.Error(1)
+}
+
+func (m *MockUserUC) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	args := m.Called(ctx, userID, currentSessionID)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) CreateAccessToken(ctx context.Context, userID uuid.UUID, input usecase.CreateAccessTokenInput) (usecase.CreatedAccessToken, error) {
+	args := m.Called(ctx, userID, input)
+	return args.Get(0).(usecase.CreatedAccessToken), args.Error(1)
+}
+
+func (m *MockUserUC) GetAccessTokens(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.AccessToken), args.Error(1)
+}
+
+func (m *MockUserUC) RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	args := m.Called(ctx, userID, tokenID)
+	return args.Error(0)
+}
+
+func (m *MockUserUC) AuthenticateAccessToken(_ context.Context, token string) (entity.AccessToken, error) {
+	if t, ok := m.AccessTokens[token]; ok {
+		return t, nil
+	}
+	return entity.AccessToken{}, usecase.ErrInvalidAccessToken
+}
+
 // MockWishlistUC
 
 type MockWishlistUC struct{ mock.Mock }
@@ -72,13 +244,13 @@ func (m *MockWishlistUC) CreateConstructor(ctx context.Context, userID uuid.UUID
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
-func (m *MockWishlistUC) GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
-	args := m.Called(ctx, id)
+func (m *MockWishlistUC) GetByID(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, viewer, id)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
-func (m *MockWishlistUC) GetByShortID(ctx context.Context, shortID string) (entity.Wishlist, error) {
-	args := m.Called(ctx, shortID)
+func (m *MockWishlistUC) GetByShortID(ctx context.Context, viewer usecase.Viewer, shortID string) (entity.Wishlist, error) {
+	args := m.Called(ctx, viewer, shortID)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
@@ -87,18 +259,133 @@ func (m *MockWishlistUC) GetAllByUser(ctx context.Context, userID uuid.UUID) ([]
 	return args.Get(0).([]entity.Wishlist), args.Error(1)
 }
 
-func (m *MockWishlistUC) Update(ctx context.Context, id uuid.UUID, input usecase.CreateWishlistInput) (entity.Wishlist, error) {
-	args := m.Called(ctx, id, input)
+func (m *MockWishlistUC) Update(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.CreateWishlistInput) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, input)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) UpdateBlocks(ctx context.Context, userID, id uuid.UUID, version uint, blocks []entity.Block) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, blocks)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
-func (m *MockWishlistUC) UpdateBlocks(ctx context.Context, id uuid.UUID, blocks []entity.Block) (entity.Wishlist, error) {
-	args := m.Called(ctx, id, blocks)
+func (m *MockWishlistUC) SetListedOnProfile(ctx context.Context, userID, id uuid.UUID, version uint, listed bool) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, listed)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
-func (m *MockWishlistUC) Delete(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockWishlistUC) GetByProfileSlug(ctx context.Context, viewer usecase.Viewer, handle, slug string) (usecase.ProfileWishlist, error) {
+	args := m.Called(ctx, viewer, handle, slug)
+	return args.Get(0).(usecase.ProfileWishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) GetRevisions(ctx context.Context, userID, id uuid.UUID) ([]entity.WishlistRevision, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).([]entity.WishlistRevision), args.Error(1)
+}
+
+func (m *MockWishlistUC) GetRevision(ctx context.Context, userID, id uuid.UUID, number int) (entity.WishlistRevision, error) {
+	args := m.Called(ctx, userID, id, number)
+	return args.Get(0).(entity.WishlistRevision), args.Error(1)
+}
+
+func (m *MockWishlistUC) DiffRevisions(ctx context.Context, userID, id uuid.UUID, from, to int) (entity.RevisionDiff, error) {
+	args := m.Called(ctx, userID, id, from, to)
+	return args.Get(0).(entity.RevisionDiff), args.Error(1)
+}
+
+func (m *MockWishlistUC) RestoreRevision(ctx context.Context, userID, id uuid.UUID, version uint, number int) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, number)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) SetEvent(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetEventInput) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, input)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) ArchivePastEvents(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWishlistUC) SetSlug(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetSlugInput) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, input)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) SetVisibility(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetVisibilityInput) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, version, input)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) Unlock(ctx context.Context, id uuid.UUID, password string, client usecase.ClientInfo) (usecase.WishlistUnlock, error) {
+	args := m.Called(ctx, id, password, client)
+	return args.Get(0).(usecase.WishlistUnlock), args.Error(1)
+}
+
+func (m *MockWishlistUC) Delete(ctx context.Context, userID, id uuid.UUID, version uint) error {
+	args := m.Called(ctx, userID, id, version)
+	return args.Error(0)
+}
+
+func (m *MockWishlistUC) Clone(ctx context.Context, userID, id uuid.UUID, title string) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id, title)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) GetTrash(ctx context.Context, userID uuid.UUID) (usecase.Trash, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(usecase.Trash), args.Error(1)
+}
+
+func (m *MockWishlistUC) Restore(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) GetCollaborators(ctx context.Context, userID, id uuid.UUID) ([]entity.Collaborator, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).([]entity.Collaborator), args.Error(1)
+}
+
+func (m *MockWishlistUC) AddCollaborator(ctx context.Context, userID, id uuid.UUID, handle, role string) (entity.Collaborator, error) {
+	args := m.Called(ctx, userID, id, handle, role)
+	return args.Get(0).(entity.Collaborator), args.Error(1)
+}
+
+func (m *MockWishlistUC) SetCollaboratorRole(ctx context.Context, userID, id, collaboratorID uuid.UUID, role string) (entity.Collaborator, error) {
+	args := m.Called(ctx, userID, id, collaboratorID, role)
+	return args.Get(0).(entity.Collaborator), args.Error(1)
+}
+
+func (m *MockWishlistUC) RemoveCollaborator(ctx context.Context, userID, id, collaboratorID uuid.UUID) error {
+	args := m.Called(ctx, userID, id, collaboratorID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistUC) CreateInvite(ctx context.Context, userID, id uuid.UUID, role string) (usecase.WishlistInviteLink, error) {
+	args := m.Called(ctx, userID, id, role)
+	return args.Get(0).(usecase.WishlistInviteLink), args.Error(1)
+}
+
+func (m *MockWishlistUC) AcceptInvite(ctx context.Context, userID uuid.UUID, token string) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, token)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) GetPendingCollaborations(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) AcceptCollaboration(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistUC) DeclineCollaboration(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
@@ -106,38 +393,43 @@ func (m *MockWishlistUC) Delete(ctx context.Context, id uuid.UUID) error {
 
 type MockPresentUC struct{ mock.Mock }
 
-func (m *MockPresentUC) Create(ctx context.Context, wishlistID uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
-	args := m.Called(ctx, wishlistID, input)
+func (m *MockPresentUC) Create(ctx context.Context, userID, wishlistID uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
+	args := m.Called(ctx, userID, wishlistID, input)
 	return args.Get(0).(entity.Present), args.Error(1)
 }
 
-func (m *MockPresentUC) GetByID(ctx context.Context, id uuid.UUID) (entity.Present, error) {
-	args := m.Called(ctx, id)
+func (m *MockPresentUC) GetByID(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) (entity.Present, error) {
+	args := m.Called(ctx, viewer, id)
 	return args.Get(0).(entity.Present), args.Error(1)
 }
 
-func (m *MockPresentUC) GetAllByWishlist(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error) {
-	args := m.Called(ctx, wishlistID)
+func (m *MockPresentUC) GetAllByWishlist(ctx context.Context, viewer usecase.Viewer, wishlistID uuid.UUID) ([]entity.Present, error) {
+	args := m.Called(ctx, viewer, wishlistID)
 	return args.Get(0).([]entity.Present), args.Error(1)
 }
 
-func (m *MockPresentUC) Update(ctx context.Context, id uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
-	args := m.Called(ctx, id, input)
+func (m *MockPresentUC) Update(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.CreatePresentInput) (entity.Present, error) {
+	args := m.Called(ctx, userID, id, version, input)
 	return args.Get(0).(entity.Present), args.Error(1)
 }
 
-func (m *MockPresentUC) Delete(ctx context.Context, wishlistID, id uuid.UUID) error {
-	args := m.Called(ctx, wishlistID, id)
+func (m *MockPresentUC) Delete(ctx context.Context, userID, wishlistID, id uuid.UUID, version uint) error {
+	args := m.Called(ctx, userID, wishlistID, id, version)
 	return args.Error(0)
 }
 
-func (m *MockPresentUC) Reserve(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockPresentUC) Restore(ctx context.Context, userID, wishlistID, id uuid.UUID) (entity.Present, error) {
+	args := m.Called(ctx, userID, wishlistID, id)
+	return args.Get(0).(entity.Present), args.Error(1)
+}
+
+func (m *MockPresentUC) Reserve(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) error {
+	args := m.Called(ctx, viewer, id)
 	return args.Error(0)
 }
 
-func (m *MockPresentUC) Release(ctx context.Context, id uuid.UUID) error {
-	args := m.Called(ctx, id)
+func (m *MockPresentUC) Release(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) error {
+	args := m.Called(ctx, viewer, id)
 	return args.Error(0)
 }
 
@@ -178,9 +470,9 @@ func (m *MockTemplateUC) GetAllByUser(ctx context.Context, userID uuid.UUID) ([]
 	return args.Get(0).([]entity.Template), args.Error(1)
 }
 
-func (m *MockTemplateUC) GetPublic(ctx context.Context, limit int, cursor string) ([]entity.TemplateWithAuthor, string, error) {
-	args := m.Called(ctx, limit, cursor)
-	return args.Get(0).([]entity.TemplateWithAuthor), args.Get(1).(string), args.Error(2)
+func (m *MockTemplateUC) GetPublic(ctx context.Context, limit, page int, userID *uuid.UUID) ([]entity.TemplateWithAuthor, bool, error) {
+	args := m.Called(ctx, limit, page, userID)
+	return args.Get(0).([]entity.TemplateWithAuthor), args.Bool(1), args.Error(2)
 }
 
 func (m *MockTemplateUC) Update(ctx context.Context, id uuid.UUID, userID uuid.UUID, input usecase.UpdateTemplateInput) (entity.Template, error) {
@@ -197,3 +489,97 @@ func (m *MockTemplateUC) CreateWishlistFromTemplate(ctx context.Context, templat
 	args := m.Called(ctx, templateID, userID, title)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
+
+func (m *MockTemplateUC) Like(ctx context.Context, userID, templateID uuid.UUID) (usecase.LikeResult, error) {
+	args := m.Called(ctx, userID, templateID)
+	return args.Get(0).(usecase.LikeResult), args.Error(1)
+}
+
+func (m *MockTemplateUC) Unlike(ctx context.Context, userID, templateID uuid.UUID) (usecase.LikeResult, error) {
+	args := m.Called(ctx, userID, templateID)
+	return args.Get(0).(usecase.LikeResult), args.Error(1)
+}
+
+// MockAdminUC
+
+type MockAdminUC struct{ mock.Mock }
+
+func (m *MockAdminUC) ListUsers(ctx context.Context, actorID uuid.UUID, query string, page int) (usecase.UserPage, error) {
+	args := m.Called(ctx, actorID, query, page)
+	return args.Get(0).(usecase.UserPage), args.Error(1)
+}
+
+func (m *MockAdminUC) Ban(ctx context.Context, actorID, userID uuid.UUID, reason string) (entity.User, error) {
+	args := m.Called(ctx, actorID, userID, reason)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockAdminUC) Unban(ctx context.Context, actorID, userID uuid.UUID) (entity.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockAdminUC) SetRole(ctx context.Context, actorID, userID uuid.UUID, role string) (entity.User, error) {
+	args := m.Called(ctx, actorID, userID, role)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockAdminUC) UnpublishTemplate(ctx context.Context, actorID, templateID uuid.UUID) (entity.Template, error) {
+	args := m.Called(ctx, actorID, templateID)
+	return args.Get(0).(entity.Template), args.Error(1)
+}
+
+func (m *MockAdminUC) UnpublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, actorID, wishlistID)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockAdminUC) PublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, actorID, wishlistID)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockAdminUC) Stats(ctx context.Context, actorID uuid.UUID) (entity.InstanceStats, error) {
+	args := m.Called(ctx, actorID)
+	return args.Get(0).(entity.InstanceStats), args.Error(1)
+}
+
+// MockAccountUC
+
+type MockAccountUC struct{ mock.Mock }
+
+func (m *MockAccountUC) RequestExport(ctx context.Context, userID uuid.UUID) (entity.DataExport, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(entity.DataExport), args.Error(1)
+}
+
+func (m *MockAccountUC) GetExports(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.DataExport), args.Error(1)
+}
+
+func (m *MockAccountUC) DownloadExport(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	args := m.Called(ctx, userID, exportID)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+func (m *MockAccountUC) ProcessExports(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountUC) PurgeExpiredExports(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountUC) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountUC) PurgeTrash(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
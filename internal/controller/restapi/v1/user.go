@@ -1,17 +1,29 @@
 package v1
 
 import (
+	"errors"
 	"io"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 
+	"main/internal/controller/restapi/middleware"
 	"main/internal/controller/restapi/v1/request"
 	"main/internal/controller/restapi/v1/response"
+	"main/internal/entity"
 	"main/internal/usecase"
 )
 
+// refresh-cookie отправляется только на эндпоинты /auth
+const (
+	refreshCookieName = "refresh_token"
+	refreshCookiePath = "/api/v1/auth"
+)
+
 type userHandler struct {
 	uc           usecase.UserUseCase
 	uploadUC     usecase.UploadUseCase
@@ -32,12 +44,12 @@ func (h *userHandler) register(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("username and password are required"))
 	}
 
-	result, err := h.uc.Register(c.Context(), req.Username, req.Password)
+	result, err := h.uc.Register(c.Context(), req.Username, req.Password, clientInfo(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
 
-	h.setTokenCookie(c, result.Token)
+	h.setTokenCookie(c, result)
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"token": result.Token})
 }
 
@@ -47,13 +59,12 @@ func (h *userHandler) login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
 	}
 
-	result, err := h.uc.Login(c.Context(), req.Username, req.Password)
+	result, err := h.uc.Login(c.Context(), req.Username, req.Password, clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+		return loginError(c, err)
 	}
 
-	h.setTokenCookie(c, result.Token)
-	return c.JSON(fiber.Map{"token": result.Token})
+	return h.authResponse(c, result)
 }
 
 func (h *userHandler) authTelegram(c *fiber.Ctx) error {
@@ -62,21 +73,26 @@ func (h *userHandler) authTelegram(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
 	}
 
-	result, err := h.uc.AuthenticateTelegram(c.Context(), usecase.TelegramAuthInput{
-		ID:        req.ID,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		PhotoURL:  req.PhotoURL,
-		Username:  req.Username,
-		AuthDate:  req.AuthDate,
-		Hash:      req.Hash,
-	})
+	result, err := h.uc.AuthenticateTelegram(c.Context(), toTelegramAuthInput(req), clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+		return loginError(c, err)
 	}
 
-	h.setTokenCookie(c, result.Token)
-	return c.JSON(fiber.Map{"token": result.Token})
+	return h.authResponse(c, result)
+}
+
+func (h *userHandler) authTelegramWebApp(c *fiber.Ctx) error {
+	var req request.TelegramWebAppAuthRequest
+	if err := c.BodyParser(&req); err != nil || req.InitData == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("initData is required"))
+	}
+
+	result, err := h.uc.AuthenticateTelegramWebApp(c.Context(), req.InitData, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+
+	return h.authResponse(c, result)
 }
 
 func (h *userHandler) me(c *fiber.Ctx) error {
@@ -88,33 +104,79 @@ func (h *userHandler) me(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"user": claims})
 }
 
+func (h *userHandler) refresh(c *fiber.Ctx) error {
+	refreshToken := c.Cookies(refreshCookieName)
+	if refreshToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error("refresh token is required"))
+	}
+
+	result, err := h.uc.Refresh(c.Context(), refreshToken, clientInfo(c))
+	if err != nil {
+		h.clearTokenCookies(c)
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+
+	h.setTokenCookie(c, result)
+	return c.JSON(fiber.Map{"token": result.Token})
+}
+
 func (h *userHandler) logout(c *fiber.Ctx) error {
+	// Сессию берём из access-токена, а если он уже истёк — из refresh-cookie
+	if err := h.uc.Logout(c.Context(), middleware.SessionID(c), c.Cookies(refreshCookieName)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	h.clearTokenCookies(c)
+	return c.JSON(fiber.Map{"message": "logout successful"})
+}
+
+// authResponse выдаёт токены после входа или, если включена 2FA, mfa-токен для второго шага
+func (h *userHandler) authResponse(c *fiber.Ctx, result usecase.AuthResult) error {
+	if result.MFARequired {
+		return c.JSON(fiber.Map{"mfaRequired": true, "mfaToken": result.MFAToken})
+	}
+	h.setTokenCookie(c, result)
+	return c.JSON(fiber.Map{"token": result.Token})
+}
+
+func (h *userHandler) setTokenCookie(c *fiber.Ctx, result usecase.AuthResult) {
 	c.Cookie(&fiber.Cookie{
 		Name:     "token",
-		Value:    "",
-		Expires:  time.Now().Add(-time.Hour * 24),
+		Value:    result.Token,
+		Expires:  result.ExpiresAt,
 		Secure:   h.secureCookie,
 		HTTPOnly: true,
 		SameSite: fiber.CookieSameSiteLaxMode,
 		Path:     "/",
 		Domain:   h.cookieDomain,
 	})
-	return c.JSON(fiber.Map{"message": "logout successful"})
-}
-
-func (h *userHandler) setTokenCookie(c *fiber.Ctx, token string) {
 	c.Cookie(&fiber.Cookie{
-		Name:     "token",
-		Value:    token,
-		Expires:  time.Now().Add(time.Hour * 24 * 30),
+		Name:     refreshCookieName,
+		Value:    result.RefreshToken,
+		Expires:  result.RefreshExpiresAt,
 		Secure:   h.secureCookie,
 		HTTPOnly: true,
 		SameSite: fiber.CookieSameSiteLaxMode,
-		Path:     "/",
+		Path:     refreshCookiePath,
 		Domain:   h.cookieDomain,
 	})
 }
 
+func (h *userHandler) clearTokenCookies(c *fiber.Ctx) {
+	expired := time.Now().Add(-time.Hour * 24)
+	for name, path := range map[string]string{"token": "/", refreshCookieName: refreshCookiePath} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			Expires:  expired,
+			Secure:   h.secureCookie,
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+			Path:     path,
+			Domain:   h.cookieDomain,
+		})
+	}
+}
+
 func (h *userHandler) getProfile(c *fiber.Ctx) error {
 	userID, err := getUserID(c)
 	if err != nil {
@@ -124,14 +186,7 @@ func (h *userHandler) getProfile(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
 	}
-	return c.JSON(fiber.Map{
-		"user": fiber.Map{
-			"id":          user.ID,
-			"username":    user.Username,
-			"displayName": user.DisplayName,
-			"avatar":      user.Avatar,
-		},
-	})
+	return c.JSON(fiber.Map{"user": profileResponse(user)})
 }
 
 func (h *userHandler) updateProfile(c *fiber.Ctx) error {
@@ -171,12 +226,461 @@ func (h *userHandler) updateProfile(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
-	return c.JSON(fiber.Map{
-		"user": fiber.Map{
-			"id":          user.ID,
-			"username":    user.Username,
-			"displayName": user.DisplayName,
-			"avatar":      user.Avatar,
-		},
+	return c.JSON(fiber.Map{"user": profileResponse(user)})
+}
+
+// deleteAccount ставит аккаунт в очередь на удаление и завершает текущую сессию
+func (h *userHandler) deleteAccount(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	scheduledAt, err := h.uc.DeleteAccount(c.Context(), userID, middleware.SessionID(c), usecase.DeleteAccountInput{
+		Password: req.Password,
+		Code:     req.Code,
 	})
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManyAttempts) {
+			return loginError(c, err)
+		}
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	h.clearTokenCookies(c)
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"deletionScheduledAt": scheduledAt})
+}
+
+func (h *userHandler) getIdentities(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	identities, err := h.uc.GetIdentities(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(identities))
+}
+
+func (h *userHandler) linkTelegram(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.TelegramAuthRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	identity, err := h.uc.LinkTelegram(c.Context(), userID, toTelegramAuthInput(req))
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.Status(fiber.StatusCreated).JSON(response.Data(identity))
+}
+
+func (h *userHandler) unlinkIdentity(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	identityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid identity ID"))
+	}
+
+	if err := h.uc.UnlinkIdentity(c.Context(), userID, identityID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "identity unlinked"})
+}
+
+func (h *userHandler) setHandle(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.SetHandleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	user, err := h.uc.SetHandle(c.Context(), userID, req.Handle)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"user": profileResponse(user)})
+}
+
+// getPublicProfile отдаёт публичную страницу по handle. Маршрут стоит перед защищёнными
+// /users/me: "me" короче минимальной длины handle, такие запросы уходят дальше по цепочке.
+// Прежний handle и handle в другом регистре отвечают 301 на текущий
+func (h *userHandler) getPublicProfile(c *fiber.Ctx) error {
+	handle := c.Params("handle")
+	if handle == "me" {
+		return c.Next()
+	}
+
+	profile, err := h.uc.GetPublicProfile(c.Context(), handle)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	if profile.Handle != handle {
+		return c.Redirect(strings.TrimSuffix(c.Path(), handle)+url.PathEscape(profile.Handle), fiber.StatusMovedPermanently)
+	}
+
+	wishlists := make([]fiber.Map, len(profile.Wishlists))
+	for i, w := range profile.Wishlists {
+		wishlists[i] = fiber.Map{
+			"id":            w.ID,
+			"shortId":       w.ShortID,
+			"title":         w.Title,
+			"description":   w.Description,
+			"cover":         w.Cover,
+			"presentsCount": w.PresentsCount,
+			"createdAt":     w.CreatedAt,
+		}
+		if status, ok := w.Location.Status(time.Now()); ok {
+			wishlists[i]["event"] = status
+		}
+	}
+	return c.JSON(response.Data(fiber.Map{
+		"handle":      profile.Handle,
+		"displayName": profile.DisplayName,
+		"avatar":      profile.Avatar,
+		"wishlists":   wishlists,
+	}))
+}
+
+func (h *userHandler) setPassword(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.SetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+	if req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("password is required"))
+	}
+
+	if err := h.uc.SetPassword(c.Context(), userID, req.Password); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "password set"})
+}
+
+func (h *userHandler) changePassword(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+	if req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("password is required"))
+	}
+
+	err = h.uc.ChangePassword(c.Context(), userID, middleware.SessionID(c), req.CurrentPassword, req.Password)
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManyAttempts) {
+			return loginError(c, err)
+		}
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "password changed"})
+}
+
+func (h *userHandler) forgotPassword(c *fiber.Ctx) error {
+	var req request.EmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("email is required"))
+	}
+
+	if err := h.uc.RequestPasswordReset(c.Context(), req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error("failed to send email"))
+	}
+	// Ответ одинаковый для известных и неизвестных адресов
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func (h *userHandler) resetPassword(c *fiber.Ctx) error {
+	var req request.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+	if req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("password is required"))
+	}
+
+	if err := h.uc.ResetPassword(c.Context(), req.Token, req.Password); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	h.clearTokenCookies(c)
+	return c.JSON(fiber.Map{"message": "password reset"})
+}
+
+func (h *userHandler) requestEmailVerification(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.EmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	if err := h.uc.RequestEmailVerification(c.Context(), userID, req.Email); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "verification email sent"})
+}
+
+func (h *userHandler) verifyEmail(c *fiber.Ctx) error {
+	var req request.TokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	user, err := h.uc.VerifyEmail(c.Context(), req.Token)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"user": profileResponse(user)})
+}
+
+func (h *userHandler) requestMagicLink(c *fiber.Ctx) error {
+	var req request.EmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	if err := h.uc.RequestMagicLink(c.Context(), req.Email); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	// Ответ одинаковый для известных и неизвестных адресов
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "if the email is registered, a login link has been sent"})
+}
+
+func (h *userHandler) loginMagicLink(c *fiber.Ctx) error {
+	var req request.TokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	result, err := h.uc.LoginWithMagicLink(c.Context(), req.Token, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+
+	return h.authResponse(c, result)
+}
+
+func (h *userHandler) completeMFA(c *fiber.Ctx) error {
+	var req request.MFARequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	result, err := h.uc.CompleteMFA(c.Context(), req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+	return h.authResponse(c, result)
+}
+
+func (h *userHandler) beginTOTP(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	enrollment, err := h.uc.BeginTOTPEnrollment(c.Context(), userID)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(fiber.Map{"secret": enrollment.Secret, "uri": enrollment.URI}))
+}
+
+func (h *userHandler) confirmTOTP(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.CodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	codes, err := h.uc.ConfirmTOTP(c.Context(), userID, req.Code)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(fiber.Map{"recoveryCodes": codes}))
+}
+
+func (h *userHandler) disableTOTP(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.CodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	if err := h.uc.DisableTOTP(c.Context(), userID, req.Code); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "2FA disabled"})
+}
+
+func profileResponse(user entity.User) fiber.Map {
+	return fiber.Map{
+		"id":            user.ID,
+		"username":      user.Username,
+		"handle":        user.Handle,
+		"displayName":   user.DisplayName,
+		"avatar":        user.Avatar,
+		"email":         user.Email,
+		"emailVerified": user.EmailVerified,
+		"hasPassword":   user.Password != "",
+		"totpEnabled":   user.TOTPEnabled,
+		"role":          user.Role,
+	}
+}
+
+func toTelegramAuthInput(req request.TelegramAuthRequest) usecase.TelegramAuthInput {
+	return usecase.TelegramAuthInput{
+		ID:        req.ID,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		PhotoURL:  req.PhotoURL,
+		Username:  req.Username,
+		AuthDate:  req.AuthDate,
+		Hash:      req.Hash,
+	}
+}
+
+func (h *userHandler) getAccessTokens(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	tokens, err := h.uc.GetAccessTokens(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(tokens))
+}
+
+func (h *userHandler) createAccessToken(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.CreateAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	created, err := h.uc.CreateAccessToken(c.Context(), userID, usecase.CreateAccessTokenInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	// Токен в открытом виде отдаётся только в этом ответе
+	return c.Status(fiber.StatusCreated).JSON(response.Data(fiber.Map{
+		"token":       created.Token,
+		"accessToken": created.AccessToken,
+	}))
+}
+
+func (h *userHandler) revokeAccessToken(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid token ID"))
+	}
+
+	if err := h.uc.RevokeAccessToken(c.Context(), userID, tokenID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "access token revoked"})
+}
+
+func (h *userHandler) getSessions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	sessions, err := h.uc.GetSessions(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	current := middleware.SessionID(c)
+	result := make([]fiber.Map, len(sessions))
+	for i, s := range sessions {
+		result[i] = sessionResponse(s, s.ID == current)
+	}
+	return c.JSON(response.Data(result))
+}
+
+func (h *userHandler) revokeSession(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid session ID"))
+	}
+
+	if err := h.uc.RevokeSession(c.Context(), userID, sessionID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	// Отзыв текущей сессии — тот же выход, cookie больше не нужны
+	if sessionID == middleware.SessionID(c) {
+		h.clearTokenCookies(c)
+	}
+	return c.JSON(fiber.Map{"message": "session revoked"})
+}
+
+func (h *userHandler) revokeOtherSessions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	if err := h.uc.RevokeOtherSessions(c.Context(), userID, middleware.SessionID(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(fiber.Map{"message": "other sessions revoked"})
+}
+
+// sessionResponse — сессия без хеша refresh-токена
+func sessionResponse(s entity.Session, current bool) fiber.Map {
+	return fiber.Map{
+		"id":         s.ID,
+		"userAgent":  s.UserAgent,
+		"ip":         s.IP,
+		"createdAt":  s.CreatedAt,
+		"lastSeenAt": s.LastSeenAt,
+		"expiresAt":  s.ExpiresAt,
+		"current":    current,
+	}
 }
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,7 +29,7 @@ func setupUserApp(userMock usecase.UserUseCase) *fiber.App {
 	wishlistMock := &MockWishlistUC{}
 	presentMock := &MockPresentUC{}
 	uploadMock := &MockUploadUC{}
-	v1.NewRouter(app, testSecret, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{})
+	v1.NewRouter(app, testKeys, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{}, &MockAdminUC{}, &MockAccountUC{})
 	return app
 }
 
@@ -34,7 +38,7 @@ func TestRegister_Success(t *testing.T) {
 	app := setupUserApp(um)
 
 	userID := uuid.New()
-	um.On("Register", mock.Anything, "alice", "password123").Return(usecase.AuthResult{
+	um.On("Register", mock.Anything, "alice", "password123", mock.Anything).Return(usecase.AuthResult{
 		Token: makeTestToken(userID),
 		User:  entity.User{ID: userID, Username: "alice"},
 	}, nil)
@@ -63,7 +67,7 @@ func TestLogin_Unauthorized(t *testing.T) {
 	um := &MockUserUC{}
 	app := setupUserApp(um)
 
-	um.On("Login", mock.Anything, "alice", "wrong").Return(
+	um.On("Login", mock.Anything, "alice", "wrong", mock.Anything).Return(
 		usecase.AuthResult{},
 		errors.New("неверный логин или пароль"),
 	)
@@ -81,6 +85,272 @@ func TestLogin_Unauthorized(t *testing.T) {
 	assert.Equal(t, "неверный логин или пароль", result["error"].(string))
 }
 
+func TestLogin_LockedOut(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("Login", mock.Anything, "alice", "guess", mock.MatchedBy(func(c usecase.ClientInfo) bool {
+		return c.IP != ""
+	})).Return(usecase.AuthResult{}, &usecase.LockoutError{RetryAfter: 90*time.Second + time.Millisecond})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(`{"username":"alice","password":"guess"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "91", resp.Header.Get("Retry-After"))
+	um.AssertExpectations(t)
+}
+
+func findCookie(resp *http.Response, name string) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestAuthTelegramWebApp(t *testing.T) {
+	t.Run("sets session cookies", func(t *testing.T) {
+		um := &MockUserUC{}
+		app := setupUserApp(um)
+		userID := uuid.New()
+		um.On("AuthenticateTelegramWebApp", mock.Anything, "user=%7B%7D&hash=abc", mock.Anything).Return(usecase.AuthResult{
+			Token:            makeTestToken(userID),
+			ExpiresAt:        time.Now().Add(15 * time.Minute),
+			RefreshToken:     "refresh",
+			RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+			User:             entity.User{ID: userID, Username: "tg_42"},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/telegram/webapp", bytes.NewBufferString(`{"initData":"user=%7B%7D&hash=abc"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.NotNil(t, findCookie(resp, "token"))
+		assert.NotNil(t, findCookie(resp, "refresh_token"))
+	})
+
+	t.Run("missing initData", func(t *testing.T) {
+		um := &MockUserUC{}
+		app := setupUserApp(um)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/telegram/webapp", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		um.AssertNotCalled(t, "AuthenticateTelegramWebApp", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		um := &MockUserUC{}
+		app := setupUserApp(um)
+		um.On("AuthenticateTelegramWebApp", mock.Anything, mock.Anything, mock.Anything).Return(usecase.AuthResult{}, errors.New("authentication failed"))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/telegram/webapp", bytes.NewBufferString(`{"initData":"hash=abc"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.Nil(t, findCookie(resp, "token"))
+	})
+}
+
+func TestRefresh_RotatesCookies(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID := uuid.New()
+	um.On("Refresh", mock.Anything, "old-refresh", mock.Anything).Return(usecase.AuthResult{
+		Token:            makeTestToken(userID),
+		ExpiresAt:        time.Now().Add(15 * time.Minute),
+		RefreshToken:     "new-refresh",
+		RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old-refresh"})
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	refresh := findCookie(resp, "refresh_token")
+	require.NotNil(t, refresh)
+	assert.Equal(t, "new-refresh", refresh.Value)
+	assert.Equal(t, "/api/v1/auth", refresh.Path)
+	require.NotNil(t, findCookie(resp, "token"))
+}
+
+func TestRefresh_RevokedSession(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("Refresh", mock.Anything, "reused", mock.Anything).Return(usecase.AuthResult{}, usecase.ErrSessionRevoked)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "reused"})
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Empty(t, findCookie(resp, "refresh_token").Value)
+}
+
+func TestRefresh_MissingCookie(t *testing.T) {
+	app := setupUserApp(&MockUserUC{})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("Logout", mock.Anything, mock.MatchedBy(func(id uuid.UUID) bool { return id != uuid.Nil }), "").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(uuid.New()))
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	um.AssertExpectations(t)
+}
+
+func TestLogout_ExpiredAccessTokenUsesRefreshCookie(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("Logout", mock.Anything, uuid.Nil, "refresh").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh"})
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	um.AssertExpectations(t)
+}
+
+func TestProtected_RevokedSessionRejected(t *testing.T) {
+	um := &MockUserUC{SessionErr: usecase.ErrSessionRevoked}
+	app := setupUserApp(um)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(uuid.New()))
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestUnlinkIdentity_LastLoginMethod(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID, identityID := uuid.New(), uuid.New()
+	um.On("UnlinkIdentity", mock.Anything, userID, identityID).Return(usecase.ErrLastLoginMethod)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/identities/"+identityID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestSetPassword_Success(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID := uuid.New()
+	um.On("SetPassword", mock.Anything, userID, "secret123").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/me/password", bytes.NewBufferString(`{"password":"secret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	um.AssertExpectations(t)
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("Login", mock.Anything, "alice", "password123", mock.Anything).Return(usecase.AuthResult{MFARequired: true, MFAToken: "pending"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(`{"username":"alice","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Nil(t, findCookie(resp, "token"), "no session before the second factor")
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, true, result["mfaRequired"])
+	assert.Equal(t, "pending", result["mfaToken"])
+}
+
+func TestCompleteMFA_SetsCookies(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	um.On("CompleteMFA", mock.Anything, "pending", "123456", mock.Anything).Return(usecase.AuthResult{
+		Token:        makeTestToken(uuid.New()),
+		ExpiresAt:    time.Now().Add(15 * time.Minute),
+		RefreshToken: "refresh",
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/mfa", bytes.NewBufferString(`{"mfaToken":"pending","code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, findCookie(resp, "token"))
+	require.NotNil(t, findCookie(resp, "refresh_token"))
+}
+
+func TestCreateAccessToken_ReturnsTokenOnce(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID := uuid.New()
+	um.On("CreateAccessToken", mock.Anything, userID, usecase.CreateAccessTokenInput{
+		Name:   "extension",
+		Scopes: []string{entity.ScopeWishlistsRead, entity.ScopeParse},
+	}).Return(usecase.CreatedAccessToken{
+		AccessToken: entity.AccessToken{ID: uuid.New(), UserID: userID, Name: "extension", TokenHash: "secret-hash"},
+		Token:       "pat_raw",
+	}, nil)
+
+	body := bytes.NewBufferString(`{"name":"extension","scopes":["wishlists:read","parse"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/tokens", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	raw, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(raw), `"token":"pat_raw"`)
+	assert.NotContains(t, string(raw), "secret-hash")
+	um.AssertExpectations(t)
+}
+
 func TestUploadBulk_NoFiles(t *testing.T) {
 	um := &MockUserUC{}
 	app := setupUserApp(um)
@@ -106,3 +376,228 @@ func TestUploadBulk_NoFiles(t *testing.T) {
 	json.NewDecoder(resp.Body).Decode(&result)
 	assert.Contains(t, result["error"].(string), "files are required")
 }
+
+func makeTestTokenForSession(userID, sessionID uuid.UUID) string {
+	return signTestClaims(jwt.MapClaims{
+		"id":  userID.String(),
+		"sid": sessionID.String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, testSecret)
+}
+
+func TestGetSessions_MarksCurrent(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID, currentID, otherID := uuid.New(), uuid.New(), uuid.New()
+	um.On("GetSessions", mock.Anything, userID).Return([]entity.Session{
+		{ID: otherID, UserID: userID, RefreshTokenHash: "secret-hash", UserAgent: "Firefox", IP: "10.0.0.2"},
+		{ID: currentID, UserID: userID, RefreshTokenHash: "secret-hash", UserAgent: "Chrome", IP: "10.0.0.1"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestTokenForSession(userID, currentID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	raw, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(raw), "secret-hash")
+	var body struct {
+		Data []struct {
+			ID        uuid.UUID `json:"id"`
+			UserAgent string    `json:"userAgent"`
+			IP        string    `json:"ip"`
+			Current   bool      `json:"current"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &body))
+	require.Len(t, body.Data, 2)
+	assert.False(t, body.Data[0].Current)
+	assert.Equal(t, currentID, body.Data[1].ID)
+	assert.Equal(t, "Chrome", body.Data[1].UserAgent)
+	assert.True(t, body.Data[1].Current)
+}
+
+func TestRevokeSession_CurrentClearsCookies(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	um.On("RevokeSession", mock.Anything, userID, sessionID).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/sessions/"+sessionID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestTokenForSession(userID, sessionID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, findCookie(resp, "refresh_token"))
+	assert.Empty(t, findCookie(resp, "refresh_token").Value)
+	um.AssertExpectations(t)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID := uuid.New()
+	um.On("RevokeSession", mock.Anything, userID, mock.Anything).Return(fmt.Errorf("session %w", usecase.ErrNotFound))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/sessions/"+uuid.NewString(), nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Nil(t, findCookie(resp, "refresh_token"))
+}
+
+func TestRevokeOtherSessions_KeepsCurrent(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	um.On("RevokeOtherSessions", mock.Anything, userID, sessionID).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestTokenForSession(userID, sessionID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	um.AssertExpectations(t)
+}
+
+func TestDeleteAccount_ClearsCookies(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	scheduledAt := time.Now().Add(7 * 24 * time.Hour).UTC().Truncate(time.Second)
+	um.On("DeleteAccount", mock.Anything, userID, sessionID, usecase.DeleteAccountInput{Password: "secret", Code: "123456"}).
+		Return(scheduledAt, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me", bytes.NewBufferString(`{"password":"secret","code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestTokenForSession(userID, sessionID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusAccepted, resp.StatusCode)
+
+	var body struct {
+		DeletionScheduledAt time.Time `json:"deletionScheduledAt"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, scheduledAt.Equal(body.DeletionScheduledAt))
+	require.NotNil(t, findCookie(resp, "token"))
+	assert.Empty(t, findCookie(resp, "token").Value)
+	um.AssertExpectations(t)
+}
+
+func TestDeleteAccount_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "wrong password", err: usecase.ErrWrongPassword, wantStatus: fiber.StatusBadRequest},
+		{name: "stale login", err: usecase.ErrReauthRequired, wantStatus: fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			um := &MockUserUC{}
+			app := setupUserApp(um)
+			um.On("DeleteAccount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(time.Time{}, tt.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/users/me", bytes.NewBufferString(`{"password":"wrong"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+makeTestToken(uuid.New()))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Nil(t, findCookie(resp, "token"))
+		})
+	}
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+	wishlistID := uuid.New()
+	um.On("GetPublicProfile", mock.Anything, "alice").Return(usecase.PublicProfile{
+		Handle:      "alice",
+		DisplayName: "Alice",
+		Wishlists:   []entity.Wishlist{{ID: wishlistID, ShortID: "abc-def-ghi", Title: "Birthday", ListedOnProfile: true}},
+	}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/users/alice", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data struct {
+			Handle      string                   `json:"handle"`
+			DisplayName string                   `json:"displayName"`
+			Wishlists   []map[string]interface{} `json:"wishlists"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "alice", body.Data.Handle)
+	assert.Equal(t, "Alice", body.Data.DisplayName)
+	require.Len(t, body.Data.Wishlists, 1)
+	assert.Equal(t, "abc-def-ghi", body.Data.Wishlists[0]["shortId"])
+	assert.NotContains(t, body.Data.Wishlists[0], "userId")
+}
+
+func TestGetPublicProfile_OldHandleRedirects(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+	um.On("GetPublicProfile", mock.Anything, "old_alice").Return(usecase.PublicProfile{Handle: "alice"}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/users/old_alice", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/api/v1/users/alice", resp.Header.Get(fiber.HeaderLocation))
+}
+
+func TestGetPublicProfile_NotFound(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+	um.On("GetPublicProfile", mock.Anything, "nobody").Return(usecase.PublicProfile{}, usecase.ErrNotFound)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/users/nobody", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetProfile_MeIsNotAHandle(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+	userID := uuid.New()
+	um.On("GetProfile", mock.Anything, userID).Return(entity.User{ID: userID, Username: "alice", Handle: "alice"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		User map[string]interface{} `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "alice", body.User["handle"])
+	um.AssertNotCalled(t, "GetPublicProfile", mock.Anything, mock.Anything)
+}
+
+func TestSetHandle_Conflict(t *testing.T) {
+	um := &MockUserUC{}
+	app := setupUserApp(um)
+	userID := uuid.New()
+	um.On("SetHandle", mock.Anything, userID, "alice").Return(entity.User{}, fmt.Errorf("handle уже занят: %w", usecase.ErrConflict))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/me/handle", bytes.NewBufferString(`{"handle":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
@@ -4,22 +4,37 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"main/internal/controller/restapi/v1/request"
 	"main/internal/controller/restapi/v1/response"
 	"main/internal/entity"
 	"main/internal/usecase"
 )
 
+// Токены разблокировки лежат в отдельной cookie на каждый вишлист: имя содержит его ID,
+// а путь покрывает и вишлисты, и подарки
+const (
+	unlockCookiePrefix = "wishlist_unlock_"
+	unlockCookiePath   = "/api/v1"
+	// maxUnlockCookies ограничивает, сколько токенов проверяется на один запрос
+	maxUnlockCookies = 20
+)
+
 type wishlistHandler struct {
-	uc usecase.WishlistUseCase
+	uc           usecase.WishlistUseCase
+	cookieDomain string
+	secureCookie bool
 }
 
-func newWishlistHandler(uc usecase.WishlistUseCase) *wishlistHandler {
-	return &wishlistHandler{uc: uc}
+func newWishlistHandler(uc usecase.WishlistUseCase, cookieDomain string, secureCookie bool) *wishlistHandler {
+	return &wishlistHandler{uc: uc, cookieDomain: cookieDomain, secureCookie: secureCookie}
 }
 
 func (h *wishlistHandler) getAll(c *fiber.Ctx) error {
@@ -32,7 +47,7 @@ func (h *wishlistHandler) getAll(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
 	}
-	return c.JSON(response.Data(wishlists))
+	return c.JSON(response.Data(wishlistsResponse(wishlists)))
 }
 
 func (h *wishlistHandler) getOne(c *fiber.Ctx) error {
@@ -41,11 +56,12 @@ func (h *wishlistHandler) getOne(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
 
-	wishlist, err := h.uc.GetByID(c.Context(), id)
+	wishlist, err := h.uc.GetByID(c.Context(), viewer(c), id)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(response.Error(err.Error()))
+		return viewError(c, err, fiber.StatusNotFound)
 	}
-	return c.JSON(response.Data(wishlist))
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
 }
 
 func (h *wishlistHandler) getByShortID(c *fiber.Ctx) error {
@@ -54,11 +70,82 @@ func (h *wishlistHandler) getByShortID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("shortId is required"))
 	}
 
-	wishlist, err := h.uc.GetByShortID(c.Context(), shortID)
+	link, err := url.PathUnescape(shortID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid shortId"))
+	}
+
+	wishlist, err := h.uc.GetByShortID(c.Context(), viewer(c), link)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(response.Error(err.Error()))
+		return viewError(c, err, fiber.StatusNotFound)
+	}
+	// Прежний slug и slug в другом написании отвечают 301 на текущую ссылку
+	if target := publicLink(wishlist); target != "" && link != wishlist.ShortID && link != target {
+		return c.Redirect(strings.TrimSuffix(c.Path(), shortID)+url.PathEscape(target), fiber.StatusMovedPermanently)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+// getByProfileSlug открывает вишлист по адресу в профиле владельца. Прежние handle и slug
+// отвечают 301 на текущий адрес, а если вишлист его больше не имеет — на короткую ссылку
+func (h *wishlistHandler) getByProfileSlug(c *fiber.Ctx) error {
+	handle := c.Params("handle")
+	rawSlug := c.Params("slug")
+	slug, err := url.PathUnescape(rawSlug)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid slug"))
+	}
+
+	result, err := h.uc.GetByProfileSlug(c.Context(), viewer(c), handle, slug)
+	if err != nil {
+		return viewError(c, err, fiber.StatusNotFound)
+	}
+	wishlist := result.Wishlist
+	base := strings.TrimSuffix(c.Path(), "/users/"+handle+"/wishlists/"+rawSlug)
+	if wishlist.SlugScope == entity.SlugScopeProfile {
+		if result.Handle == handle && wishlist.Slug == slug {
+			setETag(c, wishlist.Version)
+			return c.JSON(response.Data(wishlistResponse(wishlist)))
+		}
+		return c.Redirect(base+"/users/"+url.PathEscape(result.Handle)+"/wishlists/"+url.PathEscape(wishlist.Slug),
+			fiber.StatusMovedPermanently)
+	}
+	if target := publicLink(wishlist); target != "" {
+		return c.Redirect(base+"/wishlists/s/"+url.PathEscape(target), fiber.StatusMovedPermanently)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+// wishlistView — вишлист с обратным отсчётом до события; Event нет, если дата не задана
+type wishlistView struct {
+	entity.Wishlist
+	Event *entity.EventStatus `json:"event,omitempty"`
+}
+
+func wishlistResponse(w entity.Wishlist) wishlistView {
+	view := wishlistView{Wishlist: w}
+	if status, ok := w.Location.Status(time.Now()); ok {
+		view.Event = &status
+	}
+	return view
+}
+
+func wishlistsResponse(ws []entity.Wishlist) []wishlistView {
+	views := make([]wishlistView, len(ws))
+	for i, w := range ws {
+		views[i] = wishlistResponse(w)
+	}
+	return views
+}
+
+// publicLink — последняя часть ссылки /wishlists/s/...: глобальный slug, если он задан, иначе короткий ID
+func publicLink(w entity.Wishlist) string {
+	if w.Slug != "" && w.SlugScope == entity.SlugScopeGlobal {
+		return w.Slug
 	}
-	return c.JSON(response.Data(wishlist))
+	return w.ShortID
 }
 
 func (h *wishlistHandler) create(c *fiber.Ctx) error {
@@ -79,7 +166,7 @@ func (h *wishlistHandler) create(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
 	}
-	return c.Status(fiber.StatusCreated).JSON(response.Data(wishlist))
+	return c.Status(fiber.StatusCreated).JSON(response.Data(wishlistResponse(wishlist)))
 }
 
 func (h *wishlistHandler) createConstructor(c *fiber.Ctx) error {
@@ -100,14 +187,22 @@ func (h *wishlistHandler) createConstructor(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
-	return c.Status(fiber.StatusCreated).JSON(response.Data(wishlist))
+	return c.Status(fiber.StatusCreated).JSON(response.Data(wishlistResponse(wishlist)))
 }
 
 func (h *wishlistHandler) updateBlocks(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
 
 	var blocks []entity.Block
 	if err := c.BodyParser(&blocks); err != nil {
@@ -120,18 +215,50 @@ func (h *wishlistHandler) updateBlocks(c *fiber.Ctx) error {
 		}
 	}
 
-	wishlist, err := h.uc.UpdateBlocks(c.Context(), id, blocks)
+	wishlist, err := h.uc.UpdateBlocks(c.Context(), userID, id, version, blocks)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+		return editError(c, err, fiber.StatusBadRequest)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) clone(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	var req request.CloneWishlistRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+		}
+	}
+
+	wishlist, err := h.uc.Clone(c.Context(), userID, id, req.Title)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
 	}
-	return c.JSON(response.Data(wishlist))
+	return c.Status(fiber.StatusCreated).JSON(response.Data(wishlistResponse(wishlist)))
 }
 
 func (h *wishlistHandler) update(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
 
 	input, err := h.parseWishlistInput(c)
 	if err != nil {
@@ -141,25 +268,474 @@ func (h *wishlistHandler) update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("поле название обязательно"))
 	}
 
-	wishlist, err := h.uc.Update(c.Context(), id, input)
+	wishlist, err := h.uc.Update(c.Context(), userID, id, version, input)
+	if err != nil {
+		return editError(c, err, fiber.StatusInternalServerError)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) setListedOnProfile(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	var req request.WishlistProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	wishlist, err := h.uc.SetListedOnProfile(c.Context(), userID, id, version, req.Listed)
+	if err != nil {
+		return editError(c, err, fiber.StatusInternalServerError)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) setVisibility(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	var req request.WishlistVisibilityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	wishlist, err := h.uc.SetVisibility(c.Context(), userID, id, version, usecase.SetVisibilityInput{
+		Visibility: req.Visibility,
+		Password:   req.Password,
+	})
+	if err != nil {
+		return editError(c, err, fiber.StatusBadRequest)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) setSlug(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	var req request.WishlistSlugRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	wishlist, err := h.uc.SetSlug(c.Context(), userID, id, version, usecase.SetSlugInput{
+		Slug:  req.Slug,
+		Scope: req.Scope,
+	})
+	if err != nil {
+		return editError(c, err, fiber.StatusBadRequest)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) setEvent(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	var req request.WishlistEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	wishlist, err := h.uc.SetEvent(c.Context(), userID, id, version, usecase.SetEventInput{
+		Time:     req.Time,
+		Timezone: req.Timezone,
+		Repeat:   req.Repeat,
+	})
+	if err != nil {
+		return editError(c, err, fiber.StatusBadRequest)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+// unlock проверяет пароль вишлиста и кладёт токен разблокировки в cookie
+func (h *wishlistHandler) unlock(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	var req request.WishlistUnlockRequest
+	if err := c.BodyParser(&req); err != nil || req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("password is required"))
+	}
+
+	result, err := h.uc.Unlock(c.Context(), id, req.Password, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManyAttempts) {
+			return loginError(c, err)
+		}
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     unlockCookiePrefix + result.WishlistID.String(),
+		Value:    result.Token,
+		Expires:  result.ExpiresAt,
+		Secure:   h.secureCookie,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+		Path:     unlockCookiePath,
+		Domain:   h.cookieDomain,
+	})
+	return c.JSON(response.Data(fiber.Map{"wishlistId": result.WishlistID, "expiresAt": result.ExpiresAt}))
+}
+
+func (h *wishlistHandler) getCollaborators(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	collaborators, err := h.uc.GetCollaborators(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(collaborators))
+}
+
+func (h *wishlistHandler) addCollaborator(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	var req request.CollaboratorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	collaborator, err := h.uc.AddCollaborator(c.Context(), userID, id, req.Handle, req.Role)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(collaborator))
+}
+
+func (h *wishlistHandler) setCollaboratorRole(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	collaboratorID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid user ID"))
+	}
+	var req request.CollaboratorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	collaborator, err := h.uc.SetCollaboratorRole(c.Context(), userID, id, collaboratorID, req.Role)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(collaborator))
+}
+
+func (h *wishlistHandler) removeCollaborator(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	collaboratorID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid user ID"))
+	}
+
+	if err := h.uc.RemoveCollaborator(c.Context(), userID, id, collaboratorID); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(true))
+}
+
+func (h *wishlistHandler) createInvite(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	var req request.WishlistInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	invite, err := h.uc.CreateInvite(c.Context(), userID, id, req.Role)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.Status(fiber.StatusCreated).JSON(response.Data(fiber.Map{
+		"token":     invite.Token,
+		"role":      invite.Role,
+		"expiresAt": invite.ExpiresAt,
+	}))
+}
+
+func (h *wishlistHandler) acceptInvite(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	var req request.TokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid input"))
+	}
+
+	wishlist, err := h.uc.AcceptInvite(c.Context(), userID, req.Token)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) getPendingCollaborations(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+
+	wishlists, err := h.uc.GetPendingCollaborations(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
 	}
-	return c.JSON(response.Data(wishlist))
+	return c.JSON(response.Data(wishlistsResponse(wishlists)))
+}
+
+func (h *wishlistHandler) acceptCollaboration(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("wishlistId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	wishlist, err := h.uc.AcceptCollaboration(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
+func (h *wishlistHandler) declineCollaboration(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("wishlistId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	if err := h.uc.DeclineCollaboration(c.Context(), userID, id); err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(true))
 }
 
 func (h *wishlistHandler) delete(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
 	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
 
-	if err := h.uc.Delete(c.Context(), id); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	if err := h.uc.Delete(c.Context(), userID, id, version); err != nil {
+		return editError(c, err, fiber.StatusInternalServerError)
 	}
 	return c.JSON(response.Data(true))
 }
 
+func (h *wishlistHandler) getTrash(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+
+	trash, err := h.uc.GetTrash(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(fiber.Map{
+		"wishlists": wishlistsResponse(trash.Wishlists),
+		"presents":  trash.Presents,
+	}))
+}
+
+func (h *wishlistHandler) restore(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	w, err := h.uc.Restore(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(wishlistResponse(w)))
+}
+
+func (h *wishlistHandler) getRevisions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+
+	revisions, err := h.uc.GetRevisions(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(revisions))
+}
+
+func (h *wishlistHandler) getRevision(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	number, err := strconv.Atoi(c.Params("number"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid revision number"))
+	}
+
+	revision, err := h.uc.GetRevision(c.Context(), userID, id, number)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(revision))
+}
+
+// diffRevisions сравнивает ревизии из query-параметров from и to
+func (h *wishlistHandler) diffRevisions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("from must be a revision number"))
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("to must be a revision number"))
+	}
+
+	diff, err := h.uc.DiffRevisions(c.Context(), userID, id, from, to)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusInternalServerError)).JSON(response.Error(err.Error()))
+	}
+	return c.JSON(response.Data(diff))
+}
+
+func (h *wishlistHandler) restoreRevision(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error(err.Error()))
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid wishlist ID"))
+	}
+	number, err := strconv.Atoi(c.Params("number"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error("invalid revision number"))
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(errorStatus(err, fiber.StatusBadRequest)).JSON(response.Error(err.Error()))
+	}
+
+	wishlist, err := h.uc.RestoreRevision(c.Context(), userID, id, version, number)
+	if err != nil {
+		return editError(c, err, fiber.StatusBadRequest)
+	}
+	setETag(c, wishlist.Version)
+	return c.JSON(response.Data(wishlistResponse(wishlist)))
+}
+
 func (h *wishlistHandler) parseWishlistInput(c *fiber.Ctx) (usecase.CreateWishlistInput, error) {
 	input := usecase.CreateWishlistInput{
 		Title:                c.FormValue("title"),
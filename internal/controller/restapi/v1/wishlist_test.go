@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -24,7 +26,7 @@ func setupWishlistApp(wishlistMock usecase.WishlistUseCase) *fiber.App {
 	userMock := &MockUserUC{}
 	presentMock := &MockPresentUC{}
 	uploadMock := &MockUploadUC{}
-	v1.NewRouter(app, testSecret, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{})
+	v1.NewRouter(app, testKeys, "", false, userMock, wishlistMock, presentMock, uploadMock, &MockParseUC{}, &MockTemplateUC{}, &MockAdminUC{}, &MockAccountUC{})
 	return app
 }
 
@@ -108,7 +110,7 @@ func TestGetByShortID_NotFound(t *testing.T) {
 	wm := &MockWishlistUC{}
 	app := setupWishlistApp(wm)
 
-	wm.On("GetByShortID", mock.Anything, "abc-def-ghi").
+	wm.On("GetByShortID", mock.Anything, usecase.Viewer{}, "abc-def-ghi").
 		Return(entity.Wishlist{}, errors.New("not found"))
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/s/abc-def-ghi", nil)
@@ -122,7 +124,7 @@ func TestGetByShortID_Success(t *testing.T) {
 	app := setupWishlistApp(wm)
 
 	wid := uuid.New()
-	wm.On("GetByShortID", mock.Anything, "abc-def-ghi").
+	wm.On("GetByShortID", mock.Anything, usecase.Viewer{}, "abc-def-ghi").
 		Return(entity.Wishlist{ID: wid, ShortID: "abc-def-ghi", Title: "Test"}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/s/abc-def-ghi", nil)
@@ -136,3 +138,709 @@ func TestGetByShortID_Success(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, "abc-def-ghi", data["shortId"])
 }
+
+func TestGetByShortID_SlugRedirects(t *testing.T) {
+	w := entity.Wishlist{ID: uuid.New(), ShortID: "abc-def-ghi", Slug: "new-year", SlugScope: entity.SlugScopeGlobal}
+	tests := []struct {
+		name         string
+		link         string
+		wishlist     entity.Wishlist
+		wantLocation string // пустой — ответ без редиректа
+	}{
+		{name: "current slug", link: "new-year", wishlist: w},
+		{name: "short ID stays valid", link: "abc-def-ghi", wishlist: w},
+		{name: "old slug", link: "ny-party", wishlist: w, wantLocation: "/api/v1/wishlists/s/new-year"},
+		{name: "cyrillic spelling", link: "%D0%BD%D0%BE%D0%B2%D1%8B%D0%B9", wishlist: w, wantLocation: "/api/v1/wishlists/s/new-year"},
+		{
+			name:         "slug moved to profile",
+			link:         "new-year",
+			wishlist:     entity.Wishlist{ID: w.ID, ShortID: w.ShortID, Slug: "new-year", SlugScope: entity.SlugScopeProfile},
+			wantLocation: "/api/v1/wishlists/s/abc-def-ghi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+			wm.On("GetByShortID", mock.Anything, usecase.Viewer{}, mock.Anything).Return(tt.wishlist, nil)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/s/"+tt.link, nil))
+			require.NoError(t, err)
+			if tt.wantLocation == "" {
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+				return
+			}
+			assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+			assert.Equal(t, tt.wantLocation, resp.Header.Get(fiber.HeaderLocation))
+		})
+	}
+}
+
+func TestGetByProfileSlug(t *testing.T) {
+	w := entity.Wishlist{ID: uuid.New(), ShortID: "abc-def-ghi", Slug: "dr-mashi", SlugScope: entity.SlugScopeProfile}
+	tests := []struct {
+		name         string
+		path         string
+		result       usecase.ProfileWishlist
+		wantLocation string
+	}{
+		{name: "current", path: "/masha/wishlists/dr-mashi", result: usecase.ProfileWishlist{Handle: "masha", Wishlist: w}},
+		{
+			name:         "old handle and slug",
+			path:         "/old_masha/wishlists/birthday",
+			result:       usecase.ProfileWishlist{Handle: "masha", Wishlist: w},
+			wantLocation: "/api/v1/users/masha/wishlists/dr-mashi",
+		},
+		{
+			name:         "slug removed",
+			path:         "/masha/wishlists/dr-mashi",
+			result:       usecase.ProfileWishlist{Handle: "masha", Wishlist: entity.Wishlist{ID: w.ID, ShortID: w.ShortID}},
+			wantLocation: "/api/v1/wishlists/s/abc-def-ghi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+			wm.On("GetByProfileSlug", mock.Anything, usecase.Viewer{}, mock.Anything, mock.Anything).Return(tt.result, nil)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/users"+tt.path, nil))
+			require.NoError(t, err)
+			if tt.wantLocation == "" {
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+				return
+			}
+			assert.Equal(t, fiber.StatusMovedPermanently, resp.StatusCode)
+			assert.Equal(t, tt.wantLocation, resp.Header.Get(fiber.HeaderLocation))
+		})
+	}
+}
+
+func TestSetListedOnProfile(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	wid := uuid.New()
+	wm.On("SetListedOnProfile", mock.Anything, userID, wid, uint(3), true).
+		Return(entity.Wishlist{ID: wid, UserID: userID, ListedOnProfile: true}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/profile", bytes.NewBufferString(`{"listed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data entity.Wishlist `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.True(t, result.Data.ListedOnProfile)
+	wm.AssertExpectations(t)
+}
+
+func TestGetOne_PasswordProtected(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	wid := uuid.New()
+	wm.On("GetByID", mock.Anything, usecase.Viewer{}, wid).
+		Return(entity.Wishlist{}, &usecase.WishlistLockedError{WishlistID: wid})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String(), nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, true, result["passwordRequired"])
+	assert.Equal(t, wid.String(), result["wishlistId"])
+}
+
+func TestGetOne_PassesUnlockCookies(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	wid := uuid.New()
+	wm.On("GetByID", mock.Anything, usecase.Viewer{UnlockTokens: []string{"token-1"}}, wid).
+		Return(entity.Wishlist{ID: wid, Title: "Test"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String(), nil)
+	req.AddCookie(&http.Cookie{Name: "wishlist_unlock_" + wid.String(), Value: "token-1"})
+	req.AddCookie(&http.Cookie{Name: "unrelated", Value: "x"})
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	wm.AssertExpectations(t)
+}
+
+func TestUnlock_SetsCookie(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	wid := uuid.New()
+	wm.On("Unlock", mock.Anything, wid, "secret-1", mock.Anything).
+		Return(usecase.WishlistUnlock{WishlistID: wid, Token: "token-1", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/unlock", bytes.NewBufferString(`{"password":"secret-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "wishlist_unlock_"+wid.String() {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie)
+	assert.Equal(t, "token-1", cookie.Value)
+	assert.True(t, cookie.HttpOnly)
+}
+
+func TestUnlock_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "wrong password", err: usecase.ErrWrongWishlistPassword, wantStatus: fiber.StatusForbidden},
+		{name: "locked out", err: &usecase.LockoutError{RetryAfter: time.Minute}, wantStatus: fiber.StatusTooManyRequests},
+		{name: "hidden", err: usecase.ErrNotFound, wantStatus: fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+
+			wid := uuid.New()
+			wm.On("Unlock", mock.Anything, wid, "wrong", mock.Anything).Return(usecase.WishlistUnlock{}, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/unlock", bytes.NewBufferString(`{"password":"wrong"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Empty(t, resp.Cookies())
+		})
+	}
+}
+
+func TestSetVisibility(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	wid := uuid.New()
+	input := usecase.SetVisibilityInput{Visibility: entity.VisibilityPassword, Password: "secret-1"}
+	wm.On("SetVisibility", mock.Anything, userID, wid, uint(3), input).
+		Return(entity.Wishlist{ID: wid, UserID: userID, Visibility: entity.VisibilityPassword}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/visibility",
+		bytes.NewBufferString(`{"visibility":"password","password":"secret-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	wm.AssertExpectations(t)
+}
+
+func TestSetSlug(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	wid := uuid.New()
+	input := usecase.SetSlugInput{Slug: "Новый год", Scope: entity.SlugScopeGlobal}
+	wm.On("SetSlug", mock.Anything, userID, wid, uint(3), input).
+		Return(entity.Wishlist{ID: wid, UserID: userID, Slug: "novyy-god", SlugScope: entity.SlugScopeGlobal}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/slug",
+		bytes.NewBufferString(`{"slug":"Новый год","scope":"global"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	wm.AssertExpectations(t)
+}
+
+func TestSetSlug_Taken(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	wid := uuid.New()
+	wm.On("SetSlug", mock.Anything, userID, wid, uint(3), mock.Anything).
+		Return(entity.Wishlist{}, fmt.Errorf("адрес уже занят: %w", usecase.ErrConflict))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/slug", bytes.NewBufferString(`{"slug":"party"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+}
+
+func TestGetOne_EventCountdown(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	upcoming, past, undated := uuid.New(), uuid.New(), uuid.New()
+	wm.On("GetByID", mock.Anything, mock.Anything, upcoming).
+		Return(entity.Wishlist{ID: upcoming, Location: entity.Location{Time: time.Now().Add(10*24*time.Hour + time.Hour)}}, nil)
+	wm.On("GetByID", mock.Anything, mock.Anything, past).
+		Return(entity.Wishlist{ID: past, Location: entity.Location{Time: time.Now().Add(-72 * time.Hour)}}, nil)
+	wm.On("GetByID", mock.Anything, mock.Anything, undated).
+		Return(entity.Wishlist{ID: undated}, nil)
+
+	get := func(id uuid.UUID) map[string]interface{} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+id.String(), nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var result struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result.Data
+	}
+
+	event := get(upcoming)["event"].(map[string]interface{})
+	assert.InDelta(t, 10, event["daysLeft"], 1)
+	assert.Equal(t, false, event["over"])
+
+	event = get(past)["event"].(map[string]interface{})
+	assert.Equal(t, float64(0), event["daysLeft"])
+	assert.Equal(t, true, event["over"])
+
+	data := get(undated)
+	assert.NotContains(t, data, "event")
+	assert.Equal(t, undated.String(), data["id"])
+}
+
+func TestSetEvent(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	wid := uuid.New()
+	eventTime := time.Date(2026, 3, 10, 16, 0, 0, 0, time.UTC)
+	input := usecase.SetEventInput{Time: eventTime, Timezone: "Europe/Moscow", Repeat: entity.EventRepeatYearly}
+	wm.On("SetEvent", mock.Anything, userID, wid, uint(3), input).
+		Return(entity.Wishlist{ID: wid, UserID: userID, Location: entity.Location{Time: eventTime, Timezone: "Europe/Moscow"}, EventRepeat: entity.EventRepeatYearly}, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/event",
+		bytes.NewBufferString(`{"time":"2026-03-10T16:00:00Z","timezone":"Europe/Moscow","repeat":"yearly"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data entity.Wishlist `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "Europe/Moscow", result.Data.Location.Timezone)
+	assert.Equal(t, entity.EventRepeatYearly, result.Data.EventRepeat)
+	wm.AssertExpectations(t)
+}
+
+func TestAddCollaborator(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID, wid, friendID := uuid.New(), uuid.New(), uuid.New()
+	wm.On("AddCollaborator", mock.Anything, userID, wid, "bob", entity.WishlistRoleEditor).
+		Return(entity.Collaborator{WishlistID: wid, UserID: friendID, Role: entity.WishlistRoleEditor, Handle: "bob"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/collaborators",
+		bytes.NewBufferString(`{"handle":"bob","role":"editor"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data entity.Collaborator `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, friendID, result.Data.UserID)
+	assert.Equal(t, "editor", result.Data.Role)
+}
+
+func TestRemoveCollaborator_Forbidden(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID, wid, otherID := uuid.New(), uuid.New(), uuid.New()
+	wm.On("RemoveCollaborator", mock.Anything, userID, wid, otherID).Return(usecase.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/wishlists/"+wid.String()+"/collaborators/"+otherID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestCreateInvite(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID, wid := uuid.New(), uuid.New()
+	wm.On("CreateInvite", mock.Anything, userID, wid, entity.WishlistRoleViewer).
+		Return(usecase.WishlistInviteLink{Token: "invite-token", Role: entity.WishlistRoleViewer, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/invites", bytes.NewBufferString(`{"role":"viewer"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	var result map[string]map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "invite-token", result["data"]["token"])
+}
+
+func TestAcceptInvite(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "joined", wantStatus: fiber.StatusOK},
+		{name: "invalid token", err: usecase.ErrInvalidToken, wantStatus: fiber.StatusBadRequest},
+		{name: "own wishlist", err: usecase.ErrConflict, wantStatus: fiber.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+
+			userID := uuid.New()
+			wm.On("AcceptInvite", mock.Anything, userID, "invite-token").
+				Return(entity.Wishlist{ID: uuid.New(), Role: entity.WishlistRoleViewer}, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/invites/accept", bytes.NewBufferString(`{"token":"invite-token"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetTrash(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID := uuid.New()
+	deletedAt := time.Now()
+	wm.On("GetTrash", mock.Anything, userID).Return(usecase.Trash{
+		Wishlists: []entity.Wishlist{{ID: uuid.New(), DeletedAt: &deletedAt}},
+		Presents:  []entity.Present{},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trash", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Wishlists []map[string]interface{} `json:"wishlists"`
+			Presents  []map[string]interface{} `json:"presents"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Data.Wishlists, 1)
+	assert.NotEmpty(t, result.Data.Wishlists[0]["deletedAt"])
+	assert.NotNil(t, result.Data.Presents)
+}
+
+func TestRestoreWishlist(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "restored", wantStatus: fiber.StatusOK},
+		{name: "not in trash", err: usecase.ErrNotFound, wantStatus: fiber.StatusNotFound},
+		{name: "limit reached", err: errors.New("достигнут лимит вишлистов (20)"), wantStatus: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+
+			userID, wid := uuid.New(), uuid.New()
+			wm.On("Restore", mock.Anything, userID, wid).Return(entity.Wishlist{ID: wid}, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/restore", nil)
+			req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantTitle string
+	}{
+		{name: "without body"},
+		{name: "with title", body: `{"title":"ДР 2026"}`, wantTitle: "ДР 2026"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			app := setupWishlistApp(wm)
+
+			userID, wid := uuid.New(), uuid.New()
+			wm.On("Clone", mock.Anything, userID, wid, tt.wantTitle).Return(entity.Wishlist{ID: uuid.New()}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/clone", bytes.NewBufferString(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			wm.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRevisions(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID, wid := uuid.New(), uuid.New()
+	wm.On("GetRevisions", mock.Anything, userID, wid).Return([]entity.WishlistRevision{
+		{WishlistID: wid, Number: 2, AuthorID: userID, AuthorHandle: "alice"},
+		{WishlistID: wid, Number: 1, AuthorID: uuid.Nil},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String()+"/revisions", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data []entity.WishlistRevision `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Data, 2)
+	assert.Equal(t, 2, result.Data[0].Number)
+	assert.Equal(t, "alice", result.Data[0].AuthorHandle)
+}
+
+func TestDiffRevisions(t *testing.T) {
+	userID, wid := uuid.New(), uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		app := setupWishlistApp(wm)
+		wm.On("DiffRevisions", mock.Anything, userID, wid, 1, 3).Return(entity.RevisionDiff{
+			From:  1,
+			To:    3,
+			Added: []entity.BlockChange{{Type: "text"}},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String()+"/revisions/diff?from=1&to=3", nil)
+		req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Data entity.RevisionDiff `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, 3, result.Data.To)
+		require.Len(t, result.Data.Added, 1)
+	})
+	t.Run("bad query", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		app := setupWishlistApp(wm)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String()+"/revisions/diff?from=1", nil)
+		req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		wm.AssertNotCalled(t, "DiffRevisions", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("unknown revision", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		app := setupWishlistApp(wm)
+		wm.On("DiffRevisions", mock.Anything, userID, wid, 1, 9).Return(entity.RevisionDiff{}, usecase.ErrNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String()+"/revisions/diff?from=1&to=9", nil)
+		req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestRestoreRevision(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	userID, wid := uuid.New(), uuid.New()
+	restored := entity.Wishlist{ID: wid, UserID: userID, Settings: entity.Settings{ColorScheme: "rose"}}
+	wm.On("RestoreRevision", mock.Anything, userID, wid, uint(3), 2).Return(restored, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wishlists/"+wid.String()+"/revisions/2/restore", nil)
+	req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+	req.Header.Set(fiber.HeaderIfMatch, `"3"`)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data entity.Wishlist `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "rose", result.Data.Settings.ColorScheme)
+	wm.AssertExpectations(t)
+}
+
+func TestGetOne_ETag(t *testing.T) {
+	wm := &MockWishlistUC{}
+	app := setupWishlistApp(wm)
+
+	wid := uuid.New()
+	wm.On("GetByID", mock.Anything, usecase.Viewer{}, wid).Return(entity.Wishlist{ID: wid, Version: 7}, nil)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/wishlists/"+wid.String(), nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"7"`, resp.Header.Get(fiber.HeaderETag))
+}
+
+func TestUpdateBlocks_IfMatch(t *testing.T) {
+	userID, wid := uuid.New(), uuid.New()
+	blocks := `[{"type":"divider","row":0,"col":0,"colSpan":2}]`
+	send := func(app *fiber.App, ifMatch string) *http.Response {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/wishlists/"+wid.String()+"/blocks", bytes.NewBufferString(blocks))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		resp := send(setupWishlistApp(wm), "")
+		assert.Equal(t, fiber.StatusPreconditionRequired, resp.StatusCode)
+		wm.AssertNotCalled(t, "UpdateBlocks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		resp := send(setupWishlistApp(wm), `W/"3"`)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("current", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		wm.On("UpdateBlocks", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{ID: wid, Version: 4}, nil)
+		resp := send(setupWishlistApp(wm), `"3"`)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"4"`, resp.Header.Get(fiber.HeaderETag))
+	})
+	t.Run("stale", func(t *testing.T) {
+		wm := &MockWishlistUC{}
+		current := entity.Wishlist{ID: wid, Title: "Из другой вкладки", Version: 5}
+		wm.On("UpdateBlocks", mock.Anything, userID, wid, uint(3), mock.Anything).
+			Return(entity.Wishlist{}, fmt.Errorf("update blocks: %w", &usecase.StaleWishlistError{Current: current}))
+		resp := send(setupWishlistApp(wm), `"3"`)
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+		assert.Equal(t, `"5"`, resp.Header.Get(fiber.HeaderETag))
+
+		var result struct {
+			Error string          `json:"error"`
+			Data  entity.Wishlist `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.NotEmpty(t, result.Error)
+		assert.Equal(t, "Из другой вкладки", result.Data.Title)
+		assert.Equal(t, uint(5), result.Data.Version)
+	})
+}
+
+func TestSettings_IfMatch(t *testing.T) {
+	userID, wid := uuid.New(), uuid.New()
+	current := entity.Wishlist{ID: wid, UserID: userID, Version: 5}
+	stale := fmt.Errorf("update wishlist: %w", &usecase.StaleWishlistError{Current: current})
+	routes := []struct {
+		method string
+		path   string
+		body   string
+		expect func(wm *MockWishlistUC)
+	}{
+		{http.MethodPut, "/profile", `{"listed":true}`, func(wm *MockWishlistUC) {
+			wm.On("SetListedOnProfile", mock.Anything, userID, wid, uint(3), true).Return(entity.Wishlist{}, stale)
+		}},
+		{http.MethodPut, "/visibility", `{"visibility":"private"}`, func(wm *MockWishlistUC) {
+			wm.On("SetVisibility", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{}, stale)
+		}},
+		{http.MethodPut, "/slug", `{"slug":"party"}`, func(wm *MockWishlistUC) {
+			wm.On("SetSlug", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{}, stale)
+		}},
+		{http.MethodPut, "/event", `{}`, func(wm *MockWishlistUC) {
+			wm.On("SetEvent", mock.Anything, userID, wid, uint(3), mock.Anything).Return(entity.Wishlist{}, stale)
+		}},
+		{http.MethodPost, "/revisions/2/restore", ``, func(wm *MockWishlistUC) {
+			wm.On("RestoreRevision", mock.Anything, userID, wid, uint(3), 2).Return(entity.Wishlist{}, stale)
+		}},
+	}
+	for _, rt := range routes {
+		send := func(app *fiber.App, ifMatch string) *http.Response {
+			req := httptest.NewRequest(rt.method, "/api/v1/wishlists/"+wid.String()+rt.path, bytes.NewBufferString(rt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+makeTestToken(userID))
+			if ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			return resp
+		}
+		t.Run(rt.path+"/missing", func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			resp := send(setupWishlistApp(wm), "")
+			assert.Equal(t, fiber.StatusPreconditionRequired, resp.StatusCode)
+		})
+		t.Run(rt.path+"/stale", func(t *testing.T) {
+			wm := &MockWishlistUC{}
+			rt.expect(wm)
+			resp := send(setupWishlistApp(wm), `"3"`)
+			assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+			assert.Equal(t, `"5"`, resp.Header.Get(fiber.HeaderETag))
+			wm.AssertExpectations(t)
+		})
+	}
+}
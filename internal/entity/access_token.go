@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessTokenPrefix отличает персональные токены от JWT в заголовке Authorization
+const AccessTokenPrefix = "pat_"
+
+// Скоупы персональных токенов доступа
+const (
+	ScopeProfileRead    = "profile:read"
+	ScopeWishlistsRead  = "wishlists:read"
+	ScopeWishlistsWrite = "wishlists:write"
+	ScopePresentsRead   = "presents:read"
+	ScopePresentsWrite  = "presents:write"
+	ScopeTemplatesRead  = "templates:read"
+	ScopeTemplatesWrite = "templates:write"
+	ScopeParse          = "parse"
+	ScopeUpload         = "upload"
+)
+
+// AccessTokenScopes — все допустимые скоупы
+var AccessTokenScopes = []string{
+	ScopeProfileRead,
+	ScopeWishlistsRead,
+	ScopeWishlistsWrite,
+	ScopePresentsRead,
+	ScopePresentsWrite,
+	ScopeTemplatesRead,
+	ScopeTemplatesWrite,
+	ScopeParse,
+	ScopeUpload,
+}
+
+// AccessToken — персональный токен доступа для скриптов и интеграций.
+// В базе хранится только хеш; сам токен показывается один раз при создании.
+type AccessToken struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"userId"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"` // nil — бессрочный
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Active — токен не истёк
+func (t AccessToken) Active(now time.Time) bool {
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// ValidScope — скоуп входит в AccessTokenScopes
+func ValidScope(scope string) bool {
+	for _, s := range AccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Роли пользователя в вишлисте. Владелец — автор вишлиста, остальные роли выдаёт он
+const (
+	WishlistRoleOwner  = "owner"
+	WishlistRoleEditor = "editor" // меняет поля, блоки и подарки
+	WishlistRoleViewer = "viewer" // видит вишлист при любой видимости, но не меняет
+)
+
+// ValidCollaboratorRole сообщает, можно ли выдать роль соавтору
+func ValidCollaboratorRole(role string) bool {
+	return role == WishlistRoleEditor || role == WishlistRoleViewer
+}
+
+// Collaborator — соавтор вишлиста. Handle, DisplayName и Avatar берутся из профиля пользователя.
+// Pending — приглашён по handle, но ещё не согласился: прав в вишлисте у него нет
+type Collaborator struct {
+	WishlistID  uuid.UUID `json:"wishlistId"`
+	UserID      uuid.UUID `json:"userId"`
+	Role        string    `json:"role"`
+	Pending     bool      `json:"pending"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"displayName"`
+	Avatar      string    `json:"avatar"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// WishlistInvite — одноразовая ссылка-приглашение в соавторы. В базе хранится только хеш токена
+type WishlistInvite struct {
+	ID         uuid.UUID
+	WishlistID uuid.UUID
+	Role       string
+	TokenHash  string
+	CreatedBy  uuid.UUID
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Статусы выгрузки персональных данных
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+)
+
+// DataExport — ZIP-архив со всеми данными пользователя. Собирается фоновой задачей
+// и доступен для скачивания до ExpiresAt, после чего архив удаляется из хранилища.
+type DataExport struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      string
+	ObjectID    string // объект архива в файловом хранилище, пока не Ready — пустой
+	Size        int64
+	StartedAt   *time.Time // когда задача взяла выгрузку в работу
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// Downloadable — архив готов и ещё не истёк
+func (e DataExport) Downloadable(now time.Time) bool {
+	return e.Status == DataExportReady && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+// InProgress — выгрузка ещё собирается
+func (e DataExport) InProgress() bool {
+	return e.Status == DataExportPending || e.Status == DataExportProcessing
+}
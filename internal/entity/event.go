@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// EventStatus — обратный отсчёт до события вишлиста
+type EventStatus struct {
+	DaysLeft int  `json:"daysLeft"` // календарных дней до дня события в его часовом поясе; 0 — сегодня или прошло
+	Over     bool `json:"over"`     // день события закончился
+}
+
+// Zone возвращает часовой пояс события; пустая или неизвестная зона — UTC
+func (l Location) Zone() *time.Location {
+	if l.Timezone == "" {
+		return time.UTC
+	}
+	zone, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return zone
+}
+
+// EventEnd возвращает конец дня события в его часовом поясе; false — дата не задана
+func (l Location) EventEnd() (time.Time, bool) {
+	if l.Time.IsZero() {
+		return time.Time{}, false
+	}
+	t := l.Time.In(l.Zone())
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()), true
+}
+
+// Status возвращает обратный отсчёт на момент now; false — дата не задана
+func (l Location) Status(now time.Time) (EventStatus, bool) {
+	end, ok := l.EventEnd()
+	if !ok {
+		return EventStatus{}, false
+	}
+	if !now.Before(end) {
+		return EventStatus{Over: true}, true
+	}
+	// Считаем по календарным датам, чтобы переход на летнее время не сдвигал счёт
+	today := now.In(end.Location())
+	event := l.Time.In(end.Location())
+	days := civilDate(event).Sub(civilDate(today)) / (24 * time.Hour)
+	return EventStatus{DaysLeft: int(days)}, true
+}
+
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdentityProviderTelegram — вход через Telegram Login Widget; Subject — числовой Telegram ID
+const IdentityProviderTelegram = "telegram"
+
+// UserIdentity — внешний способ входа, привязанный к пользователю.
+// Пара (Provider, Subject) уникальна; профильные поля обновляются при каждом входе.
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"userId"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	PhotoURL  string    `json:"photoUrl"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -17,4 +17,8 @@ type Present struct {
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 	WishlistID  uuid.UUID `json:"wishlistId"`
+	// Version растёт с каждой правкой и бронированием; клиент получает его в ETag и возвращает в If-Match
+	Version uint `json:"version"`
+	// DeletedAt — когда подарок перенесён в корзину; nil — не удалён
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WishlistRevision — сохранённое состояние блоков и настроек вишлиста. Number растёт с каждой правкой;
+// AuthorHandle и AuthorName берутся из профиля автора, у удалённого аккаунта пустые
+type WishlistRevision struct {
+	ID           uuid.UUID `json:"id"`
+	WishlistID   uuid.UUID `json:"wishlistId"`
+	Number       int       `json:"number"`
+	AuthorID     uuid.UUID `json:"authorId"`
+	AuthorHandle string    `json:"authorHandle,omitempty"`
+	AuthorName   string    `json:"authorName,omitempty"`
+	// RestoredFrom — номер ревизии, откатом к которой создана эта
+	RestoredFrom *int      `json:"restoredFrom,omitempty"`
+	Blocks       []Block   `json:"blocks,omitempty"`
+	Settings     Settings  `json:"settings"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// BlockPosition — место блока в сетке конструктора
+type BlockPosition struct {
+	Row     int `json:"row"`
+	Col     int `json:"col"`
+	ColSpan int `json:"colSpan"`
+}
+
+// FieldChange — изменённое поле данных блока или настроек; nil — поля не было
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// BlockChange — блок в сравнении ревизий. FromIndex и From относятся к старой ревизии,
+// ToIndex и To — к новой; у добавленного блока нет первых, у удалённого — вторых
+type BlockChange struct {
+	Type      string         `json:"type"`
+	FromIndex *int           `json:"fromIndex,omitempty"`
+	ToIndex   *int           `json:"toIndex,omitempty"`
+	From      *BlockPosition `json:"from,omitempty"`
+	To        *BlockPosition `json:"to,omitempty"`
+	Fields    []FieldChange  `json:"fields,omitempty"`
+}
+
+// RevisionDiff — структурное сравнение двух ревизий
+type RevisionDiff struct {
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Added    []BlockChange `json:"added"`
+	Removed  []BlockChange `json:"removed"`
+	Moved    []BlockChange `json:"moved"`   // те же данные на другом месте
+	Changed  []BlockChange `json:"changed"` // блок того же типа на том же месте с другими данными
+	Settings []FieldChange `json:"settings"`
+}
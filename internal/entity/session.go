@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session — серверная сессия входа. Refresh-токены одной сессии образуют семейство:
+// при каждом обновлении токен ротируется, а повторное использование старого отзывает сессию целиком.
+type Session struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	RefreshTokenHash string
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+	UserAgent        string // UserAgent и IP — клиент, с которого последний раз входили или обновляли токены
+	IP               string
+	LastSeenAt       time.Time // время последнего входа или обновления токенов
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Active — сессия не отозвана и не истекла
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package entity
+
+// InstanceStats — счётчики по всему инстансу
+type InstanceStats struct {
+	Users           int64 `json:"users"`
+	BannedUsers     int64 `json:"bannedUsers"`
+	Wishlists       int64 `json:"wishlists"`
+	Presents        int64 `json:"presents"`
+	Templates       int64 `json:"templates"`
+	PublicTemplates int64 `json:"publicTemplates"`
+	ActiveSessions  int64 `json:"activeSessions"`
+}
@@ -1,6 +1,30 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Роли пользователей по возрастанию прав
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+var roleRanks = map[string]int{RoleUser: 0, RoleModerator: 1, RoleAdmin: 2}
+
+// ValidRole сообщает, известна ли роль
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast сообщает, что role не ниже min. Неизвестная роль приравнивается к RoleUser
+func RoleAtLeast(role, min string) bool {
+	return roleRanks[role] >= roleRanks[min]
+}
 
 type User struct {
 	ID          uuid.UUID
@@ -8,4 +32,36 @@ type User struct {
 	Password    string
 	DisplayName string
 	Avatar      string
+	// Handle — публичный адрес профиля (/users/:handle) в нижнем регистре; пустой — профиля нет
+	Handle string
+	// Email необязателен и попадает сюда только после подтверждения, в нижнем регистре
+	Email         string
+	EmailVerified bool
+	// TOTPSecret задаётся при начале подключения 2FA, TOTPEnabled — после подтверждения кодом.
+	// TOTPLastStep — последний принятый шаг, чтобы один код нельзя было использовать дважды.
+	TOTPSecret   string
+	TOTPEnabled  bool
+	TOTPLastStep int64
+	Role         string
+	// BannedAt задан у заблокированных модератором пользователей: войти они не могут
+	BannedAt  *time.Time
+	BanReason string
+	// DeletionScheduledAt — когда аккаунт будет удалён окончательно; вход до этого момента отменяет удаление
+	DeletionScheduledAt *time.Time
+}
+
+func (u User) Banned() bool {
+	return u.BannedAt != nil
+}
+
+func (u User) DeletionScheduled() bool {
+	return u.DeletionScheduledAt != nil
+}
+
+// HandleRedirect — прежний handle пользователя: по нему профиль отвечает редиректом на текущий,
+// а занять его другому пользователю нельзя, пока не истечёт срок удержания
+type HandleRedirect struct {
+	Handle    string
+	UserID    uuid.UUID
+	CreatedAt time.Time
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Назначения одноразовых токенов
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify" // Payload — подтверждаемый адрес
+	TokenPurposeMagicLink     = "magic_link"
+	TokenPurposeTOTPRecovery  = "totp_recovery"
+)
+
+// UserToken — одноразовый токен из письма. В базе хранится только хеш,
+// Payload — дополнительные данные назначения (например, подтверждаемый адрес).
+type UserToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	Payload   string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
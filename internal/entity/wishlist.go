@@ -16,7 +16,9 @@ type Settings struct {
 type Location struct {
 	Name string    `json:"name"`
 	Link string    `json:"link"`
-	Time time.Time `json:"time"`
+	Time time.Time `json:"time"` // время события; нулевое — дата не задана
+	// Timezone — IANA-зона события, например Europe/Moscow; пустая — UTC
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // Block — один блок конструктора вишлиста (координатная модель)
@@ -46,6 +48,35 @@ var ValidBlockTypes = map[string]bool{
 	"checklist":    true,
 }
 
+// Видимость вишлиста для всех, кроме владельца
+const (
+	VisibilityUnlisted = "unlisted" // открыт всем, у кого есть ссылка
+	VisibilityPrivate  = "private"  // виден только владельцу и соавторам
+	VisibilityPassword = "password" // открывается по ссылке после ввода пароля
+)
+
+// ValidVisibility сообщает, известно ли значение видимости
+func ValidVisibility(v string) bool {
+	return v == VisibilityUnlisted || v == VisibilityPrivate || v == VisibilityPassword
+}
+
+// Повтор события вишлиста
+const (
+	EventRepeatNone   = ""
+	EventRepeatYearly = "yearly" // дни рождения и годовщины: после события создаётся выпуск на следующий год
+)
+
+// ValidEventRepeat сообщает, известно ли значение повтора
+func ValidEventRepeat(v string) bool {
+	return v == EventRepeatNone || v == EventRepeatYearly
+}
+
+// Пространство имён slug — человекочитаемого адреса вишлиста
+const (
+	SlugScopeProfile = "profile" // /users/<handle>/wishlists/<slug>, уникален среди вишлистов владельца
+	SlugScopeGlobal  = "global"  // /wishlists/s/<slug>, уникален на всём инстансе
+)
+
 type Wishlist struct {
 	ID            uuid.UUID `json:"id"`
 	Title         string    `json:"title"`
@@ -56,7 +87,37 @@ type Wishlist struct {
 	Location      Location  `json:"location"`
 	PresentsCount uint      `json:"presentsCount"`
 	ShortID       string    `json:"shortId"`  // короткий публичный ID вида abc-def-ghi (nullable в БД)
+	// Slug — адрес, выбранный владельцем, в пространстве SlugScope; пустой — только короткий ID
+	Slug          string    `json:"slug,omitempty"`
+	SlugScope     string    `json:"slugScope,omitempty"`
 	Blocks        []Block   `json:"blocks"`   // nil = простой вишлист
+	Unpublished   bool      `json:"unpublished"` // снят с публикации модератором, виден только владельцу
+	ListedOnProfile bool    `json:"listedOnProfile"` // показан на публичной странице владельца
+	Visibility    string    `json:"visibility"`
+	// Role — роль текущего пользователя (WishlistRole*); заполняется, когда она известна
+	Role          string    `json:"role,omitempty"`
+	// PasswordHash задан только у вишлистов с VisibilityPassword
+	PasswordHash  string    `json:"-"`
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
+	// Version растёт с каждой правкой; клиент получает его в ETag и возвращает в If-Match
+	Version       uint      `json:"version"`
+	// DeletedAt — когда вишлист перенесён в корзину; nil — не удалён
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
+	EventRepeat   string     `json:"eventRepeat,omitempty"` // EventRepeat*
+	// ArchivedAt — когда вишлист ушёл в архив после события; NextEditionID — выпуск
+	// повторяющегося события на следующий год
+	ArchivedAt    *time.Time `json:"archivedAt,omitempty"`
+	NextEditionID *uuid.UUID `json:"nextEditionId,omitempty"`
+}
+
+// WishlistSlug — slug, закреплённый за вишлистом. Прежние slug остаются за вишлистом
+// и ведут на него, пока их не займёт другой
+type WishlistSlug struct {
+	Scope      uuid.UUID // владелец для SlugScopeProfile, uuid.Nil для SlugScopeGlobal
+	Slug       string
+	WishlistID uuid.UUID
+	CreatedAt  time.Time
+	// ReleasedAt — когда вишлист сменил этот slug на другой; nil — slug текущий
+	ReleasedAt *time.Time
 }
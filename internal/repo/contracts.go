@@ -9,31 +9,231 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserColumn — поле пользователя, которое записывает UserRepo.UpdateColumns
+type UserColumn string
+
+const (
+	UserColumnPassword            UserColumn = "Password"
+	UserColumnDisplayName         UserColumn = "DisplayName"
+	UserColumnAvatar              UserColumn = "Avatar"
+	UserColumnEmail               UserColumn = "Email"
+	UserColumnEmailVerified       UserColumn = "EmailVerified"
+	UserColumnTOTPSecret          UserColumn = "TOTPSecret"
+	UserColumnTOTPEnabled         UserColumn = "TOTPEnabled"
+	UserColumnTOTPLastStep        UserColumn = "TOTPLastStep"
+	UserColumnRole                UserColumn = "Role"
+	UserColumnBannedAt            UserColumn = "BannedAt"
+	UserColumnBanReason           UserColumn = "BanReason"
+	UserColumnDeletionScheduledAt UserColumn = "DeletionScheduledAt"
+)
+
 type UserRepo interface {
 	Create(ctx context.Context, user entity.User) error
 	GetByUsername(ctx context.Context, username string) (entity.User, error)
+	// GetByVerifiedEmail ищет пользователя только среди подтверждённых адресов
+	GetByVerifiedEmail(ctx context.Context, email string) (entity.User, error)
 	GetByID(ctx context.Context, id uuid.UUID) (entity.User, error)
-	Update(ctx context.Context, user entity.User) error
+	// GetByHandle ищет пользователя по текущему handle, прежние handle не учитываются
+	GetByHandle(ctx context.Context, handle string) (entity.User, error)
+	// UpdateColumns записывает из user только перечисленные поля: параллельные изменения
+	// остальных (бан, роль, профиль) не перетираются прочитанными ранее значениями
+	UpdateColumns(ctx context.Context, user entity.User, columns ...UserColumn) error
+	// ReplacePasswordHash меняет хеш пароля, только если сохранён всё ещё oldHash;
+	// иначе — ErrNotFound: пароль успели сменить
+	ReplacePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error
+	// UseTOTPStep запоминает принятый шаг TOTP, только если он новее сохранённого;
+	// иначе — ErrNotFound: код этого шага уже использован
+	UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) error
+	// ChangeHandle в одной транзакции меняет handle пользователя (пустой — убирает), переносит
+	// прежний в историю редиректов и удаляет из истории запись нового handle
+	ChangeHandle(ctx context.Context, userID uuid.UUID, handle string, now time.Time) error
+	// Search ищет по подстроке username, имени и email (пустой query — все) и возвращает общее число совпадений
+	Search(ctx context.Context, query string, limit, offset int) ([]entity.User, int64, error)
+	// GetDueForDeletion возвращает аккаунты, у которых истёк срок до окончательного удаления
+	GetDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entity.User, error)
+	// Delete удаляет пользователя и всё, чем он владеет, в одной транзакции: вишлисты с подарками,
+	// метаданными, соавторами, приглашениями и slug, участие в чужих вишлистах, шаблоны и лайки, identity,
+	// сессии, токены, выгрузки, историю handle и счётчики лимитов
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// HandleRedirectRepo — история прежних handle пользователей
+type HandleRedirectRepo interface {
+	GetByHandle(ctx context.Context, handle string) (entity.HandleRedirect, error)
+}
+
+// FileRefRepo ищет ссылки на загруженные файлы во всём контенте инстанса
+type FileRefRepo interface {
+	// Unreferenced возвращает те из urls, на которые не ссылается ни аватар, ни обложка, ни блок
+	Unreferenced(ctx context.Context, urls []string) ([]string, error)
+}
+
+type UserTokenRepo interface {
+	Create(ctx context.Context, token entity.UserToken) error
+	// Consume атомарно помечает неиспользованный и неистёкший токен использованным и возвращает его
+	Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (entity.UserToken, error)
+	// InvalidateAll гасит все активные токены пользователя с данным назначением
+	InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error
+}
+
+type AccessTokenRepo interface {
+	Create(ctx context.Context, token entity.AccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (entity.AccessToken, error)
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error)
+	// Touch обновляет время последнего использования
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type DataExportRepo interface {
+	Create(ctx context.Context, export entity.DataExport) error
+	GetByID(ctx context.Context, id uuid.UUID) (entity.DataExport, error)
+	// GetAllByUserID возвращает выгрузки пользователя, новые — первыми
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error)
+	// ClaimNext атомарно переводит самую старую ожидающую выгрузку в processing и возвращает её.
+	// Выгрузки, взятые в работу раньше staleBefore, считаются брошенными упавшим инстансом и берутся снова.
+	// ok=false — брать нечего
+	ClaimNext(ctx context.Context, now, staleBefore time.Time) (export entity.DataExport, ok bool, err error)
+	Update(ctx context.Context, export entity.DataExport) error
+	// GetExpired возвращает выгрузки с истёкшим сроком хранения
+	GetExpired(ctx context.Context, now time.Time, limit int) ([]entity.DataExport, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type IdentityRepo interface {
+	// CreateWithUser создаёт пользователя и его первую identity в одной транзакции
+	CreateWithUser(ctx context.Context, user entity.User, identity entity.UserIdentity) error
+	Create(ctx context.Context, identity entity.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error)
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error)
+	Update(ctx context.Context, identity entity.UserIdentity) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+type SessionRepo interface {
+	Create(ctx context.Context, session entity.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Session, error)
+	// Rotate atomically replaces the refresh token hash if it still equals oldHash
+	// and the session is not revoked. Returns false when nothing was updated.
+	Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error)
+	// Touch запоминает клиента и время последнего обновления сессии
+	Touch(ctx context.Context, id uuid.UUID, seenAt time.Time, ip, userAgent string) error
+	// GetActiveByUserID возвращает неотозванные и неистёкшие сессии, последние активные — первыми
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]entity.Session, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeAllByUserID отзывает все сессии пользователя, кроме exceptID (uuid.Nil — все)
+	RevokeAllByUserID(ctx context.Context, userID, exceptID uuid.UUID) error
+}
+
+// WishlistRepo — все методы, кроме GetDeleted* и Purge, не видят вишлисты из корзины
 type WishlistRepo interface {
 	Create(ctx context.Context, wishlist entity.Wishlist) error
+	// CreateWithPresents создаёт вишлист вместе с подарками и их метаданными в одной транзакции
+	CreateWithPresents(ctx context.Context, wishlist entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error
 	GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error)
 	GetByShortID(ctx context.Context, shortID string) (entity.Wishlist, error)
+	// GetBySlug ищет вишлист по текущему или прежнему slug в пространстве scope
+	// (owner ID или uuid.Nil для глобальных)
+	GetBySlug(ctx context.Context, scope uuid.UUID, slug string) (entity.Wishlist, error)
+	// GetSlug возвращает запись о slug, в том числе закреплённом за вишлистом в корзине
+	GetSlug(ctx context.Context, scope uuid.UUID, slug string) (entity.WishlistSlug, error)
+	// ChangeSlug в одной транзакции меняет slug вишлиста (пустой — убирает), оставляет прежний
+	// за вишлистом и увеличивает версию. Чужой slug забирается, только если тот уже сменён своим вишлистом.
+	// Если версия в базе уже не version, возвращает gorm.ErrRecordNotFound, если slug занят — ErrConflict
+	ChangeSlug(ctx context.Context, id uuid.UUID, version uint, scope uuid.UUID, slug string, now time.Time) error
 	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	// GetListedByUserID возвращает опубликованные открытые по ссылке неархивные вишлисты, которые
+	// владелец показывает в профиле
+	GetListedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	// GetSharedWithUserID возвращает чужие вишлисты, в которых пользователь соавтор, с его ролью в Role.
+	// Неподтверждённые приглашения не учитываются
+	GetSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	// GetPendingSharedWithUserID возвращает вишлисты, куда пользователя пригласили, а он ещё не ответил,
+	// с предложенной ролью в Role
+	GetPendingSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	// Update записывает вишлист, только если его версия в базе всё ещё wishlist.Version, и увеличивает её;
+	// иначе возвращает gorm.ErrRecordNotFound. Не трогает slug, архивные поля и счётчик подарков:
+	// их меняют ChangeSlug, Archive, Unarchive и Increment/DecrementPresentsCount
 	Update(ctx context.Context, wishlist entity.Wishlist) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// GetEventsEndedBefore возвращает неархивные вишлисты, день события которых закончился раньше before
+	GetEventsEndedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error)
+	// Archive убирает вишлист в архив. Если next задан, в той же транзакции создаёт следующий выпуск
+	// с подарками, их метаданными и соавторами и переносит на него slug. Уже архивный вишлист не найдётся
+	Archive(ctx context.Context, id uuid.UUID, now time.Time, next *entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error
+	// Unarchive возвращает вишлист из архива, если следующий выпуск для него не создавался
+	Unarchive(ctx context.Context, id uuid.UUID) error
+	// Delete переносит вишлист версии version в корзину; подарки, соавторы и приглашения остаются на месте
+	Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error
+	// Restore возвращает вишлист из корзины
+	Restore(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByID ищет вишлист только в корзине
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error)
+	// GetDeletedByUserID возвращает вишлисты пользователя из корзины, удалённые последними — первыми
+	GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	// GetDeletedBefore возвращает вишлисты, перенесённые в корзину раньше before
+	GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error)
+	// Purge удаляет вишлист окончательно вместе с подарками, их метаданными, соавторами, приглашениями и slug
+	Purge(ctx context.Context, id uuid.UUID) error
 	IncrementPresentsCount(ctx context.Context, id uuid.UUID) error
 	DecrementPresentsCount(ctx context.Context, id uuid.UUID) error
+	// CountByUserID не считает архивные вишлисты
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 }
 
+// CollaboratorRepo — соавторы вишлистов
+type CollaboratorRepo interface {
+	// Upsert добавляет соавтора или меняет роль уже добавленного; Pending у существующего не меняется
+	Upsert(ctx context.Context, c entity.Collaborator) error
+	// Accept принимает приглашение; ErrNotFound — неподтверждённого соавтора нет
+	Accept(ctx context.Context, wishlistID, userID uuid.UUID) error
+	// Get возвращает соавтора в том числе с неподтверждённым приглашением
+	Get(ctx context.Context, wishlistID, userID uuid.UUID) (entity.Collaborator, error)
+	// GetAllByWishlistID возвращает соавторов и приглашённых с данными их профилей, давние — первыми
+	GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Collaborator, error)
+	// CountByWishlistID учитывает и неподтверждённые приглашения
+	CountByWishlistID(ctx context.Context, wishlistID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, wishlistID, userID uuid.UUID) error
+}
+
+// RevisionRepo — история блоков и настроек вишлистов
+type RevisionRepo interface {
+	// Create присваивает ревизии следующий номер и удаляет самые старые, оставляя keep последних
+	Create(ctx context.Context, rev entity.WishlistRevision, keep int) (entity.WishlistRevision, error)
+	// GetAllByWishlistID возвращает ревизии без блоков, новые — первыми
+	GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.WishlistRevision, error)
+	GetByNumber(ctx context.Context, wishlistID uuid.UUID, number int) (entity.WishlistRevision, error)
+	GetLatest(ctx context.Context, wishlistID uuid.UUID) (entity.WishlistRevision, error)
+}
+
+// WishlistInviteRepo — одноразовые приглашения в соавторы
+type WishlistInviteRepo interface {
+	Create(ctx context.Context, invite entity.WishlistInvite) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (entity.WishlistInvite, error)
+	// Consume атомарно помечает неиспользованное и неистёкшее приглашение использованным и возвращает его
+	Consume(ctx context.Context, tokenHash string, now time.Time) (entity.WishlistInvite, error)
+}
+
+// PresentRepo — все методы, кроме GetDeleted* и Purge, не видят подарки из корзины
 type PresentRepo interface {
 	Create(ctx context.Context, present entity.Present) error
 	GetByID(ctx context.Context, id uuid.UUID) (entity.Present, error)
 	GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error)
+	// Update, как и WishlistRepo.Update, записывает подарок только той версии, что в present.Version
 	Update(ctx context.Context, present entity.Present) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete переносит подарок версии version в корзину
+	Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error
+	// Restore возвращает подарок из корзины
+	Restore(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByID ищет подарок только в корзине
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Present, error)
+	// GetDeletedByUserID возвращает подарки из корзины в неудалённых вишлистах пользователя,
+	// удалённые последними — первыми
+	GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Present, error)
+	GetDeletedByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error)
+	// GetDeletedBefore возвращает подарки, перенесённые в корзину раньше before
+	GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Present, error)
+	// Purge удаляет подарок окончательно вместе с метаданными
+	Purge(ctx context.Context, id uuid.UUID) error
 	CountByWishlistID(ctx context.Context, wishlistID uuid.UUID) (int64, error)
 }
 
@@ -44,8 +244,32 @@ type ParseRateLimitRepo interface {
 	IncrementAndCheck(ctx context.Context, userID uuid.UUID, windowStart time.Time) (int, error)
 }
 
+// LoginAttemptRepo хранит счётчики неудачных входов по произвольным ключам
+// (аккаунт, IP), общие для всех инстансов
+type LoginAttemptRepo interface {
+	// RegisterFailure atomically increments the failure counter for key and
+	// returns the new value. The counter starts over if the previous failure
+	// is older than resetAfter.
+	RegisterFailure(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (int, error)
+	// Lock блокирует ключ до until
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil возвращает самую позднюю действующую блокировку среди keys (нулевое время — блокировок нет)
+	LockedUntil(ctx context.Context, keys []string, now time.Time) (time.Time, error)
+	// Reset удаляет счётчик и возвращает число накопленных неудач
+	Reset(ctx context.Context, key string) (int, error)
+	// Acquire атомарно блокирует key до now+cooldown, если действующей блокировки нет.
+	// false — ключ ещё заблокирован, блокировка не продлевается
+	Acquire(ctx context.Context, key string, now time.Time, cooldown time.Duration) (bool, error)
+}
+
+// StatsRepo — агрегаты по всему инстансу для админки
+type StatsRepo interface {
+	Get(ctx context.Context, now time.Time) (entity.InstanceStats, error)
+}
+
 type PresentMetaRepo interface {
 	Upsert(ctx context.Context, meta entity.PresentMeta) error
+	GetByPresentIDs(ctx context.Context, presentIDs []uuid.UUID) ([]entity.PresentMeta, error)
 }
 
 type TemplateRepo interface {
@@ -58,4 +282,6 @@ type TemplateRepo interface {
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
 	Like(ctx context.Context, userID, templateID uuid.UUID) (int, error)
 	Unlike(ctx context.Context, userID, templateID uuid.UUID) (int, error)
+	// GetLikedByUserID возвращает шаблоны, которые лайкнул пользователь, последние лайки — первыми
+	GetLikedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Template, error)
 }
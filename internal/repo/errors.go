@@ -0,0 +1,15 @@
+package repo
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound — запись не найдена. Реализации возвращают его обёрнутым через %w,
+// так что проверять нужно через errors.Is
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// ErrConflict — запись нарушила бы уникальность: например, slug успел занять другой вишлист
+// между проверкой в use case и записью
+var ErrConflict = errors.New("conflict")
@@ -0,0 +1,64 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+)
+
+type accessTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepo(db *gorm.DB) *accessTokenRepo {
+	return &accessTokenRepo{db: db}
+}
+
+func (r *accessTokenRepo) Create(ctx context.Context, token entity.AccessToken) error {
+	m := toAccessTokenModel(token)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("accessTokenRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepo) GetByHash(ctx context.Context, tokenHash string) (entity.AccessToken, error) {
+	var m AccessTokenModel
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		return entity.AccessToken{}, fmt.Errorf("accessTokenRepo.GetByHash: %w", err)
+	}
+	return toAccessTokenEntity(m), nil
+}
+
+func (r *accessTokenRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error) {
+	var models []AccessTokenModel
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("accessTokenRepo.GetAllByUserID: %w", err)
+	}
+	result := make([]entity.AccessToken, len(models))
+	for i, m := range models {
+		result[i] = toAccessTokenEntity(m)
+	}
+	return result, nil
+}
+
+func (r *accessTokenRepo) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&AccessTokenModel{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error; err != nil {
+		return fmt.Errorf("accessTokenRepo.Touch: %w", err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Delete(&AccessTokenModel{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("accessTokenRepo.Delete: %w", err)
+	}
+	return nil
+}
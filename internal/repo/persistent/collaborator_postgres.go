@@ -0,0 +1,155 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/internal/entity"
+	"main/internal/repo"
+)
+
+type collaboratorRepo struct {
+	db *gorm.DB
+}
+
+func NewCollaboratorRepo(db *gorm.DB) repo.CollaboratorRepo {
+	return &collaboratorRepo{db: db}
+}
+
+func (r *collaboratorRepo) Upsert(ctx context.Context, c entity.Collaborator) error {
+	m := CollaboratorModel{WishlistID: c.WishlistID, UserID: c.UserID, Role: c.Role, Pending: c.Pending, CreatedAt: c.CreatedAt}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wishlist_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(&m).Error; err != nil {
+		return fmt.Errorf("collaboratorRepo.Upsert: %w", err)
+	}
+	return nil
+}
+
+func (r *collaboratorRepo) Accept(ctx context.Context, wishlistID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&CollaboratorModel{}).
+		Where("wishlist_id = ? AND user_id = ? AND pending", wishlistID, userID).
+		Update("pending", false)
+	if result.Error != nil {
+		return fmt.Errorf("collaboratorRepo.Accept: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("collaboratorRepo.Accept: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// collaboratorRow — соавтор вместе с полями профиля из users
+type collaboratorRow struct {
+	CollaboratorModel
+	Handle      *string
+	DisplayName string
+	Avatar      string
+}
+
+func (r *collaboratorRepo) query(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("wishlist_collaborators c").
+		Select("c.*, u.handle, u.display_name, u.avatar").
+		Joins("JOIN users u ON u.id = c.user_id")
+}
+
+func (r *collaboratorRepo) Get(ctx context.Context, wishlistID, userID uuid.UUID) (entity.Collaborator, error) {
+	var row collaboratorRow
+	if err := r.query(ctx).
+		Where("c.wishlist_id = ? AND c.user_id = ?", wishlistID, userID).
+		Take(&row).Error; err != nil {
+		return entity.Collaborator{}, fmt.Errorf("collaboratorRepo.Get: %w", err)
+	}
+	return toCollaboratorEntity(row), nil
+}
+
+func (r *collaboratorRepo) GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Collaborator, error) {
+	var rows []collaboratorRow
+	if err := r.query(ctx).
+		Where("c.wishlist_id = ?", wishlistID).
+		Order("c.created_at").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("collaboratorRepo.GetAllByWishlistID: %w", err)
+	}
+	collaborators := make([]entity.Collaborator, len(rows))
+	for i, row := range rows {
+		collaborators[i] = toCollaboratorEntity(row)
+	}
+	return collaborators, nil
+}
+
+func (r *collaboratorRepo) CountByWishlistID(ctx context.Context, wishlistID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&CollaboratorModel{}).
+		Where("wishlist_id = ?", wishlistID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("collaboratorRepo.CountByWishlistID: %w", err)
+	}
+	return count, nil
+}
+
+func (r *collaboratorRepo) Delete(ctx context.Context, wishlistID, userID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("wishlist_id = ? AND user_id = ?", wishlistID, userID).
+		Delete(&CollaboratorModel{}).Error; err != nil {
+		return fmt.Errorf("collaboratorRepo.Delete: %w", err)
+	}
+	return nil
+}
+
+func toCollaboratorEntity(row collaboratorRow) entity.Collaborator {
+	return entity.Collaborator{
+		WishlistID:  row.WishlistID,
+		UserID:      row.UserID,
+		Role:        row.Role,
+		Pending:     row.Pending,
+		Handle:      derefString(row.Handle),
+		DisplayName: row.DisplayName,
+		Avatar:      row.Avatar,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+type wishlistInviteRepo struct {
+	db *gorm.DB
+}
+
+func NewWishlistInviteRepo(db *gorm.DB) repo.WishlistInviteRepo {
+	return &wishlistInviteRepo{db: db}
+}
+
+func (r *wishlistInviteRepo) Create(ctx context.Context, invite entity.WishlistInvite) error {
+	m := toWishlistInviteModel(invite)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("wishlistInviteRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *wishlistInviteRepo) GetByTokenHash(ctx context.Context, tokenHash string) (entity.WishlistInvite, error) {
+	var m WishlistInviteModel
+	if err := r.db.WithContext(ctx).First(&m, "token_hash = ?", tokenHash).Error; err != nil {
+		return entity.WishlistInvite{}, fmt.Errorf("wishlistInviteRepo.GetByTokenHash: %w", err)
+	}
+	return toWishlistInviteEntity(m), nil
+}
+
+func (r *wishlistInviteRepo) Consume(ctx context.Context, tokenHash string, now time.Time) (entity.WishlistInvite, error) {
+	var models []WishlistInviteModel
+	result := r.db.WithContext(ctx).Model(&models).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return entity.WishlistInvite{}, fmt.Errorf("wishlistInviteRepo.Consume: %w", result.Error)
+	}
+	if len(models) == 0 {
+		return entity.WishlistInvite{}, fmt.Errorf("wishlistInviteRepo.Consume: %w", gorm.ErrRecordNotFound)
+	}
+	return toWishlistInviteEntity(models[0]), nil
+}
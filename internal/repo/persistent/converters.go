@@ -2,6 +2,8 @@ package persistent
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
 	"main/internal/entity"
 )
@@ -10,50 +12,266 @@ import (
 
 func toUserEntity(m UserModel) entity.User {
 	return entity.User{
-		ID:          m.ID,
-		Username:    m.Username,
-		Password:    m.Password,
-		DisplayName: m.DisplayName,
-		Avatar:      m.Avatar,
+		ID:            m.ID,
+		Username:      m.Username,
+		Password:      m.Password,
+		DisplayName:   m.DisplayName,
+		Avatar:        m.Avatar,
+		Handle:        derefString(m.Handle),
+		Email:         derefString(m.Email),
+		EmailVerified: m.EmailVerified,
+		TOTPSecret:    m.TOTPSecret,
+		TOTPEnabled:   m.TOTPEnabled,
+		TOTPLastStep:  m.TOTPLastStep,
+		Role:          m.Role,
+		BannedAt:      m.BannedAt,
+		BanReason:     m.BanReason,
+
+		DeletionScheduledAt: m.DeletionScheduledAt,
 	}
 }
 
 func toUserModel(u entity.User) UserModel {
 	return UserModel{
-		ID:          u.ID,
-		Username:    u.Username,
-		Password:    u.Password,
-		DisplayName: u.DisplayName,
-		Avatar:      u.Avatar,
+		ID:            u.ID,
+		Username:      u.Username,
+		Password:      u.Password,
+		DisplayName:   u.DisplayName,
+		Avatar:        u.Avatar,
+		Handle:        nullableString(u.Handle),
+		Email:         nullableString(u.Email),
+		EmailVerified: u.EmailVerified,
+		TOTPSecret:    u.TOTPSecret,
+		TOTPEnabled:   u.TOTPEnabled,
+		TOTPLastStep:  u.TOTPLastStep,
+		Role:          u.Role,
+		BannedAt:      u.BannedAt,
+		BanReason:     u.BanReason,
+
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// nullableString — пустая строка хранится как NULL, чтобы не мешать уникальному индексу
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// UserToken
+
+func toUserTokenEntity(m UserTokenModel) entity.UserToken {
+	return entity.UserToken{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Purpose:   m.Purpose,
+		TokenHash: m.TokenHash,
+		Payload:   m.Payload,
+		ExpiresAt: m.ExpiresAt,
+		UsedAt:    m.UsedAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func toUserTokenModel(t entity.UserToken) UserTokenModel {
+	return UserTokenModel{
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Purpose:   t.Purpose,
+		TokenHash: t.TokenHash,
+		Payload:   t.Payload,
+		ExpiresAt: t.ExpiresAt,
+		UsedAt:    t.UsedAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// AccessToken
+
+func toAccessTokenEntity(m AccessTokenModel) entity.AccessToken {
+	return entity.AccessToken{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		TokenHash:  m.TokenHash,
+		Scopes:     strings.Fields(m.Scopes),
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func toAccessTokenModel(t entity.AccessToken) AccessTokenModel {
+	return AccessTokenModel{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		Name:       t.Name,
+		TokenHash:  t.TokenHash,
+		Scopes:     strings.Join(t.Scopes, " "),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// DataExport
+
+func toDataExportEntity(m DataExportModel) entity.DataExport {
+	return entity.DataExport{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Status:      m.Status,
+		ObjectID:    m.ObjectID,
+		Size:        m.Size,
+		StartedAt:   m.StartedAt,
+		CompletedAt: m.CompletedAt,
+		ExpiresAt:   m.ExpiresAt,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func toDataExportModel(e entity.DataExport) DataExportModel {
+	return DataExportModel{
+		ID:          e.ID,
+		UserID:      e.UserID,
+		Status:      e.Status,
+		ObjectID:    e.ObjectID,
+		Size:        e.Size,
+		StartedAt:   e.StartedAt,
+		CompletedAt: e.CompletedAt,
+		ExpiresAt:   e.ExpiresAt,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// UserIdentity
+
+func toUserIdentityEntity(m UserIdentityModel) entity.UserIdentity {
+	return entity.UserIdentity{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Provider:  m.Provider,
+		Subject:   m.Subject,
+		Username:  m.Username,
+		FirstName: m.FirstName,
+		LastName:  m.LastName,
+		PhotoURL:  m.PhotoURL,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func toUserIdentityModel(i entity.UserIdentity) UserIdentityModel {
+	return UserIdentityModel{
+		ID:        i.ID,
+		UserID:    i.UserID,
+		Provider:  i.Provider,
+		Subject:   i.Subject,
+		Username:  i.Username,
+		FirstName: i.FirstName,
+		LastName:  i.LastName,
+		PhotoURL:  i.PhotoURL,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+	}
+}
+
+// Session
+
+func toSessionEntity(m SessionModel) entity.Session {
+	return entity.Session{
+		ID:               m.ID,
+		UserID:           m.UserID,
+		RefreshTokenHash: m.RefreshTokenHash,
+		ExpiresAt:        m.ExpiresAt,
+		RevokedAt:        m.RevokedAt,
+		UserAgent:        m.UserAgent,
+		IP:               m.IP,
+		LastSeenAt:       m.LastSeenAt,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
+
+func toSessionModel(s entity.Session) SessionModel {
+	return SessionModel{
+		ID:               s.ID,
+		UserID:           s.UserID,
+		RefreshTokenHash: s.RefreshTokenHash,
+		ExpiresAt:        s.ExpiresAt,
+		RevokedAt:        s.RevokedAt,
+		UserAgent:        s.UserAgent,
+		IP:               s.IP,
+		LastSeenAt:       s.LastSeenAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
 	}
 }
 
 // Wishlist
 
+// toBlockEntities — блоки из JSONB; блоки, сохранённые до появления ширины, занимают одну колонку
+func toBlockEntities(m BlocksJSON) []entity.Block {
+	if m == nil {
+		return nil
+	}
+	blocks := make([]entity.Block, 0, len(m))
+	for _, b := range m {
+		colSpan := b.ColSpan
+		if colSpan < 1 {
+			colSpan = 1
+		}
+		blocks = append(blocks, entity.Block{
+			Type:    b.Type,
+			Row:     b.Row,
+			Col:     b.Col,
+			ColSpan: colSpan,
+			Data:    b.Data,
+		})
+	}
+	return blocks
+}
+
+func toBlocksJSON(blocks []entity.Block) BlocksJSON {
+	if blocks == nil {
+		return nil
+	}
+	m := make(BlocksJSON, 0, len(blocks))
+	for _, b := range blocks {
+		data := b.Data
+		if data == nil {
+			data = json.RawMessage("{}")
+		}
+		colSpan := b.ColSpan
+		if colSpan < 1 {
+			colSpan = 1
+		}
+		m = append(m, blockJSON{
+			Type:    b.Type,
+			Row:     b.Row,
+			Col:     b.Col,
+			ColSpan: colSpan,
+			Data:    data,
+		})
+	}
+	return m
+}
+
 func toWishlistEntity(m WishlistModel) entity.Wishlist {
 	var shortID string
 	if m.ShortID != nil {
 		shortID = *m.ShortID
 	}
 
-	var blocks []entity.Block
-	if m.Blocks != nil {
-		blocks = make([]entity.Block, 0, len(m.Blocks))
-		for _, b := range m.Blocks {
-			colSpan := b.ColSpan
-			if colSpan < 1 {
-				colSpan = 1
-			}
-			blocks = append(blocks, entity.Block{
-				Type:    b.Type,
-				Row:     b.Row,
-				Col:     b.Col,
-				ColSpan: colSpan,
-				Data:    b.Data,
-			})
-		}
-	}
-
 	return entity.Wishlist{
 		ID:          m.ID,
 		Title:       m.Title,
@@ -66,15 +284,28 @@ func toWishlistEntity(m WishlistModel) entity.Wishlist {
 			PresentsLayout:       m.Settings.PresentsLayout,
 		},
 		Location: entity.Location{
-			Name: m.Location.Name,
-			Link: m.Location.Link,
-			Time: m.Location.Time,
+			Name:     m.Location.Name,
+			Link:     m.Location.Link,
+			Time:     m.Location.Time,
+			Timezone: m.Location.Timezone,
 		},
 		PresentsCount: m.PresentsCount,
 		ShortID:       shortID,
-		Blocks:        blocks,
+		Slug:          m.Slug,
+		SlugScope:     m.SlugScope,
+		Blocks:        toBlockEntities(m.Blocks),
+		Unpublished:   m.Unpublished,
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
+		Version:       m.Version,
+
+		ListedOnProfile: m.ListedOnProfile,
+		Visibility:      m.Visibility,
+		PasswordHash:    m.PasswordHash,
+		DeletedAt:       m.DeletedAt,
+		EventRepeat:     m.EventRepeat,
+		ArchivedAt:      m.ArchivedAt,
+		NextEditionID:   m.NextEditionID,
 	}
 }
 
@@ -85,26 +316,15 @@ func toWishlistModel(w entity.Wishlist) WishlistModel {
 		shortID = &s
 	}
 
-	var blocks BlocksJSON
-	if w.Blocks != nil {
-		blocks = make(BlocksJSON, 0, len(w.Blocks))
-		for _, b := range w.Blocks {
-			data := b.Data
-			if data == nil {
-				data = json.RawMessage("{}")
-			}
-			colSpanW := b.ColSpan
-			if colSpanW < 1 {
-				colSpanW = 1
-			}
-			blocks = append(blocks, blockJSON{
-				Type:    b.Type,
-				Row:     b.Row,
-				Col:     b.Col,
-				ColSpan: colSpanW,
-				Data:    data,
-			})
-		}
+	var eventEndsAt *time.Time
+	if end, ok := w.Location.EventEnd(); ok {
+		eventEndsAt = &end
+	}
+
+	// Вишлисты, созданные до появления настройки, открыты по ссылке
+	visibility := w.Visibility
+	if visibility == "" {
+		visibility = entity.VisibilityUnlisted
 	}
 
 	return WishlistModel{
@@ -119,15 +339,57 @@ func toWishlistModel(w entity.Wishlist) WishlistModel {
 			PresentsLayout:       w.Settings.PresentsLayout,
 		},
 		Location: LocationJSON{
-			Name: w.Location.Name,
-			Link: w.Location.Link,
-			Time: w.Location.Time,
+			Name:     w.Location.Name,
+			Link:     w.Location.Link,
+			Time:     w.Location.Time,
+			Timezone: w.Location.Timezone,
 		},
 		PresentsCount: w.PresentsCount,
 		ShortID:       shortID,
-		Blocks:        blocks,
+		Slug:          w.Slug,
+		SlugScope:     w.SlugScope,
+		Blocks:        toBlocksJSON(w.Blocks),
+		Unpublished:   w.Unpublished,
 		CreatedAt:     w.CreatedAt,
 		UpdatedAt:     w.UpdatedAt,
+		Version:       w.Version,
+
+		ListedOnProfile: w.ListedOnProfile,
+		Visibility:      visibility,
+		PasswordHash:    w.PasswordHash,
+		DeletedAt:       w.DeletedAt,
+		EventEndsAt:     eventEndsAt,
+		EventRepeat:     w.EventRepeat,
+		ArchivedAt:      w.ArchivedAt,
+		NextEditionID:   w.NextEditionID,
+	}
+}
+
+// Collaborator
+
+func toWishlistInviteEntity(m WishlistInviteModel) entity.WishlistInvite {
+	return entity.WishlistInvite{
+		ID:         m.ID,
+		WishlistID: m.WishlistID,
+		Role:       m.Role,
+		TokenHash:  m.TokenHash,
+		CreatedBy:  m.CreatedBy,
+		ExpiresAt:  m.ExpiresAt,
+		UsedAt:     m.UsedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func toWishlistInviteModel(i entity.WishlistInvite) WishlistInviteModel {
+	return WishlistInviteModel{
+		ID:         i.ID,
+		WishlistID: i.WishlistID,
+		Role:       i.Role,
+		TokenHash:  i.TokenHash,
+		CreatedBy:  i.CreatedBy,
+		ExpiresAt:  i.ExpiresAt,
+		UsedAt:     i.UsedAt,
+		CreatedAt:  i.CreatedAt,
 	}
 }
 
@@ -145,7 +407,17 @@ func toPresentEntity(m PresentModel) entity.Present {
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
 		WishlistID:  m.WishlistID,
+		Version:     m.Version,
+		DeletedAt:   m.DeletedAt,
+	}
+}
+
+func toPresentEntities(models []PresentModel) []entity.Present {
+	presents := make([]entity.Present, len(models))
+	for i, m := range models {
+		presents[i] = toPresentEntity(m)
 	}
+	return presents
 }
 
 func toPresentModel(p entity.Present) PresentModel {
@@ -160,6 +432,8 @@ func toPresentModel(p entity.Present) PresentModel {
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
 		WishlistID:  p.WishlistID,
+		Version:     p.Version,
+		DeletedAt:   p.DeletedAt,
 	}
 }
 
@@ -24,7 +24,6 @@ func TestUserConverter_RoundTrip(t *testing.T) {
 }
 
 func TestWishlistConverter_RoundTrip_WithBlocks(t *testing.T) {
-	mobilePos := 1
 	w := entity.Wishlist{
 		ID:          uuid.New(),
 		Title:       "My Wishlist",
@@ -44,12 +43,11 @@ func TestWishlistConverter_RoundTrip_WithBlocks(t *testing.T) {
 		PresentsCount: 3,
 		Blocks: []entity.Block{
 			{
-				Type:           "text",
-				Position:       0,
-				MobilePosition: &mobilePos,
-				ColSpan:        2,
-				RowSpan:        3,
-				Data:           json.RawMessage(`{"text":"hello"}`),
+				Type:    "text",
+				Row:     2,
+				Col:     1,
+				ColSpan: 2,
+				Data:    json.RawMessage(`{"text":"hello"}`),
 			},
 		},
 	}
@@ -61,10 +59,9 @@ func TestWishlistConverter_RoundTrip_WithBlocks(t *testing.T) {
 	assert.Equal(t, w.Settings, got.Settings)
 	assert.Len(t, got.Blocks, 1)
 	assert.Equal(t, w.Blocks[0].Type, got.Blocks[0].Type)
-	assert.Equal(t, w.Blocks[0].Position, got.Blocks[0].Position)
-	assert.Equal(t, w.Blocks[0].MobilePosition, got.Blocks[0].MobilePosition)
+	assert.Equal(t, w.Blocks[0].Row, got.Blocks[0].Row)
+	assert.Equal(t, w.Blocks[0].Col, got.Blocks[0].Col)
 	assert.Equal(t, w.Blocks[0].ColSpan, got.Blocks[0].ColSpan)
-	assert.Equal(t, w.Blocks[0].RowSpan, got.Blocks[0].RowSpan)
 }
 
 func TestWishlistConverter_RoundTrip_NilBlocks(t *testing.T) {
@@ -85,11 +82,9 @@ func TestWishlistConverter_Block_SpanDefaults(t *testing.T) {
 		UserID: uuid.New(),
 		Blocks: []entity.Block{
 			{
-				Type:     "text",
-				Position: 0,
-				ColSpan:  0,
-				RowSpan:  0,
-				Data:     json.RawMessage(`{"text":"test"}`),
+				Type:    "text",
+				ColSpan: 0,
+				Data:    json.RawMessage(`{"text":"test"}`),
 			},
 		},
 	}
@@ -97,7 +92,26 @@ func TestWishlistConverter_Block_SpanDefaults(t *testing.T) {
 	got := toWishlistEntity(toWishlistModel(w))
 	assert.Len(t, got.Blocks, 1)
 	assert.Equal(t, 1, got.Blocks[0].ColSpan)
-	assert.Equal(t, 1, got.Blocks[0].RowSpan)
+}
+
+func TestWishlistConverter_EventEndsAt(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+	w := entity.Wishlist{
+		ID:     uuid.New(),
+		Title:  "Birthday",
+		UserID: uuid.New(),
+		// 22:00 UTC 10 марта — уже 11 марта в Москве
+		Location: entity.Location{Time: time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC), Timezone: "Europe/Moscow"},
+	}
+	m := toWishlistModel(w)
+	if assert.NotNil(t, m.EventEndsAt) {
+		assert.True(t, time.Date(2026, 3, 12, 0, 0, 0, 0, moscow).Equal(*m.EventEndsAt))
+	}
+	assert.Equal(t, "Europe/Moscow", toWishlistEntity(m).Location.Timezone)
+
+	w.Location = entity.Location{Name: "Дома"}
+	assert.Nil(t, toWishlistModel(w).EventEndsAt)
 }
 
 func TestPresentConverter_RoundTrip(t *testing.T) {
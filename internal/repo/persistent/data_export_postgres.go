@@ -0,0 +1,105 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+	"main/internal/repo"
+)
+
+type dataExportRepo struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepo(db *gorm.DB) repo.DataExportRepo {
+	return &dataExportRepo{db: db}
+}
+
+func (r *dataExportRepo) Create(ctx context.Context, export entity.DataExport) error {
+	m := toDataExportModel(export)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("dataExportRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.DataExport, error) {
+	var m DataExportModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		return entity.DataExport{}, fmt.Errorf("dataExportRepo.GetByID: %w", err)
+	}
+	return toDataExportEntity(m), nil
+}
+
+func (r *dataExportRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error) {
+	var models []DataExportModel
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("dataExportRepo.GetAllByUserID: %w", err)
+	}
+	result := make([]entity.DataExport, len(models))
+	for i, m := range models {
+		result[i] = toDataExportEntity(m)
+	}
+	return result, nil
+}
+
+func (r *dataExportRepo) ClaimNext(ctx context.Context, now, staleBefore time.Time) (entity.DataExport, bool, error) {
+	// SKIP LOCKED — несколько инстансов не возьмут одну выгрузку
+	var models []DataExportModel
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE data_exports SET status = ?, started_at = ?
+		WHERE id = (
+			SELECT id FROM data_exports
+			WHERE status = ? OR (status = ? AND started_at < ?)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		entity.DataExportProcessing, now,
+		entity.DataExportPending, entity.DataExportProcessing, staleBefore,
+	).Scan(&models).Error
+	if err != nil {
+		return entity.DataExport{}, false, fmt.Errorf("dataExportRepo.ClaimNext: %w", err)
+	}
+	if len(models) == 0 {
+		return entity.DataExport{}, false, nil
+	}
+	return toDataExportEntity(models[0]), true, nil
+}
+
+func (r *dataExportRepo) Update(ctx context.Context, export entity.DataExport) error {
+	m := toDataExportModel(export)
+	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
+		return fmt.Errorf("dataExportRepo.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepo) GetExpired(ctx context.Context, now time.Time, limit int) ([]entity.DataExport, error) {
+	var models []DataExportModel
+	if err := r.db.WithContext(ctx).
+		Where("expires_at <= ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("dataExportRepo.GetExpired: %w", err)
+	}
+	result := make([]entity.DataExport, len(models))
+	for i, m := range models {
+		result[i] = toDataExportEntity(m)
+	}
+	return result, nil
+}
+
+func (r *dataExportRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Delete(&DataExportModel{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("dataExportRepo.Delete: %w", err)
+	}
+	return nil
+}
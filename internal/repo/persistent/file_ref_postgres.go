@@ -0,0 +1,43 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"main/internal/repo"
+)
+
+type fileRefRepo struct {
+	db *gorm.DB
+}
+
+func NewFileRefRepo(db *gorm.DB) repo.FileRefRepo {
+	return &fileRefRepo{db: db}
+}
+
+// Unreferenced проверяет аватары, обложки вишлистов и подарков и блоки вишлистов, их ревизий и шаблонов:
+// из ревизии файл может вернуться в вишлист откатом. Блоки ищутся по подстроке в JSON — URL загруженных файлов не содержат символов, которые JSON экранирует
+func (r *fileRefRepo) Unreferenced(ctx context.Context, urls []string) ([]string, error) {
+	result := make([]string, 0, len(urls))
+	for _, url := range urls {
+		pattern := "%" + escapeLike(url) + "%"
+		var referenced bool
+		err := r.db.WithContext(ctx).Raw(`
+			SELECT
+				EXISTS (SELECT 1 FROM users WHERE avatar = ?) OR
+				EXISTS (SELECT 1 FROM wishlists WHERE cover = ? OR blocks::text LIKE ?) OR
+				EXISTS (SELECT 1 FROM wishlist_revisions WHERE blocks::text LIKE ?) OR
+				EXISTS (SELECT 1 FROM presents WHERE cover = ?) OR
+				EXISTS (SELECT 1 FROM templates WHERE blocks::text LIKE ?)
+		`, url, url, pattern, pattern, url, pattern).Scan(&referenced).Error
+		if err != nil {
+			return nil, fmt.Errorf("fileRefRepo.Unreferenced: %w", err)
+		}
+		if !referenced {
+			result = append(result, url)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+	"main/internal/repo"
+)
+
+type handleRedirectRepo struct {
+	db *gorm.DB
+}
+
+func NewHandleRedirectRepo(db *gorm.DB) repo.HandleRedirectRepo {
+	return &handleRedirectRepo{db: db}
+}
+
+func (r *handleRedirectRepo) GetByHandle(ctx context.Context, handle string) (entity.HandleRedirect, error) {
+	var m HandleRedirectModel
+	if err := r.db.WithContext(ctx).First(&m, "handle = ?", handle).Error; err != nil {
+		return entity.HandleRedirect{}, fmt.Errorf("handleRedirectRepo.GetByHandle: %w", err)
+	}
+	return entity.HandleRedirect{Handle: m.Handle, UserID: m.UserID, CreatedAt: m.CreatedAt}, nil
+}
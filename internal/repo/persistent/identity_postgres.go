@@ -0,0 +1,79 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+)
+
+type identityRepo struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepo(db *gorm.DB) *identityRepo {
+	return &identityRepo{db: db}
+}
+
+func (r *identityRepo) CreateWithUser(ctx context.Context, user entity.User, identity entity.UserIdentity) error {
+	um := toUserModel(user)
+	im := toUserIdentityModel(identity)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&um).Error; err != nil {
+			return err
+		}
+		return tx.Create(&im).Error
+	})
+	if err != nil {
+		return fmt.Errorf("identityRepo.CreateWithUser: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepo) Create(ctx context.Context, identity entity.UserIdentity) error {
+	m := toUserIdentityModel(identity)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("identityRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error) {
+	var m UserIdentityModel
+	if err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&m).Error; err != nil {
+		return entity.UserIdentity{}, fmt.Errorf("identityRepo.GetByProviderSubject: %w", err)
+	}
+	return toUserIdentityEntity(m), nil
+}
+
+func (r *identityRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error) {
+	var models []UserIdentityModel
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("identityRepo.GetAllByUserID: %w", err)
+	}
+	result := make([]entity.UserIdentity, len(models))
+	for i, m := range models {
+		result[i] = toUserIdentityEntity(m)
+	}
+	return result, nil
+}
+
+func (r *identityRepo) Update(ctx context.Context, identity entity.UserIdentity) error {
+	m := toUserIdentityModel(identity)
+	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
+		return fmt.Errorf("identityRepo.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *identityRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Delete(&UserIdentityModel{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("identityRepo.Delete: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,11 @@ package persistent_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +20,7 @@ import (
 	"gorm.io/gorm"
 
 	"main/internal/entity"
+	"main/internal/repo"
 	"main/internal/repo/persistent"
 )
 
@@ -50,6 +54,20 @@ func setupDB(t *testing.T) *gorm.DB {
 		&persistent.UserModel{},
 		&persistent.WishlistModel{},
 		&persistent.PresentModel{},
+		&persistent.PresentMetaModel{},
+		&persistent.TemplateModel{},
+		&persistent.TemplateLikeModel{},
+		&persistent.SessionModel{},
+		&persistent.UserIdentityModel{},
+		&persistent.UserTokenModel{},
+		&persistent.AccessTokenModel{},
+		&persistent.ParseRateLimitModel{},
+		&persistent.DataExportModel{},
+		&persistent.HandleRedirectModel{},
+		&persistent.CollaboratorModel{},
+		&persistent.WishlistInviteModel{},
+		&persistent.WishlistSlugModel{},
+		&persistent.WishlistRevisionModel{},
 	)
 	require.NoError(t, err)
 
@@ -203,9 +221,659 @@ func TestPresentRepo_Delete(t *testing.T) {
 	p := entity.Present{ID: pid, Title: "Book", WishlistID: wid}
 	require.NoError(t, presentRepo.Create(context.Background(), p))
 
-	err := presentRepo.Delete(context.Background(), pid)
+	err := presentRepo.Delete(context.Background(), pid, 0, time.Now())
 	require.NoError(t, err)
 
 	_, err = presentRepo.GetByID(context.Background(), pid)
 	require.Error(t, err)
+	// Повторно в корзину не переносится
+	assert.Error(t, presentRepo.Delete(context.Background(), pid, 0, time.Now()))
+}
+
+func TestWishlistRepo_CreateWithPresents(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	presentRepo := persistent.NewPresentRepo(db)
+	metaRepo := persistent.NewPresentMetaRepo(db)
+
+	w := entity.Wishlist{ID: uuid.New(), Title: "Copy", UserID: uuid.New(), PresentsCount: 2}
+	presents := []entity.Present{
+		{ID: uuid.New(), Title: "Book", WishlistID: w.ID},
+		{ID: uuid.New(), Title: "Kettle", WishlistID: w.ID},
+	}
+	metas := []entity.PresentMeta{{PresentID: presents[1].ID, Source: "ozon", OriginalURL: "https://ozon.ru/1"}}
+	require.NoError(t, wishlistRepo.CreateWithPresents(ctx, w, presents, metas))
+
+	got, err := presentRepo.GetAllByWishlistID(ctx, w.ID)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	gotMeta, err := metaRepo.GetByPresentIDs(ctx, []uuid.UUID{presents[1].ID})
+	require.NoError(t, err)
+	require.Len(t, gotMeta, 1)
+	assert.Equal(t, "ozon", gotMeta[0].Source)
+
+	// Ошибка откатывает всё: вишлист с тем же ID уже есть
+	other := []entity.Present{{ID: uuid.New(), Title: "Lamp", WishlistID: w.ID}}
+	require.Error(t, wishlistRepo.CreateWithPresents(ctx, w, other, nil))
+	_, err = presentRepo.GetByID(ctx, other[0].ID)
+	assert.Error(t, err)
+}
+
+func TestWishlistRepo_ChangeSlug(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	wr := persistent.NewWishlistRepo(db)
+	userID := uuid.New()
+	now := time.Now()
+
+	a := entity.Wishlist{ID: uuid.New(), Title: "Birthday", UserID: userID, ShortID: "abc-def-ghi"}
+	b := entity.Wishlist{ID: uuid.New(), Title: "Party", UserID: userID, ShortID: "jkl-mno-pqr"}
+	require.NoError(t, wr.Create(ctx, a))
+	require.NoError(t, wr.Create(ctx, b))
+
+	require.NoError(t, wr.ChangeSlug(ctx, a.ID, 0, userID, "party", now))
+	// Пространства независимы: тот же slug глобально свободен
+	require.NoError(t, wr.ChangeSlug(ctx, b.ID, 0, uuid.Nil, "party", now))
+	got, err := wr.GetBySlug(ctx, userID, "party")
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, got.ID)
+	assert.Equal(t, "party", got.Slug)
+	assert.Equal(t, entity.SlugScopeProfile, got.SlugScope)
+
+	// Текущий slug другого вишлиста не перезаписывается
+	assert.ErrorIs(t, wr.ChangeSlug(ctx, b.ID, 1, userID, "party", now), repo.ErrConflict)
+
+	// После переименования прежний slug ведёт на тот же вишлист
+	require.NoError(t, wr.ChangeSlug(ctx, a.ID, 1, userID, "birthday", now))
+	// Смена slug увеличивает версию: устаревшая копия его уже не поменяет
+	require.ErrorIs(t, wr.ChangeSlug(ctx, a.ID, 1, userID, "anniversary", now), gorm.ErrRecordNotFound)
+	got, err = wr.GetBySlug(ctx, userID, "party")
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, got.ID)
+	assert.Equal(t, "birthday", got.Slug)
+	old, err := wr.GetSlug(ctx, userID, "party")
+	require.NoError(t, err)
+	assert.NotNil(t, old.ReleasedAt)
+
+	// Освобождённый slug может занять другой вишлист
+	require.NoError(t, wr.ChangeSlug(ctx, b.ID, 1, userID, "party", now))
+	got, err = wr.GetBySlug(ctx, userID, "party")
+	require.NoError(t, err)
+	assert.Equal(t, b.ID, got.ID)
+	// Прежний глобальный slug b по-прежнему ведёт на него
+	got, err = wr.GetBySlug(ctx, uuid.Nil, "party")
+	require.NoError(t, err)
+	assert.Equal(t, b.ID, got.ID)
+	assert.Equal(t, entity.SlugScopeProfile, got.SlugScope)
+
+	// Update не затирает slug, Purge удаляет и текущий, и прежние
+	got, err = wr.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	got.Title = "Birthday 2026"
+	require.NoError(t, wr.Update(ctx, got))
+	got, err = wr.GetBySlug(ctx, userID, "birthday")
+	require.NoError(t, err)
+	assert.Equal(t, "Birthday 2026", got.Title)
+	require.NoError(t, wr.Purge(ctx, a.ID))
+	_, err = wr.GetSlug(ctx, userID, "birthday")
+	assert.Error(t, err)
+}
+
+func TestConditionalUpdate_Versions(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	presentRepo := persistent.NewPresentRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: alice.ID}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+	require.NoError(t, wishlistRepo.IncrementPresentsCount(ctx, w.ID))
+
+	// Две вкладки прочитали версию 0; вторая запись не проходит и не трогает первую
+	first, second := w, w
+	first.Title = "Первая вкладка"
+	second.Title = "Вторая вкладка"
+	require.NoError(t, wishlistRepo.Update(ctx, first))
+	assert.ErrorIs(t, wishlistRepo.Update(ctx, second), gorm.ErrRecordNotFound)
+	got, err := wishlistRepo.GetByID(ctx, w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Первая вкладка", got.Title)
+	assert.Equal(t, uint(1), got.Version)
+	// Счётчик подарков из устаревшей копии не записывается
+	assert.Equal(t, uint(1), got.PresentsCount)
+
+	assert.ErrorIs(t, wishlistRepo.Delete(ctx, w.ID, 0, time.Now()), gorm.ErrRecordNotFound)
+	require.NoError(t, wishlistRepo.Delete(ctx, w.ID, 1, time.Now()))
+
+	p := entity.Present{ID: uuid.New(), Title: "Book", WishlistID: w.ID}
+	require.NoError(t, presentRepo.Create(ctx, p))
+	reserved := p
+	reserved.Reserved = true
+	require.NoError(t, presentRepo.Update(ctx, reserved))
+	p.Title = "Другая книга"
+	assert.ErrorIs(t, presentRepo.Update(ctx, p), gorm.ErrRecordNotFound)
+	gotPresent, err := presentRepo.GetByID(ctx, p.ID)
+	require.NoError(t, err)
+	assert.True(t, gotPresent.Reserved)
+	assert.Equal(t, uint(1), gotPresent.Version)
+	assert.ErrorIs(t, presentRepo.Delete(ctx, p.ID, 0, time.Now()), gorm.ErrRecordNotFound)
+	require.NoError(t, presentRepo.Delete(ctx, p.ID, 1, time.Now()))
+}
+
+func TestWishlistRepo_ArchiveWithNextEdition(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	repo := persistent.NewWishlistRepo(db)
+	collaboratorRepo := persistent.NewCollaboratorRepo(db)
+	now := time.Now()
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	bob := entity.User{ID: uuid.New(), Username: "bob", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	require.NoError(t, userRepo.Create(ctx, bob))
+	past := entity.Wishlist{
+		ID: uuid.New(), Title: "ДР 2025", UserID: alice.ID, ShortID: "abc-def-ghi",
+		Location: entity.Location{Time: now.Add(-72 * time.Hour)}, EventRepeat: entity.EventRepeatYearly,
+	}
+	future := entity.Wishlist{ID: uuid.New(), Title: "Свадьба", UserID: alice.ID, ShortID: "jkl-mno-pqr",
+		Location: entity.Location{Time: now.Add(72 * time.Hour)}}
+	require.NoError(t, repo.Create(ctx, past))
+	require.NoError(t, repo.Create(ctx, future))
+	require.NoError(t, repo.ChangeSlug(ctx, past.ID, 0, alice.ID, "birthday", now))
+	require.NoError(t, collaboratorRepo.Upsert(ctx, entity.Collaborator{WishlistID: past.ID, UserID: bob.ID, Role: entity.WishlistRoleEditor}))
+
+	due, err := repo.GetEventsEndedBefore(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, past.ID, due[0].ID)
+
+	next := entity.Wishlist{ID: uuid.New(), Title: "ДР 2026", UserID: alice.ID, ShortID: "stu-vwx-yz0",
+		Location: entity.Location{Time: now.Add(362 * 24 * time.Hour)}, EventRepeat: entity.EventRepeatYearly, PresentsCount: 1}
+	present := entity.Present{ID: uuid.New(), WishlistID: next.ID, Title: "Чайник"}
+	require.NoError(t, repo.Archive(ctx, past.ID, now, &next, []entity.Present{present},
+		[]entity.PresentMeta{{PresentID: present.ID, Source: "ozon"}}))
+	// Повторная архивация того же вишлиста не создаёт второй выпуск
+	assert.Error(t, repo.Archive(ctx, past.ID, now, nil, nil, nil))
+
+	got, err := repo.GetByID(ctx, past.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.ArchivedAt)
+	require.NotNil(t, got.NextEditionID)
+	assert.Equal(t, next.ID, *got.NextEditionID)
+	assert.Empty(t, got.Slug)
+
+	// Адрес переходит к новому выпуску, соавторы — тоже
+	got, err = repo.GetBySlug(ctx, alice.ID, "birthday")
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, got.ID)
+	c, err := collaboratorRepo.Get(ctx, next.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WishlistRoleEditor, c.Role)
+
+	// Update устаревшей копии не возвращает вишлист из архива
+	past.Title = "ДР 2025!"
+	require.NoError(t, repo.Update(ctx, past))
+	got, err = repo.GetByID(ctx, past.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.ArchivedAt)
+
+	count, err := repo.CountByUserID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	due, err = repo.GetEventsEndedBefore(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	// Вишлист с созданным следующим выпуском из архива не возвращается
+	assert.Error(t, repo.Unarchive(ctx, past.ID))
+}
+
+func TestTrash_DeleteRestoreAndPurge(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	presentRepo := persistent.NewPresentRepo(db)
+	userID := uuid.New()
+	now := time.Now()
+
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: userID, ShortID: "abc-def-ghi"}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+	kept := entity.Wishlist{ID: uuid.New(), Title: "Kept", UserID: userID}
+	require.NoError(t, wishlistRepo.Create(ctx, kept))
+	p := entity.Present{ID: uuid.New(), Title: "Book", WishlistID: kept.ID}
+	require.NoError(t, presentRepo.Create(ctx, p))
+
+	require.NoError(t, wishlistRepo.Delete(ctx, w.ID, 0, now.Add(-time.Hour)))
+	require.NoError(t, presentRepo.Delete(ctx, p.ID, 0, now))
+
+	// Обычные методы корзину не видят
+	_, err := wishlistRepo.GetByID(ctx, w.ID)
+	assert.Error(t, err)
+	_, err = wishlistRepo.GetByShortID(ctx, "abc-def-ghi")
+	assert.Error(t, err)
+	count, err := wishlistRepo.CountByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	presents, err := presentRepo.GetAllByWishlistID(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Empty(t, presents)
+
+	deletedWishlists, err := wishlistRepo.GetDeletedByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, deletedWishlists, 1)
+	assert.NotNil(t, deletedWishlists[0].DeletedAt)
+	deletedPresents, err := presentRepo.GetDeletedByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, deletedPresents, 1)
+	assert.Equal(t, p.ID, deletedPresents[0].ID)
+
+	expired, err := wishlistRepo.GetDeletedBefore(ctx, now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, w.ID, expired[0].ID)
+
+	// Второе восстановление ничего не меняет — счётчик подарков не увеличится дважды
+	require.NoError(t, presentRepo.Restore(ctx, p.ID))
+	assert.Error(t, presentRepo.Restore(ctx, p.ID))
+	_, err = presentRepo.GetByID(ctx, p.ID)
+	assert.NoError(t, err)
+
+	require.NoError(t, wishlistRepo.Purge(ctx, w.ID))
+	_, err = wishlistRepo.GetDeletedByID(ctx, w.ID)
+	assert.Error(t, err)
+}
+
+func TestWishlistRepo_PurgeCascadesPresents(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	presentRepo := persistent.NewPresentRepo(db)
+	metaRepo := persistent.NewPresentMetaRepo(db)
+	revisionRepo := persistent.NewRevisionRepo(db)
+
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: uuid.New()}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+	p := entity.Present{ID: uuid.New(), Title: "Book", WishlistID: w.ID}
+	require.NoError(t, presentRepo.Create(ctx, p))
+	require.NoError(t, metaRepo.Upsert(ctx, entity.PresentMeta{PresentID: p.ID, Source: "ozon", OriginalURL: "https://ozon.ru/1"}))
+	_, err := revisionRepo.Create(ctx, entity.WishlistRevision{ID: uuid.New(), WishlistID: w.ID, AuthorID: w.UserID}, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, wishlistRepo.Purge(ctx, w.ID))
+
+	_, err = presentRepo.GetByID(ctx, p.ID)
+	require.Error(t, err)
+	var metaCount int64
+	require.NoError(t, db.Model(&persistent.PresentMetaModel{}).Where("present_id = ?", p.ID).Count(&metaCount).Error)
+	assert.Zero(t, metaCount)
+	_, err = revisionRepo.GetLatest(ctx, w.ID)
+	assert.Error(t, err)
+}
+
+func TestFileRefRepo_Unreferenced(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	revisionRepo := persistent.NewRevisionRepo(db)
+	fileRefRepo := persistent.NewFileRefRepo(db)
+
+	const (
+		current = "https://files.example.com/current.webp"
+		old     = "https://files.example.com/old.webp"
+		orphan  = "https://files.example.com/orphan.webp"
+	)
+	image := func(url string) []entity.Block {
+		return []entity.Block{{Type: "image", ColSpan: 2, Data: json.RawMessage(`{"url":"` + url + `"}`)}}
+	}
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: uuid.New(), Blocks: image(current)}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+	// Картинку убрали из блоков, но откат к старой ревизии её вернёт
+	_, err := revisionRepo.Create(ctx, entity.WishlistRevision{ID: uuid.New(), WishlistID: w.ID, AuthorID: w.UserID, Blocks: image(old)}, 10)
+	require.NoError(t, err)
+
+	orphaned, err := fileRefRepo.Unreferenced(ctx, []string{current, old, orphan})
+	require.NoError(t, err)
+	assert.Equal(t, []string{orphan}, orphaned)
+
+	// Вместе с вишлистом уходят и его ревизии
+	require.NoError(t, wishlistRepo.Purge(ctx, w.ID))
+	orphaned, err = fileRefRepo.Unreferenced(ctx, []string{current, old, orphan})
+	require.NoError(t, err)
+	assert.Equal(t, []string{current, old, orphan}, orphaned)
+}
+
+func TestUserRepo_DeleteCascades(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	presentRepo := persistent.NewPresentRepo(db)
+	templateRepo := persistent.NewTemplateRepo(db)
+	sessionRepo := persistent.NewSessionRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	bob := entity.User{ID: uuid.New(), Username: "bob", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	require.NoError(t, userRepo.Create(ctx, bob))
+
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: alice.ID}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+	require.NoError(t, presentRepo.Create(ctx, entity.Present{ID: uuid.New(), Title: "Book", WishlistID: w.ID}))
+	_, err := persistent.NewRevisionRepo(db).Create(ctx, entity.WishlistRevision{ID: uuid.New(), WishlistID: w.ID, AuthorID: alice.ID}, 10)
+	require.NoError(t, err)
+	aliceTemplate := entity.Template{ID: uuid.New(), UserID: alice.ID, Name: "Mine", IsPublic: true}
+	bobTemplate := entity.Template{ID: uuid.New(), UserID: bob.ID, Name: "Bob's", IsPublic: true}
+	require.NoError(t, templateRepo.Create(ctx, aliceTemplate))
+	require.NoError(t, templateRepo.Create(ctx, bobTemplate))
+	_, err = templateRepo.Like(ctx, alice.ID, bobTemplate.ID)
+	require.NoError(t, err)
+	_, err = templateRepo.Like(ctx, bob.ID, aliceTemplate.ID)
+	require.NoError(t, err)
+	require.NoError(t, sessionRepo.Create(ctx, entity.Session{
+		ID: uuid.New(), UserID: alice.ID, RefreshTokenHash: "h", ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	require.NoError(t, userRepo.Delete(ctx, alice.ID))
+
+	_, err = userRepo.GetByID(ctx, alice.ID)
+	require.Error(t, err)
+	for model, query := range map[interface{}]string{
+		&persistent.WishlistModel{}:     "user_id = ?",
+		&persistent.TemplateModel{}:     "user_id = ?",
+		&persistent.TemplateLikeModel{}: "user_id = ?",
+		&persistent.SessionModel{}:      "user_id = ?",
+	} {
+		var count int64
+		require.NoError(t, db.Model(model).Where(query, alice.ID).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+	for model, query := range map[interface{}]string{
+		&persistent.PresentModel{}:          "wishlist_id = ?",
+		&persistent.WishlistRevisionModel{}: "wishlist_id = ?",
+	} {
+		var count int64
+		require.NoError(t, db.Model(model).Where(query, w.ID).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+
+	// Лайк Алисы снят с чужого шаблона, сам шаблон и Боб на месте
+	got, err := templateRepo.GetByID(ctx, bobTemplate.ID)
+	require.NoError(t, err)
+	var likes int
+	require.NoError(t, db.Model(&persistent.TemplateModel{}).Select("likes_count").Where("id = ?", got.ID).Scan(&likes).Error)
+	assert.Zero(t, likes)
+	_, err = userRepo.GetByID(ctx, bob.ID)
+	require.NoError(t, err)
+}
+
+func TestUserRepo_ChangeHandleKeepsHistory(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	redirectRepo := persistent.NewHandleRedirectRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+
+	require.NoError(t, userRepo.ChangeHandle(ctx, alice.ID, "alice", time.Now()))
+	require.NoError(t, userRepo.ChangeHandle(ctx, alice.ID, "alice_w", time.Now()))
+
+	got, err := userRepo.GetByHandle(ctx, "alice_w")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, got.ID)
+	_, err = userRepo.GetByHandle(ctx, "alice")
+	assert.Error(t, err)
+	rd, err := redirectRepo.GetByHandle(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, rd.UserID)
+
+	// Возврат прежнего handle убирает его из истории
+	require.NoError(t, userRepo.ChangeHandle(ctx, alice.ID, "alice", time.Now()))
+	_, err = redirectRepo.GetByHandle(ctx, "alice")
+	assert.Error(t, err)
+	_, err = redirectRepo.GetByHandle(ctx, "alice_w")
+	require.NoError(t, err)
+}
+
+func TestUserRepo_UseTOTPStepOnce(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed", TOTPLastStep: 10}
+	require.NoError(t, userRepo.Create(ctx, alice))
+
+	require.NoError(t, userRepo.UseTOTPStep(ctx, alice.ID, 11))
+	require.ErrorIs(t, userRepo.UseTOTPStep(ctx, alice.ID, 11), gorm.ErrRecordNotFound)
+	require.ErrorIs(t, userRepo.UseTOTPStep(ctx, alice.ID, 9), gorm.ErrRecordNotFound)
+	got, err := userRepo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), got.TOTPLastStep)
+}
+
+func TestUserRepo_UpdateColumnsKeepsOtherFields(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed", Role: entity.RoleUser}
+	require.NoError(t, userRepo.Create(ctx, alice))
+
+	// Модератор банит, пока у пользователя открыта форма профиля со старой копией
+	stale := alice
+	now := time.Now()
+	banned := alice
+	banned.BannedAt = &now
+	banned.BanReason = "spam"
+	require.NoError(t, userRepo.UpdateColumns(ctx, banned, repo.UserColumnBannedAt, repo.UserColumnBanReason))
+	stale.DisplayName = "Alice"
+	require.NoError(t, userRepo.UpdateColumns(ctx, stale, repo.UserColumnDisplayName))
+
+	got, err := userRepo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", got.DisplayName)
+	assert.True(t, got.Banned())
+	assert.Equal(t, "spam", got.BanReason)
+
+	missing := entity.User{ID: uuid.New()}
+	require.ErrorIs(t, userRepo.UpdateColumns(ctx, missing, repo.UserColumnRole), gorm.ErrRecordNotFound)
+}
+
+func TestUserRepo_ReplacePasswordHash(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "old"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+
+	require.NoError(t, userRepo.ReplacePasswordHash(ctx, alice.ID, "old", "rehashed"))
+	// Вторая пересборка по устаревшей копии не проходит
+	require.ErrorIs(t, userRepo.ReplacePasswordHash(ctx, alice.ID, "old", "other"), gorm.ErrRecordNotFound)
+	got, err := userRepo.GetByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "rehashed", got.Password)
+}
+
+func TestCollaboratorRepo_SharedWishlists(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	collaboratorRepo := persistent.NewCollaboratorRepo(db)
+	inviteRepo := persistent.NewWishlistInviteRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	bob := entity.User{ID: uuid.New(), Username: "bob", Password: "hashed", DisplayName: "Bob"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	require.NoError(t, userRepo.Create(ctx, bob))
+	w := entity.Wishlist{ID: uuid.New(), Title: "Family", UserID: alice.ID}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+
+	require.NoError(t, collaboratorRepo.Upsert(ctx, entity.Collaborator{WishlistID: w.ID, UserID: bob.ID, Role: entity.WishlistRoleViewer}))
+	require.NoError(t, collaboratorRepo.Upsert(ctx, entity.Collaborator{WishlistID: w.ID, UserID: bob.ID, Role: entity.WishlistRoleEditor}))
+
+	c, err := collaboratorRepo.Get(ctx, w.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WishlistRoleEditor, c.Role)
+	assert.Equal(t, "Bob", c.DisplayName)
+	count, err := collaboratorRepo.CountByWishlistID(ctx, w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	shared, err := wishlistRepo.GetSharedWithUserID(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	assert.Equal(t, w.ID, shared[0].ID)
+	assert.Equal(t, entity.WishlistRoleEditor, shared[0].Role)
+
+	// Неподтверждённое приглашение не попадает в общие вишлисты, пока его не примут; повторное приглашение его не подтверждает
+	carol := entity.User{ID: uuid.New(), Username: "carol", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, carol))
+	require.NoError(t, collaboratorRepo.Upsert(ctx, entity.Collaborator{WishlistID: w.ID, UserID: carol.ID, Role: entity.WishlistRoleViewer, Pending: true}))
+	require.NoError(t, collaboratorRepo.Upsert(ctx, entity.Collaborator{WishlistID: w.ID, UserID: carol.ID, Role: entity.WishlistRoleEditor}))
+	shared, err = wishlistRepo.GetSharedWithUserID(ctx, carol.ID)
+	require.NoError(t, err)
+	assert.Empty(t, shared)
+	pending, err := wishlistRepo.GetPendingSharedWithUserID(ctx, carol.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, entity.WishlistRoleEditor, pending[0].Role)
+	require.NoError(t, collaboratorRepo.Accept(ctx, w.ID, carol.ID))
+	require.ErrorIs(t, collaboratorRepo.Accept(ctx, w.ID, carol.ID), gorm.ErrRecordNotFound)
+	shared, err = wishlistRepo.GetSharedWithUserID(ctx, carol.ID)
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+
+	// Приглашение гасится один раз
+	invite := entity.WishlistInvite{ID: uuid.New(), WishlistID: w.ID, Role: entity.WishlistRoleViewer,
+		TokenHash: "hash", CreatedBy: alice.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, inviteRepo.Create(ctx, invite))
+	_, err = inviteRepo.Consume(ctx, "hash", time.Now())
+	require.NoError(t, err)
+	_, err = inviteRepo.Consume(ctx, "hash", time.Now())
+	assert.Error(t, err)
+
+	// Вишлист из корзины пропадает у соавторов, окончательное удаление убирает соавторов и приглашения
+	require.NoError(t, wishlistRepo.Delete(ctx, w.ID, 0, time.Now()))
+	shared, err = wishlistRepo.GetSharedWithUserID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, shared)
+	require.NoError(t, wishlistRepo.Purge(ctx, w.ID))
+	_, err = collaboratorRepo.Get(ctx, w.ID, bob.ID)
+	assert.Error(t, err)
+	_, err = inviteRepo.GetByTokenHash(ctx, "hash")
+	assert.Error(t, err)
+}
+
+func TestRevisionRepo_NumbersAndCap(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	userRepo := persistent.NewUserRepo(db)
+	wishlistRepo := persistent.NewWishlistRepo(db)
+	revisionRepo := persistent.NewRevisionRepo(db)
+
+	alice := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed"}
+	bob := entity.User{ID: uuid.New(), Username: "bob", Password: "hashed"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	require.NoError(t, userRepo.Create(ctx, bob))
+	require.NoError(t, userRepo.ChangeHandle(ctx, bob.ID, "bob", time.Now()))
+	w := entity.Wishlist{ID: uuid.New(), Title: "Gifts", UserID: alice.ID}
+	require.NoError(t, wishlistRepo.Create(ctx, w))
+
+	for i := 1; i <= 4; i++ {
+		author := alice.ID
+		if i == 4 {
+			author = bob.ID
+		}
+		rev, err := revisionRepo.Create(ctx, entity.WishlistRevision{
+			ID: uuid.New(), WishlistID: w.ID, AuthorID: author, CreatedAt: time.Now(),
+			Blocks:   []entity.Block{{Type: "text", ColSpan: 2, Data: json.RawMessage(fmt.Sprintf(`{"content":"v%d"}`, i))}},
+			Settings: entity.Settings{ColorScheme: "mint"},
+		}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, i, rev.Number)
+	}
+
+	// Старше трёх последних ревизий не хранится
+	revisions, err := revisionRepo.GetAllByWishlistID(ctx, w.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, []int{4, 3, 2}, []int{revisions[0].Number, revisions[1].Number, revisions[2].Number})
+	assert.Empty(t, revisions[0].Blocks)
+	assert.Equal(t, "bob", revisions[0].AuthorHandle)
+	_, err = revisionRepo.GetByNumber(ctx, w.ID, 1)
+	assert.Error(t, err)
+
+	rev, err := revisionRepo.GetByNumber(ctx, w.ID, 3)
+	require.NoError(t, err)
+	require.Len(t, rev.Blocks, 1)
+	assert.JSONEq(t, `{"content":"v3"}`, string(rev.Blocks[0].Data))
+	assert.Equal(t, "mint", rev.Settings.ColorScheme)
+
+	// Ревизии удалённого аккаунта остаются в чужом вишлисте без автора
+	require.NoError(t, userRepo.Delete(ctx, bob.ID))
+	latest, err := revisionRepo.GetLatest(ctx, w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, latest.Number)
+	assert.Equal(t, uuid.Nil, latest.AuthorID)
+	assert.Empty(t, latest.AuthorHandle)
+
+	require.NoError(t, wishlistRepo.Purge(ctx, w.ID))
+	_, err = revisionRepo.GetLatest(ctx, w.ID)
+	assert.Error(t, err)
+}
+
+func TestDataExportRepo_ClaimNext(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	exportRepo := persistent.NewDataExportRepo(db)
+	now := time.Now()
+
+	startedLongAgo := now.Add(-time.Hour)
+	startedRecently := now.Add(-time.Minute)
+	abandoned := entity.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: entity.DataExportProcessing, StartedAt: &startedLongAgo, CreatedAt: now.Add(-3 * time.Hour)}
+	running := entity.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: entity.DataExportProcessing, StartedAt: &startedRecently, CreatedAt: now.Add(-2 * time.Hour)}
+	pending := entity.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: entity.DataExportPending, CreatedAt: now.Add(-time.Hour)}
+	for _, e := range []entity.DataExport{abandoned, running, pending} {
+		require.NoError(t, exportRepo.Create(ctx, e))
+	}
+
+	staleBefore := now.Add(-30 * time.Minute)
+	var claimed []uuid.UUID
+	for {
+		e, ok, err := exportRepo.ClaimNext(ctx, now, staleBefore)
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		assert.Equal(t, entity.DataExportProcessing, e.Status)
+		claimed = append(claimed, e.ID)
+		// Взятая в работу выгрузка больше не выдаётся
+		require.Less(t, len(claimed), 3)
+	}
+	assert.Equal(t, []uuid.UUID{abandoned.ID, pending.ID}, claimed)
+}
+
+func TestTemplateRepo_GetLikedByUserID(t *testing.T) {
+	db := setupDB(t)
+	ctx := context.Background()
+	templateRepo := persistent.NewTemplateRepo(db)
+	userID := uuid.New()
+
+	liked := entity.Template{ID: uuid.New(), UserID: uuid.New(), Name: "Liked", IsPublic: true}
+	other := entity.Template{ID: uuid.New(), UserID: uuid.New(), Name: "Other", IsPublic: true}
+	require.NoError(t, templateRepo.Create(ctx, liked))
+	require.NoError(t, templateRepo.Create(ctx, other))
+	_, err := templateRepo.Like(ctx, userID, liked.ID)
+	require.NoError(t, err)
+
+	got, err := templateRepo.GetLikedByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, liked.ID, got[0].ID)
 }
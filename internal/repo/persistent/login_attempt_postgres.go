@@ -0,0 +1,93 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/internal/repo"
+)
+
+type loginAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepo(db *gorm.DB) repo.LoginAttemptRepo {
+	return &loginAttemptRepo{db: db}
+}
+
+func (r *loginAttemptRepo) RegisterFailure(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (int, error) {
+	var failures int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+		  failures        = CASE WHEN login_attempts.last_failure_at > ?
+		                         THEN login_attempts.failures + 1
+		                         ELSE 1 END,
+		  last_failure_at = ?
+		RETURNING failures
+	`, key, now, now.Add(-resetAfter), now).Scan(&failures).Error
+	if err != nil {
+		return 0, fmt.Errorf("loginAttemptRepo.RegisterFailure: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *loginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	err := r.db.WithContext(ctx).
+		Model(&LoginAttemptModel{}).
+		Where("key = ?", key).
+		Update("locked_until", until).Error
+	if err != nil {
+		return fmt.Errorf("loginAttemptRepo.Lock: %w", err)
+	}
+	return nil
+}
+
+func (r *loginAttemptRepo) LockedUntil(ctx context.Context, keys []string, now time.Time) (time.Time, error) {
+	var until *time.Time
+	err := r.db.WithContext(ctx).
+		Model(&LoginAttemptModel{}).
+		Select("MAX(locked_until)").
+		Where("key IN ? AND locked_until > ?", keys, now).
+		Scan(&until).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loginAttemptRepo.LockedUntil: %w", err)
+	}
+	if until == nil {
+		return time.Time{}, nil
+	}
+	return *until, nil
+}
+
+func (r *loginAttemptRepo) Reset(ctx context.Context, key string) (int, error) {
+	var failures []int
+	err := r.db.WithContext(ctx).
+		Raw(`DELETE FROM login_attempts WHERE key = ? RETURNING failures`, key).
+		Scan(&failures).Error
+	if err != nil {
+		return 0, fmt.Errorf("loginAttemptRepo.Reset: %w", err)
+	}
+	if len(failures) == 0 {
+		return 0, nil
+	}
+	return failures[0], nil
+}
+
+func (r *loginAttemptRepo) Acquire(ctx context.Context, key string, now time.Time, cooldown time.Duration) (bool, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at, locked_until)
+		VALUES (?, 0, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET locked_until = EXCLUDED.locked_until
+		WHERE login_attempts.locked_until IS NULL OR login_attempts.locked_until <= ?
+		RETURNING key
+	`, key, now, now.Add(cooldown), now).Scan(&keys).Error
+	if err != nil {
+		return false, fmt.Errorf("loginAttemptRepo.Acquire: %w", err)
+	}
+	return len(keys) > 0, nil
+}
@@ -0,0 +1,77 @@
+package persistent
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MigrateTelegramIdentities переносит Telegram-пользователей старого формата
+// (username = password = Telegram ID, пароль не захеширован) в user_identities
+// и убирает у них пароль. Идемпотентна: после переноса пароль пустой и условие не срабатывает.
+func MigrateTelegramIdentities(db *gorm.DB) error {
+	const legacy = "u.password = u.username AND u.username ~ '^[0-9]+$'"
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO user_identities (id, user_id, provider, subject, created_at, updated_at)
+			SELECT gen_random_uuid(), u.id, 'telegram', u.username, NOW(), NOW()
+			FROM users u
+			WHERE ` + legacy + `
+			ON CONFLICT (provider, subject) DO NOTHING`).Error; err != nil {
+			return err
+		}
+		// Username переводим в формат tg_<id>, если он не занят
+		return tx.Exec(`
+			UPDATE users u SET
+				password = '',
+				username = CASE
+					WHEN EXISTS (SELECT 1 FROM users o WHERE o.username = 'tg_' || u.username) THEN u.username
+					ELSE 'tg_' || u.username
+				END
+			WHERE ` + legacy).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrate telegram identities: %w", err)
+	}
+	return nil
+}
+
+// PrepareUserEmails вызывается до AutoMigrate: пустые адреса превращаются в NULL,
+// иначе уникальный индекс на users.email не создастся.
+func PrepareUserEmails(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&UserModel{}, "email") {
+		return nil
+	}
+	if err := db.Exec("UPDATE users SET email = NULL WHERE email = ''").Error; err != nil {
+		return fmt.Errorf("prepare user emails: %w", err)
+	}
+	return nil
+}
+
+// PromoteAdmins назначает роль admin перечисленным пользователям — так на новом
+// инстансе появляется первый администратор. Остальным ролям не мешает: повышает, но не понижает.
+func PromoteAdmins(db *gorm.DB, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	if err := db.Exec("UPDATE users SET role = 'admin' WHERE username IN ? AND role <> 'admin'", usernames).Error; err != nil {
+		return fmt.Errorf("promote admins: %w", err)
+	}
+	return nil
+}
+
+// BackfillEventEnds заполняет wishlists.event_ends_at для вишлистов, сохранённых до появления
+// колонки. Часового пояса у них нет, поэтому день события считается по UTC — как в entity.Location.
+// Идемпотентна: трогает только строки с датой и пустым event_ends_at.
+func BackfillEventEnds(db *gorm.DB) error {
+	if err := db.Exec(`
+		UPDATE wishlists SET event_ends_at =
+			(date_trunc('day', (location->>'time')::timestamptz AT TIME ZONE 'UTC') + interval '1 day') AT TIME ZONE 'UTC'
+		WHERE event_ends_at IS NULL
+			AND location->>'time' IS NOT NULL
+			AND location->>'time' NOT LIKE '0001-01-01%'`).Error; err != nil {
+		return fmt.Errorf("backfill event ends: %w", err)
+	}
+	return nil
+}
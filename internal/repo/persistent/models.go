@@ -11,15 +11,110 @@ import (
 
 // UserModel — GORM-модель для таблицы "users"
 type UserModel struct {
-	ID          uuid.UUID `gorm:"primaryKey"`
-	Username    string    `gorm:"unique;not null"`
-	Password    string    `gorm:"not null"`
-	DisplayName string
-	Avatar      string
+	ID            uuid.UUID `gorm:"primaryKey"`
+	Username      string    `gorm:"unique;not null"`
+	Password      string    `gorm:"not null"`
+	DisplayName   string
+	Avatar        string
+	Email         *string `gorm:"uniqueIndex"` // только подтверждённый адрес в нижнем регистре, NULL — нет адреса
+	Handle        *string `gorm:"uniqueIndex"` // в нижнем регистре, NULL — публичного профиля нет
+	EmailVerified bool    `gorm:"not null;default:false"`
+	TOTPSecret    string  `gorm:"column:totp_secret"`
+	TOTPEnabled   bool    `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep  int64   `gorm:"column:totp_last_step;not null;default:0"`
+	Role          string  `gorm:"not null;default:'user'"`
+	BannedAt      *time.Time
+	BanReason     string
+	// DeletionScheduledAt — момент окончательного удаления аккаунта, NULL — удаление не запрошено
+	DeletionScheduledAt *time.Time `gorm:"index"`
 }
 
 func (UserModel) TableName() string { return "users" }
 
+// HandleRedirectModel — GORM-модель для таблицы "handle_redirects": прежние handle пользователей
+type HandleRedirectModel struct {
+	Handle    string    `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (HandleRedirectModel) TableName() string { return "handle_redirects" }
+
+// UserTokenModel — GORM-модель для таблицы "user_tokens"
+type UserTokenModel struct {
+	ID        uuid.UUID `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	Purpose   string    `gorm:"not null"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	Payload   string
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (UserTokenModel) TableName() string { return "user_tokens" }
+
+// AccessTokenModel — GORM-модель для таблицы "access_tokens"
+type AccessTokenModel struct {
+	ID         uuid.UUID `gorm:"primaryKey"`
+	UserID     uuid.UUID `gorm:"not null;index"`
+	Name       string    `gorm:"not null"`
+	TokenHash  string    `gorm:"not null;uniqueIndex"`
+	Scopes     string    `gorm:"not null"` // через пробел, как scope в OAuth
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (AccessTokenModel) TableName() string { return "access_tokens" }
+
+// DataExportModel — GORM-модель для таблицы "data_exports"
+type DataExportModel struct {
+	ID          uuid.UUID `gorm:"primaryKey"`
+	UserID      uuid.UUID `gorm:"not null;index"`
+	Status      string    `gorm:"not null;index"`
+	ObjectID    string
+	Size        int64 `gorm:"not null;default:0"`
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time `gorm:"index"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+func (DataExportModel) TableName() string { return "data_exports" }
+
+// UserIdentityModel — GORM-модель для таблицы "user_identities"
+type UserIdentityModel struct {
+	ID        uuid.UUID `gorm:"primaryKey"`
+	UserID    uuid.UUID `gorm:"not null;index"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Username  string
+	FirstName string
+	LastName  string
+	PhotoURL  string
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (UserIdentityModel) TableName() string { return "user_identities" }
+
+// SessionModel — GORM-модель для таблицы "sessions"
+type SessionModel struct {
+	ID               uuid.UUID `gorm:"primaryKey"`
+	UserID           uuid.UUID `gorm:"not null;index"`
+	RefreshTokenHash string    `gorm:"not null"`
+	ExpiresAt        time.Time `gorm:"not null"`
+	RevokedAt        *time.Time
+	UserAgent        string
+	IP               string    `gorm:"column:ip"`
+	LastSeenAt       time.Time `gorm:"not null;default:now()"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
+
+func (SessionModel) TableName() string { return "sessions" }
+
 // WishlistModel — GORM-модель для таблицы "wishlists"
 type WishlistModel struct {
 	ID            uuid.UUID    `gorm:"primaryKey"`
@@ -32,12 +127,81 @@ type WishlistModel struct {
 	PresentsCount uint
 	ShortID       *string    `gorm:"uniqueIndex;column:short_id"`
 	Blocks        BlocksJSON `gorm:"type:jsonb"`
+	Unpublished   bool       `gorm:"not null;default:false"`
 	CreatedAt     time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+	// Version — счётчик правок для условной записи, см. wishlistRepo.Update
+	Version uint `gorm:"not null;default:0"`
+	// ListedOnProfile — владелец показывает вишлист на своей публичной странице
+	ListedOnProfile bool   `gorm:"not null;default:false"`
+	Visibility      string `gorm:"not null;default:'unlisted'"`
+	PasswordHash    string
+	// DeletedAt — момент переноса в корзину, NULL — вишлист не удалён
+	DeletedAt *time.Time `gorm:"index"`
+	// Slug и SlugScope — копия текущей записи из wishlist_slugs, чтобы читать вишлист без join.
+	// Меняются только через ChangeSlug
+	Slug      string
+	SlugScope string
+	// EventEndsAt — конец дня события в его часовом поясе, вычисляется из Location; NULL — даты нет
+	EventEndsAt   *time.Time `gorm:"index"`
+	EventRepeat   string
+	ArchivedAt    *time.Time
+	NextEditionID *uuid.UUID `gorm:"type:uuid"`
 }
 
 func (WishlistModel) TableName() string { return "wishlists" }
 
+// WishlistSlugModel — GORM-модель для таблицы "wishlist_slugs": текущие и прежние slug вишлистов.
+// Первичный ключ гарантирует уникальность slug в пространстве: owner ID или uuid.Nil для глобальных
+type WishlistSlugModel struct {
+	Scope      uuid.UUID `gorm:"primaryKey"`
+	Slug       string    `gorm:"primaryKey"`
+	WishlistID uuid.UUID `gorm:"not null;index"`
+	CreatedAt  time.Time `gorm:"not null"`
+	ReleasedAt *time.Time
+}
+
+func (WishlistSlugModel) TableName() string { return "wishlist_slugs" }
+
+// WishlistRevisionModel — GORM-модель для таблицы "wishlist_revisions": история блоков и настроек
+type WishlistRevisionModel struct {
+	ID           uuid.UUID    `gorm:"primaryKey"`
+	WishlistID   uuid.UUID    `gorm:"not null;uniqueIndex:idx_wishlist_revisions_number"`
+	Number       int          `gorm:"not null;uniqueIndex:idx_wishlist_revisions_number"`
+	AuthorID     uuid.UUID    `gorm:"not null"` // uuid.Nil — автор удалил аккаунт
+	RestoredFrom *int
+	Blocks       BlocksJSON   `gorm:"type:jsonb"`
+	Settings     SettingsJSON `gorm:"type:json"`
+	CreatedAt    time.Time    `gorm:"not null"`
+}
+
+func (WishlistRevisionModel) TableName() string { return "wishlist_revisions" }
+
+// CollaboratorModel — GORM-модель для таблицы "wishlist_collaborators"
+type CollaboratorModel struct {
+	WishlistID uuid.UUID `gorm:"primaryKey"`
+	UserID     uuid.UUID `gorm:"primaryKey;index"`
+	Role       string    `gorm:"not null"`
+	Pending    bool      `gorm:"not null;default:false"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (CollaboratorModel) TableName() string { return "wishlist_collaborators" }
+
+// WishlistInviteModel — GORM-модель для таблицы "wishlist_invites"
+type WishlistInviteModel struct {
+	ID         uuid.UUID `gorm:"primaryKey"`
+	WishlistID uuid.UUID `gorm:"not null;index"`
+	Role       string    `gorm:"not null"`
+	TokenHash  string    `gorm:"not null;uniqueIndex"`
+	CreatedBy  uuid.UUID `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	UsedAt     *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (WishlistInviteModel) TableName() string { return "wishlist_invites" }
+
 // PresentModel — GORM-модель для таблицы "presents"
 type PresentModel struct {
 	ID          uuid.UUID `gorm:"primaryKey"`
@@ -50,6 +214,9 @@ type PresentModel struct {
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
 	WishlistID  uuid.UUID `gorm:"not null"`
+	Version     uint      `gorm:"not null;default:0"`
+	// DeletedAt — момент переноса в корзину, NULL — подарок не удалён
+	DeletedAt *time.Time `gorm:"index"`
 }
 
 func (PresentModel) TableName() string { return "presents" }
@@ -63,6 +230,16 @@ type ParseRateLimitModel struct {
 
 func (ParseRateLimitModel) TableName() string { return "parse_rate_limits" }
 
+// LoginAttemptModel — GORM-модель для таблицы "login_attempts"
+type LoginAttemptModel struct {
+	Key           string    `gorm:"primaryKey"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
+
+func (LoginAttemptModel) TableName() string { return "login_attempts" }
+
 // PresentMetaModel — GORM-модель для таблицы "present_meta"
 type PresentMetaModel struct {
 	PresentID   uuid.UUID `gorm:"primaryKey"`
@@ -120,9 +297,10 @@ func (s SettingsJSON) Value() (driver.Value, error) {
 
 // LocationJSON — JSON-тип для хранения местоположения
 type LocationJSON struct {
-	Name string    `json:"name"`
-	Link string    `json:"link"`
-	Time time.Time `json:"time"`
+	Name     string    `json:"name"`
+	Link     string    `json:"link"`
+	Time     time.Time `json:"time"`
+	Timezone string    `json:"timezone,omitempty"`
 }
 
 func (l *LocationJSON) Scan(value interface{}) error {
@@ -2,7 +2,9 @@ package persistent
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
@@ -32,3 +34,25 @@ func (r *presentMetaRepo) Upsert(ctx context.Context, meta entity.PresentMeta) e
 		DoUpdates: clause.AssignmentColumns([]string{"source", "original_url", "category", "brand", "parsed_at"}),
 	}).Create(&model).Error
 }
+
+func (r *presentMetaRepo) GetByPresentIDs(ctx context.Context, presentIDs []uuid.UUID) ([]entity.PresentMeta, error) {
+	if len(presentIDs) == 0 {
+		return nil, nil
+	}
+	var models []PresentMetaModel
+	if err := r.db.WithContext(ctx).Where("present_id IN ?", presentIDs).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("presentMetaRepo.GetByPresentIDs: %w", err)
+	}
+	result := make([]entity.PresentMeta, len(models))
+	for i, m := range models {
+		result[i] = entity.PresentMeta{
+			PresentID:   m.PresentID,
+			Source:      m.Source,
+			OriginalURL: m.OriginalURL,
+			Category:    m.Category,
+			Brand:       m.Brand,
+			ParsedAt:    m.ParsedAt,
+		}
+	}
+	return result, nil
+}
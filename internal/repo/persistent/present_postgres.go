@@ -3,6 +3,7 @@ package persistent
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"main/internal/entity"
 
@@ -28,7 +29,7 @@ func (r *presentRepo) Create(ctx context.Context, present entity.Present) error
 
 func (r *presentRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Present, error) {
 	var m PresentModel
-	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&m, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
 		return entity.Present{}, fmt.Errorf("presentRepo.GetByID: %w", err)
 	}
 	return toPresentEntity(m), nil
@@ -36,33 +37,110 @@ func (r *presentRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Present
 
 func (r *presentRepo) GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error) {
 	var models []PresentModel
-	if err := r.db.WithContext(ctx).Where("wishlist_id = ?", wishlistID).Find(&models).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("wishlist_id = ? AND deleted_at IS NULL", wishlistID).Find(&models).Error; err != nil {
 		return nil, fmt.Errorf("presentRepo.GetAllByWishlistID: %w", err)
 	}
-	presents := make([]entity.Present, len(models))
-	for i, m := range models {
-		presents[i] = toPresentEntity(m)
-	}
-	return presents, nil
+	return toPresentEntities(models), nil
 }
 
 func (r *presentRepo) Update(ctx context.Context, present entity.Present) error {
 	m := toPresentModel(present)
-	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
-		return fmt.Errorf("presentRepo.Update: %w", err)
+	m.Version = present.Version + 1
+	result := r.db.WithContext(ctx).Model(&PresentModel{ID: present.ID}).
+		Where("version = ? AND deleted_at IS NULL", present.Version).
+		Select("*").
+		Omit("id", "created_at", "deleted_at").
+		Updates(&m)
+	if result.Error != nil {
+		return fmt.Errorf("presentRepo.Update: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("presentRepo.Update: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *presentRepo) Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&PresentModel{}).
+		Where("id = ? AND version = ? AND deleted_at IS NULL", id, version).
+		UpdateColumn("deleted_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("presentRepo.Delete: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("presentRepo.Delete: %w", gorm.ErrRecordNotFound)
 	}
 	return nil
 }
 
-func (r *presentRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := r.db.WithContext(ctx).Delete(&PresentModel{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("presentRepo.Delete: %w", err)
+func (r *presentRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&PresentModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("presentRepo.Restore: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("presentRepo.Restore: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *presentRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Present, error) {
+	var m PresentModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+		return entity.Present{}, fmt.Errorf("presentRepo.GetDeletedByID: %w", err)
+	}
+	return toPresentEntity(m), nil
+}
+
+func (r *presentRepo) GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Present, error) {
+	wishlists := r.db.Model(&WishlistModel{}).Select("id").Where("user_id = ? AND deleted_at IS NULL", userID)
+	var models []PresentModel
+	if err := r.db.WithContext(ctx).
+		Where("wishlist_id IN (?) AND deleted_at IS NOT NULL", wishlists).
+		Order("deleted_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("presentRepo.GetDeletedByUserID: %w", err)
+	}
+	return toPresentEntities(models), nil
+}
+
+func (r *presentRepo) GetDeletedByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error) {
+	var models []PresentModel
+	if err := r.db.WithContext(ctx).Where("wishlist_id = ? AND deleted_at IS NOT NULL", wishlistID).Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("presentRepo.GetDeletedByWishlistID: %w", err)
+	}
+	return toPresentEntities(models), nil
+}
+
+func (r *presentRepo) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Present, error) {
+	var models []PresentModel
+	if err := r.db.WithContext(ctx).
+		Where("deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("presentRepo.GetDeletedBefore: %w", err)
+	}
+	return toPresentEntities(models), nil
+}
+
+func (r *presentRepo) Purge(ctx context.Context, id uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("present_id = ?", id).Delete(&PresentMetaModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&PresentModel{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("presentRepo.Purge: %w", err)
 	}
 	return nil
 }
 
 func (r *presentRepo) CountByWishlistID(ctx context.Context, wishlistID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&PresentModel{}).Where("wishlist_id = ?", wishlistID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&PresentModel{}).Where("wishlist_id = ? AND deleted_at IS NULL", wishlistID).Count(&count).Error
 	return count, err
 }
@@ -0,0 +1,140 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/internal/entity"
+	"main/internal/repo"
+)
+
+type revisionRepo struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepo(db *gorm.DB) repo.RevisionRepo {
+	return &revisionRepo{db: db}
+}
+
+func (r *revisionRepo) Create(ctx context.Context, rev entity.WishlistRevision, keep int) (entity.WishlistRevision, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокировка вишлиста упорядочивает параллельные правки: номера идут без пропусков и повторов
+		var w WishlistModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&w, "id = ?", rev.WishlistID).Error; err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&WishlistRevisionModel{}).Where("wishlist_id = ?", rev.WishlistID).
+			Select("COALESCE(MAX(number), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		rev.Number = last + 1
+		m := toRevisionModel(rev)
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		return tx.Where("wishlist_id = ? AND number <= ?", rev.WishlistID, rev.Number-keep).
+			Delete(&WishlistRevisionModel{}).Error
+	})
+	if err != nil {
+		return entity.WishlistRevision{}, fmt.Errorf("revisionRepo.Create: %w", err)
+	}
+	return rev, nil
+}
+
+// revisionRow — ревизия вместе с профилем автора; у удалённого аккаунта поля профиля пустые
+type revisionRow struct {
+	WishlistRevisionModel
+	Handle      *string
+	DisplayName *string
+}
+
+func (r *revisionRepo) query(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Table("wishlist_revisions rv").
+		Joins("LEFT JOIN users u ON u.id = rv.author_id")
+}
+
+func (r *revisionRepo) GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.WishlistRevision, error) {
+	var rows []revisionRow
+	if err := r.query(ctx).
+		Select("rv.id, rv.wishlist_id, rv.number, rv.author_id, rv.restored_from, rv.settings, rv.created_at, u.handle, u.display_name").
+		Where("rv.wishlist_id = ?", wishlistID).
+		Order("rv.number DESC").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("revisionRepo.GetAllByWishlistID: %w", err)
+	}
+	revisions := make([]entity.WishlistRevision, len(rows))
+	for i, row := range rows {
+		revisions[i] = toRevisionEntity(row)
+	}
+	return revisions, nil
+}
+
+func (r *revisionRepo) GetByNumber(ctx context.Context, wishlistID uuid.UUID, number int) (entity.WishlistRevision, error) {
+	var row revisionRow
+	if err := r.query(ctx).
+		Select("rv.*, u.handle, u.display_name").
+		Where("rv.wishlist_id = ? AND rv.number = ?", wishlistID, number).
+		Take(&row).Error; err != nil {
+		return entity.WishlistRevision{}, fmt.Errorf("revisionRepo.GetByNumber: %w", err)
+	}
+	return toRevisionEntity(row), nil
+}
+
+func (r *revisionRepo) GetLatest(ctx context.Context, wishlistID uuid.UUID) (entity.WishlistRevision, error) {
+	var row revisionRow
+	if err := r.query(ctx).
+		Select("rv.*, u.handle, u.display_name").
+		Where("rv.wishlist_id = ?", wishlistID).
+		Order("rv.number DESC").
+		Take(&row).Error; err != nil {
+		return entity.WishlistRevision{}, fmt.Errorf("revisionRepo.GetLatest: %w", err)
+	}
+	return toRevisionEntity(row), nil
+}
+
+func toRevisionModel(rev entity.WishlistRevision) WishlistRevisionModel {
+	return WishlistRevisionModel{
+		ID:           rev.ID,
+		WishlistID:   rev.WishlistID,
+		Number:       rev.Number,
+		AuthorID:     rev.AuthorID,
+		RestoredFrom: rev.RestoredFrom,
+		Blocks:       toBlocksJSON(rev.Blocks),
+		Settings: SettingsJSON{
+			ColorScheme:          rev.Settings.ColorScheme,
+			ShowGiftAvailability: rev.Settings.ShowGiftAvailability,
+			PresentsLayout:       rev.Settings.PresentsLayout,
+		},
+		CreatedAt: rev.CreatedAt,
+	}
+}
+
+func toRevisionEntity(row revisionRow) entity.WishlistRevision {
+	rev := entity.WishlistRevision{
+		ID:           row.ID,
+		WishlistID:   row.WishlistID,
+		Number:       row.Number,
+		AuthorID:     row.AuthorID,
+		RestoredFrom: row.RestoredFrom,
+		Blocks:       toBlockEntities(row.Blocks),
+		Settings: entity.Settings{
+			ColorScheme:          row.Settings.ColorScheme,
+			ShowGiftAvailability: row.Settings.ShowGiftAvailability,
+			PresentsLayout:       row.Settings.PresentsLayout,
+		},
+		CreatedAt: row.CreatedAt,
+	}
+	if row.Handle != nil {
+		rev.AuthorHandle = *row.Handle
+	}
+	if row.DisplayName != nil {
+		rev.AuthorName = *row.DisplayName
+	}
+	return rev
+}
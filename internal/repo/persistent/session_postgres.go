@@ -0,0 +1,96 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+)
+
+type sessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepo(db *gorm.DB) *sessionRepo {
+	return &sessionRepo{db: db}
+}
+
+func (r *sessionRepo) Create(ctx context.Context, session entity.Session) error {
+	m := toSessionModel(session)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("sessionRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Session, error) {
+	var m SessionModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		return entity.Session{}, fmt.Errorf("sessionRepo.GetByID: %w", err)
+	}
+	return toSessionEntity(m), nil
+}
+
+// Rotate — compare-and-swap по хешу, исключает двойное использование одного refresh-токена
+func (r *sessionRepo) Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&SessionModel{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": newHash,
+			"expires_at":         expiresAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("sessionRepo.Rotate: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *sessionRepo) Touch(ctx context.Context, id uuid.UUID, seenAt time.Time, ip, userAgent string) error {
+	if err := r.db.WithContext(ctx).Model(&SessionModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_seen_at": seenAt,
+			"ip":           ip,
+			"user_agent":   userAgent,
+		}).Error; err != nil {
+		return fmt.Errorf("sessionRepo.Touch: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepo) GetActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]entity.Session, error) {
+	var models []SessionModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("sessionRepo.GetActiveByUserID: %w", err)
+	}
+	sessions := make([]entity.Session, len(models))
+	for i, m := range models {
+		sessions[i] = toSessionEntity(m)
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&SessionModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("sessionRepo.Revoke: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepo) RevokeAllByUserID(ctx context.Context, userID, exceptID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&SessionModel{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("sessionRepo.RevokeAllByUserID: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"main/internal/entity"
+	"main/internal/repo"
+)
+
+type statsRepo struct {
+	db *gorm.DB
+}
+
+func NewStatsRepo(db *gorm.DB) repo.StatsRepo {
+	return &statsRepo{db: db}
+}
+
+func (r *statsRepo) Get(ctx context.Context, now time.Time) (entity.InstanceStats, error) {
+	var stats entity.InstanceStats
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM users)                                   AS users,
+			(SELECT COUNT(*) FROM users WHERE banned_at IS NOT NULL)       AS banned_users,
+			(SELECT COUNT(*) FROM wishlists)                               AS wishlists,
+			(SELECT COUNT(*) FROM presents)                                AS presents,
+			(SELECT COUNT(*) FROM templates)                               AS templates,
+			(SELECT COUNT(*) FROM templates WHERE is_public)               AS public_templates,
+			(SELECT COUNT(*) FROM sessions
+				WHERE revoked_at IS NULL AND expires_at > ?)               AS active_sessions
+	`, now).Scan(&stats).Error
+	if err != nil {
+		return entity.InstanceStats{}, fmt.Errorf("statsRepo.Get: %w", err)
+	}
+	return stats, nil
+}
@@ -50,6 +50,22 @@ func (r *templateRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]
 	return result, nil
 }
 
+func (r *templateRepo) GetLikedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Template, error) {
+	var models []TemplateModel
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN template_likes tl ON tl.template_id = templates.id").
+		Where("tl.user_id = ?", userID).
+		Order("tl.created_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("templateRepo.GetLikedByUserID: %w", err)
+	}
+	result := make([]entity.Template, len(models))
+	for i, m := range models {
+		result[i] = toTemplateEntity(m)
+	}
+	return result, nil
+}
+
 type templateWithAuthorRow struct {
 	TemplateModel
 	UserDisplayName string `gorm:"column:user_display_name"`
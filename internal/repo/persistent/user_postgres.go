@@ -3,11 +3,15 @@ package persistent
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"main/internal/entity"
+	"main/internal/repo"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepo struct {
@@ -34,6 +38,16 @@ func (r *userRepo) GetByUsername(ctx context.Context, username string) (entity.U
 	return toUserEntity(m), nil
 }
 
+func (r *userRepo) GetByVerifiedEmail(ctx context.Context, email string) (entity.User, error) {
+	var m UserModel
+	if err := r.db.WithContext(ctx).
+		Where("email = LOWER(?) AND email_verified", email).
+		First(&m).Error; err != nil {
+		return entity.User{}, fmt.Errorf("userRepo.GetByVerifiedEmail: %w", err)
+	}
+	return toUserEntity(m), nil
+}
+
 func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
 	var m UserModel
 	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
@@ -42,10 +56,191 @@ func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.User, erro
 	return toUserEntity(m), nil
 }
 
-func (r *userRepo) Update(ctx context.Context, user entity.User) error {
+func (r *userRepo) GetByHandle(ctx context.Context, handle string) (entity.User, error) {
+	var m UserModel
+	if err := r.db.WithContext(ctx).Where("handle = ?", handle).First(&m).Error; err != nil {
+		return entity.User{}, fmt.Errorf("userRepo.GetByHandle: %w", err)
+	}
+	return toUserEntity(m), nil
+}
+
+func (r *userRepo) UpdateColumns(ctx context.Context, user entity.User, columns ...repo.UserColumn) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	fields := make([]string, len(columns))
+	for i, c := range columns {
+		fields[i] = string(c)
+	}
 	m := toUserModel(user)
-	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
-		return fmt.Errorf("userRepo.Update: %w", err)
+	result := r.db.WithContext(ctx).
+		Model(&UserModel{ID: user.ID}).
+		Select(fields).
+		Updates(&m)
+	if result.Error != nil {
+		return fmt.Errorf("userRepo.UpdateColumns: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("userRepo.UpdateColumns: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *userRepo) ReplacePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&UserModel{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		return fmt.Errorf("userRepo.ReplacePasswordHash: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("userRepo.ReplacePasswordHash: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *userRepo) UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&UserModel{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return fmt.Errorf("userRepo.UseTOTPStep: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("userRepo.UseTOTPStep: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *userRepo) ChangeHandle(ctx context.Context, userID uuid.UUID, handle string, now time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m UserModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&m, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		old := derefString(m.Handle)
+		if old == handle {
+			return nil
+		}
+		if handle != "" {
+			if err := tx.Delete(&HandleRedirectModel{}, "handle = ?", handle).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&UserModel{}).Where("id = ?", userID).
+			Update("handle", nullableString(handle)).Error; err != nil {
+			return err
+		}
+		if old == "" {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "handle"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "created_at"}),
+		}).Create(&HandleRedirectModel{Handle: old, UserID: userID, CreatedAt: now}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("userRepo.ChangeHandle: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) Search(ctx context.Context, query string, limit, offset int) ([]entity.User, int64, error) {
+	q := r.db.WithContext(ctx).Model(&UserModel{})
+	if query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+		q = q.Where("LOWER(username) LIKE ? OR LOWER(display_name) LIKE ? OR email LIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("userRepo.Search: %w", err)
+	}
+	var models []UserModel
+	if err := q.Order("username").Limit(limit).Offset(offset).Find(&models).Error; err != nil {
+		return nil, 0, fmt.Errorf("userRepo.Search: %w", err)
+	}
+
+	users := make([]entity.User, len(models))
+	for i, m := range models {
+		users[i] = toUserEntity(m)
+	}
+	return users, total, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы поиск шёл по буквальной подстроке
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *userRepo) GetDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entity.User, error) {
+	var models []UserModel
+	if err := r.db.WithContext(ctx).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("userRepo.GetDueForDeletion: %w", err)
+	}
+	users := make([]entity.User, len(models))
+	for i, m := range models {
+		users[i] = toUserEntity(m)
+	}
+	return users, nil
+}
+
+func (r *userRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wishlists := tx.Model(&WishlistModel{}).Select("id").Where("user_id = ?", id)
+		presents := tx.Model(&PresentModel{}).Select("id").Where("wishlist_id IN (?)", wishlists)
+		templates := tx.Model(&TemplateModel{}).Select("id").Where("user_id = ?", id)
+
+		// Лайки пользователя на чужих шаблонах уменьшают их счётчики
+		if err := tx.Exec(`
+			UPDATE templates SET likes_count = GREATEST(likes_count - 1, 0)
+			WHERE id IN (SELECT template_id FROM template_likes WHERE user_id = ?) AND user_id <> ?`,
+			id, id).Error; err != nil {
+			return err
+		}
+		// В истории чужих вишлистов правки остаются, но без автора
+		if err := tx.Model(&WishlistRevisionModel{}).Where("author_id = ?", id).
+			Update("author_id", uuid.Nil).Error; err != nil {
+			return err
+		}
+		steps := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&PresentMetaModel{}, "present_id IN (?)", []interface{}{presents}},
+			{&PresentModel{}, "wishlist_id IN (?)", []interface{}{wishlists}},
+			{&CollaboratorModel{}, "user_id = ? OR wishlist_id IN (?)", []interface{}{id, wishlists}},
+			{&WishlistInviteModel{}, "created_by = ? OR wishlist_id IN (?)", []interface{}{id, wishlists}},
+			{&WishlistSlugModel{}, "wishlist_id IN (?)", []interface{}{wishlists}},
+			{&WishlistRevisionModel{}, "wishlist_id IN (?)", []interface{}{wishlists}},
+			{&WishlistModel{}, "user_id = ?", []interface{}{id}},
+			{&TemplateLikeModel{}, "user_id = ? OR template_id IN (?)", []interface{}{id, templates}},
+			{&TemplateModel{}, "user_id = ?", []interface{}{id}},
+			{&UserIdentityModel{}, "user_id = ?", []interface{}{id}},
+			{&SessionModel{}, "user_id = ?", []interface{}{id}},
+			{&UserTokenModel{}, "user_id = ?", []interface{}{id}},
+			{&AccessTokenModel{}, "user_id = ?", []interface{}{id}},
+			{&DataExportModel{}, "user_id = ?", []interface{}{id}},
+			{&HandleRedirectModel{}, "user_id = ?", []interface{}{id}},
+			{&ParseRateLimitModel{}, "user_id = ?", []interface{}{id}},
+			{&UserModel{}, "id = ?", []interface{}{id}},
+		}
+		for _, s := range steps {
+			if err := tx.Where(s.query, s.args...).Delete(s.model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("userRepo.Delete: %w", err)
 	}
 	return nil
 }
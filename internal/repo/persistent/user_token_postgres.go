@@ -0,0 +1,54 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"main/internal/entity"
+)
+
+type userTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepo(db *gorm.DB) *userTokenRepo {
+	return &userTokenRepo{db: db}
+}
+
+func (r *userTokenRepo) Create(ctx context.Context, token entity.UserToken) error {
+	m := toUserTokenModel(token)
+	if err := r.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return fmt.Errorf("userTokenRepo.Create: %w", err)
+	}
+	return nil
+}
+
+// Consume — UPDATE ... RETURNING, поэтому один токен нельзя использовать дважды даже параллельно
+func (r *userTokenRepo) Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (entity.UserToken, error) {
+	var models []UserTokenModel
+	result := r.db.WithContext(ctx).Model(&models).
+		Clauses(clause.Returning{}).
+		Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return entity.UserToken{}, fmt.Errorf("userTokenRepo.Consume: %w", result.Error)
+	}
+	if len(models) == 0 {
+		return entity.UserToken{}, fmt.Errorf("userTokenRepo.Consume: %w", gorm.ErrRecordNotFound)
+	}
+	return toUserTokenEntity(models[0]), nil
+}
+
+func (r *userTokenRepo) InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error {
+	if err := r.db.WithContext(ctx).Model(&UserTokenModel{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("userTokenRepo.InvalidateAll: %w", err)
+	}
+	return nil
+}
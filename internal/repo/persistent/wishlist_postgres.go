@@ -3,11 +3,14 @@ package persistent
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"main/internal/entity"
+	"main/internal/repo"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type wishlistRepo struct {
@@ -26,9 +29,52 @@ func (r *wishlistRepo) Create(ctx context.Context, wishlist entity.Wishlist) err
 	return nil
 }
 
+func (r *wishlistRepo) CreateWithPresents(ctx context.Context, wishlist entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createWithPresents(tx, wishlist, presents, metas)
+	})
+	if err != nil {
+		return fmt.Errorf("wishlistRepo.CreateWithPresents: %w", err)
+	}
+	return nil
+}
+
+func createWithPresents(tx *gorm.DB, wishlist entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error {
+	m := toWishlistModel(wishlist)
+	if err := tx.Create(&m).Error; err != nil {
+		return err
+	}
+	if len(presents) > 0 {
+		models := make([]PresentModel, len(presents))
+		for i, p := range presents {
+			models[i] = toPresentModel(p)
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return err
+		}
+	}
+	if len(metas) > 0 {
+		models := make([]PresentMetaModel, len(metas))
+		for i, meta := range metas {
+			models[i] = PresentMetaModel{
+				PresentID:   meta.PresentID,
+				Source:      meta.Source,
+				OriginalURL: meta.OriginalURL,
+				Category:    meta.Category,
+				Brand:       meta.Brand,
+				ParsedAt:    meta.ParsedAt,
+			}
+		}
+		if err := tx.Create(&models).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *wishlistRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
 	var m WishlistModel
-	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&m, "id = ? AND deleted_at IS NULL", id).Error; err != nil {
 		return entity.Wishlist{}, fmt.Errorf("wishlistRepo.GetByID: %w", err)
 	}
 	return toWishlistEntity(m), nil
@@ -36,15 +82,101 @@ func (r *wishlistRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Wishli
 
 func (r *wishlistRepo) GetByShortID(ctx context.Context, shortID string) (entity.Wishlist, error) {
 	var m WishlistModel
-	if err := r.db.WithContext(ctx).First(&m, "short_id = ?", shortID).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&m, "short_id = ? AND deleted_at IS NULL", shortID).Error; err != nil {
 		return entity.Wishlist{}, fmt.Errorf("wishlistRepo.GetByShortID: %w", err)
 	}
 	return toWishlistEntity(m), nil
 }
 
+func (r *wishlistRepo) GetBySlug(ctx context.Context, scope uuid.UUID, slug string) (entity.Wishlist, error) {
+	var m WishlistModel
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN wishlist_slugs s ON s.wishlist_id = wishlists.id").
+		Where("s.scope = ? AND s.slug = ? AND wishlists.deleted_at IS NULL", scope, slug).
+		First(&m).Error; err != nil {
+		return entity.Wishlist{}, fmt.Errorf("wishlistRepo.GetBySlug: %w", err)
+	}
+	return toWishlistEntity(m), nil
+}
+
+func (r *wishlistRepo) GetSlug(ctx context.Context, scope uuid.UUID, slug string) (entity.WishlistSlug, error) {
+	var m WishlistSlugModel
+	if err := r.db.WithContext(ctx).First(&m, "scope = ? AND slug = ?", scope, slug).Error; err != nil {
+		return entity.WishlistSlug{}, fmt.Errorf("wishlistRepo.GetSlug: %w", err)
+	}
+	return entity.WishlistSlug{
+		Scope:      m.Scope,
+		Slug:       m.Slug,
+		WishlistID: m.WishlistID,
+		CreatedAt:  m.CreatedAt,
+		ReleasedAt: m.ReleasedAt,
+	}, nil
+}
+
+func (r *wishlistRepo) ChangeSlug(ctx context.Context, id uuid.UUID, version uint, scope uuid.UUID, slug string, now time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m WishlistModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&m, "id = ? AND version = ? AND deleted_at IS NULL", id, version).Error; err != nil {
+			return err
+		}
+		if m.Slug == slug && m.SlugScope == slugScopeName(scope, slug) {
+			return nil
+		}
+		if err := assignSlug(tx, id, scope, slug, now); err != nil {
+			return err
+		}
+		return tx.Model(&WishlistModel{}).Where("id = ?", id).
+			UpdateColumn("version", gorm.Expr("version + 1")).Error
+	})
+	if err != nil {
+		return fmt.Errorf("wishlistRepo.ChangeSlug: %w", err)
+	}
+	return nil
+}
+
+// assignSlug отпускает текущий slug вишлиста и закрепляет за ним новый (пустой — никакого)
+func assignSlug(tx *gorm.DB, id, scope uuid.UUID, slug string, now time.Time) error {
+	if err := tx.Model(&WishlistSlugModel{}).
+		Where("wishlist_id = ? AND released_at IS NULL", id).
+		Update("released_at", now).Error; err != nil {
+		return err
+	}
+	if slug != "" {
+		// Занятый другим вишлистом slug не перезаписывается: проверка в use case могла проиграть гонку
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scope"}, {Name: "slug"}},
+			DoUpdates: clause.AssignmentColumns([]string{"wishlist_id", "created_at", "released_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{clause.Expr{
+				SQL: "wishlist_slugs.wishlist_id = excluded.wishlist_id OR wishlist_slugs.released_at IS NOT NULL",
+			}}},
+		}).Create(&WishlistSlugModel{Scope: scope, Slug: slug, WishlistID: id, CreatedAt: now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("slug %q: %w", slug, repo.ErrConflict)
+		}
+	}
+	return tx.Model(&WishlistModel{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"slug": slug, "slug_scope": slugScopeName(scope, slug)}).Error
+}
+
+// slugScopeName — значение wishlists.slug_scope для slug в пространстве scope
+func slugScopeName(scope uuid.UUID, slug string) string {
+	switch {
+	case slug == "":
+		return ""
+	case scope == uuid.Nil:
+		return entity.SlugScopeGlobal
+	default:
+		return entity.SlugScopeProfile
+	}
+}
+
 func (r *wishlistRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
 	var models []WishlistModel
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&models).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND deleted_at IS NULL", userID).Find(&models).Error; err != nil {
 		return nil, fmt.Errorf("wishlistRepo.GetAllByUserID: %w", err)
 	}
 	wishlists := make([]entity.Wishlist, len(models))
@@ -54,17 +186,239 @@ func (r *wishlistRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]
 	return wishlists, nil
 }
 
+func (r *wishlistRepo) GetListedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	var models []WishlistModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND deleted_at IS NULL AND archived_at IS NULL AND listed_on_profile AND NOT unpublished AND visibility = ?",
+			userID, entity.VisibilityUnlisted).
+		Order("created_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetListedByUserID: %w", err)
+	}
+	wishlists := make([]entity.Wishlist, len(models))
+	for i, m := range models {
+		wishlists[i] = toWishlistEntity(m)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) GetSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	wishlists, err := r.getShared(ctx, userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetSharedWithUserID: %w", err)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) GetPendingSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	wishlists, err := r.getShared(ctx, userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetPendingSharedWithUserID: %w", err)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) getShared(ctx context.Context, userID uuid.UUID, pending bool) ([]entity.Wishlist, error) {
+	var rows []struct {
+		WishlistModel
+		CollaboratorRole string
+	}
+	if err := r.db.WithContext(ctx).Table("wishlists w").
+		Select("w.*, c.role AS collaborator_role").
+		Joins("JOIN wishlist_collaborators c ON c.wishlist_id = w.id").
+		Where("c.user_id = ? AND c.pending = ? AND w.user_id <> ? AND w.deleted_at IS NULL", userID, pending, userID).
+		Order("c.created_at DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	wishlists := make([]entity.Wishlist, len(rows))
+	for i, row := range rows {
+		wishlists[i] = toWishlistEntity(row.WishlistModel)
+		wishlists[i].Role = row.CollaboratorRole
+	}
+	return wishlists, nil
+}
+
 func (r *wishlistRepo) Update(ctx context.Context, wishlist entity.Wishlist) error {
 	m := toWishlistModel(wishlist)
-	if err := r.db.WithContext(ctx).Save(&m).Error; err != nil {
-		return fmt.Errorf("wishlistRepo.Update: %w", err)
+	m.Version = wishlist.Version + 1
+	// Проверка версии и запись — один UPDATE, так что из двух параллельных правок одной версии
+	// пройдёт только первая. Slug, архивные поля и счётчик подарков меняют отдельные методы:
+	// иначе запись устаревшей копии откатила бы их
+	result := r.db.WithContext(ctx).Model(&WishlistModel{ID: wishlist.ID}).
+		Where("version = ? AND deleted_at IS NULL", wishlist.Version).
+		Select("*").
+		Omit("id", "created_at", "deleted_at", "slug", "slug_scope", "archived_at", "next_edition_id", "presents_count").
+		Updates(&m)
+	if result.Error != nil {
+		return fmt.Errorf("wishlistRepo.Update: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wishlistRepo.Update: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *wishlistRepo) GetEventsEndedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error) {
+	var models []WishlistModel
+	if err := r.db.WithContext(ctx).
+		Where("event_ends_at <= ? AND archived_at IS NULL AND deleted_at IS NULL", before).
+		Order("event_ends_at").Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetEventsEndedBefore: %w", err)
+	}
+	wishlists := make([]entity.Wishlist, len(models))
+	for i, m := range models {
+		wishlists[i] = toWishlistEntity(m)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) Archive(ctx context.Context, id uuid.UUID, now time.Time, next *entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m WishlistModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&m, "id = ? AND archived_at IS NULL AND deleted_at IS NULL", id).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"archived_at": now}
+		if next != nil {
+			if err := createWithPresents(tx, *next, presents, metas); err != nil {
+				return err
+			}
+			if err := tx.Exec(`
+				INSERT INTO wishlist_collaborators (wishlist_id, user_id, role, pending, created_at)
+				SELECT ?, user_id, role, pending, created_at FROM wishlist_collaborators WHERE wishlist_id = ?`,
+				next.ID, id).Error; err != nil {
+				return err
+			}
+			updates["next_edition_id"] = next.ID
+		}
+		if err := tx.Model(&WishlistModel{}).Where("id = ?", id).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if next == nil || m.Slug == "" {
+			return nil
+		}
+		// Ссылка по slug ведёт на актуальный выпуск, архивный остаётся доступен по короткому ID
+		scope := uuid.Nil
+		if m.SlugScope == entity.SlugScopeProfile {
+			scope = m.UserID
+		}
+		if err := assignSlug(tx, id, scope, "", now); err != nil {
+			return err
+		}
+		return assignSlug(tx, next.ID, scope, m.Slug, now)
+	})
+	if err != nil {
+		return fmt.Errorf("wishlistRepo.Archive: %w", err)
+	}
+	return nil
+}
+
+func (r *wishlistRepo) Unarchive(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&WishlistModel{}).
+		Where("id = ? AND archived_at IS NOT NULL AND next_edition_id IS NULL", id).
+		UpdateColumn("archived_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("wishlistRepo.Unarchive: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wishlistRepo.Unarchive: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *wishlistRepo) Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&WishlistModel{}).
+		Where("id = ? AND version = ? AND deleted_at IS NULL", id, version).
+		UpdateColumn("deleted_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("wishlistRepo.Delete: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wishlistRepo.Delete: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *wishlistRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&WishlistModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("wishlistRepo.Restore: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wishlistRepo.Restore: %w", gorm.ErrRecordNotFound)
 	}
 	return nil
 }
 
-func (r *wishlistRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := r.db.WithContext(ctx).Delete(&WishlistModel{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("wishlistRepo.Delete: %w", err)
+func (r *wishlistRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
+	var m WishlistModel
+	if err := r.db.WithContext(ctx).First(&m, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+		return entity.Wishlist{}, fmt.Errorf("wishlistRepo.GetDeletedByID: %w", err)
+	}
+	return toWishlistEntity(m), nil
+}
+
+func (r *wishlistRepo) GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	var models []WishlistModel
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetDeletedByUserID: %w", err)
+	}
+	wishlists := make([]entity.Wishlist, len(models))
+	for i, m := range models {
+		wishlists[i] = toWishlistEntity(m)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error) {
+	var models []WishlistModel
+	if err := r.db.WithContext(ctx).
+		Where("deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("wishlistRepo.GetDeletedBefore: %w", err)
+	}
+	wishlists := make([]entity.Wishlist, len(models))
+	for i, m := range models {
+		wishlists[i] = toWishlistEntity(m)
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) Purge(ctx context.Context, id uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		presents := tx.Model(&PresentModel{}).Select("id").Where("wishlist_id = ?", id)
+		if err := tx.Where("present_id IN (?)", presents).Delete(&PresentMetaModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&PresentModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&CollaboratorModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&WishlistInviteModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&WishlistSlugModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wishlist_id = ?", id).Delete(&WishlistRevisionModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&WishlistModel{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("wishlistRepo.Purge: %w", err)
 	}
 	return nil
 }
@@ -96,6 +450,6 @@ func (r *wishlistRepo) DecrementPresentsCount(ctx context.Context, id uuid.UUID)
 
 func (r *wishlistRepo) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&WishlistModel{}).Where("user_id = ?", userID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&WishlistModel{}).Where("user_id = ? AND deleted_at IS NULL AND archived_at IS NULL", userID).Count(&count).Error
 	return count, err
 }
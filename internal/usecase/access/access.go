@@ -0,0 +1,165 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+)
+
+type accessPolicy struct {
+	wishlistRepo     repo.WishlistRepo
+	presentRepo      repo.PresentRepo
+	collaboratorRepo repo.CollaboratorRepo
+	unlockKey        []byte
+}
+
+// New создаёт политику доступа. Из secret выводится ключ подписи токенов разблокировки
+func New(wishlistRepo repo.WishlistRepo, presentRepo repo.PresentRepo, collaboratorRepo repo.CollaboratorRepo, secret string) usecase.AccessPolicy {
+	return &accessPolicy{
+		wishlistRepo:     wishlistRepo,
+		presentRepo:      presentRepo,
+		collaboratorRepo: collaboratorRepo,
+		unlockKey:        deriveUnlockKey(secret),
+	}
+}
+
+func (p *accessPolicy) AuthorizeWishlist(ctx context.Context, userID, wishlistID uuid.UUID, action usecase.Action) (entity.Wishlist, error) {
+	w, err := p.wishlistRepo.GetByID(ctx, wishlistID)
+	if err != nil {
+		return entity.Wishlist{}, lookupError("wishlist", err)
+	}
+	role, err := p.authorize(ctx, w, userID, action)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	w.Role = role
+	return w, nil
+}
+
+func (p *accessPolicy) AuthorizePresent(ctx context.Context, userID, presentID uuid.UUID, action usecase.Action) (entity.Present, error) {
+	present, err := p.presentRepo.GetByID(ctx, presentID)
+	if err != nil {
+		return entity.Present{}, lookupError("present", err)
+	}
+	if _, err := p.AuthorizeWishlist(ctx, userID, present.WishlistID, action); err != nil {
+		return entity.Present{}, err
+	}
+	return present, nil
+}
+
+func (p *accessPolicy) CheckWishlist(ctx context.Context, w entity.Wishlist, userID uuid.UUID, action usecase.Action) error {
+	_, err := p.authorize(ctx, w, userID, action)
+	return err
+}
+
+// authorize возвращает роль пользователя, если действие ему разрешено. Редактор меняет содержимое
+// вишлиста, пока модератор не снял его с публикации; удалять вишлист и управлять доступом
+// может только владелец
+func (p *accessPolicy) authorize(ctx context.Context, w entity.Wishlist, userID uuid.UUID, action usecase.Action) (string, error) {
+	if action == usecase.ActionView {
+		return p.CheckView(ctx, w, usecase.Viewer{UserID: userID})
+	}
+	role, err := p.role(ctx, w, userID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case role == entity.WishlistRoleOwner:
+		return role, nil
+	case action == usecase.ActionEdit && role == entity.WishlistRoleEditor && !w.Unpublished:
+		return role, nil
+	}
+	return "", usecase.ErrForbidden
+}
+
+func (p *accessPolicy) ViewWishlist(ctx context.Context, viewer usecase.Viewer, wishlistID uuid.UUID) (entity.Wishlist, error) {
+	w, err := p.wishlistRepo.GetByID(ctx, wishlistID)
+	if err != nil {
+		return entity.Wishlist{}, lookupError("wishlist", err)
+	}
+	role, err := p.CheckView(ctx, w, viewer)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	w.Role = role
+	return w, nil
+}
+
+func (p *accessPolicy) ViewPresent(ctx context.Context, viewer usecase.Viewer, presentID uuid.UUID) (entity.Present, error) {
+	present, err := p.presentRepo.GetByID(ctx, presentID)
+	if err != nil {
+		return entity.Present{}, lookupError("present", err)
+	}
+	if _, err := p.ViewWishlist(ctx, viewer, present.WishlistID); err != nil {
+		return entity.Present{}, err
+	}
+	return present, nil
+}
+
+// CheckView — владелец видит свой вишлист всегда, соавторы — при любой видимости, пока он
+// опубликован. Остальным снятый с публикации и приватный вишлисты не видны вовсе,
+// а защищённый паролем открывается по токену разблокировки
+func (p *accessPolicy) CheckView(ctx context.Context, w entity.Wishlist, viewer usecase.Viewer) (string, error) {
+	role, err := p.role(ctx, w, viewer.UserID)
+	if err != nil {
+		return "", err
+	}
+	if role == entity.WishlistRoleOwner {
+		return role, nil
+	}
+	if w.Unpublished {
+		return "", fmt.Errorf("wishlist %w", usecase.ErrNotFound)
+	}
+	if role != "" {
+		return role, nil
+	}
+	switch w.Visibility {
+	case entity.VisibilityPrivate:
+		return "", fmt.Errorf("wishlist %w", usecase.ErrNotFound)
+	case entity.VisibilityPassword:
+		for _, token := range viewer.UnlockTokens {
+			if p.validUnlockToken(token, w, time.Now()) {
+				return "", nil
+			}
+		}
+		return "", &usecase.WishlistLockedError{WishlistID: w.ID}
+	}
+	return "", nil
+}
+
+// role возвращает роль пользователя в вишлисте; пустая — посторонний, аноним или ещё не принявший
+// приглашение. Сбой хранилища возвращается ошибкой: иначе соавтор получал бы 404 вместо 500
+func (p *accessPolicy) role(ctx context.Context, w entity.Wishlist, userID uuid.UUID) (string, error) {
+	if userID == uuid.Nil {
+		return "", nil
+	}
+	if w.UserID == userID {
+		return entity.WishlistRoleOwner, nil
+	}
+	c, err := p.collaboratorRepo.Get(ctx, w.ID, userID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get collaborator: %w", err)
+	}
+	if c.Pending {
+		return "", nil
+	}
+	return c.Role, nil
+}
+
+// lookupError отличает отсутствующую запись (404) от сбоя хранилища, который отдаётся как есть
+func lookupError(what string, err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return fmt.Errorf("%s %w: %v", what, usecase.ErrNotFound, err)
+	}
+	return fmt.Errorf("get %s: %w", what, err)
+}
@@ -0,0 +1,247 @@
+package access_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	mockrepo "main/mock/repo"
+)
+
+const testSecret = "test-secret"
+
+// noCollaborators — у вишлистов нет соавторов
+func noCollaborators() *mockrepo.MockCollaboratorRepo {
+	cr := &mockrepo.MockCollaboratorRepo{}
+	cr.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(entity.Collaborator{}, repo.ErrNotFound)
+	return cr
+}
+
+// viewErr отбрасывает роль, которую возвращает CheckView
+func viewErr(p usecase.AccessPolicy, w entity.Wishlist, viewer usecase.Viewer) error {
+	_, err := p.CheckView(context.Background(), w, viewer)
+	return err
+}
+
+func TestAuthorizeWishlist(t *testing.T) {
+	ownerID := uuid.New()
+	wid := uuid.New()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		repoErr error
+		action  usecase.Action
+		wantErr error
+	}{
+		{name: "owner can edit", userID: ownerID, action: usecase.ActionEdit},
+		{name: "owner can delete", userID: ownerID, action: usecase.ActionDelete},
+		{name: "stranger cannot edit", userID: uuid.New(), action: usecase.ActionEdit, wantErr: usecase.ErrForbidden},
+		{name: "stranger cannot delete", userID: uuid.New(), action: usecase.ActionDelete, wantErr: usecase.ErrForbidden},
+		{name: "missing wishlist", userID: ownerID, repoErr: repo.ErrNotFound, action: usecase.ActionEdit, wantErr: usecase.ErrNotFound},
+		{name: "stranger can view", userID: uuid.New(), action: usecase.ActionView},
+		{name: "anonymous can view", userID: uuid.Nil, action: usecase.ActionView},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, tt.repoErr)
+			p := access.New(wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+
+			w, err := p.AuthorizeWishlist(context.Background(), tt.userID, wid, tt.action)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, wid, w.ID)
+		})
+	}
+}
+
+func TestLookup_StorageFailure(t *testing.T) {
+	wid := uuid.New()
+	dbErr := errors.New("connection refused")
+	wr := &mockrepo.MockWishlistRepo{}
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{}, dbErr)
+	p := access.New(wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+
+	// Сбой базы — не повод отвечать 404
+	_, err := p.AuthorizeWishlist(context.Background(), uuid.New(), wid, usecase.ActionEdit)
+	require.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, usecase.ErrNotFound)
+	_, err = p.ViewWishlist(context.Background(), usecase.Viewer{}, wid)
+	require.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestCollaboratorLookup_StorageFailure(t *testing.T) {
+	w := entity.Wishlist{ID: uuid.New(), UserID: uuid.New(), Visibility: entity.VisibilityPrivate}
+	dbErr := errors.New("connection refused")
+	wr := &mockrepo.MockWishlistRepo{}
+	wr.On("GetByID", mock.Anything, w.ID).Return(w, nil)
+	cr := &mockrepo.MockCollaboratorRepo{}
+	cr.On("Get", mock.Anything, w.ID, mock.Anything).Return(entity.Collaborator{}, dbErr)
+	p := access.New(wr, &mockrepo.MockPresentRepo{}, cr, testSecret)
+
+	// Соавтор не должен получать 403 или 404 из-за сбоя базы
+	_, err := p.AuthorizeWishlist(context.Background(), uuid.New(), w.ID, usecase.ActionEdit)
+	require.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, usecase.ErrForbidden)
+	_, err = p.ViewWishlist(context.Background(), usecase.Viewer{UserID: uuid.New()}, w.ID)
+	require.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, usecase.ErrNotFound)
+
+	// Владельцу таблица соавторов не нужна
+	_, err = p.AuthorizeWishlist(context.Background(), w.UserID, w.ID, usecase.ActionDelete)
+	require.NoError(t, err)
+	cr.AssertNotCalled(t, "Get", mock.Anything, w.ID, w.UserID)
+}
+
+func TestAuthorizeWishlist_Collaborators(t *testing.T) {
+	ownerID, editorID, viewerID := uuid.New(), uuid.New(), uuid.New()
+	wid := uuid.New()
+
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		unpublished bool
+		action      usecase.Action
+		wantRole    string
+		wantErr     error
+	}{
+		{name: "owner manages", userID: ownerID, action: usecase.ActionManage, wantRole: entity.WishlistRoleOwner},
+		{name: "editor edits", userID: editorID, action: usecase.ActionEdit, wantRole: entity.WishlistRoleEditor},
+		{name: "editor cannot delete", userID: editorID, action: usecase.ActionDelete, wantErr: usecase.ErrForbidden},
+		{name: "editor cannot manage", userID: editorID, action: usecase.ActionManage, wantErr: usecase.ErrForbidden},
+		{name: "editor cannot edit unpublished", userID: editorID, unpublished: true, action: usecase.ActionEdit, wantErr: usecase.ErrForbidden},
+		{name: "viewer cannot edit", userID: viewerID, action: usecase.ActionEdit, wantErr: usecase.ErrForbidden},
+		{name: "viewer sees private", userID: viewerID, action: usecase.ActionView, wantRole: entity.WishlistRoleViewer},
+		{name: "viewer cannot see unpublished", userID: viewerID, unpublished: true, action: usecase.ActionView, wantErr: usecase.ErrNotFound},
+		{name: "stranger cannot see private", userID: uuid.New(), action: usecase.ActionView, wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{
+				ID: wid, UserID: ownerID, Visibility: entity.VisibilityPrivate, Unpublished: tt.unpublished,
+			}, nil)
+			cr := &mockrepo.MockCollaboratorRepo{}
+			cr.On("Get", mock.Anything, wid, editorID).Return(entity.Collaborator{WishlistID: wid, UserID: editorID, Role: entity.WishlistRoleEditor}, nil)
+			cr.On("Get", mock.Anything, wid, viewerID).Return(entity.Collaborator{WishlistID: wid, UserID: viewerID, Role: entity.WishlistRoleViewer}, nil)
+			cr.On("Get", mock.Anything, wid, mock.Anything).Return(entity.Collaborator{}, repo.ErrNotFound)
+			p := access.New(wr, &mockrepo.MockPresentRepo{}, cr, testSecret)
+
+			w, err := p.AuthorizeWishlist(context.Background(), tt.userID, wid, tt.action)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRole, w.Role)
+		})
+	}
+}
+
+func TestCheckWishlist_Unpublished(t *testing.T) {
+	ownerID := uuid.New()
+	w := entity.Wishlist{ID: uuid.New(), UserID: ownerID, Unpublished: true}
+	p := access.New(&mockrepo.MockWishlistRepo{}, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+
+	assert.NoError(t, p.CheckWishlist(context.Background(), w, ownerID, usecase.ActionView))
+	assert.ErrorIs(t, p.CheckWishlist(context.Background(), w, uuid.New(), usecase.ActionView), usecase.ErrNotFound)
+	assert.ErrorIs(t, p.CheckWishlist(context.Background(), w, uuid.Nil, usecase.ActionView), usecase.ErrNotFound)
+	assert.ErrorIs(t, p.CheckWishlist(context.Background(), w, uuid.New(), usecase.ActionEdit), usecase.ErrForbidden)
+}
+
+func TestAuthorizePresent(t *testing.T) {
+	ownerID := uuid.New()
+	wid := uuid.New()
+	pid := uuid.New()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		repoErr error
+		wantErr error
+	}{
+		{name: "owner", userID: ownerID},
+		{name: "stranger", userID: uuid.New(), wantErr: usecase.ErrForbidden},
+		{name: "missing present", userID: ownerID, repoErr: repo.ErrNotFound, wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			pr := &mockrepo.MockPresentRepo{}
+			pr.On("GetByID", mock.Anything, pid).Return(entity.Present{ID: pid, WishlistID: wid}, tt.repoErr)
+			wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+			p := access.New(wr, pr, noCollaborators(), testSecret)
+
+			got, err := p.AuthorizePresent(context.Background(), tt.userID, pid, usecase.ActionEdit)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, pid, got.ID)
+		})
+	}
+}
+
+func TestCheckView_Visibility(t *testing.T) {
+	ownerID := uuid.New()
+	p := access.New(&mockrepo.MockWishlistRepo{}, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+	private := entity.Wishlist{ID: uuid.New(), UserID: ownerID, Visibility: entity.VisibilityPrivate}
+	unlisted := entity.Wishlist{ID: uuid.New(), UserID: ownerID, Visibility: entity.VisibilityUnlisted}
+
+	assert.NoError(t, viewErr(p, private, usecase.Viewer{UserID: ownerID}))
+	assert.ErrorIs(t, viewErr(p, private, usecase.Viewer{UserID: uuid.New()}), usecase.ErrNotFound)
+	assert.ErrorIs(t, p.CheckWishlist(context.Background(), private, uuid.Nil, usecase.ActionView), usecase.ErrNotFound)
+	assert.NoError(t, viewErr(p, unlisted, usecase.Viewer{}))
+}
+
+func TestCheckView_PasswordProtected(t *testing.T) {
+	ownerID := uuid.New()
+	p := access.New(&mockrepo.MockWishlistRepo{}, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+	w := entity.Wishlist{ID: uuid.New(), UserID: ownerID, Visibility: entity.VisibilityPassword, PasswordHash: "hash-1"}
+	token, expiresAt := p.IssueUnlockToken(w, time.Now())
+	assert.True(t, expiresAt.After(time.Now()))
+
+	var locked *usecase.WishlistLockedError
+	require.ErrorAs(t, viewErr(p, w, usecase.Viewer{}), &locked)
+	assert.Equal(t, w.ID, locked.WishlistID)
+	assert.NoError(t, viewErr(p, w, usecase.Viewer{UserID: ownerID}))
+	assert.NoError(t, viewErr(p, w, usecase.Viewer{UnlockTokens: []string{"garbage", token}}))
+
+	other := w
+	other.ID = uuid.New()
+	assert.ErrorAs(t, viewErr(p, other, usecase.Viewer{UnlockTokens: []string{token}}), &locked, "token of another wishlist")
+
+	changed := w
+	changed.PasswordHash = "hash-2"
+	assert.ErrorAs(t, viewErr(p, changed, usecase.Viewer{UnlockTokens: []string{token}}), &locked, "password was changed")
+
+	expired, _ := p.IssueUnlockToken(w, time.Now().Add(-365*24*time.Hour))
+	assert.ErrorAs(t, viewErr(p, w, usecase.Viewer{UnlockTokens: []string{expired}}), &locked, "expired token")
+
+	foreign := access.New(&mockrepo.MockWishlistRepo{}, &mockrepo.MockPresentRepo{}, noCollaborators(), "another-secret")
+	forged, _ := foreign.IssueUnlockToken(w, time.Now())
+	assert.ErrorAs(t, viewErr(p, w, usecase.Viewer{UnlockTokens: []string{forged}}), &locked, "signed with another key")
+
+	unpublished := w
+	unpublished.Unpublished = true
+	assert.ErrorIs(t, viewErr(p, unpublished, usecase.Viewer{UnlockTokens: []string{token}}), usecase.ErrNotFound)
+}
@@ -0,0 +1,69 @@
+package access
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+)
+
+// unlockTTL — сколько действует разблокировка защищённого паролем вишлиста
+const unlockTTL = 30 * 24 * time.Hour
+
+// deriveUnlockKey отделяет ключ токенов разблокировки от остальных применений общего секрета
+func deriveUnlockKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("wishlist-unlock"))
+	return mac.Sum(nil)
+}
+
+// IssueUnlockToken — токен вида <wishlistID>.<expires unix>.<отпечаток пароля>.<подпись>.
+// Отпечаток хэша пароля отзывает все токены при смене пароля без хранения их на сервере
+func (p *accessPolicy) IssueUnlockToken(w entity.Wishlist, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(unlockTTL)
+	payload := w.ID.String() + "." + strconv.FormatInt(expiresAt.Unix(), 10) + "." + passwordFingerprint(w.PasswordHash)
+	return payload + "." + p.sign(payload), expiresAt
+}
+
+func (p *accessPolicy) validUnlockToken(token string, w entity.Wishlist, now time.Time) bool {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(p.sign(payload))) {
+		return false
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil || id != w.ID {
+		return false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return parts[2] == passwordFingerprint(w.PasswordHash)
+}
+
+func (p *accessPolicy) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.unlockKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
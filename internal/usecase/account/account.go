@@ -0,0 +1,214 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	minioPkg "main/pkg/minio"
+)
+
+// purgeBatchSize — сколько аккаунтов удаляется за один проход
+const purgeBatchSize = 100
+
+type accountUseCase struct {
+	userRepo        repo.UserRepo
+	identityRepo    repo.IdentityRepo
+	wishlistRepo    repo.WishlistRepo
+	presentRepo     repo.PresentRepo
+	presentMetaRepo repo.PresentMetaRepo
+	templateRepo    repo.TemplateRepo
+	fileRefRepo     repo.FileRefRepo
+	exportRepo      repo.DataExportRepo
+	fileStorage     minioPkg.FileStorage
+	exportTTL       time.Duration
+	trashRetention  time.Duration
+}
+
+// Deps — зависимости accountUseCase
+type Deps struct {
+	UserRepo        repo.UserRepo
+	IdentityRepo    repo.IdentityRepo
+	WishlistRepo    repo.WishlistRepo
+	PresentRepo     repo.PresentRepo
+	PresentMetaRepo repo.PresentMetaRepo
+	TemplateRepo    repo.TemplateRepo
+	FileRefRepo     repo.FileRefRepo
+	ExportRepo      repo.DataExportRepo
+	FileStorage     minioPkg.FileStorage
+	ExportTTL       time.Duration // сколько готовый архив доступен для скачивания; 0 — по умолчанию
+	TrashRetention  time.Duration // сколько удалённое лежит в корзине; 0 — по умолчанию
+}
+
+func New(d Deps) usecase.AccountUseCase {
+	if d.ExportTTL <= 0 {
+		d.ExportTTL = defaultExportTTL
+	}
+	if d.TrashRetention <= 0 {
+		d.TrashRetention = defaultTrashRetention
+	}
+	return &accountUseCase{
+		userRepo:        d.UserRepo,
+		identityRepo:    d.IdentityRepo,
+		wishlistRepo:    d.WishlistRepo,
+		presentRepo:     d.PresentRepo,
+		presentMetaRepo: d.PresentMetaRepo,
+		templateRepo:    d.TemplateRepo,
+		fileRefRepo:     d.FileRefRepo,
+		exportRepo:      d.ExportRepo,
+		fileStorage:     d.FileStorage,
+		exportTTL:       d.ExportTTL,
+		trashRetention:  d.TrashRetention,
+	}
+}
+
+// PurgeDue удаляет аккаунты по одному: сбой на одном не мешает остальным и повторится в следующий проход
+func (uc *accountUseCase) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	users, err := uc.userRepo.GetDueForDeletion(ctx, now, purgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get accounts due for deletion: %w", err)
+	}
+	purged := 0
+	for _, u := range users {
+		if err := uc.purge(ctx, u); err != nil {
+			log.Printf("account %s: purge failed: %v", u.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (uc *accountUseCase) purge(ctx context.Context, user entity.User) error {
+	// Ссылки на файлы собираем до удаления — потом их уже не найти
+	urls, err := uc.fileURLs(ctx, user)
+	if err != nil {
+		return err
+	}
+	exports, err := uc.exportRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("get data exports: %w", err)
+	}
+	if err := uc.userRepo.Delete(ctx, user.ID); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	log.Printf("account %s: deleted", user.ID)
+	// Архивы выгрузок принадлежат только этому пользователю — ссылки на них не проверяем
+	for _, e := range exports {
+		if e.ObjectID == "" {
+			continue
+		}
+		if err := uc.fileStorage.Delete(e.ObjectID); err != nil {
+			log.Printf("account %s: delete export %s: %v", user.ID, e.ID, err)
+		}
+	}
+	uc.deleteUnreferenced(ctx, "account "+user.ID.String(), urls)
+	return nil
+}
+
+// deleteUnreferenced удаляет из хранилища файлы, на которые после удаления владельца никто не ссылается.
+// Тот же файл мог попасть к другим пользователям, например через публичный шаблон.
+// Ошибки только логируются с префиксом owner: контент уже удалён
+func (uc *accountUseCase) deleteUnreferenced(ctx context.Context, owner string, urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	orphaned, err := uc.fileRefRepo.Unreferenced(ctx, urls)
+	if err != nil {
+		log.Printf("%s: check file references: %v", owner, err)
+		return
+	}
+	for _, url := range orphaned {
+		objectID, _ := uc.fileStorage.ObjectID(url)
+		if err := uc.fileStorage.Delete(objectID); err != nil {
+			log.Printf("%s: delete file %s: %v", owner, objectID, err)
+		}
+	}
+}
+
+// fileURLs возвращает URL загруженных в наше хранилище файлов из аватара, вишлистов, подарков и шаблонов
+func (uc *accountUseCase) fileURLs(ctx context.Context, user entity.User) ([]string, error) {
+	c := urlCollector{storage: uc.fileStorage, seen: map[string]bool{}}
+	c.add(user.Avatar)
+
+	wishlists, err := uc.wishlistRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get wishlists: %w", err)
+	}
+	deleted, err := uc.wishlistRepo.GetDeletedByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get deleted wishlists: %w", err)
+	}
+	for _, w := range append(wishlists, deleted...) {
+		if err := uc.addWishlistFiles(ctx, &c, w); err != nil {
+			return nil, err
+		}
+	}
+
+	templates, err := uc.templateRepo.GetAllByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get templates: %w", err)
+	}
+	for _, t := range templates {
+		c.addBlocks(t.Blocks)
+	}
+	return c.urls, nil
+}
+
+// addWishlistFiles добавляет обложку и блоки вишлиста и обложки всех его подарков, включая те, что в корзине
+func (uc *accountUseCase) addWishlistFiles(ctx context.Context, c *urlCollector, w entity.Wishlist) error {
+	c.add(w.Cover)
+	c.addBlocks(w.Blocks)
+	presents, err := uc.presentRepo.GetAllByWishlistID(ctx, w.ID)
+	if err != nil {
+		return fmt.Errorf("get presents: %w", err)
+	}
+	deleted, err := uc.presentRepo.GetDeletedByWishlistID(ctx, w.ID)
+	if err != nil {
+		return fmt.Errorf("get deleted presents: %w", err)
+	}
+	for _, p := range append(presents, deleted...) {
+		c.add(p.Cover)
+	}
+	return nil
+}
+
+type urlCollector struct {
+	storage minioPkg.FileStorage
+	seen    map[string]bool
+	urls    []string
+}
+
+// add пропускает внешние ссылки (например, обложки из маркетплейсов) — удалять их не нам
+func (c *urlCollector) add(url string) {
+	if url == "" || c.seen[url] {
+		return
+	}
+	if _, ok := c.storage.ObjectID(url); !ok {
+		return
+	}
+	c.seen[url] = true
+	c.urls = append(c.urls, url)
+}
+
+// addBlocks достаёт картинки из блоков image, text_image, video и gallery
+func (c *urlCollector) addBlocks(blocks []entity.Block) {
+	for _, b := range blocks {
+		var d struct {
+			URL    string   `json:"url"`
+			Images []string `json:"images"`
+		}
+		if err := json.Unmarshal(b.Data, &d); err != nil {
+			continue
+		}
+		c.add(d.URL)
+		for _, img := range d.Images {
+			c.add(img)
+		}
+	}
+}
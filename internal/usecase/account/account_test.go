@@ -0,0 +1,173 @@
+package account_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	accountUC "main/internal/usecase/account"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+)
+
+const (
+	storagePrefix  = "https://files.example.com/bucket/"
+	exportTTL      = 24 * time.Hour
+	trashRetention = 14 * 24 * time.Hour
+)
+
+type fixture struct {
+	uc         usecase.AccountUseCase
+	users      *mockrepo.MockUserRepo
+	identities *mockrepo.MockIdentityRepo
+	wishlists  *mockrepo.MockWishlistRepo
+	presents   *mockrepo.MockPresentRepo
+	metas      *mockrepo.MockPresentMetaRepo
+	templates  *mockrepo.MockTemplateRepo
+	fileRefs   *mockrepo.MockFileRefRepo
+	exports    *mockrepo.MockDataExportRepo
+	storage    *mockminio.MockFileStorage
+}
+
+func newFixture() fixture {
+	f := fixture{
+		users:      &mockrepo.MockUserRepo{},
+		identities: &mockrepo.MockIdentityRepo{},
+		wishlists:  &mockrepo.MockWishlistRepo{},
+		presents:   &mockrepo.MockPresentRepo{},
+		metas:      &mockrepo.MockPresentMetaRepo{},
+		templates:  &mockrepo.MockTemplateRepo{},
+		fileRefs:   &mockrepo.MockFileRefRepo{},
+		exports:    &mockrepo.MockDataExportRepo{},
+		storage:    &mockminio.MockFileStorage{},
+	}
+	f.storage.On("ObjectID", mock.MatchedBy(func(url string) bool {
+		return !strings.HasPrefix(url, storagePrefix)
+	})).Return("", false)
+	f.uc = accountUC.New(accountUC.Deps{
+		UserRepo:        f.users,
+		IdentityRepo:    f.identities,
+		WishlistRepo:    f.wishlists,
+		PresentRepo:     f.presents,
+		PresentMetaRepo: f.metas,
+		TemplateRepo:    f.templates,
+		FileRefRepo:     f.fileRefs,
+		ExportRepo:      f.exports,
+		FileStorage:     f.storage,
+		ExportTTL:       exportTTL,
+		TrashRetention:  trashRetention,
+	})
+	return f
+}
+
+// stored регистрирует URL объекта из хранилища
+func (f fixture) stored(objectID string) string {
+	url := storagePrefix + objectID
+	f.storage.On("ObjectID", url).Return(objectID, true)
+	return url
+}
+
+func galleryBlock(urls ...string) entity.Block {
+	data, _ := json.Marshal(map[string]interface{}{"images": urls})
+	return entity.Block{Type: "gallery", Data: data}
+}
+
+func TestPurgeDue_DeletesOwnedFiles(t *testing.T) {
+	f := newFixture()
+	avatar := f.stored("avatar")
+	cover := f.stored("cover")
+	presentCover := f.stored("present")
+	shared := f.stored("shared")
+	trashedCover := f.stored("trashed")
+	trashedPresent := f.stored("trashed-present")
+
+	user := entity.User{ID: uuid.New(), Avatar: avatar}
+	wishlist := entity.Wishlist{ID: uuid.New(), UserID: user.ID, Cover: cover, Blocks: []entity.Block{galleryBlock(shared)}}
+	trashed := entity.Wishlist{ID: uuid.New(), UserID: user.ID, Cover: trashedCover}
+	now := time.Now()
+
+	f.users.On("GetDueForDeletion", mock.Anything, now, mock.Anything).Return([]entity.User{user}, nil)
+	f.wishlists.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Wishlist{wishlist}, nil)
+	f.wishlists.On("GetDeletedByUserID", mock.Anything, user.ID).Return([]entity.Wishlist{trashed}, nil)
+	f.presents.On("GetAllByWishlistID", mock.Anything, wishlist.ID).Return([]entity.Present{
+		{ID: uuid.New(), Cover: presentCover},
+		{ID: uuid.New(), Cover: "https://marketplace.example.com/item.jpg"},
+	}, nil)
+	f.presents.On("GetDeletedByWishlistID", mock.Anything, wishlist.ID).Return([]entity.Present{
+		{ID: uuid.New(), Cover: trashedPresent},
+	}, nil)
+	f.presents.On("GetAllByWishlistID", mock.Anything, trashed.ID).Return([]entity.Present{}, nil)
+	f.presents.On("GetDeletedByWishlistID", mock.Anything, trashed.ID).Return([]entity.Present{}, nil)
+	f.templates.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Template{
+		{ID: uuid.New(), Blocks: []entity.Block{galleryBlock(shared, cover)}},
+	}, nil)
+	f.exports.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.DataExport{
+		{ID: uuid.New(), Status: entity.DataExportReady, ObjectID: "archive"},
+		{ID: uuid.New(), Status: entity.DataExportPending},
+	}, nil)
+	f.users.On("Delete", mock.Anything, user.ID).Return(nil)
+	// shared остался в чужом шаблоне
+	f.fileRefs.On("Unreferenced", mock.Anything, mock.Anything).Return([]string{avatar, cover, presentCover, trashedCover, trashedPresent}, nil)
+	f.storage.On("Delete", mock.Anything).Return(nil)
+
+	purged, err := f.uc.PurgeDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	f.users.AssertCalled(t, "Delete", mock.Anything, user.ID)
+	// Внешняя обложка подарка в кандидаты не попадает, повторы схлопываются, корзина учитывается
+	assert.ElementsMatch(t, []string{avatar, cover, shared, presentCover, trashedCover, trashedPresent}, f.fileRefs.Calls[0].Arguments.Get(1))
+	for _, id := range []string{"avatar", "cover", "present", "trashed", "trashed-present", "archive"} {
+		f.storage.AssertCalled(t, "Delete", id)
+	}
+	f.storage.AssertNotCalled(t, "Delete", "shared")
+	f.storage.AssertNumberOfCalls(t, "Delete", 6)
+}
+
+func TestPurgeDue_FailedDeleteKeepsFilesAndContinues(t *testing.T) {
+	f := newFixture()
+	failing := entity.User{ID: uuid.New(), Avatar: f.stored("failing")}
+	ok := entity.User{ID: uuid.New()}
+
+	f.users.On("GetDueForDeletion", mock.Anything, mock.Anything, mock.Anything).Return([]entity.User{failing, ok}, nil)
+	f.wishlists.On("GetAllByUserID", mock.Anything, mock.Anything).Return([]entity.Wishlist{}, nil)
+	f.wishlists.On("GetDeletedByUserID", mock.Anything, mock.Anything).Return([]entity.Wishlist{}, nil)
+	f.templates.On("GetAllByUserID", mock.Anything, mock.Anything).Return([]entity.Template{}, nil)
+	f.exports.On("GetAllByUserID", mock.Anything, mock.Anything).Return([]entity.DataExport{}, nil)
+	f.users.On("Delete", mock.Anything, failing.ID).Return(errors.New("connection reset"))
+	f.users.On("Delete", mock.Anything, ok.ID).Return(nil)
+
+	purged, err := f.uc.PurgeDue(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	f.users.AssertCalled(t, "Delete", mock.Anything, ok.ID)
+	f.storage.AssertNotCalled(t, "Delete", mock.Anything)
+	f.fileRefs.AssertNotCalled(t, "Unreferenced", mock.Anything, mock.Anything)
+}
+
+func TestPurgeDue_SkipsExternalURLs(t *testing.T) {
+	f := newFixture()
+	user := entity.User{ID: uuid.New(), Avatar: "https://t.me/i/userpic/320/alice.jpg"}
+
+	f.users.On("GetDueForDeletion", mock.Anything, mock.Anything, mock.Anything).Return([]entity.User{user}, nil)
+	f.wishlists.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Wishlist{}, nil)
+	f.wishlists.On("GetDeletedByUserID", mock.Anything, user.ID).Return([]entity.Wishlist{}, nil)
+	f.templates.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Template{}, nil)
+	f.exports.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.DataExport{}, nil)
+	f.users.On("Delete", mock.Anything, user.ID).Return(nil)
+
+	purged, err := f.uc.PurgeDue(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	f.fileRefs.AssertNotCalled(t, "Unreferenced", mock.Anything, mock.Anything)
+	f.storage.AssertNotCalled(t, "Delete", mock.Anything)
+}
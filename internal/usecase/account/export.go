@@ -0,0 +1,370 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+const (
+	defaultExportTTL = 48 * time.Hour
+	// exportReuseWindow — в течение этого срока повторный запрос отдаёт уже собранный архив
+	exportReuseWindow = time.Hour
+	// exportStaleAfter — выгрузка, которая собирается дольше, считается брошенной упавшим инстансом
+	exportStaleAfter = 30 * time.Minute
+	exportBatchSize  = 10
+)
+
+func (uc *accountUseCase) RequestExport(ctx context.Context, userID uuid.UUID) (entity.DataExport, error) {
+	exports, err := uc.exportRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return entity.DataExport{}, fmt.Errorf("get data exports: %w", err)
+	}
+	now := time.Now()
+	for _, e := range exports {
+		if e.InProgress() || (e.Downloadable(now) && now.Sub(e.CreatedAt) < exportReuseWindow) {
+			return e, nil
+		}
+	}
+
+	export := entity.DataExport{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    entity.DataExportPending,
+		CreatedAt: now,
+	}
+	if err := uc.exportRepo.Create(ctx, export); err != nil {
+		return entity.DataExport{}, fmt.Errorf("create data export: %w", err)
+	}
+	return export, nil
+}
+
+func (uc *accountUseCase) GetExports(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error) {
+	exports, err := uc.exportRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get data exports: %w", err)
+	}
+	return exports, nil
+}
+
+func (uc *accountUseCase) DownloadExport(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	export, err := uc.exportRepo.GetByID(ctx, exportID)
+	if err != nil || export.UserID != userID || !export.Downloadable(time.Now()) {
+		return nil, usecase.ErrNotFound
+	}
+	data, err := uc.fileStorage.Get(export.ObjectID)
+	if err != nil {
+		return nil, fmt.Errorf("get export archive: %w", err)
+	}
+	return data, nil
+}
+
+func (uc *accountUseCase) ProcessExports(ctx context.Context, now time.Time) (int, error) {
+	processed := 0
+	for processed < exportBatchSize {
+		export, ok, err := uc.exportRepo.ClaimNext(ctx, now, now.Add(-exportStaleAfter))
+		if err != nil {
+			return processed, fmt.Errorf("claim data export: %w", err)
+		}
+		if !ok {
+			break
+		}
+		uc.processExport(ctx, export)
+		processed++
+	}
+	return processed, nil
+}
+
+// processExport собирает архив и сохраняет результат. Неудачная выгрузка тоже получает срок
+// хранения — по нему её подчистит PurgeExpiredExports, а пользователь может запросить новую
+func (uc *accountUseCase) processExport(ctx context.Context, export entity.DataExport) {
+	err := uc.storeArchive(ctx, &export)
+	if err != nil {
+		log.Printf("data export %s: %v", export.ID, err)
+		export.Status = entity.DataExportFailed
+	} else {
+		export.Status = entity.DataExportReady
+	}
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(uc.exportTTL)
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+	if err := uc.exportRepo.Update(ctx, export); err != nil {
+		log.Printf("data export %s: save result: %v", export.ID, err)
+	}
+}
+
+// storeArchive собирает архив и кладёт его в хранилище. Бакет публичный на чтение, но ID объекта —
+// случайный UUID, который наружу не отдаётся: скачать архив можно только через DownloadExport
+func (uc *accountUseCase) storeArchive(ctx context.Context, export *entity.DataExport) error {
+	data, err := uc.buildArchive(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+	url, err := uc.fileStorage.Upload(fmt.Sprintf("export-%s.zip", export.ID), data)
+	if err != nil {
+		return fmt.Errorf("upload archive: %w", err)
+	}
+	objectID, ok := uc.fileStorage.ObjectID(url)
+	if !ok {
+		return fmt.Errorf("unexpected archive URL %q", url)
+	}
+	export.ObjectID = objectID
+	export.Size = int64(len(data))
+	return nil
+}
+
+func (uc *accountUseCase) PurgeExpiredExports(ctx context.Context, now time.Time) (int, error) {
+	exports, err := uc.exportRepo.GetExpired(ctx, now, purgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get expired data exports: %w", err)
+	}
+	purged := 0
+	for _, e := range exports {
+		// Запись удаляем только вместе с архивом, иначе он останется в хранилище навсегда
+		if e.ObjectID != "" {
+			if err := uc.fileStorage.Delete(e.ObjectID); err != nil {
+				log.Printf("data export %s: delete archive: %v", e.ID, err)
+				continue
+			}
+		}
+		if err := uc.exportRepo.Delete(ctx, e.ID); err != nil {
+			log.Printf("data export %s: delete: %v", e.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Структура архива:
+//
+//	profile.json          — профиль и привязанные способы входа
+//	wishlists.json        — вишлисты с блоками, настройками и подарками с метаданными парсера
+//	templates.json        — собственные шаблоны
+//	liked_templates.json  — шаблоны, которые понравились пользователю
+//	files.json            — соответствие URL картинок путям в архиве
+//	files/                — сами картинки из хранилища
+
+type exportProfile struct {
+	ID                  uuid.UUID        `json:"id"`
+	Username            string           `json:"username"`
+	Handle              string           `json:"handle,omitempty"`
+	DisplayName         string           `json:"displayName"`
+	Avatar              string           `json:"avatar"`
+	Email               string           `json:"email"`
+	EmailVerified       bool             `json:"emailVerified"`
+	Role                string           `json:"role"`
+	TwoFactorEnabled    bool             `json:"twoFactorEnabled"`
+	DeletionScheduledAt *time.Time       `json:"deletionScheduledAt"`
+	Identities          []exportIdentity `json:"identities"`
+}
+
+type exportIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	PhotoURL  string    `json:"photoUrl"`
+	LinkedAt  time.Time `json:"linkedAt"`
+}
+
+type exportWishlist struct {
+	entity.Wishlist
+	Presents []exportPresent `json:"presents"`
+}
+
+type exportPresent struct {
+	entity.Present
+	Meta *exportPresentMeta `json:"meta"`
+}
+
+type exportPresentMeta struct {
+	Source      string    `json:"source"`
+	OriginalURL string    `json:"originalUrl"`
+	Category    string    `json:"category"`
+	Brand       string    `json:"brand"`
+	ParsedAt    time.Time `json:"parsedAt"`
+}
+
+func (uc *accountUseCase) buildArchive(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	identities, err := uc.identityRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get identities: %w", err)
+	}
+	wishlists, err := uc.exportWishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := uc.templateRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get templates: %w", err)
+	}
+	liked, err := uc.templateRepo.GetLikedByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get liked templates: %w", err)
+	}
+
+	profile := exportProfile{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Handle:              user.Handle,
+		DisplayName:         user.DisplayName,
+		Avatar:              user.Avatar,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		Role:                user.Role,
+		TwoFactorEnabled:    user.TOTPEnabled,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		Identities:          make([]exportIdentity, len(identities)),
+	}
+	for i, id := range identities {
+		profile.Identities[i] = exportIdentity{
+			Provider:  id.Provider,
+			Subject:   id.Subject,
+			Username:  id.Username,
+			FirstName: id.FirstName,
+			LastName:  id.LastName,
+			PhotoURL:  id.PhotoURL,
+			LinkedAt:  id.CreatedAt,
+		}
+	}
+
+	// Картинки чужих понравившихся шаблонов — не данные пользователя, их не выгружаем
+	c := urlCollector{storage: uc.fileStorage, seen: map[string]bool{}}
+	c.add(user.Avatar)
+	for _, w := range wishlists {
+		c.add(w.Cover)
+		c.addBlocks(w.Blocks)
+		for _, p := range w.Presents {
+			c.add(p.Cover)
+		}
+	}
+	for _, t := range templates {
+		c.addBlocks(t.Blocks)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{}
+	for _, url := range c.urls {
+		objectID, _ := uc.fileStorage.ObjectID(url)
+		data, err := uc.fileStorage.Get(objectID)
+		if err != nil {
+			// Пропавший файл не должен лишать пользователя всей выгрузки
+			log.Printf("data export for %s: skip file %s: %v", userID, objectID, err)
+			continue
+		}
+		name := "files/" + objectID + fileExt(data)
+		if err := writeFile(zw, name, data); err != nil {
+			return nil, err
+		}
+		files[url] = name
+	}
+
+	entries := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", profile},
+		{"wishlists.json", wishlists},
+		{"templates.json", templates},
+		{"liked_templates.json", liked},
+		{"files.json", files},
+	}
+	for _, e := range entries {
+		data, err := json.MarshalIndent(e.v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %w", e.name, err)
+		}
+		if err := writeFile(zw, e.name, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (uc *accountUseCase) exportWishlists(ctx context.Context, userID uuid.UUID) ([]exportWishlist, error) {
+	wishlists, err := uc.wishlistRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get wishlists: %w", err)
+	}
+	result := make([]exportWishlist, len(wishlists))
+	for i, w := range wishlists {
+		presents, err := uc.presentRepo.GetAllByWishlistID(ctx, w.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get presents: %w", err)
+		}
+		ids := make([]uuid.UUID, len(presents))
+		for j, p := range presents {
+			ids[j] = p.ID
+		}
+		metas, err := uc.presentMetaRepo.GetByPresentIDs(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("get present meta: %w", err)
+		}
+		byPresent := make(map[uuid.UUID]*exportPresentMeta, len(metas))
+		for _, m := range metas {
+			byPresent[m.PresentID] = &exportPresentMeta{
+				Source:      m.Source,
+				OriginalURL: m.OriginalURL,
+				Category:    m.Category,
+				Brand:       m.Brand,
+				ParsedAt:    m.ParsedAt,
+			}
+		}
+
+		result[i] = exportWishlist{Wishlist: w, Presents: make([]exportPresent, len(presents))}
+		for j, p := range presents {
+			result[i].Presents[j] = exportPresent{Present: p, Meta: byPresent[p.ID]}
+		}
+	}
+	return result, nil
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// fileExt подбирает расширение по содержимому: в хранилище объекты лежат без расширений
+func fileExt(data []byte) string {
+	if len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP" {
+		return ".webp"
+	}
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "video/mp4":
+		return ".mp4"
+	}
+	return ""
+}
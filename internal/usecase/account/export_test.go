@@ -0,0 +1,266 @@
+package account_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// readArchive распаковывает ZIP в map имя → содержимое
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestRequestExport(t *testing.T) {
+	userID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		existing []entity.DataExport
+		reused   bool
+	}{
+		{name: "first export"},
+		{
+			name:     "still building",
+			existing: []entity.DataExport{{ID: uuid.New(), Status: entity.DataExportProcessing, CreatedAt: time.Now().Add(-time.Minute)}},
+			reused:   true,
+		},
+		{
+			name:     "recently built",
+			existing: []entity.DataExport{{ID: uuid.New(), Status: entity.DataExportReady, CreatedAt: time.Now().Add(-10 * time.Minute), ExpiresAt: &expiresAt}},
+			reused:   true,
+		},
+		{
+			name:     "built long ago",
+			existing: []entity.DataExport{{ID: uuid.New(), Status: entity.DataExportReady, CreatedAt: time.Now().Add(-5 * time.Hour), ExpiresAt: &expiresAt}},
+		},
+		{
+			name:     "previous failed",
+			existing: []entity.DataExport{{ID: uuid.New(), Status: entity.DataExportFailed, CreatedAt: time.Now().Add(-time.Minute), ExpiresAt: &expiresAt}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			f.exports.On("GetAllByUserID", mock.Anything, userID).Return(tt.existing, nil)
+			f.exports.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			export, err := f.uc.RequestExport(context.Background(), userID)
+			require.NoError(t, err)
+			if tt.reused {
+				assert.Equal(t, tt.existing[0].ID, export.ID)
+				f.exports.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.Equal(t, entity.DataExportPending, export.Status)
+			assert.Equal(t, userID, export.UserID)
+			f.exports.AssertCalled(t, "Create", mock.Anything, export)
+		})
+	}
+}
+
+func TestProcessExports_BuildsArchive(t *testing.T) {
+	f := newFixture()
+	now := time.Now()
+	totpSecret := "JBSWY3DPEHPK3PXP"
+	user := entity.User{ID: uuid.New(), Username: "alice", Password: "hashed", TOTPSecret: totpSecret, TOTPEnabled: true, Avatar: f.stored("avatar")}
+	wishlist := entity.Wishlist{ID: uuid.New(), UserID: user.ID, Title: "Birthday", Cover: f.stored("missing"), Blocks: []entity.Block{galleryBlock(f.stored("photo"))}}
+	present := entity.Present{ID: uuid.New(), WishlistID: wishlist.ID, Title: "Book", Cover: "https://marketplace.example.com/item.jpg"}
+	likedTemplate := entity.Template{ID: uuid.New(), UserID: uuid.New(), Name: "Someone else's", Blocks: []entity.Block{galleryBlock(f.stored("foreign"))}}
+	export := entity.DataExport{ID: uuid.New(), UserID: user.ID, Status: entity.DataExportProcessing}
+	png := []byte("\x89PNG\r\n\x1a\n" + "rest of the image")
+
+	f.exports.On("ClaimNext", mock.Anything, now, now.Add(-30*time.Minute)).Return(export, true, nil).Once()
+	f.exports.On("ClaimNext", mock.Anything, now, mock.Anything).Return(entity.DataExport{}, false, nil)
+	f.users.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	f.identities.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.UserIdentity{
+		{Provider: entity.IdentityProviderTelegram, Subject: "42", Username: "alice_tg"},
+	}, nil)
+	f.wishlists.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Wishlist{wishlist}, nil)
+	f.presents.On("GetAllByWishlistID", mock.Anything, wishlist.ID).Return([]entity.Present{present}, nil)
+	f.metas.On("GetByPresentIDs", mock.Anything, []uuid.UUID{present.ID}).Return([]entity.PresentMeta{
+		{PresentID: present.ID, Source: "ozon", OriginalURL: "https://ozon.ru/product/1", Brand: "Acme"},
+	}, nil)
+	f.templates.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.Template{}, nil)
+	f.templates.On("GetLikedByUserID", mock.Anything, user.ID).Return([]entity.Template{likedTemplate}, nil)
+	f.storage.On("Get", "avatar").Return(png, nil)
+	f.storage.On("Get", "photo").Return([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), nil)
+	f.storage.On("Get", "missing").Return(nil, errors.New("object not found"))
+	var archive []byte
+	f.storage.On("Upload", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		archive = args.Get(1).([]byte)
+	}).Return(f.stored("archive"), nil)
+	var saved entity.DataExport
+	f.exports.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(entity.DataExport)
+	}).Return(nil)
+
+	processed, err := f.uc.ProcessExports(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	assert.Equal(t, entity.DataExportReady, saved.Status)
+	assert.Equal(t, "archive", saved.ObjectID)
+	assert.Equal(t, int64(len(archive)), saved.Size)
+	require.NotNil(t, saved.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(exportTTL), *saved.ExpiresAt, time.Minute)
+
+	files := readArchive(t, archive)
+	assert.Equal(t, png, files["files/avatar.png"])
+	assert.Contains(t, files, "files/photo.webp")
+	f.storage.AssertNotCalled(t, "Get", "foreign")
+
+	var profile map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "alice", profile["username"])
+	assert.Equal(t, true, profile["twoFactorEnabled"])
+	assert.NotContains(t, string(files["profile.json"]), "hashed")
+	assert.NotContains(t, string(files["profile.json"]), totpSecret)
+	assert.Contains(t, string(files["profile.json"]), "alice_tg")
+
+	var wishlists []struct {
+		Title    string `json:"title"`
+		Presents []struct {
+			Title string `json:"title"`
+			Meta  *struct {
+				Source string `json:"source"`
+				Brand  string `json:"brand"`
+			} `json:"meta"`
+		} `json:"presents"`
+	}
+	require.NoError(t, json.Unmarshal(files["wishlists.json"], &wishlists))
+	require.Len(t, wishlists, 1)
+	require.Len(t, wishlists[0].Presents, 1)
+	require.NotNil(t, wishlists[0].Presents[0].Meta)
+	assert.Equal(t, "Acme", wishlists[0].Presents[0].Meta.Brand)
+
+	var liked []entity.Template
+	require.NoError(t, json.Unmarshal(files["liked_templates.json"], &liked))
+	require.Len(t, liked, 1)
+	assert.Equal(t, likedTemplate.ID, liked[0].ID)
+
+	// Пропавший файл пропущен, а не ломает выгрузку
+	var fileMap map[string]string
+	require.NoError(t, json.Unmarshal(files["files.json"], &fileMap))
+	assert.Equal(t, map[string]string{
+		user.Avatar:             "files/avatar.png",
+		storagePrefix + "photo": "files/photo.webp",
+	}, fileMap)
+}
+
+func TestProcessExports_FailureMarksFailed(t *testing.T) {
+	f := newFixture()
+	now := time.Now()
+	export := entity.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: entity.DataExportProcessing}
+
+	f.exports.On("ClaimNext", mock.Anything, now, mock.Anything).Return(export, true, nil).Once()
+	f.exports.On("ClaimNext", mock.Anything, now, mock.Anything).Return(entity.DataExport{}, false, nil)
+	f.users.On("GetByID", mock.Anything, export.UserID).Return(entity.User{}, errors.New("record not found"))
+	var saved entity.DataExport
+	f.exports.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(entity.DataExport)
+	}).Return(nil)
+
+	processed, err := f.uc.ProcessExports(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, entity.DataExportFailed, saved.Status)
+	assert.Empty(t, saved.ObjectID)
+	// Срок хранения нужен, чтобы запись подчистилась
+	assert.NotNil(t, saved.ExpiresAt)
+	f.storage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+}
+
+func TestDownloadExport(t *testing.T) {
+	userID := uuid.New()
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name    string
+		export  entity.DataExport
+		wantErr error
+	}{
+		{
+			name:   "ready",
+			export: entity.DataExport{UserID: userID, Status: entity.DataExportReady, ObjectID: "archive", ExpiresAt: &future},
+		},
+		{
+			name:    "someone else's",
+			export:  entity.DataExport{UserID: uuid.New(), Status: entity.DataExportReady, ObjectID: "archive", ExpiresAt: &future},
+			wantErr: usecase.ErrNotFound,
+		},
+		{
+			name:    "expired",
+			export:  entity.DataExport{UserID: userID, Status: entity.DataExportReady, ObjectID: "archive", ExpiresAt: &past},
+			wantErr: usecase.ErrNotFound,
+		},
+		{
+			name:    "not built yet",
+			export:  entity.DataExport{UserID: userID, Status: entity.DataExportPending},
+			wantErr: usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			tt.export.ID = uuid.New()
+			f.exports.On("GetByID", mock.Anything, tt.export.ID).Return(tt.export, nil)
+			f.storage.On("Get", "archive").Return([]byte("zip"), nil)
+
+			data, err := f.uc.DownloadExport(context.Background(), userID, tt.export.ID)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				f.storage.AssertNotCalled(t, "Get", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte("zip"), data)
+		})
+	}
+}
+
+func TestPurgeExpiredExports(t *testing.T) {
+	f := newFixture()
+	now := time.Now()
+	stuck := entity.DataExport{ID: uuid.New(), Status: entity.DataExportReady, ObjectID: "stuck"}
+	ready := entity.DataExport{ID: uuid.New(), Status: entity.DataExportReady, ObjectID: "archive"}
+	failed := entity.DataExport{ID: uuid.New(), Status: entity.DataExportFailed}
+
+	f.exports.On("GetExpired", mock.Anything, now, mock.Anything).Return([]entity.DataExport{stuck, ready, failed}, nil)
+	f.storage.On("Delete", "stuck").Return(errors.New("connection reset"))
+	f.storage.On("Delete", "archive").Return(nil)
+	f.exports.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	purged, err := f.uc.PurgeExpiredExports(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	// Без удалённого архива запись остаётся до следующего прохода
+	f.exports.AssertNotCalled(t, "Delete", mock.Anything, stuck.ID)
+	f.exports.AssertCalled(t, "Delete", mock.Anything, ready.ID)
+	f.exports.AssertCalled(t, "Delete", mock.Anything, failed.ID)
+}
@@ -0,0 +1,66 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"main/internal/entity"
+)
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// PurgeTrash удаляет сначала подарки, потом вишлисты: так обложки подарков из корзины
+// не теряются при удалении вишлиста. Сбой на одном объекте не мешает остальным
+func (uc *accountUseCase) PurgeTrash(ctx context.Context, now time.Time) (int, error) {
+	before := now.Add(-uc.trashRetention)
+	purged := 0
+
+	presents, err := uc.presentRepo.GetDeletedBefore(ctx, before, purgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get expired presents: %w", err)
+	}
+	for _, p := range presents {
+		if err := uc.purgePresent(ctx, p); err != nil {
+			log.Printf("present %s: purge failed: %v", p.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	wishlists, err := uc.wishlistRepo.GetDeletedBefore(ctx, before, purgeBatchSize)
+	if err != nil {
+		return purged, fmt.Errorf("get expired wishlists: %w", err)
+	}
+	for _, w := range wishlists {
+		if err := uc.purgeWishlist(ctx, w); err != nil {
+			log.Printf("wishlist %s: purge failed: %v", w.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (uc *accountUseCase) purgePresent(ctx context.Context, p entity.Present) error {
+	c := urlCollector{storage: uc.fileStorage, seen: map[string]bool{}}
+	c.add(p.Cover)
+	if err := uc.presentRepo.Purge(ctx, p.ID); err != nil {
+		return fmt.Errorf("purge present: %w", err)
+	}
+	uc.deleteUnreferenced(ctx, "present "+p.ID.String(), c.urls)
+	return nil
+}
+
+func (uc *accountUseCase) purgeWishlist(ctx context.Context, w entity.Wishlist) error {
+	c := urlCollector{storage: uc.fileStorage, seen: map[string]bool{}}
+	if err := uc.addWishlistFiles(ctx, &c, w); err != nil {
+		return err
+	}
+	if err := uc.wishlistRepo.Purge(ctx, w.ID); err != nil {
+		return fmt.Errorf("purge wishlist: %w", err)
+	}
+	uc.deleteUnreferenced(ctx, "wishlist "+w.ID.String(), c.urls)
+	return nil
+}
@@ -0,0 +1,70 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+)
+
+func TestPurgeTrash_DeletesExpiredWithFiles(t *testing.T) {
+	f := newFixture()
+	now := time.Now()
+	before := now.Add(-trashRetention)
+
+	presentCover := f.stored("present")
+	wishlistCover := f.stored("wishlist")
+	innerCover := f.stored("inner")
+	trashedInner := f.stored("trashed-inner")
+	shared := f.stored("shared")
+
+	present := entity.Present{ID: uuid.New(), Cover: presentCover}
+	wishlist := entity.Wishlist{ID: uuid.New(), Cover: wishlistCover, Blocks: []entity.Block{galleryBlock(shared)}}
+
+	f.presents.On("GetDeletedBefore", mock.Anything, before, mock.Anything).Return([]entity.Present{present}, nil)
+	f.presents.On("Purge", mock.Anything, present.ID).Return(nil)
+	f.wishlists.On("GetDeletedBefore", mock.Anything, before, mock.Anything).Return([]entity.Wishlist{wishlist}, nil)
+	f.presents.On("GetAllByWishlistID", mock.Anything, wishlist.ID).Return([]entity.Present{{ID: uuid.New(), Cover: innerCover}}, nil)
+	f.presents.On("GetDeletedByWishlistID", mock.Anything, wishlist.ID).Return([]entity.Present{{ID: uuid.New(), Cover: trashedInner}}, nil)
+	f.wishlists.On("Purge", mock.Anything, wishlist.ID).Return(nil)
+	f.fileRefs.On("Unreferenced", mock.Anything, []string{presentCover}).Return([]string{presentCover}, nil)
+	// shared остался в чужом шаблоне
+	f.fileRefs.On("Unreferenced", mock.Anything, mock.Anything).Return([]string{wishlistCover, innerCover, trashedInner}, nil)
+	f.storage.On("Delete", mock.Anything).Return(nil)
+
+	purged, err := f.uc.PurgeTrash(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	f.presents.AssertExpectations(t)
+	f.wishlists.AssertExpectations(t)
+	assert.ElementsMatch(t, []string{wishlistCover, shared, innerCover, trashedInner}, f.fileRefs.Calls[1].Arguments.Get(1))
+	for _, id := range []string{"present", "wishlist", "inner", "trashed-inner"} {
+		f.storage.AssertCalled(t, "Delete", id)
+	}
+	f.storage.AssertNotCalled(t, "Delete", "shared")
+}
+
+func TestPurgeTrash_FailedPurgeKeepsFilesAndContinues(t *testing.T) {
+	f := newFixture()
+	failing := entity.Present{ID: uuid.New(), Cover: f.stored("failing")}
+	ok := entity.Present{ID: uuid.New(), Cover: "https://marketplace.example.com/item.jpg"}
+
+	f.presents.On("GetDeletedBefore", mock.Anything, mock.Anything, mock.Anything).Return([]entity.Present{failing, ok}, nil)
+	f.presents.On("Purge", mock.Anything, failing.ID).Return(errors.New("connection reset"))
+	f.presents.On("Purge", mock.Anything, ok.ID).Return(nil)
+	f.wishlists.On("GetDeletedBefore", mock.Anything, mock.Anything, mock.Anything).Return([]entity.Wishlist{}, nil)
+
+	purged, err := f.uc.PurgeTrash(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	f.presents.AssertCalled(t, "Purge", mock.Anything, ok.ID)
+	f.fileRefs.AssertNotCalled(t, "Unreferenced", mock.Anything, mock.Anything)
+	f.storage.AssertNotCalled(t, "Delete", mock.Anything)
+}
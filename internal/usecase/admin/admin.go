@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+)
+
+const usersPageSize = 50
+
+type adminUseCase struct {
+	userRepo     repo.UserRepo
+	sessionRepo  repo.SessionRepo
+	wishlistRepo repo.WishlistRepo
+	templateRepo repo.TemplateRepo
+	statsRepo    repo.StatsRepo
+}
+
+func New(
+	userRepo repo.UserRepo,
+	sessionRepo repo.SessionRepo,
+	wishlistRepo repo.WishlistRepo,
+	templateRepo repo.TemplateRepo,
+	statsRepo repo.StatsRepo,
+) usecase.AdminUseCase {
+	return &adminUseCase{
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		wishlistRepo: wishlistRepo,
+		templateRepo: templateRepo,
+		statsRepo:    statsRepo,
+	}
+}
+
+func (uc *adminUseCase) ListUsers(ctx context.Context, actorID uuid.UUID, query string, page int) (usecase.UserPage, error) {
+	if _, err := uc.actor(ctx, actorID, entity.RoleModerator); err != nil {
+		return usecase.UserPage{}, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * usersPageSize
+
+	users, total, err := uc.userRepo.Search(ctx, strings.TrimSpace(query), usersPageSize, offset)
+	if err != nil {
+		return usecase.UserPage{}, fmt.Errorf("search users: %w", err)
+	}
+	return usecase.UserPage{
+		Users:   users,
+		Total:   total,
+		HasMore: int64(offset+len(users)) < total,
+	}, nil
+}
+
+func (uc *adminUseCase) Ban(ctx context.Context, actorID, userID uuid.UUID, reason string) (entity.User, error) {
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > usecase.MaxBanReasonLen {
+		return entity.User{}, fmt.Errorf("причина длиннее %d символов", usecase.MaxBanReasonLen)
+	}
+	actor, target, err := uc.actorAndTarget(ctx, actorID, userID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if !target.Banned() {
+		now := time.Now()
+		target.BannedAt = &now
+	}
+	target.BanReason = reason
+	if err := uc.userRepo.UpdateColumns(ctx, target, repo.UserColumnBannedAt, repo.UserColumnBanReason); err != nil {
+		return entity.User{}, fmt.Errorf("update user: %w", err)
+	}
+	// Без активных сессий JWTProtected отклонит и уже выданные access-токены
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, target.ID, uuid.Nil); err != nil {
+		return entity.User{}, fmt.Errorf("revoke sessions: %w", err)
+	}
+	log.Printf("admin: user=%s banned user=%s reason=%q", actor.ID, target.ID, reason)
+	return target, nil
+}
+
+func (uc *adminUseCase) Unban(ctx context.Context, actorID, userID uuid.UUID) (entity.User, error) {
+	actor, target, err := uc.actorAndTarget(ctx, actorID, userID)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if !target.Banned() {
+		return target, nil
+	}
+
+	target.BannedAt = nil
+	target.BanReason = ""
+	if err := uc.userRepo.UpdateColumns(ctx, target, repo.UserColumnBannedAt, repo.UserColumnBanReason); err != nil {
+		return entity.User{}, fmt.Errorf("update user: %w", err)
+	}
+	log.Printf("admin: user=%s unbanned user=%s", actor.ID, target.ID)
+	return target, nil
+}
+
+func (uc *adminUseCase) SetRole(ctx context.Context, actorID, userID uuid.UUID, role string) (entity.User, error) {
+	if !entity.ValidRole(role) {
+		return entity.User{}, fmt.Errorf("неизвестная роль %q", role)
+	}
+	actor, err := uc.actor(ctx, actorID, entity.RoleAdmin)
+	if err != nil {
+		return entity.User{}, err
+	}
+	// Своя роль не меняется, чтобы инстанс не остался без администратора
+	if actorID == userID {
+		return entity.User{}, fmt.Errorf("нельзя изменить собственную роль: %w", usecase.ErrForbidden)
+	}
+	target, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if target.Role == role {
+		return target, nil
+	}
+
+	target.Role = role
+	if err := uc.userRepo.UpdateColumns(ctx, target, repo.UserColumnRole); err != nil {
+		return entity.User{}, fmt.Errorf("update user: %w", err)
+	}
+	// Роль зашита в claims — без отзыва сессий пониженный пользователь сохранил бы права до конца их жизни
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, target.ID, uuid.Nil); err != nil {
+		return entity.User{}, fmt.Errorf("revoke sessions: %w", err)
+	}
+	log.Printf("admin: user=%s set role of user=%s to %s", actor.ID, target.ID, role)
+	return target, nil
+}
+
+func (uc *adminUseCase) UnpublishTemplate(ctx context.Context, actorID, templateID uuid.UUID) (entity.Template, error) {
+	actor, err := uc.actor(ctx, actorID, entity.RoleModerator)
+	if err != nil {
+		return entity.Template{}, err
+	}
+	t, err := uc.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return entity.Template{}, fmt.Errorf("template %w: %v", usecase.ErrNotFound, err)
+	}
+	if !t.IsPublic {
+		return t, nil
+	}
+
+	t.IsPublic = false
+	if err := uc.templateRepo.Update(ctx, t); err != nil {
+		return entity.Template{}, fmt.Errorf("update template: %w", err)
+	}
+	log.Printf("admin: user=%s unpublished template=%s", actor.ID, t.ID)
+	return t, nil
+}
+
+func (uc *adminUseCase) UnpublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error) {
+	return uc.setWishlistUnpublished(ctx, actorID, wishlistID, true)
+}
+
+func (uc *adminUseCase) PublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error) {
+	return uc.setWishlistUnpublished(ctx, actorID, wishlistID, false)
+}
+
+func (uc *adminUseCase) Stats(ctx context.Context, actorID uuid.UUID) (entity.InstanceStats, error) {
+	if _, err := uc.actor(ctx, actorID, entity.RoleModerator); err != nil {
+		return entity.InstanceStats{}, err
+	}
+	stats, err := uc.statsRepo.Get(ctx, time.Now())
+	if err != nil {
+		return entity.InstanceStats{}, fmt.Errorf("get stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (uc *adminUseCase) setWishlistUnpublished(ctx context.Context, actorID, wishlistID uuid.UUID, unpublished bool) (entity.Wishlist, error) {
+	actor, err := uc.actor(ctx, actorID, entity.RoleModerator)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	w, err := uc.wishlistRepo.GetByID(ctx, wishlistID)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("wishlist %w: %v", usecase.ErrNotFound, err)
+	}
+	if w.Unpublished == unpublished {
+		return w, nil
+	}
+
+	w.Unpublished = unpublished
+	if err := uc.wishlistRepo.Update(ctx, w); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("update wishlist: %w", err)
+	}
+	w.Version++
+	log.Printf("admin: user=%s set unpublished=%t on wishlist=%s", actor.ID, unpublished, w.ID)
+	return w, nil
+}
+
+// actor загружает пользователя, выполняющего действие, и проверяет его роль
+func (uc *adminUseCase) actor(ctx context.Context, actorID uuid.UUID, min string) (entity.User, error) {
+	actor, err := uc.userRepo.GetByID(ctx, actorID)
+	if err != nil || actor.Banned() || !entity.RoleAtLeast(actor.Role, min) {
+		return entity.User{}, usecase.ErrForbidden
+	}
+	return actor, nil
+}
+
+// actorAndTarget — модератор управляет только теми, у кого роль ниже его собственной
+func (uc *adminUseCase) actorAndTarget(ctx context.Context, actorID, userID uuid.UUID) (entity.User, entity.User, error) {
+	actor, err := uc.actor(ctx, actorID, entity.RoleModerator)
+	if err != nil {
+		return entity.User{}, entity.User{}, err
+	}
+	if actorID == userID {
+		return entity.User{}, entity.User{}, errors.New("нельзя применить к себе")
+	}
+	target, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.User{}, entity.User{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if entity.RoleAtLeast(target.Role, actor.Role) {
+		return entity.User{}, entity.User{}, usecase.ErrForbidden
+	}
+	return actor, target, nil
+}
@@ -0,0 +1,179 @@
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	adminUC "main/internal/usecase/admin"
+	mockrepo "main/mock/repo"
+)
+
+type fixture struct {
+	uc       usecase.AdminUseCase
+	users    *mockrepo.MockUserRepo
+	sessions *mockrepo.MockSessionRepo
+	wishlist *mockrepo.MockWishlistRepo
+}
+
+func newFixture(users ...entity.User) fixture {
+	f := fixture{
+		users:    &mockrepo.MockUserRepo{},
+		sessions: &mockrepo.MockSessionRepo{},
+		wishlist: &mockrepo.MockWishlistRepo{},
+	}
+	for _, u := range users {
+		f.users.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+	}
+	f.users.On("GetByID", mock.Anything, mock.Anything).Return(entity.User{}, errors.New("record not found"))
+	f.uc = adminUC.New(f.users, f.sessions, f.wishlist, nil, nil)
+	return f
+}
+
+func newUser(role string) entity.User {
+	return entity.User{ID: uuid.New(), Username: role + "-" + uuid.NewString()[:4], Role: role}
+}
+
+func TestBan(t *testing.T) {
+	admin := newUser(entity.RoleAdmin)
+	moderator := newUser(entity.RoleModerator)
+	user := newUser(entity.RoleUser)
+	otherModerator := newUser(entity.RoleModerator)
+
+	tests := []struct {
+		name    string
+		actor   entity.User
+		target  entity.User
+		wantErr error
+	}{
+		{name: "moderator bans user", actor: moderator, target: user},
+		{name: "admin bans moderator", actor: admin, target: moderator},
+		{name: "moderator cannot ban moderator", actor: moderator, target: otherModerator, wantErr: usecase.ErrForbidden},
+		{name: "moderator cannot ban admin", actor: moderator, target: admin, wantErr: usecase.ErrForbidden},
+		{name: "user cannot ban", actor: user, target: otherModerator, wantErr: usecase.ErrForbidden},
+		{name: "unknown target", actor: moderator, target: entity.User{ID: uuid.New()}, wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(admin, moderator, user, otherModerator)
+			f.users.On("UpdateColumns", mock.Anything, mock.Anything, []repo.UserColumn{repo.UserColumnBannedAt, repo.UserColumnBanReason}).Return(nil).Maybe()
+			f.sessions.On("RevokeAllByUserID", mock.Anything, tt.target.ID, uuid.Nil).Return(nil).Maybe()
+
+			banned, err := f.uc.Ban(context.Background(), tt.actor.ID, tt.target.ID, " spam ")
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				f.users.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+				f.sessions.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, banned.Banned())
+			assert.Equal(t, "spam", banned.BanReason)
+			f.sessions.AssertCalled(t, "RevokeAllByUserID", mock.Anything, tt.target.ID, uuid.Nil)
+		})
+	}
+}
+
+func TestBan_Self(t *testing.T) {
+	admin := newUser(entity.RoleAdmin)
+	f := newFixture(admin)
+
+	_, err := f.uc.Ban(context.Background(), admin.ID, admin.ID, "")
+	require.Error(t, err)
+	f.users.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUnban(t *testing.T) {
+	moderator := newUser(entity.RoleModerator)
+	user := newUser(entity.RoleUser)
+	bannedAt := time.Now()
+	user.BannedAt = &bannedAt
+	user.BanReason = "spam"
+	f := newFixture(moderator, user)
+	f.users.On("UpdateColumns", mock.Anything, mock.MatchedBy(func(u entity.User) bool {
+		return u.ID == user.ID && u.BannedAt == nil && u.BanReason == ""
+	}), []repo.UserColumn{repo.UserColumnBannedAt, repo.UserColumnBanReason}).Return(nil)
+
+	got, err := f.uc.Unban(context.Background(), moderator.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Banned())
+	f.users.AssertExpectations(t)
+}
+
+func TestSetRole(t *testing.T) {
+	admin := newUser(entity.RoleAdmin)
+	moderator := newUser(entity.RoleModerator)
+	user := newUser(entity.RoleUser)
+
+	t.Run("admin promotes user", func(t *testing.T) {
+		f := newFixture(admin, user)
+		// Пишется только роль — параллельный бан не откатится
+		f.users.On("UpdateColumns", mock.Anything, mock.MatchedBy(func(u entity.User) bool {
+			return u.ID == user.ID && u.Role == entity.RoleModerator
+		}), []repo.UserColumn{repo.UserColumnRole}).Return(nil)
+		// Роль зашита в claims — старые сессии отзываются
+		f.sessions.On("RevokeAllByUserID", mock.Anything, user.ID, uuid.Nil).Return(nil)
+
+		got, err := f.uc.SetRole(context.Background(), admin.ID, user.ID, entity.RoleModerator)
+		require.NoError(t, err)
+		assert.Equal(t, entity.RoleModerator, got.Role)
+		f.users.AssertExpectations(t)
+		f.sessions.AssertExpectations(t)
+	})
+
+	t.Run("moderator cannot set roles", func(t *testing.T) {
+		f := newFixture(moderator, user)
+		_, err := f.uc.SetRole(context.Background(), moderator.ID, user.ID, entity.RoleModerator)
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+	})
+
+	t.Run("admin cannot change own role", func(t *testing.T) {
+		f := newFixture(admin)
+		_, err := f.uc.SetRole(context.Background(), admin.ID, admin.ID, entity.RoleUser)
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		f := newFixture(admin, user)
+		_, err := f.uc.SetRole(context.Background(), admin.ID, user.ID, "root")
+		require.Error(t, err)
+		f.users.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUnpublishWishlist(t *testing.T) {
+	moderator := newUser(entity.RoleModerator)
+	f := newFixture(moderator)
+	wid := uuid.New()
+	f.wishlist.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+	f.wishlist.On("Update", mock.Anything, mock.MatchedBy(func(w entity.Wishlist) bool {
+		return w.ID == wid && w.Unpublished
+	})).Return(nil)
+
+	w, err := f.uc.UnpublishWishlist(context.Background(), moderator.ID, wid)
+	require.NoError(t, err)
+	assert.True(t, w.Unpublished)
+	f.wishlist.AssertExpectations(t)
+}
+
+func TestListUsers_Paging(t *testing.T) {
+	moderator := newUser(entity.RoleModerator)
+	f := newFixture(moderator)
+	f.users.On("Search", mock.Anything, "ali", 50, 50).Return([]entity.User{{ID: uuid.New()}}, int64(120), nil)
+
+	page, err := f.uc.ListUsers(context.Background(), moderator.ID, "  ali ", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), page.Total)
+	assert.True(t, page.HasMore)
+	f.users.AssertExpectations(t)
+}
@@ -9,10 +9,85 @@ import (
 	"github.com/google/uuid"
 )
 
-// AuthResult — результат аутентификации
+// AuthResult — результат аутентификации: короткоживущий access-токен и ротируемый refresh-токен
 type AuthResult struct {
-	Token string
-	User  entity.User
+	Token            string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	User             entity.User
+	// MFARequired — первый фактор пройден, но токены не выданы:
+	// клиент должен обменять MFAToken и код на полноценный вход через CompleteMFA
+	MFARequired bool
+	MFAToken    string
+}
+
+// ClientInfo — сведения о клиенте, от имени которого выполняется вход;
+// сохраняются в сессии, чтобы пользователь видел, где он залогинен
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// TOTPEnrollment — данные для подключения аутентификатора
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// PublicProfile — публичная страница пользователя: только то, что владелец решил показать
+type PublicProfile struct {
+	Handle      string
+	DisplayName string
+	Avatar      string
+	Wishlists   []entity.Wishlist
+}
+
+// SetVisibilityInput — новая видимость вишлиста; Password учитывается только для VisibilityPassword
+type SetVisibilityInput struct {
+	Visibility string
+	Password   string
+}
+
+// SetSlugInput — новый slug вишлиста; пустой Slug убирает его, пустой Scope — SlugScopeProfile
+type SetSlugInput struct {
+	Slug  string
+	Scope string
+}
+
+// SetEventInput — дата события вишлиста. Нулевое Time убирает дату, пустой Timezone — UTC,
+// Repeat — entity.EventRepeat*
+type SetEventInput struct {
+	Time     time.Time
+	Timezone string
+	Repeat   string
+}
+
+// ProfileWishlist — вишлист, открытый по адресу в профиле владельца; Handle — текущий handle владельца
+type ProfileWishlist struct {
+	Handle   string
+	Wishlist entity.Wishlist
+}
+
+// WishlistUnlock — токен разблокировки защищённого паролем вишлиста
+type WishlistUnlock struct {
+	WishlistID uuid.UUID
+	Token      string
+	ExpiresAt  time.Time
+}
+
+// WishlistInviteLink — одноразовое приглашение в соавторы; ссылку из токена собирает клиент
+type WishlistInviteLink struct {
+	Token     string
+	Role      string
+	ExpiresAt time.Time
+}
+
+// Trash — содержимое корзины пользователя: его удалённые вишлисты и удалённые подарки
+// из его неудалённых вишлистов
+type Trash struct {
+	Wishlists []entity.Wishlist
+	Presents  []entity.Present
 }
 
 // CreateWishlistInput — входные данные для создания/обновления простого вишлиста
@@ -88,37 +163,257 @@ type UpdateProfileInput struct {
 	Avatar      *string // nil = не менять
 }
 
+// DeleteAccountInput — повторное подтверждение личности перед удалением аккаунта.
+// Password обязателен, если он задан у аккаунта, Code — при включённой 2FA
+type DeleteAccountInput struct {
+	Password string
+	Code     string
+}
+
+// CreateAccessTokenInput — параметры нового персонального токена
+type CreateAccessTokenInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // nil — бессрочный
+}
+
+// CreatedAccessToken — новый токен; Token в открытом виде доступен только здесь
+type CreatedAccessToken struct {
+	AccessToken entity.AccessToken
+	Token       string
+}
+
 // UserUseCase — бизнес-логика пользователей
 type UserUseCase interface {
-	Register(ctx context.Context, username, password string) (AuthResult, error)
-	Login(ctx context.Context, username, password string) (AuthResult, error)
-	AuthenticateTelegram(ctx context.Context, input TelegramAuthInput) (AuthResult, error)
+	Register(ctx context.Context, username, password string, client ClientInfo) (AuthResult, error)
+	// Login принимает username или подтверждённый email
+	Login(ctx context.Context, login, password string, client ClientInfo) (AuthResult, error)
+	AuthenticateTelegram(ctx context.Context, input TelegramAuthInput, client ClientInfo) (AuthResult, error)
+	// AuthenticateTelegramWebApp входит по подписанному initData из Telegram Mini App
+	// в тот же аккаунт, что и вход через виджет
+	AuthenticateTelegramWebApp(ctx context.Context, initData string, client ClientInfo) (AuthResult, error)
 	GetMe(ctx context.Context, userID uuid.UUID) (entity.User, error)
 	UpdateProfile(ctx context.Context, userID uuid.UUID, input UpdateProfileInput) (entity.User, error)
 	GetProfile(ctx context.Context, userID uuid.UUID) (entity.User, error)
+	// SetHandle задаёт публичный handle (пустой — скрывает профиль); прежний handle продолжает
+	// вести на профиль и удерживается за владельцем
+	SetHandle(ctx context.Context, userID uuid.UUID, handle string) (entity.User, error)
+	// GetPublicProfile ищет профиль по текущему или прежнему handle; PublicProfile.Handle
+	// всегда текущий, по нему вызывающий понимает, что нужен редирект
+	GetPublicProfile(ctx context.Context, handle string) (PublicProfile, error)
+	Refresh(ctx context.Context, refreshToken string, client ClientInfo) (AuthResult, error)
+	// Logout отзывает сессию sessionID, а если она неизвестна (uuid.Nil) — сессию refresh-токена
+	Logout(ctx context.Context, sessionID uuid.UUID, refreshToken string) error
+	ValidateSession(ctx context.Context, sessionID uuid.UUID) error
+	// GetSessions возвращает активные сессии пользователя, последние использованные — первыми
+	GetSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error)
+	// RevokeSession отзывает одну сессию; чужая сессия неотличима от несуществующей (ErrNotFound)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	// RevokeOtherSessions отзывает все сессии пользователя, кроме текущей
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error
+	GetIdentities(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error)
+	// LinkTelegram привязывает Telegram к существующему аккаунту
+	LinkTelegram(ctx context.Context, userID uuid.UUID, input TelegramAuthInput) (entity.UserIdentity, error)
+	// SetPassword задаёт пароль аккаунту, у которого его ещё нет
+	SetPassword(ctx context.Context, userID uuid.UUID, password string) error
+	UnlinkIdentity(ctx context.Context, userID, identityID uuid.UUID) error
+	// ChangePassword меняет пароль и отзывает все сессии, кроме текущей, и все персональные токены.
+	// Неверный текущий пароль копится в счётчике блокировки аккаунта, как при входе
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string) error
+	// RequestPasswordReset отправляет письмо со ссылкой сброса; неизвестный адрес не считается ошибкой
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword задаёт пароль по ссылке из письма и отзывает все сессии и персональные токены
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// RequestEmailVerification отправляет ссылку подтверждения; адрес сохраняется только после VerifyEmail.
+	// Повторный запрос раньше чем через минуту возвращает ErrLinkCooldown
+	RequestEmailVerification(ctx context.Context, userID uuid.UUID, email string) error
+	VerifyEmail(ctx context.Context, token string) (entity.User, error)
+	// RequestMagicLink отправляет ссылку входа без пароля на подтверждённый адрес
+	RequestMagicLink(ctx context.Context, email string) error
+	LoginWithMagicLink(ctx context.Context, token string, client ClientInfo) (AuthResult, error)
+	// CompleteMFA — второй шаг входа: TOTP-код или код восстановления
+	CompleteMFA(ctx context.Context, mfaToken, code string, client ClientInfo) (AuthResult, error)
+	BeginTOTPEnrollment(ctx context.Context, userID uuid.UUID) (TOTPEnrollment, error)
+	// ConfirmTOTP включает 2FA и возвращает одноразовые коды восстановления (показываются один раз)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	CreateAccessToken(ctx context.Context, userID uuid.UUID, input CreateAccessTokenInput) (CreatedAccessToken, error)
+	GetAccessTokens(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// AuthenticateAccessToken проверяет персональный токен и отмечает его использование
+	AuthenticateAccessToken(ctx context.Context, token string) (entity.AccessToken, error)
+	// DeleteAccount ставит аккаунт в очередь на удаление, отзывает все сессии и персональные токены
+	// и возвращает момент окончательного удаления. Аккаунт без пароля подтверждается свежим входом
+	DeleteAccount(ctx context.Context, userID, sessionID uuid.UUID, input DeleteAccountInput) (time.Time, error)
+}
+
+// AccountUseCase — операции над аккаунтом целиком: выгрузка персональных данных
+// и окончательное удаление аккаунтов, у которых истёк срок ожидания
+type AccountUseCase interface {
+	// RequestExport ставит выгрузку данных в очередь. Если выгрузка уже собирается
+	// или недавно собрана, возвращает её вместо новой
+	RequestExport(ctx context.Context, userID uuid.UUID) (entity.DataExport, error)
+	GetExports(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error)
+	// DownloadExport возвращает ZIP-архив; чужая, несобранная или истёкшая выгрузка — ErrNotFound
+	DownloadExport(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error)
+	// ProcessExports собирает ожидающие выгрузки и возвращает число обработанных
+	ProcessExports(ctx context.Context, now time.Time) (int, error)
+	// PurgeExpiredExports удаляет выгрузки с истёкшим сроком хранения вместе с архивами
+	PurgeExpiredExports(ctx context.Context, now time.Time) (int, error)
+	// PurgeDue удаляет всё, чем владели такие пользователи, включая загруженные файлы,
+	// и возвращает число удалённых аккаунтов
+	PurgeDue(ctx context.Context, now time.Time) (int, error)
+	// PurgeTrash окончательно удаляет вишлисты и подарки, пролежавшие в корзине дольше срока хранения,
+	// вместе с файлами, на которые больше никто не ссылается, и возвращает число удалённых объектов
+	PurgeTrash(ctx context.Context, now time.Time) (int, error)
+}
+
+// UserPage — страница результатов поиска пользователей
+type UserPage struct {
+	Users   []entity.User
+	Total   int64
+	HasMore bool
+}
+
+// AdminUseCase — модерация инстанса. Права actorID проверяются по текущей роли в базе,
+// а не по claims токена: пониженный модератор теряет их сразу
+type AdminUseCase interface {
+	// ListUsers ищет по подстроке username, имени и email; пустой query — все пользователи
+	ListUsers(ctx context.Context, actorID uuid.UUID, query string, page int) (UserPage, error)
+	// Ban блокирует вход и отзывает все сессии; действует только на пользователей с ролью ниже, чем у actorID
+	Ban(ctx context.Context, actorID, userID uuid.UUID, reason string) (entity.User, error)
+	Unban(ctx context.Context, actorID, userID uuid.UUID) (entity.User, error)
+	// SetRole доступен только администраторам
+	SetRole(ctx context.Context, actorID, userID uuid.UUID, role string) (entity.User, error)
+	// UnpublishTemplate убирает шаблон из публичной галереи
+	UnpublishTemplate(ctx context.Context, actorID, templateID uuid.UUID) (entity.Template, error)
+	// UnpublishWishlist скрывает вишлист от всех, кроме владельца; PublishWishlist возвращает его
+	UnpublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error)
+	PublishWishlist(ctx context.Context, actorID, wishlistID uuid.UUID) (entity.Wishlist, error)
+	Stats(ctx context.Context, actorID uuid.UUID) (entity.InstanceStats, error)
 }
 
 // WishlistUseCase — бизнес-логика вишлистов
 type WishlistUseCase interface {
 	Create(ctx context.Context, userID uuid.UUID, input CreateWishlistInput) (entity.Wishlist, error)
 	CreateConstructor(ctx context.Context, userID uuid.UUID, input CreateConstructorInput) (entity.Wishlist, error)
-	GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error)
-	GetByShortID(ctx context.Context, shortID string) (entity.Wishlist, error)
+	// GetByID, GetByShortID и GetByProfileSlug возвращают ErrNotFound, если вишлист скрыт от зрителя,
+	// и *WishlistLockedError, если для просмотра нужен пароль
+	GetByID(ctx context.Context, viewer Viewer, id uuid.UUID) (entity.Wishlist, error)
+	// GetByShortID ищет по короткому ID, а затем по текущему или прежнему глобальному slug
+	GetByShortID(ctx context.Context, viewer Viewer, shortID string) (entity.Wishlist, error)
+	// GetByProfileSlug ищет по slug в профиле владельца; прежние handle и slug тоже подходят
+	GetByProfileSlug(ctx context.Context, viewer Viewer, handle, slug string) (ProfileWishlist, error)
+	// GetAllByUser возвращает вишлисты пользователя и вишлисты, где он соавтор; роль — в Role
 	GetAllByUser(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
-	Update(ctx context.Context, id uuid.UUID, input CreateWishlistInput) (entity.Wishlist, error)
-	UpdateBlocks(ctx context.Context, id uuid.UUID, blocks []entity.Block) (entity.Wishlist, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Update, UpdateBlocks и Delete принимают версию, которую правит клиент. Если вишлист с тех пор
+	// изменили, возвращается *StaleWishlistError с его актуальным состоянием
+	Update(ctx context.Context, userID, id uuid.UUID, version uint, input CreateWishlistInput) (entity.Wishlist, error)
+	UpdateBlocks(ctx context.Context, userID, id uuid.UUID, version uint, blocks []entity.Block) (entity.Wishlist, error)
+	// GetRevisions возвращает историю блоков и настроек без самих блоков, новые ревизии — первыми.
+	// История, как и откат, доступна владельцу и редакторам
+	GetRevisions(ctx context.Context, userID, id uuid.UUID) ([]entity.WishlistRevision, error)
+	GetRevision(ctx context.Context, userID, id uuid.UUID, number int) (entity.WishlistRevision, error)
+	// DiffRevisions сравнивает ревизии from и to: добавленные, удалённые, перемещённые и изменённые блоки
+	DiffRevisions(ctx context.Context, userID, id uuid.UUID, from, to int) (entity.RevisionDiff, error)
+	// RestoreRevision возвращает блоки и настройки ревизии; откат сам становится новой ревизией
+	RestoreRevision(ctx context.Context, userID, id uuid.UUID, version uint, number int) (entity.Wishlist, error)
+	// Clone копирует вишлист владельца с блоками, подарками и их метаданными под новым коротким ID.
+	// Брони сбрасываются, соавторы не копируются; пустой title — название оригинала
+	Clone(ctx context.Context, userID, id uuid.UUID, title string) (entity.Wishlist, error)
+	// SetListedOnProfile показывает вишлист на публичной странице владельца или убирает его оттуда
+	SetListedOnProfile(ctx context.Context, userID, id uuid.UUID, version uint, listed bool) (entity.Wishlist, error)
+	// SetVisibility меняет видимость вишлиста. Для VisibilityPassword пароль обязателен, если его ещё
+	// нет; новый пароль делает недействительными выданные ранее токены разблокировки
+	SetVisibility(ctx context.Context, userID, id uuid.UUID, version uint, input SetVisibilityInput) (entity.Wishlist, error)
+	// SetSlug задаёт вишлисту человекочитаемый адрес. Кириллица транслитерируется; прежний slug
+	// продолжает вести на вишлист и удерживается за ним, пока его не займёт другой
+	SetSlug(ctx context.Context, userID, id uuid.UUID, version uint, input SetSlugInput) (entity.Wishlist, error)
+	// SetEvent задаёт дату события, её часовой пояс и повтор. Перенос даты архивного вишлиста
+	// в будущее возвращает его из архива, если следующего выпуска ещё нет
+	SetEvent(ctx context.Context, userID, id uuid.UUID, version uint, input SetEventInput) (entity.Wishlist, error)
+	// ArchivePastEvents архивирует вишлисты, чей день события закончился к now. Для ежегодных
+	// событий создаётся выпуск следующего года с неподаренными подарками. Возвращает число архивированных
+	ArchivePastEvents(ctx context.Context, now time.Time) (int, error)
+	// Unlock проверяет пароль вишлиста и выдаёт токен разблокировки. Частые неудачи
+	// с одного IP блокируются (*LockoutError)
+	Unlock(ctx context.Context, id uuid.UUID, password string, client ClientInfo) (WishlistUnlock, error)
+	// Delete переносит вишлист в корзину, откуда его можно восстановить до окончательного удаления
+	Delete(ctx context.Context, userID, id uuid.UUID, version uint) error
+	// GetTrash возвращает корзину пользователя, удалённое последним — первым
+	GetTrash(ctx context.Context, userID uuid.UUID) (Trash, error)
+	// Restore возвращает вишлист из корзины; восстановить его может только владелец
+	Restore(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error)
+	// GetCollaborators доступен владельцу и соавторам вишлиста
+	GetCollaborators(ctx context.Context, userID, id uuid.UUID) ([]entity.Collaborator, error)
+	// AddCollaborator приглашает пользователя по handle: соавтором он станет, только приняв приглашение.
+	// Повторное приглашение меняет роль
+	AddCollaborator(ctx context.Context, userID, id uuid.UUID, handle, role string) (entity.Collaborator, error)
+	SetCollaboratorRole(ctx context.Context, userID, id, collaboratorID uuid.UUID, role string) (entity.Collaborator, error)
+	// RemoveCollaborator — владелец убирает любого соавтора, соавтор может убрать себя сам
+	RemoveCollaborator(ctx context.Context, userID, id, collaboratorID uuid.UUID) error
+	CreateInvite(ctx context.Context, userID, id uuid.UUID, role string) (WishlistInviteLink, error)
+	// AcceptInvite гасит приглашение и делает пользователя соавтором. Роль редактора
+	// приглашением на просмотр не понижается
+	AcceptInvite(ctx context.Context, userID uuid.UUID, token string) (entity.Wishlist, error)
+	// GetPendingCollaborations возвращает вишлисты, куда пользователя пригласили по handle, с предложенной ролью
+	GetPendingCollaborations(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error)
+	AcceptCollaboration(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error)
+	DeclineCollaboration(ctx context.Context, userID, id uuid.UUID) error
 }
 
 // PresentUseCase — бизнес-логика подарков
 type PresentUseCase interface {
-	Create(ctx context.Context, wishlistID uuid.UUID, input CreatePresentInput) (entity.Present, error)
-	GetByID(ctx context.Context, id uuid.UUID) (entity.Present, error)
-	GetAllByWishlist(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error)
-	Update(ctx context.Context, id uuid.UUID, input CreatePresentInput) (entity.Present, error)
-	Delete(ctx context.Context, wishlistID, id uuid.UUID) error
-	Reserve(ctx context.Context, id uuid.UUID) error
-	Release(ctx context.Context, id uuid.UUID) error
+	Create(ctx context.Context, userID, wishlistID uuid.UUID, input CreatePresentInput) (entity.Present, error)
+	GetByID(ctx context.Context, viewer Viewer, id uuid.UUID) (entity.Present, error)
+	GetAllByWishlist(ctx context.Context, viewer Viewer, wishlistID uuid.UUID) ([]entity.Present, error)
+	// Update и Delete правят подарок только версии version, иначе возвращают *StalePresentError
+	Update(ctx context.Context, userID, id uuid.UUID, version uint, input CreatePresentInput) (entity.Present, error)
+	// Delete переносит подарок в корзину
+	Delete(ctx context.Context, userID, wishlistID, id uuid.UUID, version uint) error
+	// Restore возвращает подарок из корзины, если его вишлист не удалён
+	Restore(ctx context.Context, userID, wishlistID, id uuid.UUID) (entity.Present, error)
+	// Reserve и Release доступны анонимно, но только тем, кому виден вишлист подарка:
+	// по подарку скрытого вишлиста нельзя узнать, что он существует
+	Reserve(ctx context.Context, viewer Viewer, id uuid.UUID) error
+	Release(ctx context.Context, viewer Viewer, id uuid.UUID) error
+}
+
+// Action — действие над вишлистом, право на которое проверяет AccessPolicy
+type Action int
+
+const (
+	ActionEdit   Action = iota // изменение полей, блоков и подарков вишлиста — владелец и редакторы
+	ActionDelete               // удаление вишлиста — только владелец
+	ActionView                 // просмотр вишлиста и его подарков, в том числе анонимный (userID = uuid.Nil), без токенов разблокировки
+	ActionManage               // видимость, адрес, показ в профиле, соавторы и копирование — только владелец
+)
+
+// Viewer — тот, кто смотрит вишлист: пользователь (uuid.Nil — аноним) и токены разблокировки
+// защищённых паролем вишлистов, которые он предъявил
+type Viewer struct {
+	UserID       uuid.UUID
+	UnlockTokens []string
+}
+
+// AccessPolicy — проверка прав пользователя на вишлисты и подарки с учётом ролей соавторов.
+// Возвращает ErrNotFound, если объекта нет, и ErrForbidden, если действие запрещено.
+// Скрытый от пользователя вишлист для ActionView неотличим от несуществующего — ErrNotFound.
+// Возвращаемый вишлист содержит в Role роль пользователя, если она есть.
+type AccessPolicy interface {
+	AuthorizeWishlist(ctx context.Context, userID, wishlistID uuid.UUID, action Action) (entity.Wishlist, error)
+	AuthorizePresent(ctx context.Context, userID, presentID uuid.UUID, action Action) (entity.Present, error)
+	// CheckWishlist проверяет право на уже загруженный вишлист
+	CheckWishlist(ctx context.Context, w entity.Wishlist, userID uuid.UUID, action Action) error
+	// ViewWishlist, ViewPresent и CheckView — просмотр с учётом токенов разблокировки зрителя.
+	// Защищённый паролем вишлист без действующего токена — *WishlistLockedError.
+	// CheckView возвращает роль зрителя в вишлисте (пустая — посторонний)
+	ViewWishlist(ctx context.Context, viewer Viewer, wishlistID uuid.UUID) (entity.Wishlist, error)
+	ViewPresent(ctx context.Context, viewer Viewer, presentID uuid.UUID) (entity.Present, error)
+	CheckView(ctx context.Context, w entity.Wishlist, viewer Viewer) (role string, err error)
+	// IssueUnlockToken выдаёт токен разблокировки вишлиста; пароль проверяет вызывающий.
+	// Токен привязан к текущему паролю и перестаёт действовать после его смены
+	IssueUnlockToken(w entity.Wishlist, now time.Time) (token string, expiresAt time.Time)
 }
 
 // UploadUseCase — загрузка файлов
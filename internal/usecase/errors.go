@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+)
+
+// Типизированные ошибки бизнес-логики, которые HTTP-слой маппит в статусы
+var (
+	ErrForbidden = errors.New("forbidden")
+	ErrNotFound  = errors.New("not found")
+	ErrConflict  = errors.New("conflict")
+	// ErrStale — правка основана на устаревшей версии: запись успели изменить в другой вкладке или другие соавторы
+	ErrStale = errors.New("данные уже изменились, обновите страницу")
+
+	ErrSessionRevoked      = errors.New("session revoked or expired")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrInvalidToken — одноразовый токен из письма или приглашения не найден, истёк или уже использован
+	ErrInvalidToken  = errors.New("ссылка недействительна или устарела")
+	ErrWrongPassword = errors.New("неверный текущий пароль")
+	// ErrWrongWishlistPassword — неверный пароль защищённого вишлиста
+	ErrWrongWishlistPassword = errors.New("неверный пароль")
+	ErrInvalidEmail          = errors.New("некорректный email")
+	ErrInvalidMFA            = errors.New("неверный код подтверждения")
+	// ErrInvalidAccessToken — персональный токен не найден или истёк
+	ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+	// ErrLastLoginMethod — операция оставила бы аккаунт без единого способа входа
+	ErrLastLoginMethod = errors.New("нельзя удалить последний способ входа")
+
+	// ErrBanned — аккаунт заблокирован модератором
+	ErrBanned = errors.New("аккаунт заблокирован")
+
+	ErrTooManyAttempts = errors.New("слишком много неудачных попыток входа, попробуйте позже")
+	// ErrLinkCooldown — письмо со ссылкой уже отправлено недавно, повторить можно после паузы
+	ErrLinkCooldown = errors.New("письмо уже отправлено, повторите запрос через минуту")
+
+	// ErrReauthRequired — для опасной операции нужно заново подтвердить пароль или войти
+	ErrReauthRequired = errors.New("подтвердите пароль или войдите заново")
+)
+
+// WishlistLockedError — вишлист защищён паролем, а у зрителя нет действующего токена разблокировки.
+// WishlistID нужен клиенту, открывшему вишлист по короткой ссылке, чтобы запросить разблокировку
+type WishlistLockedError struct {
+	WishlistID uuid.UUID
+}
+
+func (e *WishlistLockedError) Error() string { return "вишлист защищён паролем" }
+
+func (e *WishlistLockedError) Unwrap() error { return ErrForbidden }
+
+// StaleWishlistError — вишлист изменили после того, как клиент получил версию, которую правит.
+// Current — актуальное состояние, чтобы клиент мог свести правки без лишнего запроса
+type StaleWishlistError struct {
+	Current entity.Wishlist
+}
+
+func (e *StaleWishlistError) Error() string { return ErrStale.Error() }
+
+func (e *StaleWishlistError) Unwrap() error { return ErrStale }
+
+// StalePresentError — то же для подарка
+type StalePresentError struct {
+	Current entity.Present
+}
+
+func (e *StalePresentError) Error() string { return ErrStale.Error() }
+
+func (e *StalePresentError) Unwrap() error { return ErrStale }
+
+// LockoutError — вход временно заблокирован после серии неудачных попыток
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string { return ErrTooManyAttempts.Error() }
+
+func (e *LockoutError) Unwrap() error { return ErrTooManyAttempts }
@@ -1,11 +1,21 @@
 package usecase
 
 const (
-	MaxWishlistsPerUser    = 20
-	MaxPresentsPerWishlist = 100
-	MaxBlocksPerWishlist   = 100
-	MaxBulkUploadFiles     = 10
-	MaxFileSize            = 10 * 1024 * 1024 // 10MB
+	MaxWishlistsPerUser     = 20
+	MaxPresentsPerWishlist  = 100
+	MaxCollaborators        = 20 // соавторов на вишлист
+	MaxBlocksPerWishlist    = 100
+	MaxRevisionsPerWishlist = 50 // ревизий блоков и настроек в истории вишлиста
+	MaxBulkUploadFiles      = 10
+	MaxFileSize             = 10 * 1024 * 1024 // 10MB
+	MaxAccessTokensPerUser  = 20
+	MaxBanReasonLen         = 500
+	MinHandleLen            = 3
+	MaxHandleLen            = 30
+	MinSlugLen              = 3
+	MaxSlugLen              = 60
+	MinWishlistPasswordLen  = 4
+	MaxWishlistPasswordLen  = 72
 
 	MaxTitleLen       = 200
 	MaxDescriptionLen = 2000
@@ -17,25 +17,32 @@ import (
 	minioPkg "main/pkg/minio"
 )
 
+var errAlreadyReserved = errors.New("упс... подарок уже был забронирован, пожалуйста перезагрузите страницу")
+
 type presentUseCase struct {
 	presentRepo  repo.PresentRepo
 	wishlistRepo repo.WishlistRepo
 	fileStorage  minioPkg.FileStorage
 	metaRepo     repo.PresentMetaRepo
+	policy       usecase.AccessPolicy
 }
 
-func New(presentRepo repo.PresentRepo, wishlistRepo repo.WishlistRepo, fileStorage minioPkg.FileStorage, metaRepo repo.PresentMetaRepo) usecase.PresentUseCase {
+func New(presentRepo repo.PresentRepo, wishlistRepo repo.WishlistRepo, fileStorage minioPkg.FileStorage, metaRepo repo.PresentMetaRepo, policy usecase.AccessPolicy) usecase.PresentUseCase {
 	return &presentUseCase{
 		presentRepo:  presentRepo,
 		wishlistRepo: wishlistRepo,
 		fileStorage:  fileStorage,
 		metaRepo:     metaRepo,
+		policy:       policy,
 	}
 }
 
-func (uc *presentUseCase) Create(ctx context.Context, wishlistID uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
-	if _, err := uc.wishlistRepo.GetByID(ctx, wishlistID); err != nil {
-		return entity.Present{}, errors.New("вишлист с таким ID не существует")
+func (uc *presentUseCase) Create(ctx context.Context, userID, wishlistID uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, wishlistID, usecase.ActionEdit); err != nil {
+		if errors.Is(err, usecase.ErrNotFound) {
+			return entity.Present{}, fmt.Errorf("вишлист с таким ID не существует: %w", err)
+		}
+		return entity.Present{}, err
 	}
 
 	count, err := uc.presentRepo.CountByWishlistID(ctx, wishlistID)
@@ -95,22 +102,28 @@ func (uc *presentUseCase) Create(ctx context.Context, wishlistID uuid.UUID, inpu
 	return p, nil
 }
 
-func (uc *presentUseCase) GetByID(ctx context.Context, id uuid.UUID) (entity.Present, error) {
-	return uc.presentRepo.GetByID(ctx, id)
+func (uc *presentUseCase) GetByID(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) (entity.Present, error) {
+	return uc.policy.ViewPresent(ctx, viewer, id)
 }
 
-func (uc *presentUseCase) GetAllByWishlist(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error) {
+func (uc *presentUseCase) GetAllByWishlist(ctx context.Context, viewer usecase.Viewer, wishlistID uuid.UUID) ([]entity.Present, error) {
+	if _, err := uc.policy.ViewWishlist(ctx, viewer, wishlistID); err != nil {
+		return nil, err
+	}
 	return uc.presentRepo.GetAllByWishlistID(ctx, wishlistID)
 }
 
-func (uc *presentUseCase) Update(ctx context.Context, id uuid.UUID, input usecase.CreatePresentInput) (entity.Present, error) {
+func (uc *presentUseCase) Update(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.CreatePresentInput) (entity.Present, error) {
 	if err := validatePresentFields(input.Title, input.Description, input.Link, input.CoverURL); err != nil {
 		return entity.Present{}, err
 	}
 
-	p, err := uc.presentRepo.GetByID(ctx, id)
+	p, err := uc.policy.AuthorizePresent(ctx, userID, id, usecase.ActionEdit)
 	if err != nil {
-		return entity.Present{}, fmt.Errorf("present not found: %w", err)
+		return entity.Present{}, err
+	}
+	if p.Version != version {
+		return entity.Present{}, &usecase.StalePresentError{Current: p}
 	}
 
 	p.Title = input.Title
@@ -129,7 +142,7 @@ func (uc *presentUseCase) Update(ctx context.Context, id uuid.UUID, input usecas
 	}
 	p.Cover = coverURL
 
-	if err := uc.presentRepo.Update(ctx, p); err != nil {
+	if err := uc.update(ctx, &p); err != nil {
 		return entity.Present{}, fmt.Errorf("update present: %w", err)
 	}
 
@@ -150,9 +163,22 @@ func (uc *presentUseCase) Update(ctx context.Context, id uuid.UUID, input usecas
 	return p, nil
 }
 
-func (uc *presentUseCase) Delete(ctx context.Context, wishlistID, id uuid.UUID) error {
-	if err := uc.presentRepo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("delete present: %w", err)
+func (uc *presentUseCase) Delete(ctx context.Context, userID, wishlistID, id uuid.UUID, version uint) error {
+	p, err := uc.policy.AuthorizePresent(ctx, userID, id, usecase.ActionEdit)
+	if err != nil {
+		return err
+	}
+	// Подарок из чужого вишлиста не удаляем: иначе уменьшится счётчик не того вишлиста
+	if p.WishlistID != wishlistID {
+		return fmt.Errorf("present %w in wishlist %s", usecase.ErrNotFound, wishlistID)
+	}
+	if p.Version != version {
+		return &usecase.StalePresentError{Current: p}
+	}
+	// Счётчик уменьшаем, только если подарок действительно попал в корзину: повторное удаление
+	// его не трогает
+	if err := uc.presentRepo.Delete(ctx, id, version, time.Now()); err != nil {
+		return fmt.Errorf("delete present: %w", uc.staleOr(ctx, p, err))
 	}
 	if err := uc.wishlistRepo.DecrementPresentsCount(ctx, wishlistID); err != nil {
 		return fmt.Errorf("decrement presents count: %w", err)
@@ -160,25 +186,75 @@ func (uc *presentUseCase) Delete(ctx context.Context, wishlistID, id uuid.UUID)
 	return nil
 }
 
-func (uc *presentUseCase) Reserve(ctx context.Context, id uuid.UUID) error {
-	p, err := uc.presentRepo.GetByID(ctx, id)
+func (uc *presentUseCase) Restore(ctx context.Context, userID, wishlistID, id uuid.UUID) (entity.Present, error) {
+	p, err := uc.presentRepo.GetDeletedByID(ctx, id)
+	if err != nil || p.WishlistID != wishlistID {
+		return entity.Present{}, fmt.Errorf("present %w in trash", usecase.ErrNotFound)
+	}
+	// Пока вишлист в корзине, политика его не найдёт: сначала восстанавливают вишлист
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, wishlistID, usecase.ActionEdit); err != nil {
+		return entity.Present{}, err
+	}
+	count, err := uc.presentRepo.CountByWishlistID(ctx, wishlistID)
 	if err != nil {
-		return fmt.Errorf("present not found: %w", err)
+		return entity.Present{}, fmt.Errorf("count presents: %w", err)
+	}
+	if count >= usecase.MaxPresentsPerWishlist {
+		return entity.Present{}, errors.New("достигнут лимит подарков (100)")
+	}
+	if err := uc.presentRepo.Restore(ctx, id); err != nil {
+		return entity.Present{}, fmt.Errorf("present %w in trash: %v", usecase.ErrNotFound, err)
+	}
+	if err := uc.wishlistRepo.IncrementPresentsCount(ctx, wishlistID); err != nil {
+		return entity.Present{}, fmt.Errorf("increment presents count: %w", err)
+	}
+	p.DeletedAt = nil
+	return p, nil
+}
+
+func (uc *presentUseCase) Reserve(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) error {
+	p, err := uc.policy.ViewPresent(ctx, viewer, id)
+	if err != nil {
+		return err
 	}
 	if p.Reserved {
-		return errors.New("упс... подарок уже был забронирован, пожалуйста перезагрузите страницу")
+		return errAlreadyReserved
 	}
 	p.Reserved = true
-	return uc.presentRepo.Update(ctx, p)
+	// Из двух одновременных броней условная запись пропустит только одну
+	err = uc.update(ctx, &p)
+	var stale *usecase.StalePresentError
+	if errors.As(err, &stale) && stale.Current.Reserved {
+		return errAlreadyReserved
+	}
+	return err
 }
 
-func (uc *presentUseCase) Release(ctx context.Context, id uuid.UUID) error {
-	p, err := uc.presentRepo.GetByID(ctx, id)
+func (uc *presentUseCase) Release(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) error {
+	p, err := uc.policy.ViewPresent(ctx, viewer, id)
 	if err != nil {
-		return fmt.Errorf("present not found: %w", err)
+		return err
 	}
 	p.Reserved = false
-	return uc.presentRepo.Update(ctx, p)
+	return uc.update(ctx, &p)
+}
+
+// update записывает подарок и увеличивает его версию
+func (uc *presentUseCase) update(ctx context.Context, p *entity.Present) error {
+	if err := uc.presentRepo.Update(ctx, *p); err != nil {
+		return uc.staleOr(ctx, *p, err)
+	}
+	p.Version++
+	return nil
+}
+
+// staleOr возвращает *usecase.StalePresentError, если подарок успели изменить между чтением и записью
+func (uc *presentUseCase) staleOr(ctx context.Context, p entity.Present, err error) error {
+	current, getErr := uc.presentRepo.GetByID(ctx, p.ID)
+	if getErr != nil || current.Version == p.Version {
+		return err
+	}
+	return &usecase.StalePresentError{Current: current}
 }
 
 func (uc *presentUseCase) resolveCover(data []byte, name, url string) (string, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -11,15 +12,26 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"main/internal/entity"
+	"main/internal/repo"
 	"main/internal/usecase"
+	"main/internal/usecase/access"
 	presentUC "main/internal/usecase/present"
 	mockminio "main/mock/minio"
 	mockrepo "main/mock/repo"
 )
 
+const testSecret = "test-secret"
+
 func newPresentUC(pr *mockrepo.MockPresentRepo, wr *mockrepo.MockWishlistRepo, fs *mockminio.MockFileStorage) usecase.PresentUseCase {
 	mr := &mockrepo.MockPresentMetaRepo{}
-	return presentUC.New(pr, wr, fs, mr)
+	return presentUC.New(pr, wr, fs, mr, access.New(wr, pr, noCollaborators(), testSecret))
+}
+
+// noCollaborators — у вишлистов нет соавторов
+func noCollaborators() *mockrepo.MockCollaboratorRepo {
+	cr := &mockrepo.MockCollaboratorRepo{}
+	cr.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(entity.Collaborator{}, repo.ErrNotFound)
+	return cr
 }
 
 func TestParsePrice_Empty(t *testing.T) {
@@ -29,12 +41,13 @@ func TestParsePrice_Empty(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 	pr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
 
-	p, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{
+	p, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{
 		Title:    "Gift",
 		PriceStr: "",
 	})
@@ -49,12 +62,13 @@ func TestParsePrice_CommaSpaces(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 	pr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
 
-	p, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{
+	p, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{
 		Title:    "Gift",
 		PriceStr: "1 500,50",
 	})
@@ -70,10 +84,11 @@ func TestParsePrice_Invalid(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{
 		Title:    "Gift",
 		PriceStr: "abc",
 	})
@@ -87,10 +102,11 @@ func TestReserve_AlreadyReserved(t *testing.T) {
 	fs := &mockminio.MockFileStorage{}
 	uc := newPresentUC(pr, wr, fs)
 
-	id := uuid.New()
-	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, Reserved: true}, nil)
+	id, wid := uuid.New(), uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Reserved: true}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
 
-	err := uc.Reserve(context.Background(), id)
+	err := uc.Reserve(context.Background(), usecase.Viewer{}, id)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "уже был забронирован")
 }
@@ -101,13 +117,14 @@ func TestReserve_Success(t *testing.T) {
 	fs := &mockminio.MockFileStorage{}
 	uc := newPresentUC(pr, wr, fs)
 
-	id := uuid.New()
-	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, Reserved: false}, nil)
+	id, wid := uuid.New(), uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Reserved: false}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
 	pr.On("Update", mock.Anything, mock.MatchedBy(func(p entity.Present) bool {
 		return p.Reserved == true
 	})).Return(nil)
 
-	err := uc.Reserve(context.Background(), id)
+	err := uc.Reserve(context.Background(), usecase.Viewer{}, id)
 	require.NoError(t, err)
 	pr.AssertExpectations(t)
 }
@@ -118,13 +135,86 @@ func TestRelease_Success(t *testing.T) {
 	fs := &mockminio.MockFileStorage{}
 	uc := newPresentUC(pr, wr, fs)
 
-	id := uuid.New()
-	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, Reserved: true}, nil)
+	id, wid := uuid.New(), uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Reserved: true}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
 	pr.On("Update", mock.Anything, mock.MatchedBy(func(p entity.Present) bool {
 		return p.Reserved == false
 	})).Return(nil)
 
-	err := uc.Release(context.Background(), id)
+	err := uc.Release(context.Background(), usecase.Viewer{}, id)
+	require.NoError(t, err)
+	pr.AssertExpectations(t)
+}
+
+func TestReserve_UnpublishedWishlist(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newPresentUC(pr, wr, fs)
+
+	id, wid := uuid.New(), uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New(), Unpublished: true}, nil)
+
+	err := uc.Reserve(context.Background(), usecase.Viewer{}, id)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+	pr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReserve_HiddenWishlist(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility string
+		viewer     usecase.Viewer
+		check      func(t *testing.T, err error)
+	}{
+		{
+			name:       "private",
+			visibility: entity.VisibilityPrivate,
+			check:      func(t *testing.T, err error) { require.ErrorIs(t, err, usecase.ErrNotFound) },
+		},
+		{
+			name:       "password without token",
+			visibility: entity.VisibilityPassword,
+			viewer:     usecase.Viewer{UnlockTokens: []string{"garbage"}},
+			check: func(t *testing.T, err error) {
+				var locked *usecase.WishlistLockedError
+				require.ErrorAs(t, err, &locked)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &mockrepo.MockPresentRepo{}
+			wr := &mockrepo.MockWishlistRepo{}
+			uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+			id, wid := uuid.New(), uuid.New()
+			pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+			wr.On("GetByID", mock.Anything, wid).
+				Return(entity.Wishlist{ID: wid, UserID: uuid.New(), Visibility: tt.visibility, PasswordHash: "hash"}, nil)
+
+			tt.check(t, uc.Reserve(context.Background(), tt.viewer, id))
+			tt.check(t, uc.Release(context.Background(), tt.viewer, id))
+			pr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReserve_UnlockedWishlist(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+	id, wid := uuid.New(), uuid.New()
+	w := entity.Wishlist{ID: wid, UserID: uuid.New(), Visibility: entity.VisibilityPassword, PasswordHash: "hash"}
+	token, _ := access.New(wr, pr, noCollaborators(), testSecret).IssueUnlockToken(w, time.Now())
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(w, nil)
+	pr.On("Update", mock.Anything, mock.MatchedBy(func(p entity.Present) bool { return p.Reserved })).Return(nil)
+
+	err := uc.Reserve(context.Background(), usecase.Viewer{UnlockTokens: []string{token}}, id)
 	require.NoError(t, err)
 	pr.AssertExpectations(t)
 }
@@ -136,9 +226,10 @@ func TestCreate_WishlistNotFound(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{}, errors.New("not found"))
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{}, repo.ErrNotFound)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{Title: "Gift"})
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{Title: "Gift"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "не существует")
 }
@@ -150,12 +241,13 @@ func TestCreate_Success(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 	pr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{Title: "Gift"})
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{Title: "Gift"})
 	require.NoError(t, err)
 	pr.AssertCalled(t, "Create", mock.Anything, mock.Anything)
 	wr.AssertCalled(t, "IncrementPresentsCount", mock.Anything, wid)
@@ -169,12 +261,15 @@ func TestDelete_Success(t *testing.T) {
 
 	id := uuid.New()
 	wid := uuid.New()
-	pr.On("Delete", mock.Anything, id).Return(nil)
+	ownerID := uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+	pr.On("Delete", mock.Anything, id, uint(0), mock.Anything).Return(nil)
 	wr.On("DecrementPresentsCount", mock.Anything, wid).Return(nil)
 
-	err := uc.Delete(context.Background(), wid, id)
+	err := uc.Delete(context.Background(), ownerID, wid, id, 0)
 	require.NoError(t, err)
-	pr.AssertCalled(t, "Delete", mock.Anything, id)
+	pr.AssertCalled(t, "Delete", mock.Anything, id, uint(0), mock.Anything)
 	wr.AssertCalled(t, "DecrementPresentsCount", mock.Anything, wid)
 }
 
@@ -183,10 +278,11 @@ func TestCreate_WithSource_SavesMeta(t *testing.T) {
 	wr := &mockrepo.MockWishlistRepo{}
 	fs := &mockminio.MockFileStorage{}
 	mr := &mockrepo.MockPresentMetaRepo{}
-	uc := presentUC.New(pr, wr, fs, mr)
+	uc := presentUC.New(pr, wr, fs, mr, access.New(wr, pr, noCollaborators(), testSecret))
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 	pr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
@@ -194,7 +290,7 @@ func TestCreate_WithSource_SavesMeta(t *testing.T) {
 		return m.Source == "ozon" && m.OriginalURL == "https://ozon.ru/product/1"
 	})).Return(nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{
 		Title:       "Gift",
 		Source:      "ozon",
 		OriginalURL: "https://ozon.ru/product/1",
@@ -208,15 +304,16 @@ func TestCreate_WithoutSource_SkipsMeta(t *testing.T) {
 	wr := &mockrepo.MockWishlistRepo{}
 	fs := &mockminio.MockFileStorage{}
 	mr := &mockrepo.MockPresentMetaRepo{}
-	uc := presentUC.New(pr, wr, fs, mr)
+	uc := presentUC.New(pr, wr, fs, mr, access.New(wr, pr, noCollaborators(), testSecret))
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 	pr.On("Create", mock.Anything, mock.Anything).Return(nil)
 	wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{Title: "Gift"})
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{Title: "Gift"})
 	require.NoError(t, err)
 	mr.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
 }
@@ -228,10 +325,11 @@ func TestCreate_PresentLimitExceeded(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(100), nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{Title: "Gift"})
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{Title: "Gift"})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "лимит подарков")
 }
@@ -243,10 +341,11 @@ func TestCreate_PresentTitleTooLong(t *testing.T) {
 	uc := newPresentUC(pr, wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	pr.On("CountByWishlistID", mock.Anything, wid).Return(int64(0), nil)
 
-	_, err := uc.Create(context.Background(), wid, usecase.CreatePresentInput{
+	_, err := uc.Create(context.Background(), ownerID, wid, usecase.CreatePresentInput{
 		Title: string(make([]byte, 201)),
 	})
 	require.Error(t, err)
@@ -261,9 +360,206 @@ func TestUpdate_PresentTitleTooLong(t *testing.T) {
 
 	// Validation runs before GetByID, so no mock setup needed.
 	id := uuid.New()
-	_, err := uc.Update(context.Background(), id, usecase.CreatePresentInput{
+	_, err := uc.Update(context.Background(), uuid.New(), id, 0, usecase.CreatePresentInput{
 		Title: string(make([]byte, 201)),
 	})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "title")
 }
+
+func TestCreate_NotOwner_Forbidden(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newPresentUC(pr, wr, fs)
+
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	_, err := uc.Create(context.Background(), uuid.New(), wid, usecase.CreatePresentInput{Title: "Gift"})
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	pr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdate_NotOwner_Forbidden(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newPresentUC(pr, wr, fs)
+
+	id := uuid.New()
+	wid := uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	_, err := uc.Update(context.Background(), uuid.New(), id, 0, usecase.CreatePresentInput{Title: "Gift"})
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	pr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDelete_NotOwner_Forbidden(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newPresentUC(pr, wr, fs)
+
+	id := uuid.New()
+	wid := uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	err := uc.Delete(context.Background(), uuid.New(), wid, id, 0)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	pr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	wr.AssertNotCalled(t, "DecrementPresentsCount", mock.Anything, mock.Anything)
+}
+
+func TestDelete_PresentFromOtherWishlist_NotFound(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newPresentUC(pr, wr, fs)
+
+	id := uuid.New()
+	wid := uuid.New()
+	otherWid := uuid.New()
+	ownerID := uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+
+	err := uc.Delete(context.Background(), ownerID, otherWid, id, 0)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+	pr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_AlreadyDeleted_KeepsCount(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+	id := uuid.New()
+	wid := uuid.New()
+	ownerID := uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+	// Параллельный запрос успел перенести подарок в корзину
+	pr.On("Delete", mock.Anything, id, uint(0), mock.Anything).Return(errors.New("record not found"))
+
+	err := uc.Delete(context.Background(), ownerID, wid, id, 0)
+	require.Error(t, err)
+	wr.AssertNotCalled(t, "DecrementPresentsCount", mock.Anything, mock.Anything)
+}
+
+func TestRestore(t *testing.T) {
+	ownerID := uuid.New()
+	wid := uuid.New()
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		wishlistID  uuid.UUID
+		wishlistErr error // вишлист в корзине политика не находит
+		count       int64
+		restoreErr  error
+		wantErr     error
+	}{
+		{name: "success", wishlistID: wid},
+		{name: "other wishlist", wishlistID: uuid.New(), wantErr: usecase.ErrNotFound},
+		{name: "wishlist in trash", wishlistID: wid, wishlistErr: repo.ErrNotFound, wantErr: usecase.ErrNotFound},
+		{name: "limit reached", wishlistID: wid, count: usecase.MaxPresentsPerWishlist},
+		{name: "restored concurrently", wishlistID: wid, restoreErr: errors.New("record not found"), wantErr: usecase.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &mockrepo.MockPresentRepo{}
+			wr := &mockrepo.MockWishlistRepo{}
+			uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+			p := entity.Present{ID: uuid.New(), WishlistID: wid, DeletedAt: &deletedAt}
+			pr.On("GetDeletedByID", mock.Anything, p.ID).Return(p, nil)
+			wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, tt.wishlistErr)
+			pr.On("CountByWishlistID", mock.Anything, wid).Return(tt.count, nil)
+			pr.On("Restore", mock.Anything, p.ID).Return(tt.restoreErr)
+			wr.On("IncrementPresentsCount", mock.Anything, wid).Return(nil)
+
+			restored, err := uc.Restore(context.Background(), ownerID, tt.wishlistID, p.ID)
+			if tt.wantErr != nil || tt.count > 0 {
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				wr.AssertNotCalled(t, "IncrementPresentsCount", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, restored.DeletedAt)
+			wr.AssertNumberOfCalls(t, "IncrementPresentsCount", 1)
+		})
+	}
+}
+
+func TestUpdate_Collaborators(t *testing.T) {
+	tests := []struct {
+		role    string
+		wantErr error
+	}{
+		{role: entity.WishlistRoleEditor},
+		{role: entity.WishlistRoleViewer, wantErr: usecase.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			pr := &mockrepo.MockPresentRepo{}
+			wr := &mockrepo.MockWishlistRepo{}
+			cr := &mockrepo.MockCollaboratorRepo{}
+			uc := presentUC.New(pr, wr, &mockminio.MockFileStorage{}, &mockrepo.MockPresentMetaRepo{}, access.New(wr, pr, cr, testSecret))
+
+			id, wid, userID := uuid.New(), uuid.New(), uuid.New()
+			pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid}, nil)
+			wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+			cr.On("Get", mock.Anything, wid, userID).Return(entity.Collaborator{WishlistID: wid, UserID: userID, Role: tt.role}, nil)
+			pr.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			_, err := uc.Update(context.Background(), userID, id, 0, usecase.CreatePresentInput{Title: "Gift"})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				pr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			pr.AssertCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdate_StaleVersion(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+	id, wid, ownerID := uuid.New(), uuid.New(), uuid.New()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Title: "Чайник", Version: 2}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+
+	_, err := uc.Update(context.Background(), ownerID, id, 1, usecase.CreatePresentInput{Title: "Кофеварка"})
+	var stale *usecase.StalePresentError
+	require.ErrorAs(t, err, &stale)
+	assert.Equal(t, "Чайник", stale.Current.Title)
+	pr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReserve_Concurrent(t *testing.T) {
+	pr := &mockrepo.MockPresentRepo{}
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newPresentUC(pr, wr, &mockminio.MockFileStorage{})
+
+	id, wid := uuid.New(), uuid.New()
+	// Оба гостя прочитали свободный подарок, но первым записал другой
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Version: 1}, nil).Once()
+	pr.On("GetByID", mock.Anything, id).Return(entity.Present{ID: id, WishlistID: wid, Reserved: true, Version: 2}, nil)
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+	pr.On("Update", mock.Anything, mock.Anything).Return(errors.New("record not found"))
+
+	err := uc.Reserve(context.Background(), usecase.Viewer{}, id)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "уже был забронирован")
+}
@@ -0,0 +1,146 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/pkg/randtoken"
+)
+
+// accessTokenTouchInterval — last_used_at обновляется не чаще, чтобы не писать в базу на каждый запрос
+const accessTokenTouchInterval = time.Minute
+
+func (uc *userUseCase) CreateAccessToken(ctx context.Context, userID uuid.UUID, input usecase.CreateAccessTokenInput) (usecase.CreatedAccessToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return usecase.CreatedAccessToken{}, errors.New("укажите название токена")
+	}
+	if len([]rune(name)) > 100 {
+		return usecase.CreatedAccessToken{}, errors.New("название токена длиннее 100 символов")
+	}
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return usecase.CreatedAccessToken{}, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return usecase.CreatedAccessToken{}, errors.New("срок действия токена уже истёк")
+	}
+
+	existing, err := uc.accessTokenRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return usecase.CreatedAccessToken{}, fmt.Errorf("get access tokens: %w", err)
+	}
+	if len(existing) >= usecase.MaxAccessTokensPerUser {
+		return usecase.CreatedAccessToken{}, fmt.Errorf("достигнут лимит токенов (%d): %w", usecase.MaxAccessTokensPerUser, usecase.ErrConflict)
+	}
+
+	secret, err := randtoken.Generate()
+	if err != nil {
+		return usecase.CreatedAccessToken{}, fmt.Errorf("generate token: %w", err)
+	}
+	raw := entity.AccessTokenPrefix + secret
+	token := entity.AccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: randtoken.Hash(raw),
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.accessTokenRepo.Create(ctx, token); err != nil {
+		return usecase.CreatedAccessToken{}, fmt.Errorf("create access token: %w", err)
+	}
+	return usecase.CreatedAccessToken{AccessToken: token, Token: raw}, nil
+}
+
+func (uc *userUseCase) GetAccessTokens(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error) {
+	return uc.accessTokenRepo.GetAllByUserID(ctx, userID)
+}
+
+func (uc *userUseCase) RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	tokens, err := uc.accessTokenRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get access tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if t.ID == tokenID {
+			if err := uc.accessTokenRepo.Delete(ctx, tokenID); err != nil {
+				return fmt.Errorf("delete access token: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("access token %w", usecase.ErrNotFound)
+}
+
+// revokeAccessTokens удаляет все персональные токены пользователя
+func (uc *userUseCase) revokeAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	tokens, err := uc.accessTokenRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get access tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if err := uc.accessTokenRepo.Delete(ctx, t.ID); err != nil {
+			return fmt.Errorf("delete access token: %w", err)
+		}
+	}
+	return nil
+}
+
+func (uc *userUseCase) AuthenticateAccessToken(ctx context.Context, raw string) (entity.AccessToken, error) {
+	if !strings.HasPrefix(raw, entity.AccessTokenPrefix) {
+		return entity.AccessToken{}, usecase.ErrInvalidAccessToken
+	}
+	token, err := uc.accessTokenRepo.GetByHash(ctx, randtoken.Hash(raw))
+	if err != nil {
+		return entity.AccessToken{}, usecase.ErrInvalidAccessToken
+	}
+	now := time.Now()
+	if !token.Active(now) {
+		return entity.AccessToken{}, usecase.ErrInvalidAccessToken
+	}
+	user, err := uc.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return entity.AccessToken{}, usecase.ErrInvalidAccessToken
+	}
+	if user.Banned() {
+		return entity.AccessToken{}, usecase.ErrBanned
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= accessTokenTouchInterval {
+		if err := uc.accessTokenRepo.Touch(ctx, token.ID, now); err != nil {
+			log.Printf("access token %s: touch failed: %v", token.ID, err)
+		} else {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
+
+// normalizeScopes проверяет скоупы и убирает повторы, сохраняя порядок
+func normalizeScopes(scopes []string) ([]string, error) {
+	result := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		if !entity.ValidScope(s) {
+			return nil, fmt.Errorf("неизвестный скоуп %q", s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("укажите хотя бы один скоуп")
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package user_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/randtoken"
+)
+
+func TestCreateAccessToken_StoresOnlyHash(t *testing.T) {
+	atr := &mockrepo.MockAccessTokenRepo{}
+	uc := newTestUC(userUC.Deps{AccessTokenRepo: atr})
+	userID := uuid.New()
+
+	atr.On("GetAllByUserID", mock.Anything, userID).Return([]entity.AccessToken{}, nil)
+	var stored entity.AccessToken
+	atr.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(entity.AccessToken)
+	}).Return(nil)
+
+	created, err := uc.CreateAccessToken(context.Background(), userID, usecase.CreateAccessTokenInput{
+		Name:   " extension ",
+		Scopes: []string{entity.ScopeParse, entity.ScopeWishlistsRead, entity.ScopeParse},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, entity.AccessTokenPrefix))
+	assert.Equal(t, randtoken.Hash(created.Token), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, created.Token)
+	assert.Equal(t, "extension", stored.Name)
+	assert.Equal(t, []string{entity.ScopeParse, entity.ScopeWishlistsRead}, stored.Scopes)
+}
+
+func TestCreateAccessToken_Validation(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	cases := map[string]usecase.CreateAccessTokenInput{
+		"no name":       {Scopes: []string{entity.ScopeParse}},
+		"no scopes":     {Name: "script"},
+		"unknown scope": {Name: "script", Scopes: []string{"admin"}},
+		"expired":       {Name: "script", Scopes: []string{entity.ScopeParse}, ExpiresAt: &past},
+	}
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			uc := newTestUC(userUC.Deps{})
+			_, err := uc.CreateAccessToken(context.Background(), uuid.New(), input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAuthenticateAccessToken(t *testing.T) {
+	raw := entity.AccessTokenPrefix + "secret"
+	expired := time.Now().Add(-time.Minute)
+	recent := time.Now().Add(-10 * time.Second)
+
+	t.Run("active token is touched", func(t *testing.T) {
+		atr := &mockrepo.MockAccessTokenRepo{}
+		ur := &mockrepo.MockUserRepo{}
+		uc := newTestUC(userUC.Deps{AccessTokenRepo: atr, UserRepo: ur})
+		token := entity.AccessToken{ID: uuid.New(), UserID: uuid.New(), Scopes: []string{entity.ScopeParse}}
+		atr.On("GetByHash", mock.Anything, randtoken.Hash(raw)).Return(token, nil)
+		ur.On("GetByID", mock.Anything, token.UserID).Return(entity.User{ID: token.UserID}, nil)
+		atr.On("Touch", mock.Anything, token.ID, mock.Anything).Return(nil)
+
+		got, err := uc.AuthenticateAccessToken(context.Background(), raw)
+		require.NoError(t, err)
+		assert.Equal(t, token.UserID, got.UserID)
+		require.NotNil(t, got.LastUsedAt)
+		atr.AssertExpectations(t)
+	})
+
+	t.Run("recently used token is not touched again", func(t *testing.T) {
+		atr := &mockrepo.MockAccessTokenRepo{}
+		ur := &mockrepo.MockUserRepo{}
+		uc := newTestUC(userUC.Deps{AccessTokenRepo: atr, UserRepo: ur})
+		atr.On("GetByHash", mock.Anything, mock.Anything).Return(entity.AccessToken{ID: uuid.New(), LastUsedAt: &recent}, nil)
+		ur.On("GetByID", mock.Anything, mock.Anything).Return(entity.User{}, nil)
+
+		_, err := uc.AuthenticateAccessToken(context.Background(), raw)
+		require.NoError(t, err)
+		atr.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		atr := &mockrepo.MockAccessTokenRepo{}
+		uc := newTestUC(userUC.Deps{AccessTokenRepo: atr})
+		atr.On("GetByHash", mock.Anything, mock.Anything).Return(entity.AccessToken{ID: uuid.New(), ExpiresAt: &expired}, nil)
+
+		_, err := uc.AuthenticateAccessToken(context.Background(), raw)
+		assert.ErrorIs(t, err, usecase.ErrInvalidAccessToken)
+	})
+
+	t.Run("banned owner", func(t *testing.T) {
+		atr := &mockrepo.MockAccessTokenRepo{}
+		ur := &mockrepo.MockUserRepo{}
+		uc := newTestUC(userUC.Deps{AccessTokenRepo: atr, UserRepo: ur})
+		bannedAt := time.Now()
+		atr.On("GetByHash", mock.Anything, mock.Anything).Return(entity.AccessToken{ID: uuid.New()}, nil)
+		ur.On("GetByID", mock.Anything, mock.Anything).Return(entity.User{BannedAt: &bannedAt}, nil)
+
+		_, err := uc.AuthenticateAccessToken(context.Background(), raw)
+		assert.ErrorIs(t, err, usecase.ErrBanned)
+		atr.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("JWT is not looked up", func(t *testing.T) {
+		uc := newTestUC(userUC.Deps{})
+		_, err := uc.AuthenticateAccessToken(context.Background(), "eyJhbGciOi.payload.sig")
+		assert.ErrorIs(t, err, usecase.ErrInvalidAccessToken)
+	})
+}
+
+func TestRevokeAccessToken_OtherUsersToken(t *testing.T) {
+	atr := &mockrepo.MockAccessTokenRepo{}
+	uc := newTestUC(userUC.Deps{AccessTokenRepo: atr})
+	userID := uuid.New()
+	atr.On("GetAllByUserID", mock.Anything, userID).Return([]entity.AccessToken{{ID: uuid.New(), UserID: userID}}, nil)
+
+	err := uc.RevokeAccessToken(context.Background(), userID, uuid.New())
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+	atr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+)
+
+const (
+	// defaultDeletionGrace — сколько аккаунт ждёт окончательного удаления, если срок не задан
+	defaultDeletionGrace = 7 * 24 * time.Hour
+	// reauthMaxAge — насколько свежим должен быть вход, чтобы удалить аккаунт без пароля
+	reauthMaxAge = 10 * time.Minute
+)
+
+func (uc *userUseCase) DeleteAccount(ctx context.Context, userID, sessionID uuid.UUID, input usecase.DeleteAccountInput) (time.Time, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if err := uc.reauthenticate(ctx, &user, sessionID, input); err != nil {
+		return time.Time{}, err
+	}
+
+	scheduledAt := time.Now().Add(uc.deletionGrace)
+	user.DeletionScheduledAt = &scheduledAt
+	if err := uc.userRepo.UpdateColumns(ctx, user, repo.UserColumnDeletionScheduledAt); err != nil {
+		return time.Time{}, fmt.Errorf("update user: %w", err)
+	}
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, userID, uuid.Nil); err != nil {
+		return time.Time{}, fmt.Errorf("revoke sessions: %w", err)
+	}
+	// Персональные токены не переживают отмену удаления — их проще выпустить заново
+	if err := uc.revokeAccessTokens(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	log.Printf("account %s: deletion scheduled at %s", userID, scheduledAt.Format(time.RFC3339))
+	return scheduledAt, nil
+}
+
+// reauthenticate проверяет пароль и второй фактор, а у аккаунта без пароля — что вход был недавно
+func (uc *userUseCase) reauthenticate(ctx context.Context, user *entity.User, sessionID uuid.UUID, input usecase.DeleteAccountInput) error {
+	if user.Password != "" {
+		if err := uc.verifyPassword(ctx, *user, input.Password); err != nil {
+			return err
+		}
+	} else {
+		session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+		if err != nil || session.UserID != user.ID || time.Since(session.CreatedAt) > reauthMaxAge {
+			return usecase.ErrReauthRequired
+		}
+	}
+	if user.TOTPEnabled {
+		return uc.verifySecondFactor(ctx, user, input.Code)
+	}
+	return nil
+}
+
+// cancelDeletion снимает аккаунт с удаления — вызывается при входе в течение срока ожидания
+func (uc *userUseCase) cancelDeletion(ctx context.Context, user *entity.User) error {
+	if !user.DeletionScheduled() {
+		return nil
+	}
+	user.DeletionScheduledAt = nil
+	if err := uc.userRepo.UpdateColumns(ctx, *user, repo.UserColumnDeletionScheduledAt); err != nil {
+		return fmt.Errorf("cancel account deletion: %w", err)
+	}
+	log.Printf("account %s: deletion cancelled by login", user.ID)
+	return nil
+}
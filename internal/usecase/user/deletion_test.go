@@ -0,0 +1,107 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+func TestDeleteAccount_WithPassword(t *testing.T) {
+	hashed, _ := hasher.New().Hash("password123")
+
+	t.Run("wrong password", func(t *testing.T) {
+		user := &entity.User{ID: uuid.New(), Username: "alice", Password: hashed}
+		sr := &mockrepo.MockSessionRepo{}
+		uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}, SessionRepo: sr})
+
+		_, err := uc.DeleteAccount(context.Background(), user.ID, uuid.New(), usecase.DeleteAccountInput{Password: "wrong"})
+		require.ErrorIs(t, err, usecase.ErrWrongPassword)
+		assert.False(t, user.DeletionScheduled())
+		sr.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("schedules deletion and signs out everywhere", func(t *testing.T) {
+		user := &entity.User{ID: uuid.New(), Username: "alice", Password: hashed}
+		sr := &mockrepo.MockSessionRepo{}
+		sr.On("RevokeAllByUserID", mock.Anything, user.ID, uuid.Nil).Return(nil)
+		atr := &mockrepo.MockAccessTokenRepo{}
+		tokenID := uuid.New()
+		atr.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.AccessToken{{ID: tokenID, UserID: user.ID}}, nil)
+		atr.On("Delete", mock.Anything, tokenID).Return(nil)
+		uc := newTestUC(userUC.Deps{
+			UserRepo:        &singleUserRepo{user: user},
+			SessionRepo:     sr,
+			AccessTokenRepo: atr,
+			DeletionGrace:   48 * time.Hour,
+		})
+
+		scheduledAt, err := uc.DeleteAccount(context.Background(), user.ID, uuid.New(), usecase.DeleteAccountInput{Password: "password123"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(48*time.Hour), scheduledAt, time.Minute)
+		require.True(t, user.DeletionScheduled())
+		assert.Equal(t, scheduledAt, *user.DeletionScheduledAt)
+		sr.AssertExpectations(t)
+		atr.AssertExpectations(t)
+	})
+}
+
+func TestDeleteAccount_WithoutPasswordRequiresFreshLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		loginAgo  time.Duration
+		wantError error
+	}{
+		{name: "fresh session", loginAgo: time.Minute},
+		{name: "stale session", loginAgo: time.Hour, wantError: usecase.ErrReauthRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entity.User{ID: uuid.New(), Username: "tg_42"}
+			sessionID := uuid.New()
+			sr := &mockrepo.MockSessionRepo{}
+			sr.On("GetByID", mock.Anything, sessionID).Return(entity.Session{
+				ID:        sessionID,
+				UserID:    user.ID,
+				ExpiresAt: time.Now().Add(time.Hour),
+				CreatedAt: time.Now().Add(-tt.loginAgo),
+			}, nil)
+			sr.On("RevokeAllByUserID", mock.Anything, user.ID, uuid.Nil).Return(nil).Maybe()
+			atr := &mockrepo.MockAccessTokenRepo{}
+			atr.On("GetAllByUserID", mock.Anything, user.ID).Return([]entity.AccessToken{}, nil).Maybe()
+			uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}, SessionRepo: sr, AccessTokenRepo: atr})
+
+			_, err := uc.DeleteAccount(context.Background(), user.ID, sessionID, usecase.DeleteAccountInput{})
+			if tt.wantError != nil {
+				require.ErrorIs(t, err, tt.wantError)
+				assert.False(t, user.DeletionScheduled())
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, user.DeletionScheduled())
+		})
+	}
+}
+
+func TestLogin_CancelsScheduledDeletion(t *testing.T) {
+	hashed, _ := hasher.New().Hash("password123")
+	scheduledAt := time.Now().Add(24 * time.Hour)
+	user := &entity.User{ID: uuid.New(), Username: "alice", Password: hashed, DeletionScheduledAt: &scheduledAt}
+	sr := &mockrepo.MockSessionRepo{}
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+	uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}, SessionRepo: sr})
+
+	_, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.False(t, user.DeletionScheduled())
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/pkg/mailer"
+)
+
+const (
+	emailVerifyTTL = 24 * time.Hour
+	magicLinkTTL   = 15 * time.Minute
+)
+
+func (uc *userUseCase) RequestEmailVerification(ctx context.Context, userID uuid.UUID, email string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	if owner, err := uc.userRepo.GetByVerifiedEmail(ctx, email); err == nil && owner.ID != userID {
+		return fmt.Errorf("email уже используется: %w", usecase.ErrConflict)
+	}
+	// Пользователь вошёл, так что паузу можно не скрывать. Без неё эндпоинт рассылал бы письма
+	// на любые адреса без ограничений
+	ok, err := uc.acquireLinkCooldown(ctx, entity.TokenPurposeEmailVerify, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return usecase.ErrLinkCooldown
+	}
+
+	if err := uc.tokenRepo.InvalidateAll(ctx, userID, entity.TokenPurposeEmailVerify); err != nil {
+		return fmt.Errorf("invalidate verify tokens: %w", err)
+	}
+	// Адрес попадает в профиль только после подтверждения, до этого он живёт в токене
+	token, err := uc.issueToken(ctx, userID, entity.TokenPurposeEmailVerify, email, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	return uc.sendLink(ctx, email, "Подтверждение email", "/verify-email", token,
+		"Чтобы подтвердить адрес, перейдите по ссылке:", "Ссылка действует 24 часа.")
+}
+
+func (uc *userUseCase) VerifyEmail(ctx context.Context, token string) (entity.User, error) {
+	t, err := uc.consumeToken(ctx, entity.TokenPurposeEmailVerify, token)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	// Адрес мог успеть подтвердить другой пользователь
+	if owner, err := uc.userRepo.GetByVerifiedEmail(ctx, t.Payload); err == nil && owner.ID != t.UserID {
+		return entity.User{}, fmt.Errorf("email уже используется: %w", usecase.ErrConflict)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	user.Email = t.Payload
+	user.EmailVerified = true
+	if err := uc.userRepo.UpdateColumns(ctx, user, repo.UserColumnEmail, repo.UserColumnEmailVerified); err != nil {
+		return entity.User{}, fmt.Errorf("update user: %w", err)
+	}
+	return user, nil
+}
+
+func (uc *userUseCase) RequestMagicLink(ctx context.Context, email string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	user, err := uc.userRepo.GetByVerifiedEmail(ctx, email)
+	if err != nil {
+		// Не раскрываем, зарегистрирован ли адрес
+		return nil
+	}
+	if ok, err := uc.acquireLinkCooldown(ctx, entity.TokenPurposeMagicLink, user.ID); err != nil || !ok {
+		return err
+	}
+
+	token, err := uc.issueToken(ctx, user.ID, entity.TokenPurposeMagicLink, "", magicLinkTTL)
+	if err != nil {
+		return err
+	}
+
+	return uc.sendLink(ctx, user.Email, "Вход в аккаунт", "/magic-login", token,
+		"Чтобы войти без пароля, перейдите по ссылке:", "Ссылка одноразовая и действует 15 минут.")
+}
+
+func (uc *userUseCase) LoginWithMagicLink(ctx context.Context, token string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	t, err := uc.consumeToken(ctx, entity.TokenPurposeMagicLink, token)
+	if err != nil {
+		return usecase.AuthResult{}, err
+	}
+	user, err := uc.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	return uc.completeLogin(ctx, user, client)
+}
+
+// sendLink отправляет письмо со ссылкой на страницу фронтенда с токеном в query
+func (uc *userUseCase) sendLink(ctx context.Context, to, subject, path, token, intro, outro string) error {
+	link := uc.frontendURL + path + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      to,
+		Subject: subject,
+		Body:    intro + "\n" + link + "\n\n" + outro + " Если это были не вы, просто проигнорируйте это письмо.",
+	}
+	if err := uc.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	// ParseAddress принимает и "Имя <адрес>" — нужен только голый адрес
+	if err != nil || addr.Name != "" || addr.Address != strings.TrimSpace(email) {
+		return "", usecase.ErrInvalidEmail
+	}
+	return strings.ToLower(addr.Address), nil
+}
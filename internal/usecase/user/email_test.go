@@ -0,0 +1,207 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+	"main/pkg/mailer"
+)
+
+// memTokenRepo — рабочая in-memory реализация repo.UserTokenRepo,
+// чтобы проверять сценарии «письмо → переход по ссылке» целиком
+type memTokenRepo struct {
+	mu     sync.Mutex
+	tokens []entity.UserToken
+}
+
+func (r *memTokenRepo) Create(_ context.Context, token entity.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memTokenRepo) Consume(_ context.Context, purpose, tokenHash string, now time.Time) (entity.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, t := range r.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			r.tokens[i].UsedAt = &now
+			return r.tokens[i], nil
+		}
+	}
+	return entity.UserToken{}, errors.New("record not found")
+}
+
+func (r *memTokenRepo) InvalidateAll(_ context.Context, userID uuid.UUID, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for i, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			r.tokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// tokenFromMail достаёт токен из ссылки в последнем письме
+func tokenFromMail(t *testing.T, m *mailer.Memory, path string) string {
+	t.Helper()
+	msg, ok := m.Last()
+	require.True(t, ok, "no email sent")
+	prefix := "https://example.com" + path + "?token="
+	start := strings.Index(msg.Body, prefix)
+	require.GreaterOrEqual(t, start, 0, "link %s not found in %q", path, msg.Body)
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestEmailVerification_Flow(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, TokenRepo: &memTokenRepo{}, Mailer: m})
+
+	userID := uuid.New()
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").Return(entity.User{}, errors.New("record not found"))
+	ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Username: "alice"}, nil)
+	ur.On("UpdateColumns", mock.Anything, mock.Anything, []repo.UserColumn{repo.UserColumnEmail, repo.UserColumnEmailVerified}).Return(nil)
+
+	require.NoError(t, uc.RequestEmailVerification(context.Background(), userID, " Alice@Example.com"))
+	msg, _ := m.Last()
+	assert.Equal(t, "alice@example.com", msg.To)
+	ur.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+
+	token := tokenFromMail(t, m, "/verify-email")
+	user, err := uc.VerifyEmail(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+
+	// Ссылка одноразовая
+	_, err = uc.VerifyEmail(context.Background(), token)
+	require.ErrorIs(t, err, usecase.ErrInvalidToken)
+}
+
+func TestRequestEmailVerification_Cooldown(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, TokenRepo: &memTokenRepo{}, Mailer: m})
+	userID := uuid.New()
+	ur.On("GetByVerifiedEmail", mock.Anything, mock.Anything).Return(entity.User{}, errors.New("record not found"))
+
+	require.NoError(t, uc.RequestEmailVerification(context.Background(), userID, "alice@example.com"))
+	// Пауза на аккаунт, а не на адрес: перебор адресов тоже упирается в неё
+	err := uc.RequestEmailVerification(context.Background(), userID, "victim@example.com")
+	require.ErrorIs(t, err, usecase.ErrLinkCooldown)
+	assert.Len(t, m.Sent(), 1)
+
+	// У другого пользователя своя пауза
+	require.NoError(t, uc.RequestEmailVerification(context.Background(), uuid.New(), "bob@example.com"))
+	assert.Len(t, m.Sent(), 2)
+}
+
+func TestRequestEmailVerification_Errors(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("invalid address", func(t *testing.T) {
+		uc := newTestUC(userUC.Deps{})
+		for _, email := range []string{"", "not-an-email", "Alice <alice@example.com>"} {
+			err := uc.RequestEmailVerification(context.Background(), userID, email)
+			assert.ErrorIs(t, err, usecase.ErrInvalidEmail, email)
+		}
+	})
+
+	t.Run("taken by another user", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		uc := newTestUC(userUC.Deps{UserRepo: ur})
+		ur.On("GetByVerifiedEmail", mock.Anything, "bob@example.com").Return(entity.User{ID: uuid.New()}, nil)
+
+		err := uc.RequestEmailVerification(context.Background(), userID, "bob@example.com")
+		require.ErrorIs(t, err, usecase.ErrConflict)
+	})
+}
+
+func TestMagicLink_Flow(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, SessionRepo: sr, TokenRepo: &memTokenRepo{}, Mailer: m})
+
+	user := entity.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com", EmailVerified: true}
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").Return(user, nil)
+	ur.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "alice@example.com"))
+	token := tokenFromMail(t, m, "/magic-login")
+
+	result, err := uc.LoginWithMagicLink(context.Background(), token, usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, result.User.ID)
+	assert.NotEmpty(t, result.Token)
+
+	_, err = uc.LoginWithMagicLink(context.Background(), token, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrInvalidToken)
+}
+
+func TestMagicLink_Cooldown(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, TokenRepo: &memTokenRepo{}, Mailer: m})
+	user := entity.User{ID: uuid.New(), Email: "alice@example.com", EmailVerified: true}
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").Return(user, nil)
+
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "alice@example.com"))
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "alice@example.com"))
+	assert.Len(t, m.Sent(), 1)
+}
+
+func TestMagicLink_UnknownEmailIsSilent(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, Mailer: m})
+	ur.On("GetByVerifiedEmail", mock.Anything, "nobody@example.com").Return(entity.User{}, errors.New("record not found"))
+
+	require.NoError(t, uc.RequestMagicLink(context.Background(), "nobody@example.com"))
+	assert.Empty(t, m.Sent())
+}
+
+func TestLogin_ByVerifiedEmail(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+
+	hashed, _ := hasher.New().Hash("password123")
+	user := entity.User{ID: uuid.New(), Username: "alice", Password: hashed, Email: "alice@example.com", EmailVerified: true}
+	ur.On("GetByUsername", mock.Anything, "Alice@example.com").Return(entity.User{}, errors.New("record not found"))
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").Return(user, nil)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := uc.Login(context.Background(), "Alice@example.com", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, result.User.ID)
+}
+
+func TestRegister_RejectsAtSign(t *testing.T) {
+	uc := newUserUC(&mockrepo.MockUserRepo{}, &mockrepo.MockSessionRepo{})
+	_, err := uc.Register(context.Background(), "alice@example.com", "password123", usecase.ClientInfo{})
+	require.Error(t, err)
+}
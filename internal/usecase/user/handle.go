@@ -0,0 +1,132 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// handleHoldPeriod — сколько прежний handle удерживается за владельцем после переименования.
+// Всё это время старые ссылки ведут на его профиль, потом handle может занять кто угодно
+const handleHoldPeriod = 90 * 24 * time.Hour
+
+// handlePattern — латиница в нижнем регистре, цифры и подчёркивание; начинается с буквы,
+// чтобы handle нельзя было спутать с Telegram ID, и не заканчивается подчёркиванием
+var handlePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*[a-z0-9]$`)
+
+// reservedHandles совпадают с путями фронтенда и API или выдают себя за сервис
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "moderator": true, "support": true, "help": true,
+	"api": true, "auth": true, "login": true, "logout": true, "register": true, "signup": true,
+	"settings": true, "profile": true, "account": true, "users": true, "user": true,
+	"wishlists": true, "wishlist": true, "presents": true, "templates": true, "upload": true,
+	"about": true, "terms": true, "privacy": true, "root": true, "system": true,
+	"null": true, "undefined": true, "telegram": true,
+}
+
+func (uc *userUseCase) SetHandle(ctx context.Context, userID uuid.UUID, handle string) (entity.User, error) {
+	handle = normalizeHandle(handle)
+	if handle != "" {
+		if err := validateHandle(handle); err != nil {
+			return entity.User{}, err
+		}
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if user.Handle == handle {
+		return user, nil
+	}
+
+	if handle != "" {
+		if owner, err := uc.userRepo.GetByHandle(ctx, handle); err == nil && owner.ID != userID {
+			return entity.User{}, fmt.Errorf("handle уже занят: %w", usecase.ErrConflict)
+		}
+		// Свой прежний handle можно вернуть в любой момент, чужой — только после срока удержания
+		if rd, err := uc.redirectRepo.GetByHandle(ctx, handle); err == nil &&
+			rd.UserID != userID && time.Now().Before(rd.CreatedAt.Add(handleHoldPeriod)) {
+			return entity.User{}, fmt.Errorf("handle уже занят: %w", usecase.ErrConflict)
+		}
+	}
+
+	if err := uc.userRepo.ChangeHandle(ctx, userID, handle, time.Now()); err != nil {
+		return entity.User{}, fmt.Errorf("change handle: %w", err)
+	}
+	user.Handle = handle
+	return user, nil
+}
+
+func (uc *userUseCase) GetPublicProfile(ctx context.Context, handle string) (usecase.PublicProfile, error) {
+	handle = normalizeHandle(handle)
+	if validateHandle(handle) != nil {
+		return usecase.PublicProfile{}, fmt.Errorf("profile %w", usecase.ErrNotFound)
+	}
+
+	user, err := uc.userRepo.GetByHandle(ctx, handle)
+	if err != nil {
+		user, err = uc.resolveOldHandle(ctx, handle)
+		if err != nil {
+			return usecase.PublicProfile{}, err
+		}
+	}
+	// Заблокированные и удаляемые аккаунты не показываем, как и отсутствующие
+	if user.Banned() || user.DeletionScheduled() {
+		return usecase.PublicProfile{}, fmt.Errorf("profile %w", usecase.ErrNotFound)
+	}
+
+	wishlists, err := uc.wishlistRepo.GetListedByUserID(ctx, user.ID)
+	if err != nil {
+		return usecase.PublicProfile{}, fmt.Errorf("get listed wishlists: %w", err)
+	}
+	return usecase.PublicProfile{
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+		Avatar:      user.Avatar,
+		Wishlists:   wishlists,
+	}, nil
+}
+
+// resolveOldHandle находит владельца по прежнему handle. Если с тех пор он убрал handle,
+// профиля больше нет
+func (uc *userUseCase) resolveOldHandle(ctx context.Context, handle string) (entity.User, error) {
+	rd, err := uc.redirectRepo.GetByHandle(ctx, handle)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("profile %w: %v", usecase.ErrNotFound, err)
+	}
+	user, err := uc.userRepo.GetByID(ctx, rd.UserID)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("profile %w: %v", usecase.ErrNotFound, err)
+	}
+	if user.Handle == "" {
+		return entity.User{}, fmt.Errorf("profile %w", usecase.ErrNotFound)
+	}
+	return user, nil
+}
+
+// normalizeHandle приводит handle к нижнему регистру; ведущий @ допускается по привычке
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+func validateHandle(handle string) error {
+	if n := len(handle); n < usecase.MinHandleLen || n > usecase.MaxHandleLen {
+		return fmt.Errorf("handle должен быть длиной от %d до %d символов", usecase.MinHandleLen, usecase.MaxHandleLen)
+	}
+	if !handlePattern.MatchString(handle) {
+		return errors.New("handle может содержать только латинские буквы, цифры и _, начинаться с буквы и не заканчиваться _")
+	}
+	if reservedHandles[handle] || strings.HasPrefix(handle, telegramUsernamePrefix) {
+		return errors.New("этот handle зарезервирован")
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+)
+
+var errNotFound = errors.New("record not found")
+
+func TestSetHandle_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		handle  string
+		wantErr bool
+	}{
+		{name: "valid", handle: "alice_w"},
+		{name: "normalized", handle: " @Alice "},
+		{name: "too short", handle: "al", wantErr: true},
+		{name: "too long", handle: "a123456789012345678901234567890", wantErr: true},
+		{name: "starts with digit", handle: "42alice", wantErr: true},
+		{name: "trailing underscore", handle: "alice_", wantErr: true},
+		{name: "not latin", handle: "алиса", wantErr: true},
+		{name: "reserved", handle: "settings", wantErr: true},
+		{name: "telegram prefix", handle: "tg_42", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entity.User{ID: uuid.New(), Username: "alice"}
+			rr := &mockrepo.MockHandleRedirectRepo{}
+			rr.On("GetByHandle", mock.Anything, mock.Anything).Return(entity.HandleRedirect{}, errNotFound)
+			uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}, RedirectRepo: rr})
+
+			got, err := uc.SetHandle(context.Background(), user.ID, tt.handle)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Empty(t, user.Handle)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, user.Handle, got.Handle)
+			assert.NotEmpty(t, user.Handle)
+		})
+	}
+}
+
+func TestSetHandle_Taken(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("by another user", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID}, nil)
+		ur.On("GetByHandle", mock.Anything, "alice").Return(entity.User{ID: uuid.New(), Handle: "alice"}, nil)
+		uc := newTestUC(userUC.Deps{UserRepo: ur})
+
+		_, err := uc.SetHandle(context.Background(), userID, "alice")
+		require.ErrorIs(t, err, usecase.ErrConflict)
+		ur.AssertNotCalled(t, "ChangeHandle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	tests := []struct {
+		name      string
+		owner     uuid.UUID
+		renamedAt time.Time
+		wantError error
+	}{
+		{name: "recently renamed by someone else", owner: uuid.New(), renamedAt: time.Now().Add(-24 * time.Hour), wantError: usecase.ErrConflict},
+		{name: "hold period is over", owner: uuid.New(), renamedAt: time.Now().Add(-100 * 24 * time.Hour)},
+		{name: "own previous handle", owner: userID, renamedAt: time.Now()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &mockrepo.MockUserRepo{}
+			ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Handle: "bob"}, nil)
+			ur.On("GetByHandle", mock.Anything, "alice").Return(entity.User{}, errNotFound)
+			ur.On("ChangeHandle", mock.Anything, userID, "alice", mock.Anything).Return(nil).Maybe()
+			rr := &mockrepo.MockHandleRedirectRepo{}
+			rr.On("GetByHandle", mock.Anything, "alice").
+				Return(entity.HandleRedirect{Handle: "alice", UserID: tt.owner, CreatedAt: tt.renamedAt}, nil)
+			uc := newTestUC(userUC.Deps{UserRepo: ur, RedirectRepo: rr})
+
+			user, err := uc.SetHandle(context.Background(), userID, "alice")
+			if tt.wantError != nil {
+				require.ErrorIs(t, err, tt.wantError)
+				ur.AssertNotCalled(t, "ChangeHandle", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice", user.Handle)
+			ur.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetHandle_EmptyHidesProfile(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Username: "alice", Handle: "alice"}
+	uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}})
+
+	got, err := uc.SetHandle(context.Background(), user.ID, "")
+	require.NoError(t, err)
+	assert.Empty(t, got.Handle)
+	assert.Empty(t, user.Handle)
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	owner := entity.User{ID: uuid.New(), Username: "tg_42", Handle: "alice", DisplayName: "Alice", Avatar: "https://cdn/a.png"}
+	listed := []entity.Wishlist{{ID: uuid.New(), UserID: owner.ID, Title: "Birthday", ListedOnProfile: true}}
+
+	t.Run("current handle", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		ur.On("GetByHandle", mock.Anything, "alice").Return(owner, nil)
+		wr := &mockrepo.MockWishlistRepo{}
+		wr.On("GetListedByUserID", mock.Anything, owner.ID).Return(listed, nil)
+		uc := newTestUC(userUC.Deps{UserRepo: ur, WishlistRepo: wr})
+
+		profile, err := uc.GetPublicProfile(context.Background(), "Alice")
+		require.NoError(t, err)
+		assert.Equal(t, usecase.PublicProfile{Handle: "alice", DisplayName: "Alice", Avatar: owner.Avatar, Wishlists: listed}, profile)
+	})
+
+	t.Run("previous handle resolves to the current one", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		ur.On("GetByHandle", mock.Anything, "old_alice").Return(entity.User{}, errNotFound)
+		ur.On("GetByID", mock.Anything, owner.ID).Return(owner, nil)
+		rr := &mockrepo.MockHandleRedirectRepo{}
+		rr.On("GetByHandle", mock.Anything, "old_alice").Return(entity.HandleRedirect{Handle: "old_alice", UserID: owner.ID}, nil)
+		wr := &mockrepo.MockWishlistRepo{}
+		wr.On("GetListedByUserID", mock.Anything, owner.ID).Return(listed, nil)
+		uc := newTestUC(userUC.Deps{UserRepo: ur, RedirectRepo: rr, WishlistRepo: wr})
+
+		profile, err := uc.GetPublicProfile(context.Background(), "old_alice")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", profile.Handle)
+	})
+
+	hidden := []struct {
+		name string
+		user entity.User
+	}{
+		{name: "banned", user: entity.User{ID: owner.ID, Handle: "alice", BannedAt: ptrTime(time.Now())}},
+		{name: "deletion scheduled", user: entity.User{ID: owner.ID, Handle: "alice", DeletionScheduledAt: ptrTime(time.Now().Add(time.Hour))}},
+	}
+	for _, tt := range hidden {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &mockrepo.MockUserRepo{}
+			ur.On("GetByHandle", mock.Anything, "alice").Return(tt.user, nil)
+			uc := newTestUC(userUC.Deps{UserRepo: ur})
+
+			_, err := uc.GetPublicProfile(context.Background(), "alice")
+			require.ErrorIs(t, err, usecase.ErrNotFound)
+		})
+	}
+
+	t.Run("unknown and invalid handles", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		ur.On("GetByHandle", mock.Anything, "nobody").Return(entity.User{}, errNotFound)
+		rr := &mockrepo.MockHandleRedirectRepo{}
+		rr.On("GetByHandle", mock.Anything, "nobody").Return(entity.HandleRedirect{}, errNotFound)
+		uc := newTestUC(userUC.Deps{UserRepo: ur, RedirectRepo: rr})
+
+		_, err := uc.GetPublicProfile(context.Background(), "nobody")
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+		_, err = uc.GetPublicProfile(context.Background(), "%00")
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
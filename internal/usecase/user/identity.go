@@ -0,0 +1,98 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+func (uc *userUseCase) GetIdentities(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error) {
+	identities, err := uc.identityRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get identities: %w", err)
+	}
+	return identities, nil
+}
+
+func (uc *userUseCase) LinkTelegram(ctx context.Context, userID uuid.UUID, input usecase.TelegramAuthInput) (entity.UserIdentity, error) {
+	if err := uc.verifyTelegram(input); err != nil {
+		return entity.UserIdentity{}, err
+	}
+
+	subject := strconv.FormatInt(input.ID, 10)
+	existing, err := uc.identityRepo.GetByProviderSubject(ctx, entity.IdentityProviderTelegram, subject)
+	if err == nil {
+		if existing.UserID == userID {
+			return existing, nil
+		}
+		return entity.UserIdentity{}, fmt.Errorf("telegram уже привязан к другому аккаунту: %w", usecase.ErrConflict)
+	}
+
+	identities, err := uc.identityRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return entity.UserIdentity{}, fmt.Errorf("get identities: %w", err)
+	}
+	for _, i := range identities {
+		if i.Provider == entity.IdentityProviderTelegram {
+			return entity.UserIdentity{}, fmt.Errorf("к аккаунту уже привязан другой telegram: %w", usecase.ErrConflict)
+		}
+	}
+
+	identity := newTelegramIdentity(userID, subject, input)
+	if err := uc.identityRepo.Create(ctx, identity); err != nil {
+		return entity.UserIdentity{}, fmt.Errorf("create identity: %w", err)
+	}
+	return identity, nil
+}
+
+func (uc *userUseCase) SetPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	if password == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if user.Password != "" {
+		return fmt.Errorf("пароль уже задан: %w", usecase.ErrConflict)
+	}
+
+	return uc.updatePassword(ctx, user, password)
+}
+
+func (uc *userUseCase) UnlinkIdentity(ctx context.Context, userID, identityID uuid.UUID) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	identities, err := uc.identityRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get identities: %w", err)
+	}
+
+	found := false
+	for _, i := range identities {
+		if i.ID == identityID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("identity %w", usecase.ErrNotFound)
+	}
+	// После удаления должен остаться пароль или другая identity
+	if user.Password == "" && len(identities) == 1 {
+		return usecase.ErrLastLoginMethod
+	}
+
+	if err := uc.identityRepo.Delete(ctx, identityID); err != nil {
+		return fmt.Errorf("delete identity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	mockrepo "main/mock/repo"
+)
+
+func TestLinkTelegram(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	tests := []struct {
+		name     string
+		existing *entity.UserIdentity
+		own      []entity.UserIdentity
+		wantErr  error
+	}{
+		{name: "links new telegram"},
+		{
+			name:     "already linked to this account",
+			existing: &entity.UserIdentity{UserID: userID, Provider: entity.IdentityProviderTelegram, Subject: "42"},
+		},
+		{
+			name:     "linked to another account",
+			existing: &entity.UserIdentity{UserID: otherID, Provider: entity.IdentityProviderTelegram, Subject: "42"},
+			wantErr:  usecase.ErrConflict,
+		},
+		{
+			name:    "account already has another telegram",
+			own:     []entity.UserIdentity{{UserID: userID, Provider: entity.IdentityProviderTelegram, Subject: "7"}},
+			wantErr: usecase.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := &mockrepo.MockIdentityRepo{}
+			uc := newTelegramUC(&mockrepo.MockUserRepo{}, ir, &mockrepo.MockSessionRepo{})
+
+			if tt.existing != nil {
+				ir.On("GetByProviderSubject", mock.Anything, entity.IdentityProviderTelegram, "42").Return(*tt.existing, nil)
+			} else {
+				ir.On("GetByProviderSubject", mock.Anything, entity.IdentityProviderTelegram, "42").
+					Return(entity.UserIdentity{}, errors.New("record not found"))
+			}
+			ir.On("GetAllByUserID", mock.Anything, userID).Return(tt.own, nil)
+			ir.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			identity, err := uc.LinkTelegram(context.Background(), userID, signedTelegramInput(42, "Анна", ""))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				ir.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, userID, identity.UserID)
+			assert.Equal(t, "42", identity.Subject)
+		})
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	t.Run("telegram-only account", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
+		userID := uuid.New()
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Username: "tg_42"}, nil)
+		ur.On("UpdateColumns", mock.Anything, mock.MatchedBy(func(u entity.User) bool { return u.Password != "" }), []repo.UserColumn{repo.UserColumnPassword}).
+			Return(nil)
+
+		require.NoError(t, uc.SetPassword(context.Background(), userID, "secret123"))
+		ur.AssertExpectations(t)
+	})
+
+	t.Run("password already set", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
+		userID := uuid.New()
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Password: "hash"}, nil)
+
+		err := uc.SetPassword(context.Background(), userID, "secret123")
+		require.ErrorIs(t, err, usecase.ErrConflict)
+		ur.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	userID := uuid.New()
+	tg := entity.UserIdentity{ID: uuid.New(), UserID: userID, Provider: entity.IdentityProviderTelegram}
+	other := entity.UserIdentity{ID: uuid.New(), UserID: userID, Provider: "other"}
+
+	tests := []struct {
+		name       string
+		password   string
+		identities []entity.UserIdentity
+		target     uuid.UUID
+		wantErr    error
+	}{
+		{name: "password remains", password: "hash", identities: []entity.UserIdentity{tg}, target: tg.ID},
+		{name: "another identity remains", identities: []entity.UserIdentity{tg, other}, target: tg.ID},
+		{name: "last login method", identities: []entity.UserIdentity{tg}, target: tg.ID, wantErr: usecase.ErrLastLoginMethod},
+		{name: "foreign identity", password: "hash", identities: []entity.UserIdentity{tg}, target: uuid.New(), wantErr: usecase.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &mockrepo.MockUserRepo{}
+			ir := &mockrepo.MockIdentityRepo{}
+			uc := newTelegramUC(ur, ir, &mockrepo.MockSessionRepo{})
+
+			ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Password: tt.password}, nil)
+			ir.On("GetAllByUserID", mock.Anything, userID).Return(tt.identities, nil)
+			ir.On("Delete", mock.Anything, tt.target).Return(nil)
+
+			err := uc.UnlinkIdentity(context.Background(), userID, tt.target)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				ir.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			ir.AssertCalled(t, "Delete", mock.Anything, tt.target)
+		})
+	}
+}
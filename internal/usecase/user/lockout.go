@@ -0,0 +1,147 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// lockoutPolicy — экспоненциальная задержка: первые freeAttempts неудач бесплатны,
+// дальше каждая следующая удваивает блокировку, но не больше maxDelay
+type lockoutPolicy struct {
+	freeAttempts int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+}
+
+var (
+	accountLockout = lockoutPolicy{freeAttempts: 5, baseDelay: 30 * time.Second, maxDelay: 15 * time.Minute}
+	// С одного IP могут входить несколько человек (NAT, офис), поэтому порог выше
+	ipLockout = lockoutPolicy{freeAttempts: 20, baseDelay: 30 * time.Second, maxDelay: time.Hour}
+)
+
+// loginFailureWindow — через сколько после последней неудачи счётчик начинается заново
+const loginFailureWindow = 24 * time.Hour
+
+func (p lockoutPolicy) delay(failures int) time.Duration {
+	if failures < p.freeAttempts {
+		return 0
+	}
+	d := p.baseDelay
+	for i := p.freeAttempts; i < failures; i++ {
+		d *= 2
+		if d >= p.maxDelay {
+			return p.maxDelay
+		}
+	}
+	return d
+}
+
+// accountKey — счётчик аккаунта по его ID: вход по username и по email копит одни и те же неудачи
+func accountKey(userID uuid.UUID) string {
+	return "account:" + userID.String()
+}
+
+// unknownLoginKey — счётчик логина, которого нет в базе. Логин нормализуется,
+// чтобы варианты регистра не обходили блокировку
+func unknownLoginKey(login string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+// mfaAttemptKey — второй фактор считается отдельно: mfa-токенов можно получить сколько угодно
+func mfaAttemptKey(userID uuid.UUID) string {
+	return "mfa:" + userID.String()
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// verifyPassword сверяет текущий пароль уже вошедшего пользователя. Неудачи копятся в том же
+// счётчике аккаунта, что и при входе: иначе украденной сессией пароль можно было бы подбирать без ограничений
+func (uc *userUseCase) verifyPassword(ctx context.Context, user entity.User, password string) error {
+	key := accountKey(user.ID)
+	if err := uc.checkLockout(ctx, key, usecase.ClientInfo{}); err != nil {
+		return err
+	}
+	if !uc.passwordMatches(user, password) {
+		uc.registerFailure(ctx, key, usecase.ClientInfo{})
+		return usecase.ErrWrongPassword
+	}
+	uc.clearLockout(ctx, key, user.ID)
+	return nil
+}
+
+// linkCooldown — не чаще одного письма со ссылкой одного назначения на аккаунт,
+// иначе формой сброса можно забросать чужой ящик письмами
+const linkCooldown = time.Minute
+
+// acquireLinkCooldown сообщает, можно ли сейчас отправить пользователю письмо с purpose-ссылкой,
+// и если да — запускает паузу до следующего
+func (uc *userUseCase) acquireLinkCooldown(ctx context.Context, purpose string, userID uuid.UUID) (bool, error) {
+	ok, err := uc.attemptRepo.Acquire(ctx, "link:"+purpose+":"+userID.String(), time.Now(), linkCooldown)
+	if err != nil {
+		return false, fmt.Errorf("link cooldown: %w", err)
+	}
+	return ok, nil
+}
+
+// checkLockout возвращает *usecase.LockoutError, если ключ аккаунта или IP заблокирован
+func (uc *userUseCase) checkLockout(ctx context.Context, key string, client usecase.ClientInfo) error {
+	keys := []string{key}
+	if client.IP != "" {
+		keys = append(keys, ipKey(client.IP))
+	}
+	now := time.Now()
+	until, err := uc.attemptRepo.LockedUntil(ctx, keys, now)
+	if err != nil {
+		return fmt.Errorf("check login lockout: %w", err)
+	}
+	if until.After(now) {
+		return &usecase.LockoutError{RetryAfter: until.Sub(now)}
+	}
+	return nil
+}
+
+// registerFailure учитывает неудачную попытку и при превышении порога ставит блокировку.
+// Ошибки хранилища только логируются — исходная ошибка входа важнее
+func (uc *userUseCase) registerFailure(ctx context.Context, key string, client usecase.ClientInfo) {
+	uc.registerKeyFailure(ctx, key, accountLockout)
+	if client.IP != "" {
+		uc.registerKeyFailure(ctx, ipKey(client.IP), ipLockout)
+	}
+}
+
+func (uc *userUseCase) registerKeyFailure(ctx context.Context, key string, policy lockoutPolicy) {
+	now := time.Now()
+	failures, err := uc.attemptRepo.RegisterFailure(ctx, key, now, loginFailureWindow)
+	if err != nil {
+		log.Printf("login attempts: register failure for %s: %v", key, err)
+		return
+	}
+	if d := policy.delay(failures); d > 0 {
+		if err := uc.attemptRepo.Lock(ctx, key, now.Add(d)); err != nil {
+			log.Printf("login attempts: lock %s: %v", key, err)
+		}
+	}
+}
+
+// clearLockout сбрасывает счётчик аккаунта после успешного входа.
+// Счётчик IP не трогаем: иначе атакующий сбрасывал бы его входом в свой аккаунт
+func (uc *userUseCase) clearLockout(ctx context.Context, key string, userID uuid.UUID) {
+	failures, err := uc.attemptRepo.Reset(ctx, key)
+	if err != nil {
+		log.Printf("login attempts: reset %s: %v", key, err)
+		return
+	}
+	if failures > 0 {
+		log.Printf("login lockout cleared: user=%s key=%s failures=%d", userID, key, failures)
+	}
+}
@@ -0,0 +1,238 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+type attemptRecord struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// memAttemptRepo — in-memory LoginAttemptRepo с той же семантикой, что и postgres-реализация
+type memAttemptRepo struct {
+	mu      sync.Mutex
+	records map[string]*attemptRecord
+}
+
+func newMemAttemptRepo() *memAttemptRepo {
+	return &memAttemptRepo{records: map[string]*attemptRecord{}}
+}
+
+func (r *memAttemptRepo) RegisterFailure(_ context.Context, key string, now time.Time, resetAfter time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[key]
+	if !ok {
+		rec = &attemptRecord{}
+		r.records[key] = rec
+	}
+	if rec.lastFailure.After(now.Add(-resetAfter)) {
+		rec.failures++
+	} else {
+		rec.failures = 1
+	}
+	rec.lastFailure = now
+	return rec.failures, nil
+}
+
+func (r *memAttemptRepo) Lock(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec, ok := r.records[key]; ok {
+		rec.lockedUntil = until
+	}
+	return nil
+}
+
+func (r *memAttemptRepo) LockedUntil(_ context.Context, keys []string, now time.Time) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var until time.Time
+	for _, key := range keys {
+		if rec, ok := r.records[key]; ok && rec.lockedUntil.After(now) && rec.lockedUntil.After(until) {
+			until = rec.lockedUntil
+		}
+	}
+	return until, nil
+}
+
+func (r *memAttemptRepo) Reset(_ context.Context, key string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[key]
+	if !ok {
+		return 0, nil
+	}
+	delete(r.records, key)
+	return rec.failures, nil
+}
+
+func (r *memAttemptRepo) Acquire(_ context.Context, key string, now time.Time, cooldown time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[key]
+	if !ok {
+		rec = &attemptRecord{lastFailure: now}
+		r.records[key] = rec
+	}
+	if rec.lockedUntil.After(now) {
+		return false, nil
+	}
+	rec.lockedUntil = now.Add(cooldown)
+	return true, nil
+}
+
+func passwordUser(t *testing.T, username, password string) entity.User {
+	t.Helper()
+	hashed, err := hasher.New().Hash(password)
+	require.NoError(t, err)
+	return entity.User{ID: uuid.New(), Username: username, Password: hashed}
+}
+
+func TestLogin_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	ar := newMemAttemptRepo()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, AttemptRepo: ar})
+
+	alice := passwordUser(t, "alice", "password123")
+	alice.Email, alice.EmailVerified = "alice@example.com", true
+	ur.On("GetByUsername", mock.Anything, "alice").Return(alice, nil)
+	ur.On("GetByUsername", mock.Anything, "Alice@Example.com").Return(entity.User{}, errNotFound)
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").Return(alice, nil)
+
+	client := usecase.ClientInfo{IP: "203.0.113.7"}
+	for i := 0; i < 5; i++ {
+		_, err := uc.Login(context.Background(), "alice", "wrong", client)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, usecase.ErrTooManyAttempts), "attempt %d must not be locked", i+1)
+	}
+
+	// Даже верный пароль не принимается, пока действует блокировка, — и по email тоже
+	_, err := uc.Login(context.Background(), "Alice@Example.com", "password123", usecase.ClientInfo{IP: "198.51.100.1"})
+	var lockout *usecase.LockoutError
+	require.ErrorAs(t, err, &lockout)
+	assert.True(t, errors.Is(err, usecase.ErrTooManyAttempts))
+	assert.InDelta(t, (30 * time.Second).Seconds(), lockout.RetryAfter.Seconds(), 1)
+}
+
+func TestChangePassword_SharesAccountLockout(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	uc := newTestUC(userUC.Deps{UserRepo: ur})
+
+	alice := passwordUser(t, "alice", "password123")
+	ur.On("GetByID", mock.Anything, alice.ID).Return(alice, nil)
+	ur.On("GetByUsername", mock.Anything, "alice").Return(alice, nil)
+
+	for i := 0; i < 5; i++ {
+		err := uc.ChangePassword(context.Background(), alice.ID, uuid.New(), "wrong", "new-password")
+		require.ErrorIs(t, err, usecase.ErrWrongPassword, "attempt %d must not be locked", i+1)
+	}
+
+	err := uc.ChangePassword(context.Background(), alice.ID, uuid.New(), "password123", "new-password")
+	var lockout *usecase.LockoutError
+	require.ErrorAs(t, err, &lockout)
+	ur.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+
+	// Перебор через смену пароля блокирует и обычный вход
+	_, err = uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	assert.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+}
+
+func TestDeleteAccount_PasswordLockout(t *testing.T) {
+	alice := passwordUser(t, "alice", "password123")
+	uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: &alice}})
+
+	for i := 0; i < 5; i++ {
+		_, err := uc.DeleteAccount(context.Background(), alice.ID, uuid.New(), usecase.DeleteAccountInput{Password: "wrong"})
+		require.ErrorIs(t, err, usecase.ErrWrongPassword, "attempt %d must not be locked", i+1)
+	}
+
+	_, err := uc.DeleteAccount(context.Background(), alice.ID, uuid.New(), usecase.DeleteAccountInput{Password: "password123"})
+	require.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+	assert.False(t, alice.DeletionScheduled())
+}
+
+func TestLogin_UnknownUsernamesCountTowardsIPLockout(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	uc := newTestUC(userUC.Deps{UserRepo: ur})
+
+	ur.On("GetByUsername", mock.Anything, mock.Anything).Return(entity.User{}, errors.New("not found"))
+
+	client := usecase.ClientInfo{IP: "203.0.113.7"}
+	for i := 0; i < 20; i++ {
+		_, err := uc.Login(context.Background(), uuid.NewString(), "guess", client)
+		require.False(t, errors.Is(err, usecase.ErrTooManyAttempts), "attempt %d must not be locked", i+1)
+	}
+
+	_, err := uc.Login(context.Background(), uuid.NewString(), "guess", client)
+	assert.True(t, errors.Is(err, usecase.ErrTooManyAttempts))
+
+	_, err = uc.Login(context.Background(), uuid.NewString(), "guess", usecase.ClientInfo{IP: "198.51.100.1"})
+	assert.False(t, errors.Is(err, usecase.ErrTooManyAttempts), "other IPs are not affected")
+}
+
+func TestLogin_SuccessClearsAccountFailures(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	ar := newMemAttemptRepo()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, SessionRepo: sr, AttemptRepo: ar})
+
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+	alice := passwordUser(t, "alice", "password123")
+	ur.On("GetByUsername", mock.Anything, "alice").Return(alice, nil)
+
+	for i := 0; i < 4; i++ {
+		_, err := uc.Login(context.Background(), "alice", "wrong", usecase.ClientInfo{})
+		require.Error(t, err)
+	}
+	_, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+
+	// Счётчик начался заново: ещё четыре ошибки не приводят к блокировке
+	for i := 0; i < 4; i++ {
+		_, err := uc.Login(context.Background(), "alice", "wrong", usecase.ClientInfo{})
+		require.False(t, errors.Is(err, usecase.ErrTooManyAttempts))
+	}
+	failures, _ := ar.Reset(context.Background(), "account:"+alice.ID.String())
+	assert.Equal(t, 4, failures)
+}
+
+func TestLogin_BackoffDoublesAndIsCapped(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	ar := newMemAttemptRepo()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, AttemptRepo: ar})
+
+	alice := passwordUser(t, "alice", "password123")
+	ur.On("GetByUsername", mock.Anything, "alice").Return(alice, nil)
+	key := "account:" + alice.ID.String()
+
+	expected := map[int]time.Duration{5: 30 * time.Second, 6: time.Minute, 7: 2 * time.Minute, 12: 15 * time.Minute}
+	for i := 1; i <= 12; i++ {
+		// Снимаем блокировку вручную, сохраняя счётчик, чтобы дойти до следующей попытки
+		if rec, ok := ar.records[key]; ok {
+			rec.lockedUntil = time.Time{}
+		}
+		_, err := uc.Login(context.Background(), "alice", "wrong", usecase.ClientInfo{})
+		require.Error(t, err)
+		if d, ok := expected[i]; ok {
+			assert.WithinDuration(t, time.Now().Add(d), ar.records[key].lockedUntil, 2*time.Second, "failure %d", i)
+		}
+	}
+}
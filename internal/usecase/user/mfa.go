@@ -0,0 +1,260 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/pkg/randtoken"
+	"main/pkg/totp"
+)
+
+const (
+	totpIssuer = "prosto-namekni"
+
+	mfaTokenTTL       = 5 * time.Minute
+	mfaTokenPurpose   = "mfa"
+	recoveryCodeCount = 10
+	recoveryCodeTTL   = 10 * 365 * 24 * time.Hour
+)
+
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type mfaClaims struct {
+	Purpose string `json:"purpose"`
+	jwt.StandardClaims
+}
+
+// completeLogin завершает успешную проверку первого фактора: при включённой 2FA
+// вместо токенов выдаётся короткоживущий mfa-токен
+func (uc *userUseCase) completeLogin(ctx context.Context, user entity.User, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	if user.Banned() {
+		return usecase.AuthResult{}, usecase.ErrBanned
+	}
+	if !user.TOTPEnabled {
+		return uc.startSession(ctx, user, client)
+	}
+	token, err := uc.generateMFAToken(user.ID)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("generate mfa token: %w", err)
+	}
+	return usecase.AuthResult{MFARequired: true, MFAToken: token}, nil
+}
+
+func (uc *userUseCase) CompleteMFA(ctx context.Context, mfaToken, code string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	userID, err := uc.parseMFAToken(mfaToken)
+	if err != nil {
+		return usecase.AuthResult{}, usecase.ErrInvalidToken
+	}
+	key := mfaAttemptKey(userID)
+	if err := uc.checkLockout(ctx, key, client); err != nil {
+		return usecase.AuthResult{}, err
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	// Без второго фактора mfa-токен ничего не подтверждает: если 2FA отключили, пока шёл вход,
+	// нужно войти заново
+	if !user.TOTPEnabled {
+		return usecase.AuthResult{}, usecase.ErrInvalidToken
+	}
+	if err := uc.verifySecondFactor(ctx, &user, code); err != nil {
+		if errors.Is(err, usecase.ErrInvalidMFA) {
+			uc.registerFailure(ctx, key, client)
+		}
+		return usecase.AuthResult{}, err
+	}
+	uc.clearLockout(ctx, key, user.ID)
+	return uc.startSession(ctx, user, client)
+}
+
+func (uc *userUseCase) BeginTOTPEnrollment(ctx context.Context, userID uuid.UUID) (usecase.TOTPEnrollment, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return usecase.TOTPEnrollment{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if user.TOTPEnabled {
+		return usecase.TOTPEnrollment{}, fmt.Errorf("2FA уже включена: %w", usecase.ErrConflict)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return usecase.TOTPEnrollment{}, err
+	}
+	// Секрет сохраняется сразу, но 2FA включится только после ConfirmTOTP
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := uc.userRepo.UpdateColumns(ctx, user, repo.UserColumnTOTPSecret, repo.UserColumnTOTPLastStep); err != nil {
+		return usecase.TOTPEnrollment{}, fmt.Errorf("update user: %w", err)
+	}
+
+	return usecase.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Username, secret),
+	}, nil
+}
+
+func (uc *userUseCase) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if user.TOTPEnabled {
+		return nil, fmt.Errorf("2FA уже включена: %w", usecase.ErrConflict)
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("сначала начните подключение 2FA")
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, usecase.ErrInvalidMFA
+	}
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	if err := uc.userRepo.UpdateColumns(ctx, user, repo.UserColumnTOTPEnabled, repo.UserColumnTOTPLastStep); err != nil {
+		return nil, fmt.Errorf("update user: %w", err)
+	}
+
+	return uc.issueRecoveryCodes(ctx, user.ID)
+}
+
+func (uc *userUseCase) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	if !user.TOTPEnabled {
+		return fmt.Errorf("2FA не включена: %w", usecase.ErrConflict)
+	}
+	if err := uc.verifySecondFactor(ctx, &user, code); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	if err := uc.userRepo.UpdateColumns(ctx, user,
+		repo.UserColumnTOTPEnabled, repo.UserColumnTOTPSecret, repo.UserColumnTOTPLastStep); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if err := uc.tokenRepo.InvalidateAll(ctx, user.ID, entity.TokenPurposeTOTPRecovery); err != nil {
+		return fmt.Errorf("invalidate recovery codes: %w", err)
+	}
+	return nil
+}
+
+// verifySecondFactor принимает TOTP-код (каждый шаг — не больше одного раза) или код восстановления
+func (uc *userUseCase) verifySecondFactor(ctx context.Context, user *entity.User, code string) error {
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+		// Шаг сверяется в самом UPDATE: два входа с одним кодом не пройдут оба
+		if err := uc.userRepo.UseTOTPStep(ctx, user.ID, step); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return usecase.ErrInvalidMFA
+			}
+			return fmt.Errorf("use totp step: %w", err)
+		}
+		user.TOTPLastStep = step
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return usecase.ErrInvalidMFA
+	}
+	if _, err := uc.tokenRepo.Consume(ctx, entity.TokenPurposeTOTPRecovery, recoveryCodeHash(user.ID, normalized), time.Now()); err != nil {
+		return usecase.ErrInvalidMFA
+	}
+	return nil
+}
+
+// issueRecoveryCodes заменяет коды восстановления новым набором
+func (uc *userUseCase) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	if err := uc.tokenRepo.InvalidateAll(ctx, userID, entity.TokenPurposeTOTPRecovery); err != nil {
+		return nil, fmt.Errorf("invalidate recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		raw := recoveryEncoding.EncodeToString(b)[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+
+		token := entity.UserToken{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Purpose:   entity.TokenPurposeTOTPRecovery,
+			TokenHash: recoveryCodeHash(userID, raw),
+			ExpiresAt: time.Now().Add(recoveryCodeTTL),
+		}
+		if err := uc.tokenRepo.Create(ctx, token); err != nil {
+			return nil, fmt.Errorf("create recovery code: %w", err)
+		}
+	}
+	return codes, nil
+}
+
+// recoveryCodeHash привязывает короткий код к пользователю: одинаковые коды разных людей не пересекаются
+func recoveryCodeHash(userID uuid.UUID, code string) string {
+	return randtoken.Hash(userID.String() + ":" + code)
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != 10 {
+		return ""
+	}
+	return code
+}
+
+// mfaKey — отдельный ключ для mfa-токенов, чтобы их нельзя было выдать за access-токен
+func (uc *userUseCase) mfaKey() []byte {
+	mac := hmac.New(sha256.New, []byte(uc.jwtSecret))
+	mac.Write([]byte("mfa-pending"))
+	return mac.Sum(nil)
+}
+
+func (uc *userUseCase) generateMFAToken(userID uuid.UUID) (string, error) {
+	claims := &mfaClaims{
+		Purpose: mfaTokenPurpose,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userID.String(),
+			ExpiresAt: time.Now().Add(mfaTokenTTL).Unix(),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uc.mfaKey())
+}
+
+func (uc *userUseCase) parseMFAToken(token string) (uuid.UUID, error) {
+	claims := &mfaClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return uc.mfaKey(), nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if claims.Purpose != mfaTokenPurpose {
+		return uuid.Nil, errors.New("not an mfa token")
+	}
+	return uuid.Parse(claims.Subject)
+}
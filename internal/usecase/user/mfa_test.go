@@ -0,0 +1,238 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+	"main/pkg/totp"
+)
+
+// singleUserRepo — repo.UserRepo над одним пользователем; UpdateColumns меняет его на месте
+type singleUserRepo struct {
+	user *entity.User
+}
+
+func (r *singleUserRepo) Create(context.Context, entity.User) error {
+	return errors.New("not supported")
+}
+
+func (r *singleUserRepo) GetByUsername(_ context.Context, username string) (entity.User, error) {
+	if username != r.user.Username {
+		return entity.User{}, errors.New("record not found")
+	}
+	return *r.user, nil
+}
+
+func (r *singleUserRepo) GetByVerifiedEmail(context.Context, string) (entity.User, error) {
+	return entity.User{}, errors.New("record not found")
+}
+
+func (r *singleUserRepo) GetByID(_ context.Context, id uuid.UUID) (entity.User, error) {
+	if id != r.user.ID {
+		return entity.User{}, errors.New("record not found")
+	}
+	return *r.user, nil
+}
+
+func (r *singleUserRepo) GetByHandle(_ context.Context, handle string) (entity.User, error) {
+	if handle == "" || handle != r.user.Handle {
+		return entity.User{}, errors.New("record not found")
+	}
+	return *r.user, nil
+}
+
+func (r *singleUserRepo) UpdateColumns(_ context.Context, user entity.User, columns ...repo.UserColumn) error {
+	for _, c := range columns {
+		switch c {
+		case repo.UserColumnPassword:
+			r.user.Password = user.Password
+		case repo.UserColumnDisplayName:
+			r.user.DisplayName = user.DisplayName
+		case repo.UserColumnAvatar:
+			r.user.Avatar = user.Avatar
+		case repo.UserColumnEmail:
+			r.user.Email = user.Email
+		case repo.UserColumnEmailVerified:
+			r.user.EmailVerified = user.EmailVerified
+		case repo.UserColumnTOTPSecret:
+			r.user.TOTPSecret = user.TOTPSecret
+		case repo.UserColumnTOTPEnabled:
+			r.user.TOTPEnabled = user.TOTPEnabled
+		case repo.UserColumnTOTPLastStep:
+			r.user.TOTPLastStep = user.TOTPLastStep
+		case repo.UserColumnRole:
+			r.user.Role = user.Role
+		case repo.UserColumnBannedAt:
+			r.user.BannedAt = user.BannedAt
+		case repo.UserColumnBanReason:
+			r.user.BanReason = user.BanReason
+		case repo.UserColumnDeletionScheduledAt:
+			r.user.DeletionScheduledAt = user.DeletionScheduledAt
+		default:
+			return fmt.Errorf("unknown column %s", c)
+		}
+	}
+	return nil
+}
+
+func (r *singleUserRepo) ReplacePasswordHash(_ context.Context, id uuid.UUID, oldHash, newHash string) error {
+	if id != r.user.ID || r.user.Password != oldHash {
+		return repo.ErrNotFound
+	}
+	r.user.Password = newHash
+	return nil
+}
+
+func (r *singleUserRepo) UseTOTPStep(_ context.Context, id uuid.UUID, step int64) error {
+	if id != r.user.ID || step <= r.user.TOTPLastStep {
+		return repo.ErrNotFound
+	}
+	r.user.TOTPLastStep = step
+	return nil
+}
+
+func (r *singleUserRepo) ChangeHandle(_ context.Context, _ uuid.UUID, handle string, _ time.Time) error {
+	r.user.Handle = handle
+	return nil
+}
+
+func (r *singleUserRepo) Search(context.Context, string, int, int) ([]entity.User, int64, error) {
+	return []entity.User{*r.user}, 1, nil
+}
+
+func (r *singleUserRepo) GetDueForDeletion(context.Context, time.Time, int) ([]entity.User, error) {
+	return nil, nil
+}
+
+func (r *singleUserRepo) Delete(context.Context, uuid.UUID) error {
+	return errors.New("not supported")
+}
+
+// enrolledUser возвращает use case и пользователя с подтверждённой 2FA
+func enrolledUser(t *testing.T) (usecase.UserUseCase, *entity.User, *mockrepo.MockSessionRepo, []string) {
+	t.Helper()
+	hashed, _ := hasher.New().Hash("password123")
+	user := &entity.User{ID: uuid.New(), Username: "alice", Password: hashed}
+
+	sr := &mockrepo.MockSessionRepo{}
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+	uc := newTestUC(userUC.Deps{UserRepo: &singleUserRepo{user: user}, SessionRepo: sr, TokenRepo: &memTokenRepo{}})
+
+	enrollment, err := uc.BeginTOTPEnrollment(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+	assert.False(t, user.TOTPEnabled, "2FA must stay off until confirmed")
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	codes, err := uc.ConfirmTOTP(context.Background(), user.ID, code)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	require.True(t, user.TOTPEnabled)
+	return uc, user, sr, codes
+}
+
+func TestTOTP_LoginRequiresSecondFactor(t *testing.T) {
+	uc, user, sr, _ := enrolledUser(t)
+
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, result.MFARequired)
+	assert.Empty(t, result.Token)
+	sr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// Код, уже использованный при подтверждении, повторно не принимается
+	used, _ := totp.Code(user.TOTPSecret, user.TOTPLastStep)
+	_, err = uc.CompleteMFA(context.Background(), result.MFAToken, used, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrInvalidMFA)
+
+	next, _ := totp.Code(user.TOTPSecret, user.TOTPLastStep+1)
+	final, err := uc.CompleteMFA(context.Background(), result.MFAToken, next, usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, final.Token)
+	assert.Equal(t, user.ID, final.User.ID)
+}
+
+func TestTOTP_RecoveryCodeIsSingleUse(t *testing.T) {
+	uc, _, _, codes := enrolledUser(t)
+
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+
+	_, err = uc.CompleteMFA(context.Background(), result.MFAToken, codes[0], usecase.ClientInfo{})
+	require.NoError(t, err)
+	_, err = uc.CompleteMFA(context.Background(), result.MFAToken, codes[0], usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrInvalidMFA)
+}
+
+func TestTOTP_AccessTokenCannotBeUsedAsMFAToken(t *testing.T) {
+	uc, user, _, codes := enrolledUser(t)
+
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose": "mfa",
+		"sub":     user.ID.String(),
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}).SignedString([]byte(testJWTSecret))
+
+	_, err := uc.CompleteMFA(context.Background(), forged, codes[0], usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrInvalidToken)
+}
+
+func TestTOTP_Disable(t *testing.T) {
+	uc, user, _, codes := enrolledUser(t)
+
+	require.ErrorIs(t, uc.DisableTOTP(context.Background(), user.ID, "000000"), usecase.ErrInvalidMFA)
+	require.NoError(t, uc.DisableTOTP(context.Background(), user.ID, codes[1]))
+	assert.False(t, user.TOTPEnabled)
+	assert.Empty(t, user.TOTPSecret)
+
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.False(t, result.MFARequired)
+	assert.NotEmpty(t, result.Token)
+}
+
+func TestTOTP_DisabledDuringLogin(t *testing.T) {
+	uc, user, sr, codes := enrolledUser(t)
+
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	require.NoError(t, uc.DisableTOTP(context.Background(), user.ID, codes[0]))
+
+	_, err = uc.CompleteMFA(context.Background(), result.MFAToken, "", usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrInvalidToken)
+	sr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTOTP_RepeatedWrongCodesLockSecondFactor(t *testing.T) {
+	uc, user, _, _ := enrolledUser(t)
+
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := uc.CompleteMFA(context.Background(), result.MFAToken, "000000", usecase.ClientInfo{})
+		require.ErrorIs(t, err, usecase.ErrInvalidMFA)
+	}
+
+	// Новый mfa-токен блокировку не обходит
+	result, err = uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.NoError(t, err)
+	next, _ := totp.Code(user.TOTPSecret, user.TOTPLastStep+1)
+	_, err = uc.CompleteMFA(context.Background(), result.MFAToken, next, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrTooManyAttempts)
+}
@@ -0,0 +1,128 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+)
+
+const passwordResetTTL = time.Hour
+
+func (uc *userUseCase) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, currentPassword, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+	// Аккаунту без пароля сначала нужно его задать через SetPassword
+	if user.Password == "" {
+		return usecase.ErrWrongPassword
+	}
+	if err := uc.verifyPassword(ctx, user, currentPassword); err != nil {
+		return err
+	}
+
+	if err := uc.updatePassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, userID, sessionID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	// Токен мог выпустить тот, кто завладел сессией, — он не должен пережить смену пароля
+	return uc.revokeAccessTokens(ctx, userID)
+}
+
+func (uc *userUseCase) RequestPasswordReset(ctx context.Context, email string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	user, err := uc.userRepo.GetByVerifiedEmail(ctx, email)
+	if err != nil {
+		// Не раскрываем, зарегистрирован ли адрес
+		return nil
+	}
+	// Повторный запрос во время паузы тоже молча игнорируем — по ответу не понять, есть ли аккаунт
+	if ok, err := uc.acquireLinkCooldown(ctx, entity.TokenPurposePasswordReset, user.ID); err != nil || !ok {
+		return err
+	}
+
+	// Действует только последняя ссылка
+	if err := uc.tokenRepo.InvalidateAll(ctx, user.ID, entity.TokenPurposePasswordReset); err != nil {
+		return fmt.Errorf("invalidate reset tokens: %w", err)
+	}
+	token, err := uc.issueToken(ctx, user.ID, entity.TokenPurposePasswordReset, "", passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return uc.sendLink(ctx, user.Email, "Сброс пароля", "/reset-password", token,
+		"Чтобы задать новый пароль, перейдите по ссылке:", "Ссылка действует 1 час.")
+}
+
+func (uc *userUseCase) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	t, err := uc.consumeToken(ctx, entity.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+	user, err := uc.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+	}
+
+	if err := uc.updatePassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+	// Пароль мог утечь — выходим со всех устройств и отзываем персональные токены
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, user.ID, uuid.Nil); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return uc.revokeAccessTokens(ctx, user.ID)
+}
+
+func (uc *userUseCase) updatePassword(ctx context.Context, user entity.User, password string) error {
+	hashed, err := uc.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	user.Password = hashed
+	if err := uc.userRepo.UpdateColumns(ctx, user, repo.UserColumnPassword); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	return nil
+}
+
+// upgradePasswordHash пересчитывает хеш, сделанный устаревшим алгоритмом или с прежними параметрами.
+// Открытый пароль есть только при входе, так что аккаунты переезжают постепенно, без сброса паролей.
+// Хеш меняется, только если пароль не сменили параллельно. Сбой не мешает входу — попробуем в следующий раз
+func (uc *userUseCase) upgradePasswordHash(ctx context.Context, user entity.User, password string) entity.User {
+	if !uc.hasher.NeedsRehash(user.Password) {
+		return user
+	}
+	hashed, err := uc.hasher.Hash(password)
+	if err != nil {
+		log.Printf("account %s: rehash password: %v", user.ID, err)
+		return user
+	}
+	upgraded := user
+	upgraded.Password = hashed
+	if err := uc.userRepo.ReplacePasswordHash(ctx, user.ID, user.Password, hashed); err != nil {
+		log.Printf("account %s: save rehashed password: %v", user.ID, err)
+		return user
+	}
+	return upgraded
+}
@@ -0,0 +1,196 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	userUC "main/internal/usecase/user"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+	"main/pkg/mailer"
+	"main/pkg/randtoken"
+)
+
+func TestChangePassword(t *testing.T) {
+	hashed, _ := hasher.New().Hash("old-password")
+	userID, sessionID := uuid.New(), uuid.New()
+
+	t.Run("revokes other sessions and access tokens", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		sr := &mockrepo.MockSessionRepo{}
+		atr := &mockrepo.MockAccessTokenRepo{}
+		uc := newTestUC(userUC.Deps{UserRepo: ur, SessionRepo: sr, AccessTokenRepo: atr})
+
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Password: hashed}, nil)
+		ur.On("UpdateColumns", mock.Anything, mock.MatchedBy(func(u entity.User) bool {
+			return hasher.New().Compare(u.Password, "new-password") == nil
+		}), []repo.UserColumn{repo.UserColumnPassword}).Return(nil)
+		sr.On("RevokeAllByUserID", mock.Anything, userID, sessionID).Return(nil)
+		tokenID := uuid.New()
+		atr.On("GetAllByUserID", mock.Anything, userID).Return([]entity.AccessToken{{ID: tokenID, UserID: userID}}, nil)
+		atr.On("Delete", mock.Anything, tokenID).Return(nil)
+
+		require.NoError(t, uc.ChangePassword(context.Background(), userID, sessionID, "old-password", "new-password"))
+		ur.AssertExpectations(t)
+		sr.AssertExpectations(t)
+		atr.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Password: hashed}, nil)
+
+		err := uc.ChangePassword(context.Background(), userID, sessionID, "guess", "new-password")
+		require.ErrorIs(t, err, usecase.ErrWrongPassword)
+		ur.AssertNotCalled(t, "UpdateColumns", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRequestPasswordReset_SendsLink(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	tr := &mockrepo.MockUserTokenRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, TokenRepo: tr, Mailer: m})
+
+	userID := uuid.New()
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").
+		Return(entity.User{ID: userID, Email: "alice@example.com", EmailVerified: true}, nil)
+	tr.On("InvalidateAll", mock.Anything, userID, entity.TokenPurposePasswordReset).Return(nil)
+	tr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	require.NoError(t, uc.RequestPasswordReset(context.Background(), "alice@example.com"))
+
+	msg, ok := m.Last()
+	require.True(t, ok)
+	assert.Equal(t, "alice@example.com", msg.To)
+
+	// В письме — сам токен, в базе — только его хеш
+	start := strings.Index(msg.Body, "https://example.com/reset-password?token=")
+	require.GreaterOrEqual(t, start, 0)
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	require.NoError(t, err)
+	raw := link.Query().Get("token")
+	stored := tr.Calls[1].Arguments.Get(1).(entity.UserToken)
+	assert.Equal(t, randtoken.Hash(raw), stored.TokenHash)
+	assert.Equal(t, entity.TokenPurposePasswordReset, stored.Purpose)
+}
+
+func TestRequestPasswordReset_Cooldown(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	tr := &mockrepo.MockUserTokenRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, TokenRepo: tr, Mailer: m})
+
+	userID := uuid.New()
+	ur.On("GetByVerifiedEmail", mock.Anything, "alice@example.com").
+		Return(entity.User{ID: userID, Email: "alice@example.com", EmailVerified: true}, nil)
+	tr.On("InvalidateAll", mock.Anything, userID, entity.TokenPurposePasswordReset).Return(nil)
+	tr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Повтор в пределах паузы не шлёт письмо и не отзывает уже отправленную ссылку
+	require.NoError(t, uc.RequestPasswordReset(context.Background(), "alice@example.com"))
+	require.NoError(t, uc.RequestPasswordReset(context.Background(), "Alice@example.com"))
+	assert.Len(t, m.Sent(), 1)
+	tr.AssertNumberOfCalls(t, "InvalidateAll", 1)
+}
+
+func TestRequestPasswordReset_UnknownEmailIsSilent(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	m := mailer.NewMemory()
+	uc := newTestUC(userUC.Deps{UserRepo: ur, Mailer: m})
+
+	ur.On("GetByVerifiedEmail", mock.Anything, "nobody@example.com").Return(entity.User{}, errors.New("record not found"))
+
+	require.NoError(t, uc.RequestPasswordReset(context.Background(), "nobody@example.com"))
+	assert.Empty(t, m.Sent())
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("valid token revokes all sessions and access tokens", func(t *testing.T) {
+		ur := &mockrepo.MockUserRepo{}
+		sr := &mockrepo.MockSessionRepo{}
+		tr := &mockrepo.MockUserTokenRepo{}
+		atr := &mockrepo.MockAccessTokenRepo{}
+		uc := newTestUC(userUC.Deps{UserRepo: ur, SessionRepo: sr, TokenRepo: tr, AccessTokenRepo: atr})
+
+		userID := uuid.New()
+		tr.On("Consume", mock.Anything, entity.TokenPurposePasswordReset, randtoken.Hash("raw"), mock.Anything).
+			Return(entity.UserToken{UserID: userID}, nil)
+		ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID}, nil)
+		ur.On("UpdateColumns", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		sr.On("RevokeAllByUserID", mock.Anything, userID, uuid.Nil).Return(nil)
+		tokenIDs := []uuid.UUID{uuid.New(), uuid.New()}
+		atr.On("GetAllByUserID", mock.Anything, userID).
+			Return([]entity.AccessToken{{ID: tokenIDs[0], UserID: userID}, {ID: tokenIDs[1], UserID: userID}}, nil)
+		atr.On("Delete", mock.Anything, tokenIDs[0]).Return(nil)
+		atr.On("Delete", mock.Anything, tokenIDs[1]).Return(nil)
+
+		require.NoError(t, uc.ResetPassword(context.Background(), "raw", "new-password"))
+		sr.AssertExpectations(t)
+		atr.AssertExpectations(t)
+	})
+
+	t.Run("used or expired token", func(t *testing.T) {
+		tr := &mockrepo.MockUserTokenRepo{}
+		uc := newTestUC(userUC.Deps{TokenRepo: tr})
+		tr.On("Consume", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(entity.UserToken{}, errors.New("record not found"))
+
+		err := uc.ResetPassword(context.Background(), "raw", "new-password")
+		require.ErrorIs(t, err, usecase.ErrInvalidToken)
+	})
+}
+
+func TestLogin_RehashesOutdatedPassword(t *testing.T) {
+	legacy, _ := hasher.NewBcrypt(bcrypt.MinCost).Hash("password123")
+	current, _ := hasher.New().Hash("password123")
+
+	tests := []struct {
+		name      string
+		stored    string
+		updateErr error
+	}{
+		{name: "bcrypt hash is migrated", stored: legacy},
+		{name: "current hash is left alone", stored: current},
+		{name: "failed save does not block login", stored: legacy, updateErr: errors.New("db down")},
+		{name: "concurrent password change wins", stored: legacy, updateErr: repo.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ur := &mockrepo.MockUserRepo{}
+			sr := &mockrepo.MockSessionRepo{}
+			uc := newUserUC(ur, sr)
+			user := entity.User{ID: uuid.New(), Username: "alice", Password: tt.stored}
+			ur.On("GetByUsername", mock.Anything, "alice").Return(user, nil)
+			var saved string
+			// Старый хеш — условие записи: пароль, сменённый параллельно, не перетирается
+			ur.On("ReplacePasswordHash", mock.Anything, user.ID, tt.stored, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.String(3)
+			}).Return(tt.updateErr)
+			sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+			require.NoError(t, err)
+			if tt.stored == current {
+				ur.AssertNotCalled(t, "ReplacePasswordHash", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.True(t, strings.HasPrefix(saved, "$argon2id$"), saved)
+			assert.NoError(t, hasher.New().Compare(saved, "password123"))
+			assert.False(t, hasher.New().NeedsRehash(saved))
+		})
+	}
+}
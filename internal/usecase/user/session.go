@@ -0,0 +1,231 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/pkg/randtoken"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// maxUserAgentLen — длиннее User-Agent обрезается: в списке сессий он нужен только для узнавания устройства
+	maxUserAgentLen = 512
+)
+
+// Refresh-токен имеет вид "<sessionID>.<secret>": по ID находим семейство,
+// по хешу секрета отличаем актуальный токен от уже использованного.
+
+func (uc *userUseCase) Refresh(ctx context.Context, refreshToken string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	sessionID, secret, ok := parseRefreshToken(refreshToken)
+	if !ok {
+		return usecase.AuthResult{}, usecase.ErrInvalidRefreshToken
+	}
+
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return usecase.AuthResult{}, usecase.ErrInvalidRefreshToken
+	}
+	if !session.Active(time.Now()) {
+		return usecase.AuthResult{}, usecase.ErrSessionRevoked
+	}
+
+	oldHash := randtoken.Hash(secret)
+	if oldHash != session.RefreshTokenHash {
+		// Предъявлен уже ротированный токен — он утёк, отзываем всё семейство
+		return usecase.AuthResult{}, uc.revokeReusedSession(ctx, session.ID)
+	}
+
+	newSecret, err := randtoken.Generate()
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+	refreshExpiresAt := time.Now().Add(refreshTokenTTL)
+	rotated, err := uc.sessionRepo.Rotate(ctx, session.ID, oldHash, randtoken.Hash(newSecret), refreshExpiresAt)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("rotate session: %w", err)
+	}
+	if !rotated {
+		// Токен успели использовать параллельно — это тоже повторное использование
+		return usecase.AuthResult{}, uc.revokeReusedSession(ctx, session.ID)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("user not found: %w", err)
+	}
+	if user.Banned() {
+		if err := uc.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return usecase.AuthResult{}, fmt.Errorf("revoke session: %w", err)
+		}
+		return usecase.AuthResult{}, usecase.ErrBanned
+	}
+	// Устройство в списке сессий — вспомогательная информация, из-за неё обновление не ломаем
+	if err := uc.sessionRepo.Touch(ctx, session.ID, time.Now(), client.IP, truncateUserAgent(client.UserAgent)); err != nil {
+		log.Printf("session %s: touch failed: %v", session.ID, err)
+	}
+
+	return uc.buildAuthResult(user, session.ID, formatRefreshToken(session.ID, newSecret), refreshExpiresAt)
+}
+
+func (uc *userUseCase) Logout(ctx context.Context, sessionID uuid.UUID, refreshToken string) error {
+	if sessionID == uuid.Nil {
+		id, secret, ok := parseRefreshToken(refreshToken)
+		if !ok {
+			return nil
+		}
+		session, err := uc.sessionRepo.GetByID(ctx, id)
+		if err != nil || session.RefreshTokenHash != randtoken.Hash(secret) {
+			return nil
+		}
+		sessionID = id
+	}
+	if err := uc.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+func (uc *userUseCase) ValidateSession(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return usecase.ErrSessionRevoked
+	}
+	if !session.Active(time.Now()) {
+		return usecase.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (uc *userUseCase) GetSessions(ctx context.Context, userID uuid.UUID) ([]entity.Session, error) {
+	return uc.sessionRepo.GetActiveByUserID(ctx, userID, time.Now())
+}
+
+func (uc *userUseCase) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || !session.Active(time.Now()) {
+		return fmt.Errorf("session %w", usecase.ErrNotFound)
+	}
+	if err := uc.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+func (uc *userUseCase) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) error {
+	if err := uc.sessionRepo.RevokeAllByUserID(ctx, userID, currentSessionID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// startSession создаёт новую сессию (семейство refresh-токенов) и выдаёт пару токенов
+func (uc *userUseCase) startSession(ctx context.Context, user entity.User, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	if user.Banned() {
+		return usecase.AuthResult{}, usecase.ErrBanned
+	}
+	if err := uc.cancelDeletion(ctx, &user); err != nil {
+		return usecase.AuthResult{}, err
+	}
+	secret, err := randtoken.Generate()
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	session := entity.Session{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		RefreshTokenHash: randtoken.Hash(secret),
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		UserAgent:        truncateUserAgent(client.UserAgent),
+		IP:               client.IP,
+		LastSeenAt:       now,
+	}
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("create session: %w", err)
+	}
+
+	return uc.buildAuthResult(user, session.ID, formatRefreshToken(session.ID, secret), session.ExpiresAt)
+}
+
+func (uc *userUseCase) buildAuthResult(user entity.User, sessionID uuid.UUID, refreshToken string, refreshExpiresAt time.Time) (usecase.AuthResult, error) {
+	expiresAt := time.Now().Add(accessTokenTTL)
+	token, err := uc.generateToken(user, sessionID, expiresAt)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("generate token: %w", err)
+	}
+	return usecase.AuthResult{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
+	}, nil
+}
+
+func (uc *userUseCase) revokeReusedSession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := uc.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return fmt.Errorf("revoke reused session: %w", err)
+	}
+	return usecase.ErrSessionRevoked
+}
+
+func (uc *userUseCase) generateToken(user entity.User, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	claims := &Claims{
+		Username:  user.Username,
+		Id:        user.ID,
+		SessionID: sessionID,
+		Role:      userRole(user),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+	return uc.jwtKeys.Sign(claims)
+}
+
+// userRole — роль для claims; у аккаунтов, созданных до появления ролей, она пустая
+func userRole(user entity.User) string {
+	if user.Role == "" {
+		return entity.RoleUser
+	}
+	return user.Role
+}
+
+func truncateUserAgent(ua string) string {
+	if len(ua) <= maxUserAgentLen {
+		return ua
+	}
+	// Не разрезаем многобайтовый символ посередине
+	cut := maxUserAgentLen
+	for cut > 0 && !utf8.RuneStart(ua[cut]) {
+		cut--
+	}
+	return ua[:cut]
+}
+
+func formatRefreshToken(sessionID uuid.UUID, secret string) string {
+	return sessionID.String() + "." + secret
+}
+
+func parseRefreshToken(token string) (uuid.UUID, string, bool) {
+	idStr, secret, found := strings.Cut(token, ".")
+	if !found || secret == "" {
+		return uuid.Nil, "", false
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, "", false
+	}
+	return id, secret, true
+}
@@ -0,0 +1,207 @@
+package user_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+	"main/pkg/randtoken"
+)
+
+// newSession возвращает активную сессию и refresh-токен к ней
+func newSession(userID uuid.UUID) (entity.Session, string) {
+	secret, _ := randtoken.Generate()
+	s := entity.Session{
+		ID:               uuid.New(),
+		UserID:           userID,
+		RefreshTokenHash: randtoken.Hash(secret),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+	return s, s.ID.String() + "." + secret
+}
+
+func TestRefresh_Rotates(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+
+	userID := uuid.New()
+	session, token := newSession(userID)
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Rotate", mock.Anything, session.ID, session.RefreshTokenHash, mock.Anything, mock.Anything).Return(true, nil)
+	ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Username: "alice"}, nil)
+	sr.On("Touch", mock.Anything, session.ID, mock.Anything, "10.0.0.1", "Firefox").Return(nil)
+
+	result, err := uc.Refresh(context.Background(), token, usecase.ClientInfo{IP: "10.0.0.1", UserAgent: "Firefox"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Token)
+	assert.NotEqual(t, token, result.RefreshToken)
+	assert.Contains(t, result.RefreshToken, session.ID.String()+".")
+	sr.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	sr.AssertCalled(t, "Touch", mock.Anything, session.ID, mock.Anything, "10.0.0.1", "Firefox")
+}
+
+func TestRefresh_BannedUser(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+
+	userID := uuid.New()
+	bannedAt := time.Now()
+	session, token := newSession(userID)
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Rotate", mock.Anything, session.ID, session.RefreshTokenHash, mock.Anything, mock.Anything).Return(true, nil)
+	sr.On("Revoke", mock.Anything, session.ID).Return(nil)
+	ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, BannedAt: &bannedAt}, nil)
+
+	_, err := uc.Refresh(context.Background(), token, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrBanned)
+	sr.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	session, _ := newSession(uuid.New())
+	// В базе уже лежит хеш следующего токена — предъявлен старый
+	stale := session.ID.String() + ".already-rotated-secret"
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Revoke", mock.Anything, session.ID).Return(nil)
+
+	_, err := uc.Refresh(context.Background(), stale, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrSessionRevoked)
+	sr.AssertCalled(t, "Revoke", mock.Anything, session.ID)
+	sr.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_ConcurrentRotationRevokesFamily(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	session, token := newSession(uuid.New())
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Rotate", mock.Anything, session.ID, mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	sr.On("Revoke", mock.Anything, session.ID).Return(nil)
+
+	_, err := uc.Refresh(context.Background(), token, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrSessionRevoked)
+	sr.AssertCalled(t, "Revoke", mock.Anything, session.ID)
+}
+
+func TestRefresh_RevokedSession(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	session, token := newSession(uuid.New())
+	now := time.Now()
+	session.RevokedAt = &now
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+	_, err := uc.Refresh(context.Background(), token, usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrSessionRevoked)
+}
+
+func TestRefresh_MalformedToken(t *testing.T) {
+	uc := newUserUC(&mockrepo.MockUserRepo{}, &mockrepo.MockSessionRepo{})
+
+	for _, token := range []string{"", "no-dot", "not-a-uuid.secret", uuid.NewString() + "."} {
+		_, err := uc.Refresh(context.Background(), token, usecase.ClientInfo{})
+		assert.ErrorIs(t, err, usecase.ErrInvalidRefreshToken, token)
+	}
+}
+
+func TestLogout_BySessionID(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	sid := uuid.New()
+	sr.On("Revoke", mock.Anything, sid).Return(nil)
+
+	require.NoError(t, uc.Logout(context.Background(), sid, ""))
+	sr.AssertExpectations(t)
+}
+
+func TestLogout_ByRefreshToken(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	session, token := newSession(uuid.New())
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Revoke", mock.Anything, session.ID).Return(nil)
+
+	require.NoError(t, uc.Logout(context.Background(), uuid.Nil, token))
+	sr.AssertExpectations(t)
+}
+
+func TestValidateSession(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	active, _ := newSession(uuid.New())
+	expired, _ := newSession(uuid.New())
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	sr.On("GetByID", mock.Anything, active.ID).Return(active, nil)
+	sr.On("GetByID", mock.Anything, expired.ID).Return(expired, nil)
+
+	assert.NoError(t, uc.ValidateSession(context.Background(), active.ID))
+	assert.ErrorIs(t, uc.ValidateSession(context.Background(), expired.ID), usecase.ErrSessionRevoked)
+}
+
+func TestLogin_SessionStoresClient(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+
+	hashed, _ := hasher.New().Hash("password123")
+	ur.On("GetByUsername", mock.Anything, "alice").Return(entity.User{ID: uuid.New(), Username: "alice", Password: hashed}, nil)
+	var created entity.Session
+	sr.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(entity.Session)
+	}).Return(nil)
+
+	longUA := strings.Repeat("я", 400)
+	_, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{IP: "10.0.0.1", UserAgent: longUA})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", created.IP)
+	assert.True(t, strings.HasPrefix(longUA, created.UserAgent))
+	assert.LessOrEqual(t, len(created.UserAgent), 512)
+	assert.True(t, utf8.ValidString(created.UserAgent))
+	assert.WithinDuration(t, time.Now(), created.LastSeenAt, time.Minute)
+}
+
+func TestRevokeSession_OtherUsersSessionNotFound(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	session, _ := newSession(uuid.New())
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+
+	err := uc.RevokeSession(context.Background(), uuid.New(), session.ID)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+	sr.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+}
+
+func TestRevokeSession_Own(t *testing.T) {
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(&mockrepo.MockUserRepo{}, sr)
+
+	userID := uuid.New()
+	session, _ := newSession(userID)
+	sr.On("GetByID", mock.Anything, session.ID).Return(session, nil)
+	sr.On("Revoke", mock.Anything, session.ID).Return(nil)
+
+	require.NoError(t, uc.RevokeSession(context.Background(), userID, session.ID))
+	sr.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package user_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	tgverifier "github.com/electrofocus/telegram-auth-verifier"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	mockrepo "main/mock/repo"
+)
+
+// signedTelegramInput подписывает данные виджета так же, как это делает Telegram
+func signedTelegramInput(id int64, firstName, photoURL string) usecase.TelegramAuthInput {
+	creds := tgverifier.Credentials{
+		ID:        id,
+		FirstName: firstName,
+		PhotoURL:  photoURL,
+		AuthDate:  time.Now().Unix(),
+	}
+	secret := sha256.Sum256([]byte(testBotToken))
+	h := hmac.New(sha256.New, secret[:])
+	h.Write([]byte(creds.String()))
+
+	return usecase.TelegramAuthInput{
+		ID:        creds.ID,
+		FirstName: creds.FirstName,
+		PhotoURL:  creds.PhotoURL,
+		AuthDate:  creds.AuthDate,
+		Hash:      hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+func TestAuthenticateTelegram_NewUser(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	ir := &mockrepo.MockIdentityRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newTelegramUC(ur, ir, sr)
+
+	ir.On("GetByProviderSubject", mock.Anything, entity.IdentityProviderTelegram, "42").
+		Return(entity.UserIdentity{}, errors.New("record not found"))
+	ir.On("CreateWithUser", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := uc.AuthenticateTelegram(context.Background(), signedTelegramInput(42, "Анна", "https://t.me/a.jpg"), usecase.ClientInfo{})
+	require.NoError(t, err)
+
+	created := ir.Calls[1].Arguments.Get(1).(entity.User)
+	identity := ir.Calls[1].Arguments.Get(2).(entity.UserIdentity)
+	assert.Equal(t, "tg_42", created.Username)
+	assert.Empty(t, created.Password, "telegram account must not have a usable password")
+	assert.Equal(t, "Анна", created.DisplayName)
+	assert.Equal(t, "https://t.me/a.jpg", created.Avatar)
+	assert.Equal(t, entity.RoleUser, created.Role)
+	assert.Equal(t, created.ID, identity.UserID)
+	assert.Equal(t, "42", identity.Subject)
+	assert.Equal(t, created.ID, result.User.ID)
+}
+
+func TestAuthenticateTelegram_ExistingIdentity(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	ir := &mockrepo.MockIdentityRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newTelegramUC(ur, ir, sr)
+
+	userID := uuid.New()
+	identity := entity.UserIdentity{ID: uuid.New(), UserID: userID, Provider: entity.IdentityProviderTelegram, Subject: "42"}
+	ir.On("GetByProviderSubject", mock.Anything, entity.IdentityProviderTelegram, "42").Return(identity, nil)
+	ir.On("Update", mock.Anything, mock.Anything).Return(nil)
+	ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Username: "tg_42", DisplayName: "Своё имя"}, nil)
+	ur.On("UpdateColumns", mock.Anything, mock.Anything, []repo.UserColumn{repo.UserColumnAvatar}).Return(nil)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := uc.AuthenticateTelegram(context.Background(), signedTelegramInput(42, "Анна", "https://t.me/a.jpg"), usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, result.User.ID)
+	// Заданное пользователем имя сохраняется, пустой аватар заполняется из Telegram
+	assert.Equal(t, "Своё имя", result.User.DisplayName)
+	assert.Equal(t, "https://t.me/a.jpg", result.User.Avatar)
+	ir.AssertNotCalled(t, "CreateWithUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateTelegram_InvalidHash(t *testing.T) {
+	uc := newTelegramUC(&mockrepo.MockUserRepo{}, &mockrepo.MockIdentityRepo{}, &mockrepo.MockSessionRepo{})
+
+	input := signedTelegramInput(42, "Анна", "")
+	input.Hash = "deadbeef"
+
+	_, err := uc.AuthenticateTelegram(context.Background(), input, usecase.ClientInfo{})
+	require.Error(t, err)
+}
+
+func TestLogin_AccountWithoutPassword(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
+
+	ur.On("GetByUsername", mock.Anything, "tg_42").Return(entity.User{ID: uuid.New(), Username: "tg_42"}, nil)
+
+	_, err := uc.Login(context.Background(), "tg_42", "", usecase.ClientInfo{})
+	require.Error(t, err)
+	assert.Equal(t, "неверный логин или пароль", err.Error())
+}
+
+func TestRegister_ReservedTelegramPrefix(t *testing.T) {
+	uc := newUserUC(&mockrepo.MockUserRepo{}, &mockrepo.MockSessionRepo{})
+
+	_, err := uc.Register(context.Background(), "tg_42", "password123", usecase.ClientInfo{})
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"main/internal/usecase"
+)
+
+// defaultWebAppMaxAge — initData выдаётся при открытии Mini App, так что свежие данные есть всегда,
+// а старые подписанные initData не должны годиться для входа навсегда
+const defaultWebAppMaxAge = time.Hour
+
+// webAppClockSkew — допустимое опережение auth_date относительно наших часов
+const webAppClockSkew = time.Minute
+
+var errWebAppAuth = errors.New("authentication failed")
+
+func (uc *userUseCase) AuthenticateTelegramWebApp(ctx context.Context, initData string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	input, err := uc.verifyWebAppInitData(initData, time.Now())
+	if err != nil {
+		return usecase.AuthResult{}, err
+	}
+	return uc.loginTelegram(ctx, input, client)
+}
+
+// verifyWebAppInitData проверяет подпись initData Mini App и возвращает данные пользователя.
+// Подпись: HMAC-SHA256 от data-check-string — всех полей, кроме hash, в виде key=value,
+// отсортированных по ключу и разделённых \n; ключ — HMAC-SHA256 токена бота с ключом "WebAppData"
+func (uc *userUseCase) verifyWebAppInitData(initData string, now time.Time) (usecase.TelegramAuthInput, error) {
+	if uc.botToken == "" {
+		return usecase.TelegramAuthInput{}, errors.New("BOT_TOKEN is not set")
+	}
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return usecase.TelegramAuthInput{}, errWebAppAuth
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return usecase.TelegramAuthInput{}, errWebAppAuth
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(uc.botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	if !hmac.Equal(mac.Sum(nil), hash) {
+		return usecase.TelegramAuthInput{}, errWebAppAuth
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return usecase.TelegramAuthInput{}, errWebAppAuth
+	}
+	signedAt := time.Unix(authDate, 0)
+	if now.Sub(signedAt) > uc.webAppMaxAge || signedAt.Sub(now) > webAppClockSkew {
+		return usecase.TelegramAuthInput{}, errors.New("данные Telegram устарели, откройте приложение заново")
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Username  string `json:"username"`
+		PhotoURL  string `json:"photo_url"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return usecase.TelegramAuthInput{}, errWebAppAuth
+	}
+	return usecase.TelegramAuthInput{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		PhotoURL:  user.PhotoURL,
+		Username:  user.Username,
+		AuthDate:  authDate,
+	}, nil
+}
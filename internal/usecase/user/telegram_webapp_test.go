@@ -0,0 +1,118 @@
+package user_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	mockrepo "main/mock/repo"
+)
+
+// signedInitData подписывает initData так же, как Telegram для Mini App
+func signedInitData(botToken string, values url.Values) string {
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	signed := url.Values{}
+	for key := range values {
+		signed.Set(key, values.Get(key))
+	}
+	signed.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return signed.Encode()
+}
+
+func webAppValues(authDate time.Time) url.Values {
+	return url.Values{
+		"query_id":  {"AAHdF6IQAAAAAN0XohDhrOrc"},
+		"user":      {`{"id":42,"first_name":"Анна","username":"anna","photo_url":"https://t.me/a.jpg","language_code":"ru"}`},
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+	}
+}
+
+func TestAuthenticateTelegramWebApp_SameAccountAsWidget(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	ir := &mockrepo.MockIdentityRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newTelegramUC(ur, ir, sr)
+
+	userID := uuid.New()
+	identity := entity.UserIdentity{ID: uuid.New(), UserID: userID, Provider: entity.IdentityProviderTelegram, Subject: "42"}
+	ir.On("GetByProviderSubject", mock.Anything, entity.IdentityProviderTelegram, "42").Return(identity, nil)
+	ir.On("Update", mock.Anything, mock.Anything).Return(nil)
+	ur.On("GetByID", mock.Anything, userID).Return(entity.User{ID: userID, Username: "tg_42", DisplayName: "Анна"}, nil)
+	ur.On("UpdateColumns", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	initData := signedInitData(testBotToken, webAppValues(time.Now().Add(-time.Minute)))
+	result, err := uc.AuthenticateTelegramWebApp(context.Background(), initData, usecase.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, result.User.ID)
+	assert.NotEmpty(t, result.Token)
+
+	updated := ir.Calls[1].Arguments.Get(1).(entity.UserIdentity)
+	assert.Equal(t, "anna", updated.Username)
+	ir.AssertNotCalled(t, "CreateWithUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateTelegramWebApp_Rejects(t *testing.T) {
+	fresh := time.Now().Add(-time.Minute)
+	tampered := signedInitData(testBotToken, webAppValues(fresh))
+	tampered = strings.Replace(tampered, url.QueryEscape(`"id":42`), url.QueryEscape(`"id":43`), 1)
+	noUser := webAppValues(fresh)
+	noUser.Del("user")
+
+	tests := []struct {
+		name     string
+		initData string
+	}{
+		{name: "empty", initData: ""},
+		{name: "unsigned", initData: webAppValues(fresh).Encode()},
+		{name: "tampered user", initData: tampered},
+		{name: "another bot", initData: signedInitData("999:other-bot", webAppValues(fresh))},
+		{name: "expired", initData: signedInitData(testBotToken, webAppValues(time.Now().Add(-2*time.Hour)))},
+		{name: "from the future", initData: signedInitData(testBotToken, webAppValues(time.Now().Add(time.Hour)))},
+		{name: "no user", initData: signedInitData(testBotToken, noUser)},
+		// Подпись виджета не подходит для Mini App: ключи выводятся по-разному
+		{name: "widget signature", initData: func() string {
+			input := signedTelegramInput(42, "Анна", "")
+			return url.Values{
+				"id":         {"42"},
+				"first_name": {"Анна"},
+				"auth_date":  {strconv.FormatInt(input.AuthDate, 10)},
+				"hash":       {input.Hash},
+			}.Encode()
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := &mockrepo.MockIdentityRepo{}
+			uc := newTelegramUC(&mockrepo.MockUserRepo{}, ir, &mockrepo.MockSessionRepo{})
+
+			_, err := uc.AuthenticateTelegramWebApp(context.Background(), tt.initData, usecase.ClientInfo{})
+			require.Error(t, err)
+			ir.AssertNotCalled(t, "GetByProviderSubject", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
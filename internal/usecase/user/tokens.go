@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/pkg/randtoken"
+)
+
+// issueToken создаёт одноразовый токен; наружу уходит сам токен, в базе остаётся только хеш
+func (uc *userUseCase) issueToken(ctx context.Context, userID uuid.UUID, purpose, payload string, ttl time.Duration) (string, error) {
+	raw, err := randtoken.Generate()
+	if err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := entity.UserToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: randtoken.Hash(raw),
+		Payload:   payload,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := uc.tokenRepo.Create(ctx, token); err != nil {
+		return "", fmt.Errorf("create token: %w", err)
+	}
+	return raw, nil
+}
+
+// consumeToken гасит токен; любая неудача — ErrInvalidToken, чтобы не подсказывать причину
+func (uc *userUseCase) consumeToken(ctx context.Context, purpose, raw string) (entity.UserToken, error) {
+	if raw == "" {
+		return entity.UserToken{}, usecase.ErrInvalidToken
+	}
+	token, err := uc.tokenRepo.Consume(ctx, purpose, randtoken.Hash(raw), time.Now())
+	if err != nil {
+		return entity.UserToken{}, usecase.ErrInvalidToken
+	}
+	return token, nil
+}
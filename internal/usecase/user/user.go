@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	tgverifier "github.com/electrofocus/telegram-auth-verifier"
@@ -15,31 +16,100 @@ import (
 	"main/internal/repo"
 	"main/internal/usecase"
 	"main/pkg/hasher"
+	"main/pkg/jwtkeys"
+	"main/pkg/mailer"
 )
 
 type Claims struct {
-	Username string    `json:"username"`
-	Id       uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Id        uuid.UUID `json:"id"`
+	SessionID uuid.UUID `json:"sid"`
+	Role      string    `json:"role"`
 	jwt.StandardClaims
 }
 
+// telegramUsernamePrefix — префикс служебных username Telegram-аккаунтов, недоступный при регистрации
+const telegramUsernamePrefix = "tg_"
+
 type userUseCase struct {
-	userRepo  repo.UserRepo
-	hasher    hasher.PasswordHasher
-	jwtSecret string
-	botToken  string
+	userRepo        repo.UserRepo
+	identityRepo    repo.IdentityRepo
+	sessionRepo     repo.SessionRepo
+	tokenRepo       repo.UserTokenRepo
+	attemptRepo     repo.LoginAttemptRepo
+	accessTokenRepo repo.AccessTokenRepo
+	redirectRepo    repo.HandleRedirectRepo
+	wishlistRepo    repo.WishlistRepo
+	hasher          hasher.PasswordHasher
+	mailer          mailer.Mailer
+	jwtKeys         *jwtkeys.KeySet
+	jwtSecret       string
+	botToken        string
+	webAppMaxAge    time.Duration
+	frontendURL     string
+	deletionGrace   time.Duration
 }
 
-func New(userRepo repo.UserRepo, h hasher.PasswordHasher, jwtSecret, botToken string) usecase.UserUseCase {
-	return &userUseCase{
-		userRepo:  userRepo,
-		hasher:    h,
-		jwtSecret: jwtSecret,
-		botToken:  botToken,
+// Deps — зависимости userUseCase
+type Deps struct {
+	UserRepo        repo.UserRepo
+	IdentityRepo    repo.IdentityRepo
+	SessionRepo     repo.SessionRepo
+	TokenRepo       repo.UserTokenRepo
+	AttemptRepo     repo.LoginAttemptRepo
+	AccessTokenRepo repo.AccessTokenRepo
+	RedirectRepo    repo.HandleRedirectRepo
+	WishlistRepo    repo.WishlistRepo // вишлисты публичного профиля
+	Hasher          hasher.PasswordHasher
+	Mailer          mailer.Mailer
+	JWTKeys         *jwtkeys.KeySet // подпись access-токенов
+	JWTSecret       string          // ключ внутренних mfa-токенов
+	BotToken        string
+	WebAppMaxAge    time.Duration // срок годности initData Telegram Mini App; 0 — по умолчанию
+	FrontendURL     string        // база для ссылок в письмах
+	DeletionGrace   time.Duration // срок до окончательного удаления аккаунта; 0 — по умолчанию
+}
+
+// New не принимает пустой JWTSecret: с ним ключ mfa-токенов был бы общеизвестен
+func New(d Deps) (usecase.UserUseCase, error) {
+	if d.JWTSecret == "" {
+		return nil, errors.New("user: JWT secret is required")
 	}
+	if d.DeletionGrace <= 0 {
+		d.DeletionGrace = defaultDeletionGrace
+	}
+	if d.WebAppMaxAge <= 0 {
+		d.WebAppMaxAge = defaultWebAppMaxAge
+	}
+	return &userUseCase{
+		userRepo:        d.UserRepo,
+		identityRepo:    d.IdentityRepo,
+		sessionRepo:     d.SessionRepo,
+		tokenRepo:       d.TokenRepo,
+		attemptRepo:     d.AttemptRepo,
+		accessTokenRepo: d.AccessTokenRepo,
+		redirectRepo:    d.RedirectRepo,
+		wishlistRepo:    d.WishlistRepo,
+		hasher:          d.Hasher,
+		mailer:          d.Mailer,
+		jwtKeys:         d.JWTKeys,
+		jwtSecret:       d.JWTSecret,
+		botToken:        d.BotToken,
+		webAppMaxAge:    d.WebAppMaxAge,
+		frontendURL:     strings.TrimRight(d.FrontendURL, "/"),
+		deletionGrace:   d.DeletionGrace,
+	}, nil
 }
 
-func (uc *userUseCase) Register(ctx context.Context, username, password string) (usecase.AuthResult, error) {
+func (uc *userUseCase) Register(ctx context.Context, username, password string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	if strings.HasPrefix(strings.ToLower(username), telegramUsernamePrefix) {
+		return usecase.AuthResult{}, fmt.Errorf("username не может начинаться с %q", telegramUsernamePrefix)
+	}
+	// "@" зарезервирован за входом по email
+	if strings.Contains(username, "@") {
+		return usecase.AuthResult{}, errors.New("username не может содержать @")
+	}
+
 	_, err := uc.userRepo.GetByUsername(ctx, username)
 	if err == nil {
 		return usecase.AuthResult{}, errors.New("пользователь с таким username уже существует")
@@ -54,41 +124,122 @@ func (uc *userUseCase) Register(ctx context.Context, username, password string)
 		ID:       uuid.New(),
 		Username: username,
 		Password: hashed,
+		Role:     entity.RoleUser,
 	}
 
 	if err := uc.userRepo.Create(ctx, user); err != nil {
 		return usecase.AuthResult{}, fmt.Errorf("create user: %w", err)
 	}
 
-	token, err := uc.generateToken(user)
-	if err != nil {
-		return usecase.AuthResult{}, fmt.Errorf("generate token: %w", err)
+	return uc.startSession(ctx, user, client)
+}
+
+func (uc *userUseCase) Login(ctx context.Context, login, password string, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	user, findErr := uc.findByLogin(ctx, login)
+	// Несуществующие логины тоже считаем — иначе перебор выдавал бы, какие аккаунты есть
+	key := unknownLoginKey(login)
+	if findErr == nil {
+		key = accountKey(user.ID)
+	}
+	if err := uc.checkLockout(ctx, key, client); err != nil {
+		return usecase.AuthResult{}, err
 	}
 
-	return usecase.AuthResult{Token: token, User: user}, nil
+	if findErr != nil || !uc.passwordMatches(user, password) {
+		uc.registerFailure(ctx, key, client)
+		return usecase.AuthResult{}, errors.New("неверный логин или пароль")
+	}
+	uc.clearLockout(ctx, key, user.ID)
+	user = uc.upgradePasswordHash(ctx, user, password)
+
+	return uc.completeLogin(ctx, user, client)
 }
 
-func (uc *userUseCase) Login(ctx context.Context, username, password string) (usecase.AuthResult, error) {
-	user, err := uc.userRepo.GetByUsername(ctx, username)
-	if err != nil {
-		return usecase.AuthResult{}, errors.New("неверный логин или пароль")
+func (uc *userUseCase) passwordMatches(user entity.User, password string) bool {
+	// Пустой пароль — аккаунт без входа по паролю (например, только Telegram)
+	if user.Password == "" {
+		return false
 	}
+	return uc.hasher.Compare(user.Password, password) == nil
+}
 
-	if err := uc.hasher.Compare(user.Password, password); err != nil {
-		return usecase.AuthResult{}, errors.New("неверный логин или пароль")
+// findByLogin ищет по username, а для строк с "@" — ещё и по подтверждённому email
+func (uc *userUseCase) findByLogin(ctx context.Context, login string) (entity.User, error) {
+	user, err := uc.userRepo.GetByUsername(ctx, login)
+	if err == nil || !strings.Contains(login, "@") {
+		return user, err
+	}
+	email, emailErr := normalizeEmail(login)
+	if emailErr != nil {
+		return entity.User{}, err
 	}
+	return uc.userRepo.GetByVerifiedEmail(ctx, email)
+}
+
+func (uc *userUseCase) AuthenticateTelegram(ctx context.Context, input usecase.TelegramAuthInput, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	if err := uc.verifyTelegram(input); err != nil {
+		return usecase.AuthResult{}, err
+	}
+	return uc.loginTelegram(ctx, input, client)
+}
 
-	token, err := uc.generateToken(user)
+// loginTelegram входит по проверенным данным Telegram — виджета или Mini App: оба дают один
+// и тот же Telegram ID, поэтому попадают в один аккаунт
+func (uc *userUseCase) loginTelegram(ctx context.Context, input usecase.TelegramAuthInput, client usecase.ClientInfo) (usecase.AuthResult, error) {
+	subject := strconv.FormatInt(input.ID, 10)
+
+	identity, err := uc.identityRepo.GetByProviderSubject(ctx, entity.IdentityProviderTelegram, subject)
 	if err != nil {
-		return usecase.AuthResult{}, fmt.Errorf("generate token: %w", err)
+		// Первый вход — создаём пользователя без пароля и с профилем из Telegram
+		user := entity.User{
+			ID:          uuid.New(),
+			Username:    telegramUsernamePrefix + subject,
+			DisplayName: input.FirstName,
+			Avatar:      input.PhotoURL,
+			Role:        entity.RoleUser,
+		}
+		identity = newTelegramIdentity(user.ID, subject, input)
+		if err := uc.identityRepo.CreateWithUser(ctx, user, identity); err != nil {
+			return usecase.AuthResult{}, fmt.Errorf("create telegram user: %w", err)
+		}
+		return uc.startSession(ctx, user, client)
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, identity.UserID)
+	if err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("user not found: %w", err)
+	}
+
+	identity.Username = input.Username
+	identity.FirstName = input.FirstName
+	identity.LastName = input.LastName
+	identity.PhotoURL = input.PhotoURL
+	if err := uc.identityRepo.Update(ctx, identity); err != nil {
+		return usecase.AuthResult{}, fmt.Errorf("update telegram identity: %w", err)
+	}
+
+	// Заполняем только пустые поля — то, что пользователь задал сам, не перетираем
+	var changed []repo.UserColumn
+	if user.DisplayName == "" && input.FirstName != "" {
+		user.DisplayName = input.FirstName
+		changed = append(changed, repo.UserColumnDisplayName)
+	}
+	if user.Avatar == "" && input.PhotoURL != "" {
+		user.Avatar = input.PhotoURL
+		changed = append(changed, repo.UserColumnAvatar)
+	}
+	if len(changed) > 0 {
+		if err := uc.userRepo.UpdateColumns(ctx, user, changed...); err != nil {
+			return usecase.AuthResult{}, fmt.Errorf("update user: %w", err)
+		}
 	}
 
-	return usecase.AuthResult{Token: token, User: user}, nil
+	return uc.completeLogin(ctx, user, client)
 }
 
-func (uc *userUseCase) AuthenticateTelegram(ctx context.Context, input usecase.TelegramAuthInput) (usecase.AuthResult, error) {
+func (uc *userUseCase) verifyTelegram(input usecase.TelegramAuthInput) error {
 	if uc.botToken == "" {
-		return usecase.AuthResult{}, errors.New("BOT_TOKEN is not set")
+		return errors.New("BOT_TOKEN is not set")
 	}
 
 	creds := tgverifier.Credentials{
@@ -102,33 +253,22 @@ func (uc *userUseCase) AuthenticateTelegram(ctx context.Context, input usecase.T
 	}
 
 	if err := creds.Verify([]byte(uc.botToken)); err != nil {
-		return usecase.AuthResult{}, errors.New("authentication failed")
-	}
-
-	userIDStr := strconv.FormatInt(input.ID, 10)
-
-	existingUser, err := uc.userRepo.GetByUsername(ctx, userIDStr)
-	var user entity.User
-	if err != nil {
-		// Пользователь не найден — создаём нового
-		user = entity.User{
-			ID:       uuid.New(),
-			Username: userIDStr,
-			Password: userIDStr,
-		}
-		if err := uc.userRepo.Create(ctx, user); err != nil {
-			return usecase.AuthResult{}, fmt.Errorf("create telegram user: %w", err)
-		}
-	} else {
-		user = existingUser
+		return errors.New("authentication failed")
 	}
+	return nil
+}
 
-	token, err := uc.generateToken(user)
-	if err != nil {
-		return usecase.AuthResult{}, fmt.Errorf("generate token: %w", err)
+func newTelegramIdentity(userID uuid.UUID, subject string, input usecase.TelegramAuthInput) entity.UserIdentity {
+	return entity.UserIdentity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Provider:  entity.IdentityProviderTelegram,
+		Subject:   subject,
+		Username:  input.Username,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		PhotoURL:  input.PhotoURL,
 	}
-
-	return usecase.AuthResult{Token: token, User: user}, nil
 }
 
 func (uc *userUseCase) GetMe(ctx context.Context, userID uuid.UUID) (entity.User, error) {
@@ -144,29 +284,20 @@ func (uc *userUseCase) UpdateProfile(ctx context.Context, userID uuid.UUID, inpu
 	if err != nil {
 		return entity.User{}, fmt.Errorf("user not found: %w", err)
 	}
+	var changed []repo.UserColumn
 	if input.DisplayName != nil {
 		if len([]rune(*input.DisplayName)) > 100 {
 			return entity.User{}, fmt.Errorf("display name exceeds 100 characters")
 		}
 		user.DisplayName = *input.DisplayName
+		changed = append(changed, repo.UserColumnDisplayName)
 	}
 	if input.Avatar != nil {
 		user.Avatar = *input.Avatar
+		changed = append(changed, repo.UserColumnAvatar)
 	}
-	if err := uc.userRepo.Update(ctx, user); err != nil {
+	if err := uc.userRepo.UpdateColumns(ctx, user, changed...); err != nil {
 		return entity.User{}, fmt.Errorf("update user: %w", err)
 	}
 	return user, nil
 }
-
-func (uc *userUseCase) generateToken(user entity.User) (string, error) {
-	claims := &Claims{
-		Username: user.Username,
-		Id:       user.ID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 30).Unix(),
-		},
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(uc.jwtSecret))
-}
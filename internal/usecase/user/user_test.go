@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -16,28 +17,83 @@ import (
 	userUC "main/internal/usecase/user"
 	mockrepo "main/mock/repo"
 	"main/pkg/hasher"
+	"main/pkg/jwtkeys"
+	"main/pkg/mailer"
 )
 
-const testJWTSecret = "test-secret-key"
+const (
+	testJWTSecret = "test-secret-key"
+	testBotToken  = "123456:test-bot-token"
+)
+
+// newTestUC заполняет незаданные зависимости пустыми моками
+func newTestUC(d userUC.Deps) usecase.UserUseCase {
+	if d.UserRepo == nil {
+		d.UserRepo = &mockrepo.MockUserRepo{}
+	}
+	if d.IdentityRepo == nil {
+		d.IdentityRepo = &mockrepo.MockIdentityRepo{}
+	}
+	if d.SessionRepo == nil {
+		d.SessionRepo = &mockrepo.MockSessionRepo{}
+	}
+	if d.TokenRepo == nil {
+		d.TokenRepo = &mockrepo.MockUserTokenRepo{}
+	}
+	if d.AttemptRepo == nil {
+		d.AttemptRepo = newMemAttemptRepo()
+	}
+	if d.AccessTokenRepo == nil {
+		d.AccessTokenRepo = &mockrepo.MockAccessTokenRepo{}
+	}
+	if d.RedirectRepo == nil {
+		d.RedirectRepo = &mockrepo.MockHandleRedirectRepo{}
+	}
+	if d.WishlistRepo == nil {
+		d.WishlistRepo = &mockrepo.MockWishlistRepo{}
+	}
+	if d.Mailer == nil {
+		d.Mailer = mailer.NewMemory()
+	}
+	d.Hasher = hasher.New()
+	d.JWTKeys = jwtkeys.NewHMAC(testJWTSecret)
+	d.JWTSecret = testJWTSecret
+	d.BotToken = testBotToken
+	d.FrontendURL = "https://example.com"
+	uc, err := userUC.New(d)
+	if err != nil {
+		panic(err)
+	}
+	return uc
+}
+
+func newUserUC(ur *mockrepo.MockUserRepo, sr *mockrepo.MockSessionRepo) usecase.UserUseCase {
+	return newTestUC(userUC.Deps{UserRepo: ur, SessionRepo: sr})
+}
 
-func newUserUC(ur *mockrepo.MockUserRepo) usecase.UserUseCase {
-	return userUC.New(ur, hasher.New(), testJWTSecret, "")
+func newTelegramUC(ur *mockrepo.MockUserRepo, ir *mockrepo.MockIdentityRepo, sr *mockrepo.MockSessionRepo) usecase.UserUseCase {
+	return newTestUC(userUC.Deps{UserRepo: ur, IdentityRepo: ir, SessionRepo: sr})
+}
+
+func TestNew_RequiresJWTSecret(t *testing.T) {
+	_, err := userUC.New(userUC.Deps{JWTKeys: jwtkeys.NewHMAC(testJWTSecret)})
+	require.Error(t, err)
 }
 
 func TestRegister_DuplicateUsername(t *testing.T) {
 	ur := &mockrepo.MockUserRepo{}
-	uc := newUserUC(ur)
+	uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
 
 	ur.On("GetByUsername", mock.Anything, "alice").Return(entity.User{Username: "alice"}, nil)
 
-	_, err := uc.Register(context.Background(), "alice", "password123")
+	_, err := uc.Register(context.Background(), "alice", "password123", usecase.ClientInfo{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "уже существует")
 }
 
 func TestLogin_WrongPassword(t *testing.T) {
 	ur := &mockrepo.MockUserRepo{}
-	uc := newUserUC(ur)
+	uc := newUserUC(ur, &mockrepo.MockSessionRepo{})
 
 	h := hasher.New()
 	hashed, _ := h.Hash("correctpassword")
@@ -47,14 +103,16 @@ func TestLogin_WrongPassword(t *testing.T) {
 		Password: hashed,
 	}, nil)
 
-	_, err := uc.Login(context.Background(), "bob", "wrongpassword")
+	_, err := uc.Login(context.Background(), "bob", "wrongpassword", usecase.ClientInfo{})
 	require.Error(t, err)
 	assert.Equal(t, "неверный логин или пароль", err.Error())
 }
 
 func TestLogin_Success(t *testing.T) {
 	ur := &mockrepo.MockUserRepo{}
-	uc := newUserUC(ur)
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
 
 	h := hasher.New()
 	hashed, _ := h.Hash("password123")
@@ -65,7 +123,7 @@ func TestLogin_Success(t *testing.T) {
 		Password: hashed,
 	}, nil)
 
-	result, err := uc.Login(context.Background(), "alice", "password123")
+	result, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, result.Token)
 
@@ -77,16 +135,41 @@ func TestLogin_Success(t *testing.T) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 	require.True(t, ok)
 	assert.Equal(t, userID.String(), claims["id"].(string))
+	assert.NotEmpty(t, claims["sid"])
+	assert.Equal(t, entity.RoleUser, claims["role"])
+	assert.NotEmpty(t, result.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), result.ExpiresAt, time.Minute)
+}
+
+func TestLogin_Banned(t *testing.T) {
+	ur := &mockrepo.MockUserRepo{}
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+
+	hashed, _ := hasher.New().Hash("password123")
+	bannedAt := time.Now()
+	ur.On("GetByUsername", mock.Anything, "alice").Return(entity.User{
+		ID:       uuid.New(),
+		Username: "alice",
+		Password: hashed,
+		BannedAt: &bannedAt,
+	}, nil)
+
+	_, err := uc.Login(context.Background(), "alice", "password123", usecase.ClientInfo{})
+	require.ErrorIs(t, err, usecase.ErrBanned)
+	sr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
 
 func TestRegister_NotFound_ThenCreates(t *testing.T) {
 	ur := &mockrepo.MockUserRepo{}
-	uc := newUserUC(ur)
+	sr := &mockrepo.MockSessionRepo{}
+	uc := newUserUC(ur, sr)
+	sr.On("Create", mock.Anything, mock.Anything).Return(nil)
 
 	ur.On("GetByUsername", mock.Anything, "newuser").Return(entity.User{}, errors.New("not found"))
 	ur.On("Create", mock.Anything, mock.Anything).Return(nil)
 
-	result, err := uc.Register(context.Background(), "newuser", "password123")
+	result, err := uc.Register(context.Background(), "newuser", "password123", usecase.ClientInfo{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, result.Token)
 	ur.AssertCalled(t, "Create", mock.Anything, mock.Anything)
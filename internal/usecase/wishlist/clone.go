@@ -0,0 +1,124 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// Clone не копирует файлы обложек: копия ссылается на те же объекты, а из хранилища файл
+// удаляется, только когда на него больше никто не ссылается (repo.FileRefRepo)
+func (uc *wishlistUseCase) Clone(ctx context.Context, userID, id uuid.UUID, title string) (entity.Wishlist, error) {
+	src, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if title == "" {
+		title = src.Title
+	}
+	if err := validateWishlistFields(title, "", "", "", ""); err != nil {
+		return entity.Wishlist{}, err
+	}
+
+	count, err := uc.wishlistRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("count wishlists: %w", err)
+	}
+	if count >= usecase.MaxWishlistsPerUser {
+		return entity.Wishlist{}, errors.New("достигнут лимит вишлистов (20)")
+	}
+
+	presents, err := uc.presentRepo.GetAllByWishlistID(ctx, id)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("get presents: %w", err)
+	}
+	sourceIDs := make([]uuid.UUID, len(presents))
+	for i, p := range presents {
+		sourceIDs[i] = p.ID
+	}
+	metas, err := uc.metaRepo.GetByPresentIDs(ctx, sourceIDs)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("get present meta: %w", err)
+	}
+
+	sid, err := uc.generateUniqueShortID(ctx)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+
+	// Снятый модератором вишлист остаётся снятым и в копии
+	w := entity.Wishlist{
+		ID:            uuid.New(),
+		UserID:        userID,
+		ShortID:       sid,
+		Title:         title,
+		Description:   src.Description,
+		Cover:         src.Cover,
+		Settings:      src.Settings,
+		Location:      src.Location,
+		PresentsCount: uint(len(presents)),
+		Blocks:        cloneBlocks(src.Blocks),
+		Unpublished:   src.Unpublished,
+		Visibility:    src.Visibility,
+		PasswordHash:  src.PasswordHash,
+	}
+
+	copies, metas := copyPresents(w.ID, presents, metas)
+	if err := uc.wishlistRepo.CreateWithPresents(ctx, w, copies, metas); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("clone wishlist: %w", err)
+	}
+	w.Role = entity.WishlistRoleOwner
+	return w, nil
+}
+
+// copyPresents копирует подарки в вишлист wishlistID под новыми ID без броней;
+// метаданные переносятся только для скопированных подарков
+func copyPresents(wishlistID uuid.UUID, presents []entity.Present, metas []entity.PresentMeta) ([]entity.Present, []entity.PresentMeta) {
+	newIDs := make(map[uuid.UUID]uuid.UUID, len(presents))
+	copies := make([]entity.Present, len(presents))
+	for i, p := range presents {
+		newIDs[p.ID] = uuid.New()
+		copies[i] = entity.Present{
+			ID:          newIDs[p.ID],
+			WishlistID:  wishlistID,
+			Title:       p.Title,
+			Description: p.Description,
+			Cover:       p.Cover,
+			Link:        p.Link,
+			Price:       clonePrice(p.Price),
+		}
+	}
+	copiedMetas := make([]entity.PresentMeta, 0, len(metas))
+	for _, meta := range metas {
+		if id, ok := newIDs[meta.PresentID]; ok {
+			meta.PresentID = id
+			copiedMetas = append(copiedMetas, meta)
+		}
+	}
+	return copies, copiedMetas
+}
+
+func cloneBlocks(blocks []entity.Block) []entity.Block {
+	if blocks == nil {
+		return nil
+	}
+	result := make([]entity.Block, len(blocks))
+	for i, b := range blocks {
+		result[i] = b
+		result[i].Data = append([]byte(nil), b.Data...)
+	}
+	return result
+}
+
+func clonePrice(price *float64) *float64 {
+	if price == nil {
+		return nil
+	}
+	v := *price
+	return &v
+}
@@ -0,0 +1,146 @@
+package wishlist_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+type cloneFixture struct {
+	uc  usecase.WishlistUseCase
+	wr  *mockrepo.MockWishlistRepo
+	pr  *mockrepo.MockPresentRepo
+	mr  *mockrepo.MockPresentMetaRepo
+	cr  *mockrepo.MockCollaboratorRepo
+	src entity.Wishlist
+}
+
+// newCloneFixture — у владельца уже owned вишлистов, включая копируемый
+func newCloneFixture(owned int64) cloneFixture {
+	price := 1990.0
+	f := cloneFixture{
+		wr: &mockrepo.MockWishlistRepo{},
+		pr: &mockrepo.MockPresentRepo{},
+		mr: &mockrepo.MockPresentMetaRepo{},
+		cr: &mockrepo.MockCollaboratorRepo{},
+		src: entity.Wishlist{
+			ID:              uuid.New(),
+			UserID:          uuid.New(),
+			ShortID:         "abc-def-ghi",
+			Title:           "ДР 2025",
+			Cover:           "https://files.example.com/bucket/cover.webp",
+			Settings:        entity.Settings{ColorScheme: "mint", PresentsLayout: "grid2"},
+			Location:        entity.Location{Name: "Дома"},
+			Blocks:          []entity.Block{{Type: "text", ColSpan: 2, Data: json.RawMessage(`{"text":"Привет"}`)}},
+			PresentsCount:   2,
+			ListedOnProfile: true,
+			Visibility:      entity.VisibilityPassword,
+			PasswordHash:    "hash",
+		},
+	}
+	f.wr.On("GetByID", mock.Anything, f.src.ID).Return(f.src, nil)
+	f.wr.On("CountByUserID", mock.Anything, f.src.UserID).Return(owned, nil)
+	f.wr.On("GetByShortID", mock.Anything, mock.Anything).Return(entity.Wishlist{}, errors.New("record not found"))
+	presents := []entity.Present{
+		{ID: uuid.New(), WishlistID: f.src.ID, Title: "Книга", Reserved: true, Price: &price},
+		{ID: uuid.New(), WishlistID: f.src.ID, Title: "Чайник", Link: "https://ozon.ru/1"},
+	}
+	f.pr.On("GetAllByWishlistID", mock.Anything, f.src.ID).Return(presents, nil)
+	f.mr.On("GetByPresentIDs", mock.Anything, []uuid.UUID{presents[0].ID, presents[1].ID}).
+		Return([]entity.PresentMeta{{PresentID: presents[1].ID, Source: "ozon", OriginalURL: "https://ozon.ru/1"}}, nil)
+	f.uc = wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo:     f.wr,
+		PresentRepo:      f.pr,
+		PresentMetaRepo:  f.mr,
+		CollaboratorRepo: f.cr,
+		FileStorage:      &mockminio.MockFileStorage{},
+		Policy:           access.New(f.wr, f.pr, f.cr, testSecret),
+		Hasher:           hasher.New(),
+	})
+	return f
+}
+
+func TestClone_CopiesPresentsAndMeta(t *testing.T) {
+	f := newCloneFixture(1)
+	var (
+		saved    entity.Wishlist
+		presents []entity.Present
+		metas    []entity.PresentMeta
+	)
+	f.wr.On("CreateWithPresents", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(entity.Wishlist)
+		presents = args.Get(2).([]entity.Present)
+		metas = args.Get(3).([]entity.PresentMeta)
+	}).Return(nil)
+
+	w, err := f.uc.Clone(context.Background(), f.src.UserID, f.src.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, saved.ID, w.ID)
+	assert.NotEqual(t, f.src.ID, saved.ID)
+	assert.NotEmpty(t, saved.ShortID)
+	assert.NotEqual(t, f.src.ShortID, saved.ShortID)
+	assert.Equal(t, f.src.Title, saved.Title)
+	assert.Equal(t, f.src.Cover, saved.Cover)
+	assert.Equal(t, f.src.Settings, saved.Settings)
+	assert.Equal(t, f.src.Location, saved.Location)
+	assert.Equal(t, f.src.Blocks, saved.Blocks)
+	assert.Equal(t, f.src.Visibility, saved.Visibility)
+	assert.Equal(t, f.src.PasswordHash, saved.PasswordHash)
+	assert.False(t, saved.ListedOnProfile)
+	assert.Equal(t, uint(2), saved.PresentsCount)
+	assert.Equal(t, entity.WishlistRoleOwner, w.Role)
+
+	require.Len(t, presents, 2)
+	for _, p := range presents {
+		assert.Equal(t, saved.ID, p.WishlistID)
+		assert.False(t, p.Reserved)
+	}
+	assert.Equal(t, "Книга", presents[0].Title)
+	require.NotNil(t, presents[0].Price)
+	assert.Equal(t, 1990.0, *presents[0].Price)
+	require.Len(t, metas, 1)
+	assert.Equal(t, presents[1].ID, metas[0].PresentID)
+	assert.Equal(t, "ozon", metas[0].Source)
+}
+
+func TestClone_CustomTitle(t *testing.T) {
+	f := newCloneFixture(1)
+	f.wr.On("CreateWithPresents", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	w, err := f.uc.Clone(context.Background(), f.src.UserID, f.src.ID, "ДР 2026")
+	require.NoError(t, err)
+	assert.Equal(t, "ДР 2026", w.Title)
+}
+
+func TestClone_Denied(t *testing.T) {
+	t.Run("editor", func(t *testing.T) {
+		f := newCloneFixture(1)
+		editorID := uuid.New()
+		f.cr.On("Get", mock.Anything, f.src.ID, editorID).Return(entity.Collaborator{Role: entity.WishlistRoleEditor}, nil)
+
+		_, err := f.uc.Clone(context.Background(), editorID, f.src.ID, "")
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+		f.wr.AssertNotCalled(t, "CreateWithPresents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("limit reached", func(t *testing.T) {
+		f := newCloneFixture(usecase.MaxWishlistsPerUser)
+
+		_, err := f.uc.Clone(context.Background(), f.src.UserID, f.src.ID, "")
+		require.Error(t, err)
+		f.wr.AssertNotCalled(t, "CreateWithPresents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
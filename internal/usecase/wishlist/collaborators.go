@@ -0,0 +1,236 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/pkg/randtoken"
+)
+
+// inviteTTL — сколько действует ссылка-приглашение в соавторы
+const inviteTTL = 7 * 24 * time.Hour
+
+var errInvalidRole = errors.New("роль соавтора должна быть editor или viewer")
+
+func (uc *wishlistUseCase) GetCollaborators(ctx context.Context, userID, id uuid.UUID) ([]entity.Collaborator, error) {
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionView)
+	if err != nil {
+		return nil, err
+	}
+	// Список соавторов не показываем тем, кто просто видит вишлист по ссылке
+	if w.Role == "" {
+		return nil, usecase.ErrForbidden
+	}
+	collaborators, err := uc.collaboratorRepo.GetAllByWishlistID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+func (uc *wishlistUseCase) AddCollaborator(ctx context.Context, userID, id uuid.UUID, handle, role string) (entity.Collaborator, error) {
+	if !entity.ValidCollaboratorRole(role) {
+		return entity.Collaborator{}, errInvalidRole
+	}
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage)
+	if err != nil {
+		return entity.Collaborator{}, err
+	}
+
+	handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+	if handle == "" {
+		return entity.Collaborator{}, errors.New("укажите handle пользователя")
+	}
+	user, err := uc.userRepo.GetByHandle(ctx, handle)
+	if err != nil || user.Banned() || user.DeletionScheduled() {
+		return entity.Collaborator{}, fmt.Errorf("user %w", usecase.ErrNotFound)
+	}
+	if user.ID == w.UserID {
+		return entity.Collaborator{}, fmt.Errorf("владелец не может быть соавтором: %w", usecase.ErrConflict)
+	}
+
+	c, err := uc.saveCollaborator(ctx, w.ID, user.ID, role)
+	if err != nil {
+		return entity.Collaborator{}, err
+	}
+	c.Handle, c.DisplayName, c.Avatar = user.Handle, user.DisplayName, user.Avatar
+	return c, nil
+}
+
+func (uc *wishlistUseCase) SetCollaboratorRole(ctx context.Context, userID, id, collaboratorID uuid.UUID, role string) (entity.Collaborator, error) {
+	if !entity.ValidCollaboratorRole(role) {
+		return entity.Collaborator{}, errInvalidRole
+	}
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage); err != nil {
+		return entity.Collaborator{}, err
+	}
+	c, err := uc.collaboratorRepo.Get(ctx, id, collaboratorID)
+	if err != nil {
+		return entity.Collaborator{}, fmt.Errorf("collaborator %w: %v", usecase.ErrNotFound, err)
+	}
+	if c.Role == role {
+		return c, nil
+	}
+	c.Role = role
+	if err := uc.collaboratorRepo.Upsert(ctx, c); err != nil {
+		return entity.Collaborator{}, fmt.Errorf("update collaborator: %w", err)
+	}
+	return c, nil
+}
+
+func (uc *wishlistUseCase) RemoveCollaborator(ctx context.Context, userID, id, collaboratorID uuid.UUID) error {
+	if userID != collaboratorID {
+		if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage); err != nil {
+			return err
+		}
+	}
+	if _, err := uc.collaboratorRepo.Get(ctx, id, collaboratorID); err != nil {
+		return fmt.Errorf("collaborator %w: %v", usecase.ErrNotFound, err)
+	}
+	if err := uc.collaboratorRepo.Delete(ctx, id, collaboratorID); err != nil {
+		return fmt.Errorf("remove collaborator: %w", err)
+	}
+	return nil
+}
+
+func (uc *wishlistUseCase) CreateInvite(ctx context.Context, userID, id uuid.UUID, role string) (usecase.WishlistInviteLink, error) {
+	if !entity.ValidCollaboratorRole(role) {
+		return usecase.WishlistInviteLink{}, errInvalidRole
+	}
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage); err != nil {
+		return usecase.WishlistInviteLink{}, err
+	}
+
+	raw, err := randtoken.Generate()
+	if err != nil {
+		return usecase.WishlistInviteLink{}, fmt.Errorf("generate invite token: %w", err)
+	}
+	invite := entity.WishlistInvite{
+		ID:         uuid.New(),
+		WishlistID: id,
+		Role:       role,
+		TokenHash:  randtoken.Hash(raw),
+		CreatedBy:  userID,
+		ExpiresAt:  time.Now().Add(inviteTTL),
+	}
+	if err := uc.inviteRepo.Create(ctx, invite); err != nil {
+		return usecase.WishlistInviteLink{}, fmt.Errorf("create invite: %w", err)
+	}
+	return usecase.WishlistInviteLink{Token: raw, Role: role, ExpiresAt: invite.ExpiresAt}, nil
+}
+
+func (uc *wishlistUseCase) AcceptInvite(ctx context.Context, userID uuid.UUID, token string) (entity.Wishlist, error) {
+	if token == "" {
+		return entity.Wishlist{}, usecase.ErrInvalidToken
+	}
+	hash := randtoken.Hash(token)
+	now := time.Now()
+
+	// Сначала проверяем приглашение без погашения: владелец, открывший свою же ссылку,
+	// не должен её сжечь
+	invite, err := uc.inviteRepo.GetByTokenHash(ctx, hash)
+	if err != nil || invite.UsedAt != nil || !now.Before(invite.ExpiresAt) {
+		return entity.Wishlist{}, usecase.ErrInvalidToken
+	}
+	w, err := uc.wishlistRepo.GetByID(ctx, invite.WishlistID)
+	if err != nil {
+		return entity.Wishlist{}, usecase.ErrInvalidToken
+	}
+	if w.UserID == userID {
+		return entity.Wishlist{}, fmt.Errorf("это ваш вишлист: %w", usecase.ErrConflict)
+	}
+
+	c, err := uc.collaboratorRepo.Get(ctx, w.ID, userID)
+	if err != nil {
+		if err := uc.checkCollaboratorLimit(ctx, w.ID); err != nil {
+			return entity.Wishlist{}, err
+		}
+		c = entity.Collaborator{WishlistID: w.ID, UserID: userID, CreatedAt: now}
+	}
+	if c.Role != entity.WishlistRoleEditor {
+		c.Role = invite.Role
+	}
+	if _, err := uc.inviteRepo.Consume(ctx, hash, now); err != nil {
+		return entity.Wishlist{}, usecase.ErrInvalidToken
+	}
+	if err := uc.collaboratorRepo.Upsert(ctx, c); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("save collaborator: %w", err)
+	}
+	// Ссылку открыл сам пользователь — это и есть согласие на приглашение по handle
+	if c.Pending {
+		if err := uc.collaboratorRepo.Accept(ctx, w.ID, userID); err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return entity.Wishlist{}, fmt.Errorf("accept collaboration: %w", err)
+		}
+	}
+	w.Role = c.Role
+	return w, nil
+}
+
+func (uc *wishlistUseCase) GetPendingCollaborations(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	wishlists, err := uc.wishlistRepo.GetPendingSharedWithUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get pending collaborations: %w", err)
+	}
+	return wishlists, nil
+}
+
+func (uc *wishlistUseCase) AcceptCollaboration(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error) {
+	if err := uc.collaboratorRepo.Accept(ctx, id, userID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return entity.Wishlist{}, fmt.Errorf("invitation %w", usecase.ErrNotFound)
+		}
+		return entity.Wishlist{}, fmt.Errorf("accept collaboration: %w", err)
+	}
+	return uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionView)
+}
+
+func (uc *wishlistUseCase) DeclineCollaboration(ctx context.Context, userID, id uuid.UUID) error {
+	c, err := uc.collaboratorRepo.Get(ctx, id, userID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return fmt.Errorf("get collaborator: %w", err)
+	}
+	// Принятое приглашение так не отклонить — из соавторов выходят через RemoveCollaborator
+	if err != nil || !c.Pending {
+		return fmt.Errorf("invitation %w", usecase.ErrNotFound)
+	}
+	if err := uc.collaboratorRepo.Delete(ctx, id, userID); err != nil {
+		return fmt.Errorf("decline collaboration: %w", err)
+	}
+	return nil
+}
+
+// saveCollaborator приглашает нового соавтора или меняет роль уже приглашённого
+func (uc *wishlistUseCase) saveCollaborator(ctx context.Context, wishlistID, userID uuid.UUID, role string) (entity.Collaborator, error) {
+	c, err := uc.collaboratorRepo.Get(ctx, wishlistID, userID)
+	if err != nil {
+		if err := uc.checkCollaboratorLimit(ctx, wishlistID); err != nil {
+			return entity.Collaborator{}, err
+		}
+		c = entity.Collaborator{WishlistID: wishlistID, UserID: userID, Pending: true, CreatedAt: time.Now()}
+	}
+	c.Role = role
+	if err := uc.collaboratorRepo.Upsert(ctx, c); err != nil {
+		return entity.Collaborator{}, fmt.Errorf("save collaborator: %w", err)
+	}
+	return c, nil
+}
+
+// checkCollaboratorLimit вызывается перед добавлением нового соавтора
+func (uc *wishlistUseCase) checkCollaboratorLimit(ctx context.Context, wishlistID uuid.UUID) error {
+	count, err := uc.collaboratorRepo.CountByWishlistID(ctx, wishlistID)
+	if err != nil {
+		return fmt.Errorf("count collaborators: %w", err)
+	}
+	if count >= usecase.MaxCollaborators {
+		return fmt.Errorf("достигнут лимит соавторов (%d)", usecase.MaxCollaborators)
+	}
+	return nil
+}
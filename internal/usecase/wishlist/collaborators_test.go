@@ -0,0 +1,385 @@
+package wishlist_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+	"main/pkg/randtoken"
+)
+
+type collaboratorFixture struct {
+	uc      usecase.WishlistUseCase
+	wr      *mockrepo.MockWishlistRepo
+	ur      *mockrepo.MockUserRepo
+	cr      *mockrepo.MockCollaboratorRepo
+	ir      *mockrepo.MockWishlistInviteRepo
+	ownerID uuid.UUID
+	w       entity.Wishlist
+}
+
+// newCollaboratorFixture — вишлист ownerID; соавторов описывает сам тест через cr
+func newCollaboratorFixture() *collaboratorFixture {
+	f := &collaboratorFixture{
+		wr:      &mockrepo.MockWishlistRepo{},
+		ur:      &mockrepo.MockUserRepo{},
+		cr:      &mockrepo.MockCollaboratorRepo{},
+		ir:      &mockrepo.MockWishlistInviteRepo{},
+		ownerID: uuid.New(),
+	}
+	f.w = entity.Wishlist{ID: uuid.New(), UserID: f.ownerID, Title: "Shared", Visibility: entity.VisibilityPrivate}
+	f.wr.On("GetByID", mock.Anything, f.w.ID).Return(f.w, nil)
+	f.uc = wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo:     f.wr,
+		AttemptRepo:      &mockrepo.MockLoginAttemptRepo{},
+		UserRepo:         f.ur,
+		CollaboratorRepo: f.cr,
+		InviteRepo:       f.ir,
+		FileStorage:      &mockminio.MockFileStorage{},
+		Policy:           access.New(f.wr, &mockrepo.MockPresentRepo{}, f.cr, testSecret),
+		Hasher:           hasher.New(),
+	})
+	return f
+}
+
+func (f *collaboratorFixture) withCollaborator(userID uuid.UUID, role string) {
+	f.cr.On("Get", mock.Anything, f.w.ID, userID).
+		Return(entity.Collaborator{WishlistID: f.w.ID, UserID: userID, Role: role}, nil)
+}
+
+func (f *collaboratorFixture) withoutCollaborator(userID uuid.UUID) {
+	f.cr.On("Get", mock.Anything, f.w.ID, userID).Return(entity.Collaborator{}, repo.ErrNotFound)
+}
+
+func TestGetAllByUser_IncludesShared(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	userID := uuid.New()
+	own := []entity.Wishlist{{ID: uuid.New(), UserID: userID}}
+	shared := []entity.Wishlist{{ID: uuid.New(), UserID: uuid.New(), Role: entity.WishlistRoleEditor}}
+	wr.On("GetAllByUserID", mock.Anything, userID).Return(own, nil)
+	wr.On("GetSharedWithUserID", mock.Anything, userID).Return(shared, nil)
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	got, err := uc.GetAllByUser(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, entity.WishlistRoleOwner, got[0].Role)
+	assert.Equal(t, shared[0].ID, got[1].ID)
+	assert.Equal(t, entity.WishlistRoleEditor, got[1].Role)
+}
+
+func TestAddCollaborator(t *testing.T) {
+	t.Run("by handle", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		friend := entity.User{ID: uuid.New(), Handle: "bob", DisplayName: "Bob"}
+		f.ur.On("GetByHandle", mock.Anything, "bob").Return(friend, nil)
+		f.withoutCollaborator(friend.ID)
+		f.cr.On("CountByWishlistID", mock.Anything, f.w.ID).Return(int64(0), nil)
+		f.cr.On("Upsert", mock.Anything, mock.MatchedBy(func(c entity.Collaborator) bool {
+			return c.UserID == friend.ID && c.Role == entity.WishlistRoleEditor && c.Pending
+		})).Return(nil)
+
+		c, err := f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, " @Bob ", entity.WishlistRoleEditor)
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", c.DisplayName)
+		f.cr.AssertExpectations(t)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		friend := entity.User{ID: uuid.New(), Handle: "bob"}
+		f.ur.On("GetByHandle", mock.Anything, "bob").Return(friend, nil)
+		f.withoutCollaborator(friend.ID)
+		f.cr.On("CountByWishlistID", mock.Anything, f.w.ID).Return(int64(usecase.MaxCollaborators), nil)
+
+		_, err := f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, "bob", entity.WishlistRoleViewer)
+		require.Error(t, err)
+		f.cr.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner cannot be added", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		f.ur.On("GetByHandle", mock.Anything, "alice").Return(entity.User{ID: f.ownerID, Handle: "alice"}, nil)
+
+		_, err := f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, "alice", entity.WishlistRoleViewer)
+		require.ErrorIs(t, err, usecase.ErrConflict)
+	})
+
+	t.Run("unknown or banned user", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		f.ur.On("GetByHandle", mock.Anything, "nobody").Return(entity.User{}, errors.New("record not found"))
+		f.ur.On("GetByHandle", mock.Anything, "banned").Return(entity.User{ID: uuid.New(), BannedAt: ptrTime(time.Now())}, nil)
+
+		_, err := f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, "nobody", entity.WishlistRoleViewer)
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+		_, err = f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, "banned", entity.WishlistRoleViewer)
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+
+	t.Run("editor cannot invite", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		editorID := uuid.New()
+		f.withCollaborator(editorID, entity.WishlistRoleEditor)
+
+		_, err := f.uc.AddCollaborator(context.Background(), editorID, f.w.ID, "bob", entity.WishlistRoleViewer)
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+		f.ur.AssertNotCalled(t, "GetByHandle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner role cannot be granted", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		_, err := f.uc.AddCollaborator(context.Background(), f.ownerID, f.w.ID, "bob", entity.WishlistRoleOwner)
+		require.Error(t, err)
+	})
+}
+
+func TestEditorPermissions(t *testing.T) {
+	f := newCollaboratorFixture()
+	editorID := uuid.New()
+	f.withCollaborator(editorID, entity.WishlistRoleEditor)
+	f.wr.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	w, err := f.uc.UpdateBlocks(context.Background(), editorID, f.w.ID, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WishlistRoleEditor, w.Role)
+
+	_, err = f.uc.SetVisibility(context.Background(), editorID, f.w.ID, 0, usecase.SetVisibilityInput{Visibility: entity.VisibilityUnlisted})
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = f.uc.SetListedOnProfile(context.Background(), editorID, f.w.ID, 0, true)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	require.ErrorIs(t, f.uc.Delete(context.Background(), editorID, f.w.ID, 0), usecase.ErrForbidden)
+	f.wr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetCollaborators(t *testing.T) {
+	f := newCollaboratorFixture()
+	viewerID, strangerID := uuid.New(), uuid.New()
+	f.withCollaborator(viewerID, entity.WishlistRoleViewer)
+	f.withoutCollaborator(strangerID)
+	list := []entity.Collaborator{{WishlistID: f.w.ID, UserID: viewerID, Role: entity.WishlistRoleViewer}}
+	f.cr.On("GetAllByWishlistID", mock.Anything, f.w.ID).Return(list, nil)
+
+	got, err := f.uc.GetCollaborators(context.Background(), viewerID, f.w.ID)
+	require.NoError(t, err)
+	assert.Equal(t, list, got)
+
+	// Посторонний не видит приватный вишлист вовсе
+	_, err = f.uc.GetCollaborators(context.Background(), strangerID, f.w.ID)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestRemoveCollaborator(t *testing.T) {
+	t.Run("collaborator leaves", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		viewerID := uuid.New()
+		f.withCollaborator(viewerID, entity.WishlistRoleViewer)
+		f.cr.On("Delete", mock.Anything, f.w.ID, viewerID).Return(nil)
+
+		require.NoError(t, f.uc.RemoveCollaborator(context.Background(), viewerID, f.w.ID, viewerID))
+		f.cr.AssertExpectations(t)
+	})
+
+	t.Run("editor cannot remove others", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		editorID, viewerID := uuid.New(), uuid.New()
+		f.withCollaborator(editorID, entity.WishlistRoleEditor)
+		f.withCollaborator(viewerID, entity.WishlistRoleViewer)
+
+		err := f.uc.RemoveCollaborator(context.Background(), editorID, f.w.ID, viewerID)
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+		f.cr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateInvite_StoresOnlyHash(t *testing.T) {
+	f := newCollaboratorFixture()
+	var stored entity.WishlistInvite
+	f.ir.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(entity.WishlistInvite)
+	}).Return(nil)
+
+	link, err := f.uc.CreateInvite(context.Background(), f.ownerID, f.w.ID, entity.WishlistRoleViewer)
+	require.NoError(t, err)
+	require.NotEmpty(t, link.Token)
+	assert.Equal(t, randtoken.Hash(link.Token), stored.TokenHash)
+	assert.NotEqual(t, link.Token, stored.TokenHash)
+	assert.Equal(t, f.w.ID, stored.WishlistID)
+	assert.True(t, link.ExpiresAt.After(time.Now()))
+}
+
+func TestAcceptInvite(t *testing.T) {
+	const token = "invite-token"
+	hash := randtoken.Hash(token)
+
+	newInvite := func(f *collaboratorFixture, role string) entity.WishlistInvite {
+		return entity.WishlistInvite{ID: uuid.New(), WishlistID: f.w.ID, Role: role, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	t.Run("joins as viewer", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		invite := newInvite(f, entity.WishlistRoleViewer)
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(invite, nil)
+		f.ir.On("Consume", mock.Anything, hash, mock.Anything).Return(invite, nil)
+		f.withoutCollaborator(userID)
+		f.cr.On("CountByWishlistID", mock.Anything, f.w.ID).Return(int64(0), nil)
+		f.cr.On("Upsert", mock.Anything, mock.MatchedBy(func(c entity.Collaborator) bool {
+			return c.UserID == userID && c.Role == entity.WishlistRoleViewer && !c.Pending
+		})).Return(nil)
+
+		w, err := f.uc.AcceptInvite(context.Background(), userID, token)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WishlistRoleViewer, w.Role)
+		f.ir.AssertExpectations(t)
+		f.cr.AssertExpectations(t)
+	})
+
+	t.Run("does not downgrade editor", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		editorID := uuid.New()
+		invite := newInvite(f, entity.WishlistRoleViewer)
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(invite, nil)
+		f.ir.On("Consume", mock.Anything, hash, mock.Anything).Return(invite, nil)
+		f.withCollaborator(editorID, entity.WishlistRoleEditor)
+		f.cr.On("Upsert", mock.Anything, mock.MatchedBy(func(c entity.Collaborator) bool {
+			return c.Role == entity.WishlistRoleEditor
+		})).Return(nil)
+
+		w, err := f.uc.AcceptInvite(context.Background(), editorID, token)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WishlistRoleEditor, w.Role)
+	})
+
+	t.Run("accepts a pending invitation", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		invite := newInvite(f, entity.WishlistRoleViewer)
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(invite, nil)
+		f.ir.On("Consume", mock.Anything, hash, mock.Anything).Return(invite, nil)
+		f.cr.On("Get", mock.Anything, f.w.ID, userID).
+			Return(entity.Collaborator{WishlistID: f.w.ID, UserID: userID, Role: entity.WishlistRoleEditor, Pending: true}, nil)
+		f.cr.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+		f.cr.On("Accept", mock.Anything, f.w.ID, userID).Return(nil)
+
+		w, err := f.uc.AcceptInvite(context.Background(), userID, token)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WishlistRoleEditor, w.Role)
+		f.cr.AssertExpectations(t)
+	})
+
+	t.Run("owner keeps the link unused", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(newInvite(f, entity.WishlistRoleEditor), nil)
+
+		_, err := f.uc.AcceptInvite(context.Background(), f.ownerID, token)
+		require.ErrorIs(t, err, usecase.ErrConflict)
+		f.ir.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("full wishlist keeps the link unused", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(newInvite(f, entity.WishlistRoleViewer), nil)
+		f.withoutCollaborator(userID)
+		f.cr.On("CountByWishlistID", mock.Anything, f.w.ID).Return(int64(usecase.MaxCollaborators), nil)
+
+		_, err := f.uc.AcceptInvite(context.Background(), userID, token)
+		require.Error(t, err)
+		f.ir.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("used or expired", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		used := newInvite(f, entity.WishlistRoleViewer)
+		used.UsedAt = ptrTime(time.Now())
+		expired := newInvite(f, entity.WishlistRoleViewer)
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(used, nil).Once()
+		f.ir.On("GetByTokenHash", mock.Anything, hash).Return(expired, nil).Once()
+
+		_, err := f.uc.AcceptInvite(context.Background(), uuid.New(), token)
+		require.ErrorIs(t, err, usecase.ErrInvalidToken)
+		_, err = f.uc.AcceptInvite(context.Background(), uuid.New(), token)
+		require.ErrorIs(t, err, usecase.ErrInvalidToken)
+		_, err = f.uc.AcceptInvite(context.Background(), uuid.New(), "")
+		require.ErrorIs(t, err, usecase.ErrInvalidToken)
+	})
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestPendingCollaboration(t *testing.T) {
+	pending := func(f *collaboratorFixture, userID uuid.UUID) {
+		f.cr.On("Get", mock.Anything, f.w.ID, userID).
+			Return(entity.Collaborator{WishlistID: f.w.ID, UserID: userID, Role: entity.WishlistRoleEditor, Pending: true}, nil)
+	}
+
+	t.Run("no access before accepting", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		pending(f, userID)
+
+		_, err := f.uc.UpdateBlocks(context.Background(), userID, f.w.ID, 0, nil)
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+		_, err = f.uc.GetByID(context.Background(), usecase.Viewer{UserID: userID}, f.w.ID)
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		f.cr.On("Accept", mock.Anything, f.w.ID, userID).Return(nil)
+		f.withCollaborator(userID, entity.WishlistRoleEditor)
+
+		w, err := f.uc.AcceptCollaboration(context.Background(), userID, f.w.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WishlistRoleEditor, w.Role)
+	})
+
+	t.Run("accept without invitation", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		f.cr.On("Accept", mock.Anything, f.w.ID, userID).Return(fmt.Errorf("collaboratorRepo.Accept: %w", repo.ErrNotFound))
+
+		_, err := f.uc.AcceptCollaboration(context.Background(), userID, f.w.ID)
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		userID := uuid.New()
+		pending(f, userID)
+		f.cr.On("Delete", mock.Anything, f.w.ID, userID).Return(nil)
+
+		require.NoError(t, f.uc.DeclineCollaboration(context.Background(), userID, f.w.ID))
+		f.cr.AssertExpectations(t)
+	})
+
+	t.Run("decline does not remove an accepted collaborator", func(t *testing.T) {
+		f := newCollaboratorFixture()
+		editorID, strangerID := uuid.New(), uuid.New()
+		f.withCollaborator(editorID, entity.WishlistRoleEditor)
+		f.withoutCollaborator(strangerID)
+
+		require.ErrorIs(t, f.uc.DeclineCollaboration(context.Background(), editorID, f.w.ID), usecase.ErrNotFound)
+		require.ErrorIs(t, f.uc.DeclineCollaboration(context.Background(), strangerID, f.w.ID), usecase.ErrNotFound)
+		f.cr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
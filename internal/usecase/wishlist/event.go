@@ -0,0 +1,178 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+const archiveBatchSize = 100
+
+func (uc *wishlistUseCase) SetEvent(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetEventInput) (entity.Wishlist, error) {
+	if input.Timezone != "" {
+		if _, err := time.LoadLocation(input.Timezone); err != nil {
+			return entity.Wishlist{}, fmt.Errorf("неизвестный часовой пояс %q", input.Timezone)
+		}
+	}
+	if !entity.ValidEventRepeat(input.Repeat) {
+		return entity.Wishlist{}, fmt.Errorf("repeat должен быть пустым или %s", entity.EventRepeatYearly)
+	}
+	if input.Repeat != entity.EventRepeatNone && input.Time.IsZero() {
+		return entity.Wishlist{}, errors.New("для повторяющегося события нужна дата")
+	}
+
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
+	}
+	w.Location.Time = input.Time
+	w.Location.Timezone = input.Timezone
+	w.EventRepeat = input.Repeat
+
+	unarchive, err := uc.checkUnarchive(ctx, w)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if err := uc.update(ctx, &w); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("update wishlist: %w", err)
+	}
+	if unarchive {
+		if err := uc.wishlistRepo.Unarchive(ctx, w.ID); err != nil {
+			return entity.Wishlist{}, fmt.Errorf("unarchive wishlist: %w", err)
+		}
+		w.ArchivedAt = nil
+	}
+	return w, nil
+}
+
+// checkUnarchive сообщает, нужно ли вернуть из архива вишлист, чьё событие перенесли в будущее.
+// Если следующий выпуск уже создан, архивный остаётся в архиве
+func (uc *wishlistUseCase) checkUnarchive(ctx context.Context, w entity.Wishlist) (bool, error) {
+	if w.ArchivedAt == nil || w.NextEditionID != nil {
+		return false, nil
+	}
+	if status, ok := w.Location.Status(time.Now()); !ok || status.Over {
+		return false, nil
+	}
+	// Архивные вишлисты в лимит не входят, поэтому место могли уже занять
+	count, err := uc.wishlistRepo.CountByUserID(ctx, w.UserID)
+	if err != nil {
+		return false, fmt.Errorf("count wishlists: %w", err)
+	}
+	if count >= usecase.MaxWishlistsPerUser {
+		return false, errors.New("достигнут лимит вишлистов (20)")
+	}
+	return true, nil
+}
+
+// ArchivePastEvents обрабатывает одну партию за запуск: остальное заберут следующие.
+// Сбой на одном вишлисте не мешает остальным
+func (uc *wishlistUseCase) ArchivePastEvents(ctx context.Context, now time.Time) (int, error) {
+	wishlists, err := uc.wishlistRepo.GetEventsEndedBefore(ctx, now, archiveBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("get past events: %w", err)
+	}
+	archived := 0
+	for _, w := range wishlists {
+		if err := uc.archive(ctx, w, now); err != nil {
+			log.Printf("wishlist %s: archive failed: %v", w.ID, err)
+			continue
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+func (uc *wishlistUseCase) archive(ctx context.Context, w entity.Wishlist, now time.Time) error {
+	if w.EventRepeat != entity.EventRepeatYearly {
+		return uc.wishlistRepo.Archive(ctx, w.ID, now, nil, nil, nil)
+	}
+
+	// В следующий выпуск переходят только подарки, которые так и не подарили
+	presents, err := uc.presentRepo.GetAllByWishlistID(ctx, w.ID)
+	if err != nil {
+		return fmt.Errorf("get presents: %w", err)
+	}
+	var open []entity.Present
+	ids := make([]uuid.UUID, 0, len(presents))
+	for _, p := range presents {
+		if !p.Reserved {
+			open = append(open, p)
+			ids = append(ids, p.ID)
+		}
+	}
+	metas, err := uc.metaRepo.GetByPresentIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("get present meta: %w", err)
+	}
+	sid, err := uc.generateUniqueShortID(ctx)
+	if err != nil {
+		return err
+	}
+
+	next := entity.Wishlist{
+		ID:              uuid.New(),
+		UserID:          w.UserID,
+		ShortID:         sid,
+		Title:           w.Title,
+		Description:     w.Description,
+		Cover:           w.Cover,
+		Settings:        w.Settings,
+		Location:        w.Location,
+		Blocks:          cloneBlocks(w.Blocks),
+		Unpublished:     w.Unpublished,
+		Visibility:      w.Visibility,
+		PasswordHash:    w.PasswordHash,
+		ListedOnProfile: w.ListedOnProfile,
+		EventRepeat:     w.EventRepeat,
+	}
+	next.Location.Time = nextOccurrence(w.Location, now)
+	next.Title = bumpYear(w.Title, w.Location.Time.In(w.Location.Zone()).Year(), next.Location.Time.In(w.Location.Zone()).Year())
+	copies, metas := copyPresents(next.ID, open, metas)
+	next.PresentsCount = uint(len(copies))
+
+	return uc.wishlistRepo.Archive(ctx, w.ID, now, &next, copies, metas)
+}
+
+// nextOccurrence переносит событие на ближайший год, в котором оно ещё не закончилось к now.
+// Время суток сохраняется по часам пояса события; 29 февраля в невисокосный год — 28-е
+func nextOccurrence(l entity.Location, now time.Time) time.Time {
+	zone := l.Zone()
+	t := l.Time.In(zone)
+	for years := 1; ; years++ {
+		year := t.Year() + years
+		day := t.Day()
+		if t.Month() == time.February && day == 29 && !isLeap(year) {
+			day = 28
+		}
+		next := entity.Location{
+			Time:     time.Date(year, t.Month(), day, t.Hour(), t.Minute(), t.Second(), 0, zone),
+			Timezone: l.Timezone,
+		}
+		if end, _ := next.EventEnd(); end.After(now) {
+			return next.Time
+		}
+	}
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// bumpYear заменяет в названии год прошедшего события на год следующего: «ДР 2025» → «ДР 2026»
+func bumpYear(title string, from, to int) string {
+	re := regexp.MustCompile(`\b` + strconv.Itoa(from) + `\b`)
+	return re.ReplaceAllString(title, strconv.Itoa(to))
+}
@@ -0,0 +1,259 @@
+package wishlist_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+type eventFixture struct {
+	uc usecase.WishlistUseCase
+	wr *mockrepo.MockWishlistRepo
+	pr *mockrepo.MockPresentRepo
+	mr *mockrepo.MockPresentMetaRepo
+}
+
+func newEventFixture() eventFixture {
+	f := eventFixture{
+		wr: &mockrepo.MockWishlistRepo{},
+		pr: &mockrepo.MockPresentRepo{},
+		mr: &mockrepo.MockPresentMetaRepo{},
+	}
+	f.wr.On("GetByShortID", mock.Anything, mock.Anything).Return(entity.Wishlist{}, errNotFound)
+	f.uc = wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo:    f.wr,
+		PresentRepo:     f.pr,
+		PresentMetaRepo: f.mr,
+		FileStorage:     &mockminio.MockFileStorage{},
+		Policy:          access.New(f.wr, f.pr, noCollaborators(), testSecret),
+		Hasher:          hasher.New(),
+	})
+	return f
+}
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	zone, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return zone
+}
+
+func TestArchivePastEvents_OneOff(t *testing.T) {
+	f := newEventFixture()
+	now := time.Now()
+	w := entity.Wishlist{ID: uuid.New(), UserID: uuid.New(), Location: entity.Location{Time: now.Add(-48 * time.Hour)}}
+	f.wr.On("GetEventsEndedBefore", mock.Anything, now, mock.Anything).Return([]entity.Wishlist{w}, nil)
+	f.wr.On("Archive", mock.Anything, w.ID, now, (*entity.Wishlist)(nil), []entity.Present(nil), []entity.PresentMeta(nil)).Return(nil)
+
+	archived, err := f.uc.ArchivePastEvents(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	f.wr.AssertCalled(t, "Archive", mock.Anything, w.ID, now, (*entity.Wishlist)(nil), []entity.Present(nil), []entity.PresentMeta(nil))
+	f.pr.AssertNotCalled(t, "GetAllByWishlistID", mock.Anything, mock.Anything)
+}
+
+func TestArchivePastEvents_YearlyCarriesOverPresents(t *testing.T) {
+	f := newEventFixture()
+	moscow := mustZone(t, "Europe/Moscow")
+	price := 1500.0
+	w := entity.Wishlist{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		ShortID:         "abc-def-ghi",
+		Title:           "ДР Маши 2025",
+		Description:     "Жду всех",
+		Location:        entity.Location{Name: "Дома", Time: time.Date(2025, 3, 10, 19, 0, 0, 0, moscow), Timezone: "Europe/Moscow"},
+		EventRepeat:     entity.EventRepeatYearly,
+		Visibility:      entity.VisibilityPassword,
+		PasswordHash:    "hash",
+		ListedOnProfile: true,
+		PresentsCount:   2,
+	}
+	gifted := entity.Present{ID: uuid.New(), WishlistID: w.ID, Title: "Книга", Reserved: true}
+	open := entity.Present{ID: uuid.New(), WishlistID: w.ID, Title: "Чайник", Link: "https://ozon.ru/1", Price: &price}
+	now := time.Date(2025, 3, 11, 0, 30, 0, 0, moscow)
+
+	f.wr.On("GetEventsEndedBefore", mock.Anything, now, mock.Anything).Return([]entity.Wishlist{w}, nil)
+	f.pr.On("GetAllByWishlistID", mock.Anything, w.ID).Return([]entity.Present{gifted, open}, nil)
+	f.mr.On("GetByPresentIDs", mock.Anything, []uuid.UUID{open.ID}).
+		Return([]entity.PresentMeta{{PresentID: open.ID, Source: "ozon"}}, nil)
+	var (
+		next     *entity.Wishlist
+		presents []entity.Present
+		metas    []entity.PresentMeta
+	)
+	f.wr.On("Archive", mock.Anything, w.ID, now, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		next = args.Get(3).(*entity.Wishlist)
+		presents = args.Get(4).([]entity.Present)
+		metas = args.Get(5).([]entity.PresentMeta)
+	}).Return(nil)
+
+	archived, err := f.uc.ArchivePastEvents(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+
+	require.NotNil(t, next)
+	assert.NotEqual(t, w.ID, next.ID)
+	assert.NotEqual(t, w.ShortID, next.ShortID)
+	assert.Equal(t, w.UserID, next.UserID)
+	assert.Equal(t, "ДР Маши 2026", next.Title)
+	assert.True(t, time.Date(2026, 3, 10, 19, 0, 0, 0, moscow).Equal(next.Location.Time))
+	assert.Equal(t, "Europe/Moscow", next.Location.Timezone)
+	assert.Equal(t, "Дома", next.Location.Name)
+	assert.Equal(t, entity.EventRepeatYearly, next.EventRepeat)
+	assert.Equal(t, w.Visibility, next.Visibility)
+	assert.Equal(t, w.PasswordHash, next.PasswordHash)
+	assert.True(t, next.ListedOnProfile)
+	assert.Nil(t, next.ArchivedAt)
+	assert.Equal(t, uint(1), next.PresentsCount)
+
+	require.Len(t, presents, 1)
+	assert.Equal(t, "Чайник", presents[0].Title)
+	assert.Equal(t, next.ID, presents[0].WishlistID)
+	assert.NotEqual(t, open.ID, presents[0].ID)
+	require.Len(t, metas, 1)
+	assert.Equal(t, presents[0].ID, metas[0].PresentID)
+}
+
+func TestArchivePastEvents_NextDate(t *testing.T) {
+	utc := time.UTC
+	tests := []struct {
+		name      string
+		title     string
+		event     time.Time
+		now       time.Time
+		want      time.Time
+		wantTitle string
+	}{
+		{
+			name:      "next year",
+			title:     "Новый год 2025",
+			event:     time.Date(2025, 12, 31, 20, 0, 0, 0, utc),
+			now:       time.Date(2026, 1, 1, 1, 0, 0, 0, utc),
+			want:      time.Date(2026, 12, 31, 20, 0, 0, 0, utc),
+			wantTitle: "Новый год 2026",
+		},
+		{
+			name:      "leap day",
+			title:     "ДР",
+			event:     time.Date(2024, 2, 29, 12, 0, 0, 0, utc),
+			now:       time.Date(2024, 3, 1, 1, 0, 0, 0, utc),
+			want:      time.Date(2025, 2, 28, 12, 0, 0, 0, utc),
+			wantTitle: "ДР",
+		},
+		{
+			name:      "job was late for years",
+			title:     "ДР 2021, 20210",
+			event:     time.Date(2021, 5, 1, 12, 0, 0, 0, utc),
+			now:       time.Date(2023, 6, 1, 0, 0, 0, 0, utc),
+			want:      time.Date(2024, 5, 1, 12, 0, 0, 0, utc),
+			wantTitle: "ДР 2024, 20210",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newEventFixture()
+			w := entity.Wishlist{ID: uuid.New(), Title: tt.title, Location: entity.Location{Time: tt.event}, EventRepeat: entity.EventRepeatYearly}
+			f.wr.On("GetEventsEndedBefore", mock.Anything, tt.now, mock.Anything).Return([]entity.Wishlist{w}, nil)
+			f.pr.On("GetAllByWishlistID", mock.Anything, w.ID).Return([]entity.Present{}, nil)
+			f.mr.On("GetByPresentIDs", mock.Anything, mock.Anything).Return([]entity.PresentMeta{}, nil)
+			var next *entity.Wishlist
+			f.wr.On("Archive", mock.Anything, w.ID, tt.now, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				next = args.Get(3).(*entity.Wishlist)
+			}).Return(nil)
+
+			_, err := f.uc.ArchivePastEvents(context.Background(), tt.now)
+			require.NoError(t, err)
+			require.NotNil(t, next)
+			assert.True(t, tt.want.Equal(next.Location.Time), "got %s", next.Location.Time)
+			assert.Equal(t, tt.wantTitle, next.Title)
+		})
+	}
+}
+
+func TestArchivePastEvents_FailureDoesNotStopBatch(t *testing.T) {
+	f := newEventFixture()
+	now := time.Now()
+	broken := entity.Wishlist{ID: uuid.New()}
+	ok := entity.Wishlist{ID: uuid.New()}
+	f.wr.On("GetEventsEndedBefore", mock.Anything, now, mock.Anything).Return([]entity.Wishlist{broken, ok}, nil)
+	f.wr.On("Archive", mock.Anything, broken.ID, now, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+	f.wr.On("Archive", mock.Anything, ok.ID, now, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	archived, err := f.uc.ArchivePastEvents(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+}
+
+func TestSetEvent(t *testing.T) {
+	ownerID := uuid.New()
+	archivedAt := time.Now().Add(-24 * time.Hour)
+	nextID := uuid.New()
+	future := time.Now().Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		archived      bool
+		nextEdition   bool
+		input         usecase.SetEventInput
+		wantFail      bool
+		wantUnarchive bool
+	}{
+		{name: "date with zone", input: usecase.SetEventInput{Time: future, Timezone: "Asia/Yekaterinburg", Repeat: entity.EventRepeatYearly}},
+		{name: "removes date", input: usecase.SetEventInput{}},
+		{name: "unknown zone", input: usecase.SetEventInput{Time: future, Timezone: "Mars/Olympus"}, wantFail: true},
+		{name: "unknown repeat", input: usecase.SetEventInput{Time: future, Repeat: "weekly"}, wantFail: true},
+		{name: "yearly without date", input: usecase.SetEventInput{Repeat: entity.EventRepeatYearly}, wantFail: true},
+		{name: "rescheduled archived", archived: true, input: usecase.SetEventInput{Time: future}, wantUnarchive: true},
+		{name: "archived with next edition", archived: true, nextEdition: true, input: usecase.SetEventInput{Time: future}},
+		{name: "archived and still past", archived: true, input: usecase.SetEventInput{Time: time.Now().Add(-72 * time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newEventFixture()
+			w := entity.Wishlist{ID: uuid.New(), UserID: ownerID, Location: entity.Location{Name: "Дома"}}
+			if tt.archived {
+				w.ArchivedAt = &archivedAt
+			}
+			if tt.nextEdition {
+				w.NextEditionID = &nextID
+			}
+			f.wr.On("GetByID", mock.Anything, w.ID).Return(w, nil)
+			f.wr.On("CountByUserID", mock.Anything, ownerID).Return(int64(3), nil)
+			f.wr.On("Update", mock.Anything, mock.Anything).Return(nil)
+			f.wr.On("Unarchive", mock.Anything, w.ID).Return(nil)
+
+			got, err := f.uc.SetEvent(context.Background(), ownerID, w.ID, 0, tt.input)
+			if tt.wantFail {
+				require.Error(t, err)
+				f.wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Дома", got.Location.Name)
+			assert.True(t, tt.input.Time.Equal(got.Location.Time))
+			assert.Equal(t, tt.input.Timezone, got.Location.Timezone)
+			assert.Equal(t, tt.input.Repeat, got.EventRepeat)
+			if tt.wantUnarchive {
+				f.wr.AssertCalled(t, "Unarchive", mock.Anything, w.ID)
+				assert.Nil(t, got.ArchivedAt)
+				return
+			}
+			f.wr.AssertNotCalled(t, "Unarchive", mock.Anything, mock.Anything)
+		})
+	}
+}
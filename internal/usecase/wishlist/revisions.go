@@ -0,0 +1,262 @@
+package wishlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+func (uc *wishlistUseCase) GetRevisions(ctx context.Context, userID, id uuid.UUID) ([]entity.WishlistRevision, error) {
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit); err != nil {
+		return nil, err
+	}
+	revisions, err := uc.revisionRepo.GetAllByWishlistID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (uc *wishlistUseCase) GetRevision(ctx context.Context, userID, id uuid.UUID, number int) (entity.WishlistRevision, error) {
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit); err != nil {
+		return entity.WishlistRevision{}, err
+	}
+	return uc.getRevision(ctx, id, number)
+}
+
+func (uc *wishlistUseCase) DiffRevisions(ctx context.Context, userID, id uuid.UUID, from, to int) (entity.RevisionDiff, error) {
+	if _, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit); err != nil {
+		return entity.RevisionDiff{}, err
+	}
+	older, err := uc.getRevision(ctx, id, from)
+	if err != nil {
+		return entity.RevisionDiff{}, err
+	}
+	newer, err := uc.getRevision(ctx, id, to)
+	if err != nil {
+		return entity.RevisionDiff{}, err
+	}
+	return diffRevisions(older, newer), nil
+}
+
+// RestoreRevision не переписывает историю: откат сохраняется новой ревизией
+func (uc *wishlistUseCase) RestoreRevision(ctx context.Context, userID, id uuid.UUID, version uint, number int) (entity.Wishlist, error) {
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
+	}
+	rev, err := uc.getRevision(ctx, id, number)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+
+	before := w
+	w.Blocks = rev.Blocks
+	w.Settings = rev.Settings
+	if err := uc.update(ctx, &w); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("restore revision: %w", err)
+	}
+	uc.recordRevision(ctx, before, w, userID, &number)
+	return w, nil
+}
+
+func (uc *wishlistUseCase) getRevision(ctx context.Context, id uuid.UUID, number int) (entity.WishlistRevision, error) {
+	rev, err := uc.revisionRepo.GetByNumber(ctx, id, number)
+	if err != nil {
+		return entity.WishlistRevision{}, fmt.Errorf("revision %d %w: %v", number, usecase.ErrNotFound, err)
+	}
+	return rev, nil
+}
+
+// recordRevision сохраняет новое состояние блоков и настроек в историю. У вишлистов без истории
+// сначала сохраняется состояние до правки — иначе первую же неудачную правку нельзя было бы откатить.
+// Правка к этому моменту уже записана, поэтому сбой истории только логируется
+func (uc *wishlistUseCase) recordRevision(ctx context.Context, before, after entity.Wishlist, authorID uuid.UUID, restoredFrom *int) {
+	if restoredFrom == nil && sameBlocks(before.Blocks, after.Blocks) && before.Settings == after.Settings {
+		return
+	}
+	now := time.Now()
+	if _, err := uc.revisionRepo.GetLatest(ctx, after.ID); err != nil {
+		created := before.UpdatedAt
+		if created.IsZero() {
+			created = now
+		}
+		base := entity.WishlistRevision{
+			ID:         uuid.New(),
+			WishlistID: before.ID,
+			AuthorID:   before.UserID,
+			Blocks:     before.Blocks,
+			Settings:   before.Settings,
+			CreatedAt:  created,
+		}
+		if _, err := uc.revisionRepo.Create(ctx, base, usecase.MaxRevisionsPerWishlist); err != nil {
+			log.Printf("wishlist %s: save base revision failed: %v", after.ID, err)
+		}
+	}
+	rev := entity.WishlistRevision{
+		ID:           uuid.New(),
+		WishlistID:   after.ID,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+		Blocks:       after.Blocks,
+		Settings:     after.Settings,
+		CreatedAt:    now,
+	}
+	if _, err := uc.revisionRepo.Create(ctx, rev, usecase.MaxRevisionsPerWishlist); err != nil {
+		log.Printf("wishlist %s: save revision failed: %v", after.ID, err)
+	}
+}
+
+// diffRevisions сопоставляет блоки без идентификаторов: сначала блоки с теми же типом и данными
+// (на другом месте — перемещённые), затем блоки того же типа на том же месте (изменённые).
+// Несопоставленные блоки старой ревизии удалены, новой — добавлены
+func diffRevisions(older, newer entity.WishlistRevision) entity.RevisionDiff {
+	diff := entity.RevisionDiff{
+		From:     older.Number,
+		To:       newer.Number,
+		Added:    []entity.BlockChange{},
+		Removed:  []entity.BlockChange{},
+		Moved:    []entity.BlockChange{},
+		Changed:  []entity.BlockChange{},
+		Settings: diffFields(settingsFields(older.Settings), settingsFields(newer.Settings)),
+	}
+	matchedOld := make([]bool, len(older.Blocks))
+	matchedNew := make([]bool, len(newer.Blocks))
+
+	// Неподвижные блоки с теми же данными забираем первыми, чтобы дубликаты не сочлись перемещёнными
+	for _, samePlace := range []bool{true, false} {
+		for j, nb := range newer.Blocks {
+			if matchedNew[j] {
+				continue
+			}
+			for i, ob := range older.Blocks {
+				if matchedOld[i] || ob.Type != nb.Type || !sameJSON(ob.Data, nb.Data) ||
+					samePlace && blockPosition(ob) != blockPosition(nb) {
+					continue
+				}
+				matchedOld[i], matchedNew[j] = true, true
+				if !samePlace {
+					diff.Moved = append(diff.Moved, blockChange(older.Blocks, i, newer.Blocks, j))
+				}
+				break
+			}
+		}
+	}
+	for j, nb := range newer.Blocks {
+		if matchedNew[j] {
+			continue
+		}
+		for i, ob := range older.Blocks {
+			if matchedOld[i] || ob.Type != nb.Type || blockPosition(ob) != blockPosition(nb) {
+				continue
+			}
+			matchedOld[i], matchedNew[j] = true, true
+			change := blockChange(older.Blocks, i, newer.Blocks, j)
+			change.Fields = diffFields(dataFields(ob.Data), dataFields(nb.Data))
+			diff.Changed = append(diff.Changed, change)
+			break
+		}
+	}
+	for i := range older.Blocks {
+		if !matchedOld[i] {
+			diff.Removed = append(diff.Removed, blockChange(older.Blocks, i, nil, -1))
+		}
+	}
+	for j := range newer.Blocks {
+		if !matchedNew[j] {
+			diff.Added = append(diff.Added, blockChange(nil, -1, newer.Blocks, j))
+		}
+	}
+	return diff
+}
+
+// blockChange описывает блок from[i] и/или to[j]; отрицательный индекс — блока в этой ревизии нет
+func blockChange(from []entity.Block, i int, to []entity.Block, j int) entity.BlockChange {
+	var change entity.BlockChange
+	if i >= 0 {
+		pos := blockPosition(from[i])
+		change.Type, change.FromIndex, change.From = from[i].Type, &i, &pos
+	}
+	if j >= 0 {
+		pos := blockPosition(to[j])
+		change.Type, change.ToIndex, change.To = to[j].Type, &j, &pos
+	}
+	return change
+}
+
+func blockPosition(b entity.Block) entity.BlockPosition {
+	colSpan := b.ColSpan
+	if colSpan < 1 {
+		colSpan = 1
+	}
+	return entity.BlockPosition{Row: b.Row, Col: b.Col, ColSpan: colSpan}
+}
+
+// dataFields раскладывает данные блока на поля верхнего уровня; не-объект считается одним полем ""
+func dataFields(data json.RawMessage) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if len(data) == 0 {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return map[string]json.RawMessage{"": data}
+	}
+	return fields
+}
+
+func settingsFields(s entity.Settings) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	raw, _ := json.Marshal(s)
+	_ = json.Unmarshal(raw, &fields)
+	return fields
+}
+
+// diffFields возвращает различающиеся поля в алфавитном порядке
+func diffFields(before, after map[string]json.RawMessage) []entity.FieldChange {
+	changes := []entity.FieldChange{}
+	for name, ov := range before {
+		if nv, ok := after[name]; !ok || !sameJSON(ov, nv) {
+			changes = append(changes, entity.FieldChange{Field: name, Old: ov, New: after[name]})
+		}
+	}
+	for name, nv := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, entity.FieldChange{Field: name, New: nv})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func sameBlocks(a, b []entity.Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || blockPosition(a[i]) != blockPosition(b[i]) || !sameJSON(a[i].Data, b[i].Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON сравнивает JSON по значению, а не по байтам: пробелы и порядок ключей не важны
+func sameJSON(a, b json.RawMessage) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,205 @@
+package wishlist_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+type revisionFixture struct {
+	uc usecase.WishlistUseCase
+	wr *mockrepo.MockWishlistRepo
+	rr *mockrepo.MockRevisionRepo
+	w  entity.Wishlist
+}
+
+func newRevisionFixture() revisionFixture {
+	f := revisionFixture{
+		wr: &mockrepo.MockWishlistRepo{},
+		rr: &mockrepo.MockRevisionRepo{},
+		w: entity.Wishlist{
+			ID:       uuid.New(),
+			UserID:   uuid.New(),
+			Settings: entity.Settings{ColorScheme: "mint"},
+			Blocks:   []entity.Block{{Type: "text", ColSpan: 2, Data: json.RawMessage(`{"content":"Привет"}`)}},
+		},
+	}
+	f.wr.On("GetByID", mock.Anything, f.w.ID).Return(f.w, nil)
+	f.wr.On("Update", mock.Anything, mock.Anything).Return(nil)
+	f.uc = wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo: f.wr,
+		RevisionRepo: f.rr,
+		FileStorage:  &mockminio.MockFileStorage{},
+		Policy:       access.New(f.wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret),
+		Hasher:       hasher.New(),
+	})
+	return f
+}
+
+func TestUpdateBlocks_RecordsRevisions(t *testing.T) {
+	edited := []entity.Block{{Type: "text", ColSpan: 2, Data: json.RawMessage(`{"content":"Пока"}`)}}
+
+	t.Run("first edit saves the previous state too", func(t *testing.T) {
+		f := newRevisionFixture()
+		f.rr.On("GetLatest", mock.Anything, f.w.ID).Return(entity.WishlistRevision{}, errNotFound)
+		var saved []entity.WishlistRevision
+		f.rr.On("Create", mock.Anything, mock.Anything, usecase.MaxRevisionsPerWishlist).Run(func(args mock.Arguments) {
+			saved = append(saved, args.Get(1).(entity.WishlistRevision))
+		}).Return(entity.WishlistRevision{}, nil)
+
+		_, err := f.uc.UpdateBlocks(context.Background(), f.w.UserID, f.w.ID, 0, edited)
+		require.NoError(t, err)
+		require.Len(t, saved, 2)
+		assert.Equal(t, f.w.Blocks, saved[0].Blocks)
+		assert.Equal(t, f.w.UserID, saved[0].AuthorID)
+		assert.Equal(t, edited, saved[1].Blocks)
+		assert.Equal(t, f.w.Settings, saved[1].Settings)
+		assert.Nil(t, saved[1].RestoredFrom)
+	})
+	t.Run("next edits save only the new state", func(t *testing.T) {
+		f := newRevisionFixture()
+		f.rr.On("GetLatest", mock.Anything, f.w.ID).Return(entity.WishlistRevision{Number: 3}, nil)
+		f.rr.On("Create", mock.Anything, mock.Anything, usecase.MaxRevisionsPerWishlist).Return(entity.WishlistRevision{}, nil)
+
+		_, err := f.uc.UpdateBlocks(context.Background(), f.w.UserID, f.w.ID, 0, edited)
+		require.NoError(t, err)
+		f.rr.AssertNumberOfCalls(t, "Create", 1)
+	})
+	t.Run("unchanged blocks are not a revision", func(t *testing.T) {
+		f := newRevisionFixture()
+		// Тот же JSON с другими пробелами — не правка
+		same := []entity.Block{{Type: "text", ColSpan: 2, Data: json.RawMessage(`{ "content": "Привет" }`)}}
+
+		_, err := f.uc.UpdateBlocks(context.Background(), f.w.UserID, f.w.ID, 0, same)
+		require.NoError(t, err)
+		f.rr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRestoreRevision(t *testing.T) {
+	f := newRevisionFixture()
+	old := entity.WishlistRevision{
+		Number:   2,
+		Blocks:   []entity.Block{{Type: "divider", ColSpan: 2, Data: json.RawMessage(`{}`)}},
+		Settings: entity.Settings{ColorScheme: "rose"},
+	}
+	f.rr.On("GetByNumber", mock.Anything, f.w.ID, 2).Return(old, nil)
+	f.rr.On("GetLatest", mock.Anything, f.w.ID).Return(entity.WishlistRevision{Number: 5}, nil)
+	var saved entity.WishlistRevision
+	f.rr.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(entity.WishlistRevision)
+	}).Return(entity.WishlistRevision{}, nil)
+
+	w, err := f.uc.RestoreRevision(context.Background(), f.w.UserID, f.w.ID, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, old.Blocks, w.Blocks)
+	assert.Equal(t, old.Settings, w.Settings)
+	assert.Equal(t, uint(1), w.Version)
+	written := w
+	written.Version = 0
+	f.wr.AssertCalled(t, "Update", mock.Anything, written)
+	require.NotNil(t, saved.RestoredFrom)
+	assert.Equal(t, 2, *saved.RestoredFrom)
+	assert.Equal(t, old.Blocks, saved.Blocks)
+}
+
+func TestRevisions_Denied(t *testing.T) {
+	f := newRevisionFixture()
+	stranger := uuid.New()
+
+	_, err := f.uc.GetRevisions(context.Background(), stranger, f.w.ID)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = f.uc.RestoreRevision(context.Background(), stranger, f.w.ID, 0, 1)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	f.rr.AssertNotCalled(t, "GetAllByWishlistID", mock.Anything, mock.Anything)
+	f.wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDiffRevisions(t *testing.T) {
+	f := newRevisionFixture()
+	text := func(content string, row int) entity.Block {
+		return entity.Block{Type: "text", Row: row, ColSpan: 2, Data: json.RawMessage(`{"content":"` + content + `","align":"left"}`)}
+	}
+	divider := entity.Block{Type: "divider", Row: 1, ColSpan: 2, Data: json.RawMessage(`{}`)}
+	older := entity.WishlistRevision{
+		Number:   1,
+		Settings: entity.Settings{ColorScheme: "mint", PresentsLayout: "grid2"},
+		Blocks: []entity.Block{
+			text("Привет", 0),
+			divider,
+			{Type: "date", Row: 2, ColSpan: 1, Data: json.RawMessage(`{"date":"2026-03-10"}`)},
+			{Type: "quote", Row: 3, ColSpan: 2, Data: json.RawMessage(`{"content":"Цитата"}`)},
+		},
+	}
+	movedDivider := divider
+	movedDivider.Row = 4
+	newer := entity.WishlistRevision{
+		Number:   4,
+		Settings: entity.Settings{ColorScheme: "rose", PresentsLayout: "grid2"},
+		Blocks: []entity.Block{
+			text("Привет", 0),
+			{Type: "date", Row: 2, ColSpan: 1, Data: json.RawMessage(`{"date":"2026-03-11","time":"19:00"}`)},
+			{Type: "quote", Row: 3, Col: 1, ColSpan: 1, Data: json.RawMessage(`{"content":"Другая"}`)},
+			movedDivider,
+			{Type: "image", Row: 5, ColSpan: 2, Data: json.RawMessage(`{"url":"https://files.example.com/a.webp"}`)},
+		},
+	}
+	f.rr.On("GetByNumber", mock.Anything, f.w.ID, 1).Return(older, nil)
+	f.rr.On("GetByNumber", mock.Anything, f.w.ID, 4).Return(newer, nil)
+
+	diff, err := f.uc.DiffRevisions(context.Background(), f.w.UserID, f.w.ID, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 4, diff.To)
+
+	require.Len(t, diff.Moved, 1)
+	assert.Equal(t, "divider", diff.Moved[0].Type)
+	assert.Equal(t, 1, *diff.Moved[0].FromIndex)
+	assert.Equal(t, 3, *diff.Moved[0].ToIndex)
+	assert.Equal(t, entity.BlockPosition{Row: 4, ColSpan: 2}, *diff.Moved[0].To)
+
+	require.Len(t, diff.Changed, 1)
+	changed := diff.Changed[0]
+	assert.Equal(t, "date", changed.Type)
+	require.Len(t, changed.Fields, 2)
+	assert.Equal(t, "date", changed.Fields[0].Field)
+	assert.JSONEq(t, `"2026-03-10"`, string(changed.Fields[0].Old))
+	assert.JSONEq(t, `"2026-03-11"`, string(changed.Fields[0].New))
+	assert.Equal(t, "time", changed.Fields[1].Field)
+	assert.Nil(t, changed.Fields[1].Old)
+
+	// Цитата сменила и место, и текст — сопоставить её не с чем
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "quote", diff.Removed[0].Type)
+	assert.Nil(t, diff.Removed[0].ToIndex)
+	require.Len(t, diff.Added, 2)
+	assert.Equal(t, "quote", diff.Added[0].Type)
+	assert.Equal(t, "image", diff.Added[1].Type)
+	assert.Nil(t, diff.Added[1].FromIndex)
+
+	require.Len(t, diff.Settings, 1)
+	assert.Equal(t, "colorScheme", diff.Settings[0].Field)
+	assert.JSONEq(t, `"rose"`, string(diff.Settings[0].New))
+}
+
+func TestDiffRevisions_UnknownRevision(t *testing.T) {
+	f := newRevisionFixture()
+	f.rr.On("GetByNumber", mock.Anything, f.w.ID, 1).Return(entity.WishlistRevision{Number: 1}, nil)
+	f.rr.On("GetByNumber", mock.Anything, f.w.ID, 9).Return(entity.WishlistRevision{}, errNotFound)
+
+	_, err := f.uc.DiffRevisions(context.Background(), f.w.UserID, f.w.ID, 1, 9)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+}
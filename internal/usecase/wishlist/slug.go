@@ -0,0 +1,161 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/pkg/shortid"
+	"main/pkg/slugify"
+)
+
+// slugHoldPeriod — сколько прежний slug удерживается за вишлистом после переименования.
+// Ведёт на вишлист он и дольше, пока его не займёт другой
+const slugHoldPeriod = 90 * 24 * time.Hour
+
+// reservedSlugs совпадают с путями фронтенда и API или выдают себя за сервис
+var reservedSlugs = map[string]bool{
+	"new": true, "create": true, "edit": true, "settings": true, "share": true, "public": true,
+	"api": true, "admin": true, "auth": true, "login": true, "logout": true, "register": true,
+	"wishlists": true, "wishlist": true, "presents": true, "templates": true, "users": true,
+	"profile": true, "trash": true, "restore": true, "clone": true, "unlock": true, "upload": true,
+	"help": true, "support": true, "about": true, "terms": true, "privacy": true,
+	"null": true, "undefined": true,
+}
+
+func (uc *wishlistUseCase) SetSlug(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetSlugInput) (entity.Wishlist, error) {
+	scope := input.Scope
+	if scope == "" {
+		scope = entity.SlugScopeProfile
+	}
+	if scope != entity.SlugScopeProfile && scope != entity.SlugScopeGlobal {
+		return entity.Wishlist{}, fmt.Errorf("scope должен быть %s или %s", entity.SlugScopeProfile, entity.SlugScopeGlobal)
+	}
+	slug := slugify.Make(input.Slug)
+	if strings.TrimSpace(input.Slug) != "" {
+		if err := validateSlug(slug); err != nil {
+			return entity.Wishlist{}, err
+		}
+	}
+
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
+	}
+	if slug == "" {
+		scope = ""
+	}
+	if w.Slug == slug && w.SlugScope == scope {
+		return w, nil
+	}
+
+	scopeID := uuid.Nil
+	if slug != "" && scope == entity.SlugScopeProfile {
+		// Адрес в профиле строится от handle — без него ссылку не открыть
+		owner, err := uc.userRepo.GetByID(ctx, w.UserID)
+		if err != nil {
+			return entity.Wishlist{}, fmt.Errorf("user %w: %v", usecase.ErrNotFound, err)
+		}
+		if owner.Handle == "" {
+			return entity.Wishlist{}, errors.New("чтобы выбрать адрес в профиле, сначала задайте handle")
+		}
+		scopeID = w.UserID
+	}
+	if slug != "" {
+		// Свой прежний slug можно вернуть в любой момент, чужой — только после срока удержания
+		if taken, err := uc.wishlistRepo.GetSlug(ctx, scopeID, slug); err == nil && taken.WishlistID != id &&
+			(taken.ReleasedAt == nil || time.Now().Before(taken.ReleasedAt.Add(slugHoldPeriod))) {
+			return entity.Wishlist{}, fmt.Errorf("адрес уже занят: %w", usecase.ErrConflict)
+		}
+	}
+
+	if err := uc.wishlistRepo.ChangeSlug(ctx, id, w.Version, scopeID, slug, time.Now()); err != nil {
+		// Проверку выше мог обогнать параллельный запрос с тем же slug
+		if errors.Is(err, repo.ErrConflict) {
+			return entity.Wishlist{}, fmt.Errorf("адрес уже занят: %w", usecase.ErrConflict)
+		}
+		return entity.Wishlist{}, fmt.Errorf("change slug: %w", uc.staleOr(ctx, w, err))
+	}
+	w.Slug, w.SlugScope = slug, scope
+	w.Version++
+	return w, nil
+}
+
+func (uc *wishlistUseCase) GetByProfileSlug(ctx context.Context, viewer usecase.Viewer, handle, slug string) (usecase.ProfileWishlist, error) {
+	owner, err := uc.findByHandle(ctx, strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@")))
+	if err != nil {
+		return usecase.ProfileWishlist{}, fmt.Errorf("wishlist %w: %v", usecase.ErrNotFound, err)
+	}
+	// Как и профиль, вишлисты заблокированных и удаляемых аккаунтов по этому адресу не открываются
+	if owner.Banned() || owner.DeletionScheduled() {
+		return usecase.ProfileWishlist{}, fmt.Errorf("wishlist %w", usecase.ErrNotFound)
+	}
+
+	w, err := uc.wishlistRepo.GetBySlug(ctx, owner.ID, slugify.Make(slug))
+	if err != nil {
+		return usecase.ProfileWishlist{}, fmt.Errorf("wishlist %w: %v", usecase.ErrNotFound, err)
+	}
+	role, err := uc.policy.CheckView(ctx, w, viewer)
+	if err != nil {
+		return usecase.ProfileWishlist{}, err
+	}
+	w.Role = role
+	return usecase.ProfileWishlist{Handle: owner.Handle, Wishlist: w}, nil
+}
+
+// findByLink ищет вишлист по короткому ID, а если ссылка на него не похожа или такого нет —
+// по глобальному slug
+func (uc *wishlistUseCase) findByLink(ctx context.Context, link string) (entity.Wishlist, error) {
+	w, err := uc.wishlistRepo.GetByShortID(ctx, link)
+	if err == nil || shortid.Valid(link) {
+		return w, err
+	}
+	slug := slugify.Make(link)
+	if slug == "" {
+		return entity.Wishlist{}, err
+	}
+	return uc.wishlistRepo.GetBySlug(ctx, uuid.Nil, slug)
+}
+
+// findByHandle находит пользователя по текущему или прежнему handle
+func (uc *wishlistUseCase) findByHandle(ctx context.Context, handle string) (entity.User, error) {
+	if user, err := uc.userRepo.GetByHandle(ctx, handle); err == nil {
+		return user, nil
+	}
+	rd, err := uc.redirectRepo.GetByHandle(ctx, handle)
+	if err != nil {
+		return entity.User{}, err
+	}
+	user, err := uc.userRepo.GetByID(ctx, rd.UserID)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if user.Handle == "" {
+		return entity.User{}, errors.New("handle removed")
+	}
+	return user, nil
+}
+
+func validateSlug(slug string) error {
+	if n := len(slug); n < usecase.MinSlugLen || n > usecase.MaxSlugLen {
+		return fmt.Errorf("адрес должен быть длиной от %d до %d символов", usecase.MinSlugLen, usecase.MaxSlugLen)
+	}
+	if reservedSlugs[slug] {
+		return errors.New("этот адрес зарезервирован")
+	}
+	// Иначе глобальный slug мог бы перекрыть чужой короткий ID
+	if shortid.Valid(slug) {
+		return errors.New("адрес не может выглядеть как короткий ID")
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package wishlist_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/repo"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+var errNotFound = errors.New("record not found")
+
+type slugFixture struct {
+	uc    usecase.WishlistUseCase
+	wr    *mockrepo.MockWishlistRepo
+	ur    *mockrepo.MockUserRepo
+	rr    *mockrepo.MockHandleRedirectRepo
+	owner entity.User
+	w     entity.Wishlist
+}
+
+// newSlugFixture — вишлист владельца с handle и текущим slug в профиле (пустой — без slug)
+func newSlugFixture(slug string) slugFixture {
+	f := slugFixture{
+		wr:    &mockrepo.MockWishlistRepo{},
+		ur:    &mockrepo.MockUserRepo{},
+		rr:    &mockrepo.MockHandleRedirectRepo{},
+		owner: entity.User{ID: uuid.New(), Handle: "masha"},
+	}
+	f.w = entity.Wishlist{ID: uuid.New(), UserID: f.owner.ID, ShortID: "abc-def-ghi", Visibility: entity.VisibilityUnlisted}
+	if slug != "" {
+		f.w.Slug, f.w.SlugScope = slug, entity.SlugScopeProfile
+	}
+	f.wr.On("GetByID", mock.Anything, f.w.ID).Return(f.w, nil)
+	f.uc = wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo: f.wr,
+		UserRepo:     f.ur,
+		RedirectRepo: f.rr,
+		FileStorage:  &mockminio.MockFileStorage{},
+		Policy:       access.New(f.wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret),
+		Hasher:       hasher.New(),
+	})
+	return f
+}
+
+func TestSetSlug(t *testing.T) {
+	otherID := uuid.New()
+	recently := time.Now().Add(-24 * time.Hour)
+	longAgo := time.Now().Add(-200 * 24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		current   string
+		input     usecase.SetSlugInput
+		taken     *entity.WishlistSlug // запись о slug в нужном пространстве; nil — свободен
+		global    bool
+		wantSlug  string
+		wantError error
+		wantFail  bool
+	}{
+		{name: "transliterated", input: usecase.SetSlugInput{Slug: "ДР Маши 2025"}, wantSlug: "dr-mashi-2025"},
+		{name: "global", input: usecase.SetSlugInput{Slug: "New-Year", Scope: entity.SlugScopeGlobal}, global: true, wantSlug: "new-year"},
+		{name: "unknown scope", input: usecase.SetSlugInput{Slug: "party", Scope: "team"}, wantFail: true},
+		{name: "too short", input: usecase.SetSlugInput{Slug: "ny"}, wantFail: true},
+		{name: "nothing left after normalization", input: usecase.SetSlugInput{Slug: "!!!"}, wantFail: true},
+		{name: "reserved", input: usecase.SetSlugInput{Slug: "Settings"}, wantFail: true},
+		{name: "looks like short ID", input: usecase.SetSlugInput{Slug: "xyz-123-abc", Scope: entity.SlugScopeGlobal}, wantFail: true},
+		{
+			name:      "current slug of another wishlist",
+			input:     usecase.SetSlugInput{Slug: "party"},
+			taken:     &entity.WishlistSlug{WishlistID: otherID},
+			wantError: usecase.ErrConflict,
+		},
+		{
+			name:      "recently released by another wishlist",
+			input:     usecase.SetSlugInput{Slug: "party"},
+			taken:     &entity.WishlistSlug{WishlistID: otherID, ReleasedAt: &recently},
+			wantError: usecase.ErrConflict,
+		},
+		{
+			name:     "released long ago by another wishlist",
+			input:    usecase.SetSlugInput{Slug: "party"},
+			taken:    &entity.WishlistSlug{WishlistID: otherID, ReleasedAt: &longAgo},
+			wantSlug: "party",
+		},
+		{name: "removes slug", current: "party", input: usecase.SetSlugInput{Slug: "  "}, wantSlug: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSlugFixture(tt.current)
+			f.ur.On("GetByID", mock.Anything, f.owner.ID).Return(f.owner, nil)
+			scope := f.owner.ID
+			if tt.global {
+				scope = uuid.Nil
+			}
+			if tt.taken != nil {
+				f.wr.On("GetSlug", mock.Anything, scope, "party").Return(*tt.taken, nil)
+			}
+			f.wr.On("GetSlug", mock.Anything, mock.Anything, mock.Anything).Return(entity.WishlistSlug{}, errNotFound)
+			f.wr.On("ChangeSlug", mock.Anything, f.w.ID, uint(0), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			w, err := f.uc.SetSlug(context.Background(), f.owner.ID, f.w.ID, 0, tt.input)
+			if tt.wantFail || tt.wantError != nil {
+				require.Error(t, err)
+				if tt.wantError != nil {
+					assert.ErrorIs(t, err, tt.wantError)
+				}
+				f.wr.AssertNotCalled(t, "ChangeSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSlug, w.Slug)
+			assert.Equal(t, uint(1), w.Version)
+			if tt.wantSlug == "" {
+				assert.Empty(t, w.SlugScope)
+				f.wr.AssertCalled(t, "ChangeSlug", mock.Anything, f.w.ID, uint(0), mock.Anything, "", mock.Anything)
+				return
+			}
+			f.wr.AssertCalled(t, "ChangeSlug", mock.Anything, f.w.ID, uint(0), scope, tt.wantSlug, mock.Anything)
+		})
+	}
+}
+
+func TestSetSlug_Denied(t *testing.T) {
+	t.Run("profile slug without handle", func(t *testing.T) {
+		f := newSlugFixture("")
+		f.ur.On("GetByID", mock.Anything, f.owner.ID).Return(entity.User{ID: f.owner.ID}, nil)
+
+		_, err := f.uc.SetSlug(context.Background(), f.owner.ID, f.w.ID, 0, usecase.SetSlugInput{Slug: "party"})
+		require.Error(t, err)
+		f.wr.AssertNotCalled(t, "ChangeSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("not owner", func(t *testing.T) {
+		f := newSlugFixture("")
+		f.ur.On("GetByID", mock.Anything, f.owner.ID).Return(f.owner, nil)
+
+		_, err := f.uc.SetSlug(context.Background(), uuid.New(), f.w.ID, 0, usecase.SetSlugInput{Slug: "party"})
+		require.ErrorIs(t, err, usecase.ErrForbidden)
+		f.wr.AssertNotCalled(t, "ChangeSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetSlug_ConcurrentWrite(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	// Версия совпала при чтении, но соседняя правка успела записаться раньше
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 2}, nil).Once()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 3}, nil)
+	wr.On("GetSlug", mock.Anything, uuid.Nil, "party").Return(entity.WishlistSlug{}, errNotFound)
+	wr.On("ChangeSlug", mock.Anything, wid, uint(2), uuid.Nil, "party", mock.Anything).Return(errNotFound)
+
+	_, err := uc.SetSlug(context.Background(), ownerID, wid, 2, usecase.SetSlugInput{Slug: "party", Scope: entity.SlugScopeGlobal})
+	var stale *usecase.StaleWishlistError
+	require.ErrorAs(t, err, &stale)
+	assert.Equal(t, uint(3), stale.Current.Version)
+}
+
+func TestSetSlug_LostRace(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	// Slug был свободен при проверке, но его успел занять другой вишлист
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+	wr.On("GetSlug", mock.Anything, uuid.Nil, "party").Return(entity.WishlistSlug{}, errNotFound)
+	wr.On("ChangeSlug", mock.Anything, wid, uint(0), uuid.Nil, "party", mock.Anything).
+		Return(fmt.Errorf("wishlistRepo.ChangeSlug: %w", repo.ErrConflict))
+
+	_, err := uc.SetSlug(context.Background(), ownerID, wid, 0, usecase.SetSlugInput{Slug: "party", Scope: entity.SlugScopeGlobal})
+	require.ErrorIs(t, err, usecase.ErrConflict)
+}
+
+func TestGetByShortID_FallsBackToGlobalSlug(t *testing.T) {
+	f := newSlugFixture("")
+	w := f.w
+	w.Slug, w.SlugScope = "new-year", entity.SlugScopeGlobal
+	f.wr.On("GetByShortID", mock.Anything, mock.Anything).Return(entity.Wishlist{}, errNotFound)
+	f.wr.On("GetBySlug", mock.Anything, uuid.Nil, "novyy-god").Return(w, nil)
+
+	got, err := f.uc.GetByShortID(context.Background(), usecase.Viewer{}, "Новый год")
+	require.NoError(t, err)
+	assert.Equal(t, w.ID, got.ID)
+
+	// Ссылка в формате короткого ID среди slug не ищется
+	_, err = f.uc.GetByShortID(context.Background(), usecase.Viewer{}, "zzz-zzz-zzz")
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+	f.wr.AssertNumberOfCalls(t, "GetBySlug", 1)
+}
+
+func TestGetByProfileSlug(t *testing.T) {
+	t.Run("old handle", func(t *testing.T) {
+		f := newSlugFixture("party")
+		f.ur.On("GetByHandle", mock.Anything, "old_masha").Return(entity.User{}, errNotFound)
+		f.rr.On("GetByHandle", mock.Anything, "old_masha").Return(entity.HandleRedirect{Handle: "old_masha", UserID: f.owner.ID}, nil)
+		f.ur.On("GetByID", mock.Anything, f.owner.ID).Return(f.owner, nil)
+		f.wr.On("GetBySlug", mock.Anything, f.owner.ID, "party").Return(f.w, nil)
+
+		got, err := f.uc.GetByProfileSlug(context.Background(), usecase.Viewer{}, "@Old_Masha", "party")
+		require.NoError(t, err)
+		assert.Equal(t, "masha", got.Handle)
+		assert.Equal(t, f.w.ID, got.Wishlist.ID)
+	})
+	t.Run("banned owner", func(t *testing.T) {
+		f := newSlugFixture("party")
+		bannedAt := time.Now()
+		banned := f.owner
+		banned.BannedAt = &bannedAt
+		f.ur.On("GetByHandle", mock.Anything, "masha").Return(banned, nil)
+
+		_, err := f.uc.GetByProfileSlug(context.Background(), usecase.Viewer{}, "masha", "party")
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+		f.wr.AssertNotCalled(t, "GetBySlug", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("private wishlist", func(t *testing.T) {
+		f := newSlugFixture("party")
+		private := f.w
+		private.Visibility = entity.VisibilityPrivate
+		f.ur.On("GetByHandle", mock.Anything, "masha").Return(f.owner, nil)
+		f.wr.On("GetBySlug", mock.Anything, f.owner.ID, "party").Return(private, nil)
+
+		_, err := f.uc.GetByProfileSlug(context.Background(), usecase.Viewer{}, "masha", "party")
+		require.ErrorIs(t, err, usecase.ErrNotFound)
+	})
+}
@@ -0,0 +1,46 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+func (uc *wishlistUseCase) GetTrash(ctx context.Context, userID uuid.UUID) (usecase.Trash, error) {
+	wishlists, err := uc.wishlistRepo.GetDeletedByUserID(ctx, userID)
+	if err != nil {
+		return usecase.Trash{}, fmt.Errorf("get deleted wishlists: %w", err)
+	}
+	presents, err := uc.presentRepo.GetDeletedByUserID(ctx, userID)
+	if err != nil {
+		return usecase.Trash{}, fmt.Errorf("get deleted presents: %w", err)
+	}
+	return usecase.Trash{Wishlists: wishlists, Presents: presents}, nil
+}
+
+func (uc *wishlistUseCase) Restore(ctx context.Context, userID, id uuid.UUID) (entity.Wishlist, error) {
+	w, err := uc.wishlistRepo.GetDeletedByID(ctx, id)
+	// Чужую корзину не показываем: её вишлист неотличим от несуществующего
+	if err != nil || w.UserID != userID {
+		return entity.Wishlist{}, fmt.Errorf("wishlist %w in trash", usecase.ErrNotFound)
+	}
+	// Удалённые вишлисты в лимит не входят, поэтому место могли уже занять
+	count, err := uc.wishlistRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("count wishlists: %w", err)
+	}
+	if count >= usecase.MaxWishlistsPerUser {
+		return entity.Wishlist{}, errors.New("достигнут лимит вишлистов (20)")
+	}
+	if err := uc.wishlistRepo.Restore(ctx, id); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("wishlist %w in trash: %v", usecase.ErrNotFound, err)
+	}
+	w.DeletedAt = nil
+	w.Role = entity.WishlistRoleOwner
+	return w, nil
+}
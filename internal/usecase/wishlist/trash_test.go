@@ -0,0 +1,86 @@
+package wishlist_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+func TestGetTrash(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	pr := &mockrepo.MockPresentRepo{}
+	uc := wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo: wr,
+		PresentRepo:  pr,
+		FileStorage:  &mockminio.MockFileStorage{},
+		Policy:       access.New(wr, pr, noCollaborators(), testSecret),
+		Hasher:       hasher.New(),
+	})
+	userID := uuid.New()
+	deletedAt := time.Now()
+	wishlists := []entity.Wishlist{{ID: uuid.New(), UserID: userID, DeletedAt: &deletedAt}}
+	presents := []entity.Present{{ID: uuid.New(), WishlistID: uuid.New(), DeletedAt: &deletedAt}}
+	wr.On("GetDeletedByUserID", mock.Anything, userID).Return(wishlists, nil)
+	pr.On("GetDeletedByUserID", mock.Anything, userID).Return(presents, nil)
+
+	trash, err := uc.GetTrash(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, wishlists, trash.Wishlists)
+	assert.Equal(t, presents, trash.Presents)
+}
+
+func TestRestore(t *testing.T) {
+	ownerID := uuid.New()
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		lookupErr error
+		count     int64
+		wantErr   error
+	}{
+		{name: "owner", userID: ownerID},
+		{name: "not in trash", userID: ownerID, lookupErr: errors.New("record not found"), wantErr: usecase.ErrNotFound},
+		{name: "someone else's trash", userID: uuid.New(), wantErr: usecase.ErrNotFound},
+		{name: "limit reached", userID: ownerID, count: usecase.MaxWishlistsPerUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			w := entity.Wishlist{ID: uuid.New(), UserID: ownerID, DeletedAt: &deletedAt}
+			wr.On("GetDeletedByID", mock.Anything, w.ID).Return(w, tt.lookupErr)
+			wr.On("CountByUserID", mock.Anything, ownerID).Return(tt.count, nil)
+			wr.On("Restore", mock.Anything, w.ID).Return(nil)
+			uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+			restored, err := uc.Restore(context.Background(), tt.userID, w.ID)
+			if tt.wantErr != nil || tt.count > 0 {
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				wr.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, restored.DeletedAt)
+			assert.Equal(t, entity.WishlistRoleOwner, restored.Role)
+			wr.AssertCalled(t, "Restore", mock.Anything, w.ID)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+)
+
+// Подбор пароля ограничивается отдельно для каждого IP и для вишлиста в целом,
+// чтобы перебор с множества адресов тоже упирался в блокировку
+const (
+	unlockClientAttempts   = 5
+	unlockWishlistAttempts = 50
+	unlockLockDuration     = 15 * time.Minute
+	unlockFailureWindow    = time.Hour
+)
+
+func (uc *wishlistUseCase) SetVisibility(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.SetVisibilityInput) (entity.Wishlist, error) {
+	if !entity.ValidVisibility(input.Visibility) {
+		return entity.Wishlist{}, errors.New("visibility должна быть unlisted, private или password")
+	}
+
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
+	}
+
+	switch {
+	case input.Visibility != entity.VisibilityPassword:
+		// Пароль не хранится без надобности: при повторной защите его придётся задать заново
+		w.PasswordHash = ""
+	case input.Password != "":
+		if n := len([]rune(input.Password)); n < usecase.MinWishlistPasswordLen || n > usecase.MaxWishlistPasswordLen {
+			return entity.Wishlist{}, fmt.Errorf("пароль должен быть длиной от %d до %d символов",
+				usecase.MinWishlistPasswordLen, usecase.MaxWishlistPasswordLen)
+		}
+		hashed, err := uc.hasher.Hash(input.Password)
+		if err != nil {
+			return entity.Wishlist{}, fmt.Errorf("hash password: %w", err)
+		}
+		w.PasswordHash = hashed
+	case w.PasswordHash == "":
+		return entity.Wishlist{}, errors.New("для защищённого вишлиста нужен пароль")
+	}
+	w.Visibility = input.Visibility
+
+	if err := uc.update(ctx, &w); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("update wishlist: %w", err)
+	}
+	return w, nil
+}
+
+func (uc *wishlistUseCase) Unlock(ctx context.Context, id uuid.UUID, password string, client usecase.ClientInfo) (usecase.WishlistUnlock, error) {
+	w, err := uc.wishlistRepo.GetByID(ctx, id)
+	if err != nil {
+		return usecase.WishlistUnlock{}, fmt.Errorf("wishlist %w: %v", usecase.ErrNotFound, err)
+	}
+	if w.Unpublished || w.Visibility == entity.VisibilityPrivate {
+		return usecase.WishlistUnlock{}, fmt.Errorf("wishlist %w", usecase.ErrNotFound)
+	}
+	if w.Visibility != entity.VisibilityPassword {
+		return usecase.WishlistUnlock{}, fmt.Errorf("вишлист не защищён паролем: %w", usecase.ErrConflict)
+	}
+
+	now := time.Now()
+	clientKey, wishlistKey := unlockKeys(w.ID, client.IP)
+	until, err := uc.attemptRepo.LockedUntil(ctx, []string{clientKey, wishlistKey}, now)
+	if err != nil {
+		return usecase.WishlistUnlock{}, fmt.Errorf("check unlock lockout: %w", err)
+	}
+	if until.After(now) {
+		return usecase.WishlistUnlock{}, &usecase.LockoutError{RetryAfter: until.Sub(now)}
+	}
+
+	if err := uc.hasher.Compare(w.PasswordHash, password); err != nil {
+		uc.registerUnlockFailure(ctx, clientKey, unlockClientAttempts, now)
+		uc.registerUnlockFailure(ctx, wishlistKey, unlockWishlistAttempts, now)
+		return usecase.WishlistUnlock{}, usecase.ErrWrongWishlistPassword
+	}
+	if _, err := uc.attemptRepo.Reset(ctx, clientKey); err != nil {
+		log.Printf("wishlist unlock: reset %s: %v", clientKey, err)
+	}
+
+	token, expiresAt := uc.policy.IssueUnlockToken(w, now)
+	return usecase.WishlistUnlock{WishlistID: w.ID, Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func unlockKeys(wishlistID uuid.UUID, ip string) (clientKey, wishlistKey string) {
+	wishlistKey = "unlock:" + wishlistID.String()
+	return wishlistKey + ":" + ip, wishlistKey
+}
+
+// registerUnlockFailure блокирует ключ, когда неудач набралось limit. Ошибки хранилища
+// только логируются — ответ «неверный пароль» важнее
+func (uc *wishlistUseCase) registerUnlockFailure(ctx context.Context, key string, limit int, now time.Time) {
+	failures, err := uc.attemptRepo.RegisterFailure(ctx, key, now, unlockFailureWindow)
+	if err != nil {
+		log.Printf("wishlist unlock: register failure for %s: %v", key, err)
+		return
+	}
+	if failures >= limit {
+		if err := uc.attemptRepo.Lock(ctx, key, now.Add(unlockLockDuration)); err != nil {
+			log.Printf("wishlist unlock: lock %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package wishlist_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"main/internal/entity"
+	"main/internal/usecase"
+	"main/internal/usecase/access"
+	wishlistUC "main/internal/usecase/wishlist"
+	mockminio "main/mock/minio"
+	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
+)
+
+func TestSetVisibility(t *testing.T) {
+	ownerID := uuid.New()
+	wid := uuid.New()
+	existingHash, _ := hasher.New().Hash("secret-1")
+
+	tests := []struct {
+		name         string
+		current      entity.Wishlist
+		input        usecase.SetVisibilityInput
+		wantErr      bool
+		wantPassword string // пароль, который должен подходить к сохранённому хэшу; пустой — хэша нет
+	}{
+		{name: "unknown value", input: usecase.SetVisibilityInput{Visibility: "public"}, wantErr: true},
+		{name: "password without password", input: usecase.SetVisibilityInput{Visibility: entity.VisibilityPassword}, wantErr: true},
+		{name: "password too short", input: usecase.SetVisibilityInput{Visibility: entity.VisibilityPassword, Password: "abc"}, wantErr: true},
+		{name: "password", input: usecase.SetVisibilityInput{Visibility: entity.VisibilityPassword, Password: "secret-2"}, wantPassword: "secret-2"},
+		{
+			name:         "keeps existing password",
+			current:      entity.Wishlist{Visibility: entity.VisibilityPassword, PasswordHash: existingHash},
+			input:        usecase.SetVisibilityInput{Visibility: entity.VisibilityPassword},
+			wantPassword: "secret-1",
+		},
+		{
+			name:    "private drops password",
+			current: entity.Wishlist{Visibility: entity.VisibilityPassword, PasswordHash: existingHash},
+			input:   usecase.SetVisibilityInput{Visibility: entity.VisibilityPrivate},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			current := tt.current
+			current.ID, current.UserID = wid, ownerID
+			wr.On("GetByID", mock.Anything, wid).Return(current, nil)
+			var saved entity.Wishlist
+			wr.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(entity.Wishlist)
+			}).Return(nil)
+			uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+			w, err := uc.SetVisibility(context.Background(), ownerID, wid, 0, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.input.Visibility, w.Visibility)
+			assert.Equal(t, tt.input.Visibility, saved.Visibility)
+			if tt.wantPassword == "" {
+				assert.Empty(t, saved.PasswordHash)
+				return
+			}
+			assert.NoError(t, hasher.New().Compare(saved.PasswordHash, tt.wantPassword))
+		})
+	}
+}
+
+func TestSetVisibility_NotOwner_Forbidden(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	_, err := uc.SetVisibility(context.Background(), uuid.New(), wid, 0, usecase.SetVisibilityInput{Visibility: entity.VisibilityPrivate})
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+// newUnlockFixture возвращает use case с защищённым паролем "secret-1" вишлистом
+func newUnlockFixture(t *testing.T) (usecase.WishlistUseCase, usecase.AccessPolicy, *mockrepo.MockLoginAttemptRepo, entity.Wishlist) {
+	t.Helper()
+	hashed, err := hasher.New().Hash("secret-1")
+	require.NoError(t, err)
+	w := entity.Wishlist{ID: uuid.New(), UserID: uuid.New(), Visibility: entity.VisibilityPassword, PasswordHash: hashed}
+
+	wr := &mockrepo.MockWishlistRepo{}
+	wr.On("GetByID", mock.Anything, w.ID).Return(w, nil)
+	ar := &mockrepo.MockLoginAttemptRepo{}
+	policy := access.New(wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret)
+	uc := wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo: wr,
+		AttemptRepo:  ar,
+		FileStorage:  &mockminio.MockFileStorage{},
+		Policy:       policy,
+		Hasher:       hasher.New(),
+	})
+	return uc, policy, ar, w
+}
+
+func TestUnlock_Success(t *testing.T) {
+	uc, policy, ar, w := newUnlockFixture(t)
+	client := usecase.ClientInfo{IP: "203.0.113.7"}
+	clientKey := "unlock:" + w.ID.String() + ":" + client.IP
+	ar.On("LockedUntil", mock.Anything, []string{clientKey, "unlock:" + w.ID.String()}, mock.Anything).Return(time.Time{}, nil)
+	ar.On("Reset", mock.Anything, clientKey).Return(0, nil)
+
+	result, err := uc.Unlock(context.Background(), w.ID, "secret-1", client)
+	require.NoError(t, err)
+	assert.Equal(t, w.ID, result.WishlistID)
+	_, err = policy.CheckView(context.Background(), w, usecase.Viewer{UnlockTokens: []string{result.Token}})
+	assert.NoError(t, err)
+	ar.AssertExpectations(t)
+}
+
+func TestUnlock_WrongPasswordLocksAfterLimit(t *testing.T) {
+	uc, _, ar, w := newUnlockFixture(t)
+	client := usecase.ClientInfo{IP: "203.0.113.7"}
+	clientKey := "unlock:" + w.ID.String() + ":" + client.IP
+	ar.On("LockedUntil", mock.Anything, mock.Anything, mock.Anything).Return(time.Time{}, nil).Once()
+	ar.On("RegisterFailure", mock.Anything, clientKey, mock.Anything, mock.Anything).Return(5, nil)
+	ar.On("RegisterFailure", mock.Anything, "unlock:"+w.ID.String(), mock.Anything, mock.Anything).Return(5, nil)
+	ar.On("Lock", mock.Anything, clientKey, mock.Anything).Return(nil)
+
+	_, err := uc.Unlock(context.Background(), w.ID, "wrong", client)
+	require.ErrorIs(t, err, usecase.ErrWrongWishlistPassword)
+	ar.AssertExpectations(t)
+	// Общий счётчик вишлиста ещё не дошёл до своего порога
+	ar.AssertNotCalled(t, "Lock", mock.Anything, "unlock:"+w.ID.String(), mock.Anything)
+
+	// Пока блокировка действует, пароль даже не проверяется
+	ar.On("LockedUntil", mock.Anything, mock.Anything, mock.Anything).Return(time.Now().Add(10*time.Minute), nil)
+	_, err = uc.Unlock(context.Background(), w.ID, "secret-1", client)
+	var lockout *usecase.LockoutError
+	require.ErrorAs(t, err, &lockout)
+	assert.Greater(t, lockout.RetryAfter, time.Duration(0))
+}
+
+func TestUnlock_NotProtected(t *testing.T) {
+	tests := []struct {
+		name      string
+		wishlist  entity.Wishlist
+		wantError error
+	}{
+		{name: "private looks missing", wishlist: entity.Wishlist{Visibility: entity.VisibilityPrivate}, wantError: usecase.ErrNotFound},
+		{name: "unpublished looks missing", wishlist: entity.Wishlist{Visibility: entity.VisibilityPassword, Unpublished: true}, wantError: usecase.ErrNotFound},
+		{name: "unlisted needs no password", wishlist: entity.Wishlist{Visibility: entity.VisibilityUnlisted}, wantError: usecase.ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wr := &mockrepo.MockWishlistRepo{}
+			w := tt.wishlist
+			w.ID, w.UserID = uuid.New(), uuid.New()
+			wr.On("GetByID", mock.Anything, w.ID).Return(w, nil)
+			uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+			_, err := uc.Unlock(context.Background(), w.ID, "secret-1", usecase.ClientInfo{})
+			require.ErrorIs(t, err, tt.wantError)
+		})
+	}
+}
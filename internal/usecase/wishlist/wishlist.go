@@ -5,25 +5,63 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
 	"main/internal/entity"
 	"main/internal/repo"
 	"main/internal/usecase"
+	"main/pkg/hasher"
 	minioPkg "main/pkg/minio"
 	"main/pkg/shortid"
 )
 
 type wishlistUseCase struct {
-	wishlistRepo repo.WishlistRepo
-	fileStorage  minioPkg.FileStorage
+	wishlistRepo     repo.WishlistRepo
+	presentRepo      repo.PresentRepo
+	metaRepo         repo.PresentMetaRepo
+	attemptRepo      repo.LoginAttemptRepo
+	userRepo         repo.UserRepo
+	redirectRepo     repo.HandleRedirectRepo
+	revisionRepo     repo.RevisionRepo
+	collaboratorRepo repo.CollaboratorRepo
+	inviteRepo       repo.WishlistInviteRepo
+	fileStorage      minioPkg.FileStorage
+	policy           usecase.AccessPolicy
+	hasher           hasher.PasswordHasher
 }
 
-func New(wishlistRepo repo.WishlistRepo, fileStorage minioPkg.FileStorage) usecase.WishlistUseCase {
+// Deps — зависимости wishlistUseCase
+type Deps struct {
+	WishlistRepo     repo.WishlistRepo
+	PresentRepo      repo.PresentRepo // подарки в корзине и при копировании
+	PresentMetaRepo  repo.PresentMetaRepo
+	AttemptRepo      repo.LoginAttemptRepo   // неудачные попытки ввести пароль вишлиста
+	UserRepo         repo.UserRepo           // поиск приглашаемых соавторов и владельцев адресов по handle
+	RedirectRepo     repo.HandleRedirectRepo // адреса в профиле по прежнему handle
+	RevisionRepo     repo.RevisionRepo       // история блоков и настроек
+	CollaboratorRepo repo.CollaboratorRepo
+	InviteRepo       repo.WishlistInviteRepo
+	FileStorage      minioPkg.FileStorage
+	Policy           usecase.AccessPolicy
+	Hasher           hasher.PasswordHasher // пароли защищённых вишлистов
+}
+
+func New(d Deps) usecase.WishlistUseCase {
 	return &wishlistUseCase{
-		wishlistRepo: wishlistRepo,
-		fileStorage:  fileStorage,
+		wishlistRepo:     d.WishlistRepo,
+		presentRepo:      d.PresentRepo,
+		metaRepo:         d.PresentMetaRepo,
+		attemptRepo:      d.AttemptRepo,
+		userRepo:         d.UserRepo,
+		redirectRepo:     d.RedirectRepo,
+		revisionRepo:     d.RevisionRepo,
+		collaboratorRepo: d.CollaboratorRepo,
+		inviteRepo:       d.InviteRepo,
+		fileStorage:      d.FileStorage,
+		policy:           d.Policy,
+		hasher:           d.Hasher,
 	}
 }
 
@@ -67,6 +105,7 @@ func (uc *wishlistUseCase) Create(ctx context.Context, userID uuid.UUID, input u
 			Time: input.LocationTime,
 		},
 		PresentsCount: 0,
+		Visibility:    entity.VisibilityUnlisted,
 	}
 
 	if err := uc.wishlistRepo.Create(ctx, w); err != nil {
@@ -116,6 +155,7 @@ func (uc *wishlistUseCase) CreateConstructor(ctx context.Context, userID uuid.UU
 		},
 		Blocks:        input.Blocks,
 		PresentsCount: 0,
+		Visibility:    entity.VisibilityUnlisted,
 	}
 
 	if err := uc.wishlistRepo.Create(ctx, w); err != nil {
@@ -125,27 +165,51 @@ func (uc *wishlistUseCase) CreateConstructor(ctx context.Context, userID uuid.UU
 	return w, nil
 }
 
-func (uc *wishlistUseCase) GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
-	return uc.wishlistRepo.GetByID(ctx, id)
+func (uc *wishlistUseCase) GetByID(ctx context.Context, viewer usecase.Viewer, id uuid.UUID) (entity.Wishlist, error) {
+	return uc.policy.ViewWishlist(ctx, viewer, id)
 }
 
-func (uc *wishlistUseCase) GetByShortID(ctx context.Context, shortID string) (entity.Wishlist, error) {
-	return uc.wishlistRepo.GetByShortID(ctx, shortID)
+func (uc *wishlistUseCase) GetByShortID(ctx context.Context, viewer usecase.Viewer, shortID string) (entity.Wishlist, error) {
+	w, err := uc.findByLink(ctx, shortID)
+	if err != nil {
+		return entity.Wishlist{}, fmt.Errorf("wishlist %w: %v", usecase.ErrNotFound, err)
+	}
+	role, err := uc.policy.CheckView(ctx, w, viewer)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	w.Role = role
+	return w, nil
 }
 
 func (uc *wishlistUseCase) GetAllByUser(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
-	return uc.wishlistRepo.GetAllByUserID(ctx, userID)
+	own, err := uc.wishlistRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range own {
+		own[i].Role = entity.WishlistRoleOwner
+	}
+	shared, err := uc.wishlistRepo.GetSharedWithUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(own, shared...), nil
 }
 
-func (uc *wishlistUseCase) Update(ctx context.Context, id uuid.UUID, input usecase.CreateWishlistInput) (entity.Wishlist, error) {
+func (uc *wishlistUseCase) Update(ctx context.Context, userID, id uuid.UUID, version uint, input usecase.CreateWishlistInput) (entity.Wishlist, error) {
 	if err := validateWishlistFields(input.Title, input.Description, input.LocationName, input.LocationLink, input.CoverURL); err != nil {
 		return entity.Wishlist{}, err
 	}
 
-	w, err := uc.wishlistRepo.GetByID(ctx, id)
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit)
 	if err != nil {
-		return entity.Wishlist{}, fmt.Errorf("wishlist not found: %w", err)
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
 	}
+	before := w
 
 	w.Title = input.Title
 	w.Description = input.Description
@@ -155,9 +219,14 @@ func (uc *wishlistUseCase) Update(ctx context.Context, id uuid.UUID, input useca
 		PresentsLayout:       input.PresentsLayout,
 	}
 	w.Location = entity.Location{
-		Name: input.LocationName,
-		Link: input.LocationLink,
-		Time: input.LocationTime,
+		Name:     input.LocationName,
+		Link:     input.LocationLink,
+		Time:     input.LocationTime,
+		Timezone: w.Location.Timezone,
+	}
+	unarchive, err := uc.checkUnarchive(ctx, w)
+	if err != nil {
+		return entity.Wishlist{}, err
 	}
 
 	coverURL, err := uc.resolveCover(input.CoverData, input.CoverName, input.CoverURL)
@@ -166,34 +235,95 @@ func (uc *wishlistUseCase) Update(ctx context.Context, id uuid.UUID, input useca
 	}
 	w.Cover = coverURL
 
-	if err := uc.wishlistRepo.Update(ctx, w); err != nil {
+	if err := uc.update(ctx, &w); err != nil {
 		return entity.Wishlist{}, fmt.Errorf("update wishlist: %w", err)
 	}
+	uc.recordRevision(ctx, before, w, userID, nil)
+	if unarchive {
+		if err := uc.wishlistRepo.Unarchive(ctx, w.ID); err != nil {
+			return entity.Wishlist{}, fmt.Errorf("unarchive wishlist: %w", err)
+		}
+		w.ArchivedAt = nil
+	}
 
 	return w, nil
 }
 
-func (uc *wishlistUseCase) UpdateBlocks(ctx context.Context, id uuid.UUID, blocks []entity.Block) (entity.Wishlist, error) {
+func (uc *wishlistUseCase) UpdateBlocks(ctx context.Context, userID, id uuid.UUID, version uint, blocks []entity.Block) (entity.Wishlist, error) {
 	if err := validateBlocks(blocks); err != nil {
 		return entity.Wishlist{}, err
 	}
 
-	w, err := uc.wishlistRepo.GetByID(ctx, id)
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionEdit)
 	if err != nil {
-		return entity.Wishlist{}, fmt.Errorf("wishlist not found: %w", err)
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
 	}
 
+	before := w
 	w.Blocks = blocks
 
-	if err := uc.wishlistRepo.Update(ctx, w); err != nil {
+	if err := uc.update(ctx, &w); err != nil {
 		return entity.Wishlist{}, fmt.Errorf("update blocks: %w", err)
 	}
+	uc.recordRevision(ctx, before, w, userID, nil)
 
 	return w, nil
 }
 
-func (uc *wishlistUseCase) Delete(ctx context.Context, id uuid.UUID) error {
-	return uc.wishlistRepo.Delete(ctx, id)
+func (uc *wishlistUseCase) SetListedOnProfile(ctx context.Context, userID, id uuid.UUID, version uint, listed bool) (entity.Wishlist, error) {
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionManage)
+	if err != nil {
+		return entity.Wishlist{}, err
+	}
+	if w.Version != version {
+		return entity.Wishlist{}, &usecase.StaleWishlistError{Current: w}
+	}
+	if w.ListedOnProfile == listed {
+		return w, nil
+	}
+
+	w.ListedOnProfile = listed
+	if err := uc.update(ctx, &w); err != nil {
+		return entity.Wishlist{}, fmt.Errorf("update wishlist: %w", err)
+	}
+	return w, nil
+}
+
+func (uc *wishlistUseCase) Delete(ctx context.Context, userID, id uuid.UUID, version uint) error {
+	w, err := uc.policy.AuthorizeWishlist(ctx, userID, id, usecase.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if w.Version != version {
+		return &usecase.StaleWishlistError{Current: w}
+	}
+	if err := uc.wishlistRepo.Delete(ctx, id, version, time.Now()); err != nil {
+		return fmt.Errorf("delete wishlist: %w", uc.staleOr(ctx, w, err))
+	}
+	return nil
+}
+
+// update записывает вишлист и увеличивает его версию
+func (uc *wishlistUseCase) update(ctx context.Context, w *entity.Wishlist) error {
+	if err := uc.wishlistRepo.Update(ctx, *w); err != nil {
+		return uc.staleOr(ctx, *w, err)
+	}
+	w.Version++
+	return nil
+}
+
+// staleOr объясняет несостоявшуюся условную запись: если вишлист успели изменить между чтением
+// и записью, возвращает *usecase.StaleWishlistError, иначе — исходную ошибку
+func (uc *wishlistUseCase) staleOr(ctx context.Context, w entity.Wishlist, err error) error {
+	current, getErr := uc.wishlistRepo.GetByID(ctx, w.ID)
+	if getErr != nil || current.Version == w.Version {
+		return err
+	}
+	current.Role = w.Role
+	return &usecase.StaleWishlistError{Current: current}
 }
 
 // resolveCover — возвращает URL обложки: загружает файл в MinIO или возвращает URL as-is
@@ -12,14 +12,41 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"main/internal/entity"
+	"main/internal/repo"
 	"main/internal/usecase"
+	"main/internal/usecase/access"
 	wishlistUC "main/internal/usecase/wishlist"
 	mockminio "main/mock/minio"
 	mockrepo "main/mock/repo"
+	"main/pkg/hasher"
 )
 
+const testSecret = "test-secret"
+
 func newWishlistUC(wr *mockrepo.MockWishlistRepo, fs *mockminio.MockFileStorage) usecase.WishlistUseCase {
-	return wishlistUC.New(wr, fs)
+	return wishlistUC.New(wishlistUC.Deps{
+		WishlistRepo: wr,
+		AttemptRepo:  &mockrepo.MockLoginAttemptRepo{},
+		RevisionRepo: anyRevisions(),
+		FileStorage:  fs,
+		Policy:       access.New(wr, &mockrepo.MockPresentRepo{}, noCollaborators(), testSecret),
+		Hasher:       hasher.New(),
+	})
+}
+
+// anyRevisions — история принимает любые ревизии
+func anyRevisions() *mockrepo.MockRevisionRepo {
+	rr := &mockrepo.MockRevisionRepo{}
+	rr.On("GetLatest", mock.Anything, mock.Anything).Return(entity.WishlistRevision{}, nil).Maybe()
+	rr.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(entity.WishlistRevision{}, nil).Maybe()
+	return rr
+}
+
+// noCollaborators — у вишлистов нет соавторов
+func noCollaborators() *mockrepo.MockCollaboratorRepo {
+	cr := &mockrepo.MockCollaboratorRepo{}
+	cr.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(entity.Collaborator{}, repo.ErrNotFound)
+	return cr
 }
 
 func TestValidateBlocks_UnknownType(t *testing.T) {
@@ -192,13 +219,14 @@ func TestUpdateBlocks_Success(t *testing.T) {
 	uc := newWishlistUC(wr, fs)
 
 	wid := uuid.New()
-	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid}, nil)
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
 	wr.On("Update", mock.Anything, mock.MatchedBy(func(w entity.Wishlist) bool {
 		return len(w.Blocks) == 1 && w.Blocks[0].Type == "text"
 	})).Return(nil)
 
 	blocks := []entity.Block{{Type: "text", Row: 0, Col: 0, ColSpan: 1}}
-	w, err := uc.UpdateBlocks(context.Background(), wid, blocks)
+	w, err := uc.UpdateBlocks(context.Background(), ownerID, wid, 0, blocks)
 	require.NoError(t, err)
 	assert.Len(t, w.Blocks, 1)
 	wr.AssertExpectations(t)
@@ -293,3 +321,193 @@ func TestValidateBlocks_VideoURLTooLong(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "url")
 }
+
+func TestUpdateBlocks_NotOwner_Forbidden(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newWishlistUC(wr, fs)
+
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	blocks := []entity.Block{{Type: "text", Row: 0, Col: 0, ColSpan: 1}}
+	_, err := uc.UpdateBlocks(context.Background(), uuid.New(), wid, 0, blocks)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdate_NotOwner_Forbidden(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newWishlistUC(wr, fs)
+
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	_, err := uc.Update(context.Background(), uuid.New(), wid, 0, usecase.CreateWishlistInput{
+		Title:     "Hijacked",
+		CoverData: []byte("imgdata"),
+		CoverName: "cover.jpg",
+	})
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	fs.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+}
+
+func TestSetListedOnProfile(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	ownerID := uuid.New()
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+	wr.On("Update", mock.Anything, mock.MatchedBy(func(w entity.Wishlist) bool { return w.ListedOnProfile })).Return(nil)
+
+	_, err := uc.SetListedOnProfile(context.Background(), uuid.New(), wid, 0, true)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	w, err := uc.SetListedOnProfile(context.Background(), ownerID, wid, 0, true)
+	require.NoError(t, err)
+	assert.True(t, w.ListedOnProfile)
+	wr.AssertExpectations(t)
+}
+
+func TestDelete_NotOwner_Forbidden(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newWishlistUC(wr, fs)
+
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: uuid.New()}, nil)
+
+	err := uc.Delete(context.Background(), uuid.New(), wid, 0)
+	require.ErrorIs(t, err, usecase.ErrForbidden)
+	wr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newWishlistUC(wr, fs)
+
+	wid := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{}, repo.ErrNotFound)
+
+	err := uc.Delete(context.Background(), uuid.New(), wid, 0)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestDelete_Owner_Success(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	fs := &mockminio.MockFileStorage{}
+	uc := newWishlistUC(wr, fs)
+
+	wid := uuid.New()
+	ownerID := uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID}, nil)
+	wr.On("Delete", mock.Anything, wid, uint(0), mock.Anything).Return(nil)
+
+	err := uc.Delete(context.Background(), ownerID, wid, 0)
+	require.NoError(t, err)
+	wr.AssertExpectations(t)
+}
+
+func TestUpdate_StaleVersion(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	current := entity.Wishlist{ID: wid, UserID: ownerID, Title: "Из другой вкладки", Version: 4}
+	wr.On("GetByID", mock.Anything, wid).Return(current, nil)
+
+	_, err := uc.Update(context.Background(), ownerID, wid, 3, usecase.CreateWishlistInput{Title: "Моя правка"})
+	var stale *usecase.StaleWishlistError
+	require.ErrorAs(t, err, &stale)
+	assert.Equal(t, "Из другой вкладки", stale.Current.Title)
+	assert.Equal(t, uint(4), stale.Current.Version)
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateBlocks_ConcurrentWrite(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	// Версия совпала при чтении, но соседняя правка успела записаться раньше
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 2}, nil).Once()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 3}, nil)
+	wr.On("Update", mock.Anything, mock.Anything).Return(errNotFound)
+
+	_, err := uc.UpdateBlocks(context.Background(), ownerID, wid, 2, []entity.Block{{Type: "divider", ColSpan: 2}})
+	var stale *usecase.StaleWishlistError
+	require.ErrorAs(t, err, &stale)
+	assert.Equal(t, uint(3), stale.Current.Version)
+	assert.Equal(t, entity.WishlistRoleOwner, stale.Current.Role)
+}
+
+func TestUpdateBlocks_BumpsVersion(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 2}, nil)
+	wr.On("Update", mock.Anything, mock.MatchedBy(func(w entity.Wishlist) bool { return w.Version == 2 })).Return(nil)
+
+	w, err := uc.UpdateBlocks(context.Background(), ownerID, wid, 2, []entity.Block{{Type: "divider", ColSpan: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), w.Version)
+}
+
+func TestSettings_StaleVersion(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 4}, nil)
+	ctx := context.Background()
+
+	for name, call := range map[string]func() error{
+		"visibility": func() error {
+			_, err := uc.SetVisibility(ctx, ownerID, wid, 3, usecase.SetVisibilityInput{Visibility: entity.VisibilityPrivate})
+			return err
+		},
+		"slug": func() error {
+			_, err := uc.SetSlug(ctx, ownerID, wid, 3, usecase.SetSlugInput{Slug: "party", Scope: entity.SlugScopeGlobal})
+			return err
+		},
+		"event": func() error {
+			_, err := uc.SetEvent(ctx, ownerID, wid, 3, usecase.SetEventInput{})
+			return err
+		},
+		"profile": func() error {
+			_, err := uc.SetListedOnProfile(ctx, ownerID, wid, 3, true)
+			return err
+		},
+		"restore revision": func() error {
+			_, err := uc.RestoreRevision(ctx, ownerID, wid, 3, 1)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			var stale *usecase.StaleWishlistError
+			require.ErrorAs(t, err, &stale)
+			assert.Equal(t, uint(4), stale.Current.Version)
+		})
+	}
+	wr.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	wr.AssertNotCalled(t, "ChangeSlug", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDelete_StaleVersion(t *testing.T) {
+	wr := &mockrepo.MockWishlistRepo{}
+	uc := newWishlistUC(wr, &mockminio.MockFileStorage{})
+
+	wid, ownerID := uuid.New(), uuid.New()
+	wr.On("GetByID", mock.Anything, wid).Return(entity.Wishlist{ID: wid, UserID: ownerID, Version: 5}, nil)
+
+	err := uc.Delete(context.Background(), ownerID, wid, 4)
+	require.ErrorIs(t, err, usecase.ErrStale)
+	wr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
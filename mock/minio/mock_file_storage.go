@@ -11,7 +11,18 @@ func (m *MockFileStorage) Upload(name string, data []byte) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockFileStorage) Get(objectID string) ([]byte, error) {
+	args := m.Called(objectID)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
 func (m *MockFileStorage) Delete(objectID string) error {
 	args := m.Called(objectID)
 	return args.Error(0)
 }
+
+func (m *MockFileStorage) ObjectID(url string) (string, bool) {
+	args := m.Called(url)
+	return args.String(0), args.Bool(1)
+}
@@ -0,0 +1,40 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockAccessTokenRepo struct {
+	mock.Mock
+}
+
+func (m *MockAccessTokenRepo) Create(ctx context.Context, token entity.AccessToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenRepo) GetByHash(ctx context.Context, tokenHash string) (entity.AccessToken, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(entity.AccessToken), args.Error(1)
+}
+
+func (m *MockAccessTokenRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.AccessToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.AccessToken), args.Error(1)
+}
+
+func (m *MockAccessTokenRepo) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,44 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockCollaboratorRepo struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorRepo) Upsert(ctx context.Context, c entity.Collaborator) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepo) Accept(ctx context.Context, wishlistID, userID uuid.UUID) error {
+	args := m.Called(ctx, wishlistID, userID)
+	return args.Error(0)
+}
+
+func (m *MockCollaboratorRepo) Get(ctx context.Context, wishlistID, userID uuid.UUID) (entity.Collaborator, error) {
+	args := m.Called(ctx, wishlistID, userID)
+	return args.Get(0).(entity.Collaborator), args.Error(1)
+}
+
+func (m *MockCollaboratorRepo) GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Collaborator, error) {
+	args := m.Called(ctx, wishlistID)
+	return args.Get(0).([]entity.Collaborator), args.Error(1)
+}
+
+func (m *MockCollaboratorRepo) CountByWishlistID(ctx context.Context, wishlistID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, wishlistID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCollaboratorRepo) Delete(ctx context.Context, wishlistID, userID uuid.UUID) error {
+	args := m.Called(ctx, wishlistID, userID)
+	return args.Error(0)
+}
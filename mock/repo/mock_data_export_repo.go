@@ -0,0 +1,50 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockDataExportRepo struct {
+	mock.Mock
+}
+
+func (m *MockDataExportRepo) Create(ctx context.Context, export entity.DataExport) error {
+	args := m.Called(ctx, export)
+	return args.Error(0)
+}
+
+func (m *MockDataExportRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.DataExport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.DataExport), args.Error(1)
+}
+
+func (m *MockDataExportRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.DataExport, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.DataExport), args.Error(1)
+}
+
+func (m *MockDataExportRepo) ClaimNext(ctx context.Context, now, staleBefore time.Time) (entity.DataExport, bool, error) {
+	args := m.Called(ctx, now, staleBefore)
+	return args.Get(0).(entity.DataExport), args.Bool(1), args.Error(2)
+}
+
+func (m *MockDataExportRepo) Update(ctx context.Context, export entity.DataExport) error {
+	args := m.Called(ctx, export)
+	return args.Error(0)
+}
+
+func (m *MockDataExportRepo) GetExpired(ctx context.Context, now time.Time, limit int) ([]entity.DataExport, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]entity.DataExport), args.Error(1)
+}
+
+func (m *MockDataExportRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,16 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFileRefRepo struct {
+	mock.Mock
+}
+
+func (m *MockFileRefRepo) Unreferenced(ctx context.Context, urls []string) ([]string, error) {
+	args := m.Called(ctx, urls)
+	return args.Get(0).([]string), args.Error(1)
+}
@@ -0,0 +1,18 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockHandleRedirectRepo struct {
+	mock.Mock
+}
+
+func (m *MockHandleRedirectRepo) GetByHandle(ctx context.Context, handle string) (entity.HandleRedirect, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(entity.HandleRedirect), args.Error(1)
+}
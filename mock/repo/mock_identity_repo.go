@@ -0,0 +1,44 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockIdentityRepo struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepo) CreateWithUser(ctx context.Context, user entity.User, identity entity.UserIdentity) error {
+	args := m.Called(ctx, user, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) Create(ctx context.Context, identity entity.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	return args.Get(0).(entity.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.UserIdentity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepo) Update(ctx context.Context, identity entity.UserIdentity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
@@ -0,0 +1,37 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginAttemptRepo struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepo) RegisterFailure(ctx context.Context, key string, now time.Time, resetAfter time.Duration) (int, error) {
+	args := m.Called(ctx, key, now, resetAfter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	args := m.Called(ctx, key, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepo) LockedUntil(ctx context.Context, keys []string, now time.Time) (time.Time, error) {
+	args := m.Called(ctx, keys, now)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepo) Reset(ctx context.Context, key string) (int, error) {
+	args := m.Called(ctx, key)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepo) Acquire(ctx context.Context, key string, now time.Time, cooldown time.Duration) (bool, error) {
+	args := m.Called(ctx, key, now, cooldown)
+	return args.Bool(0), args.Error(1)
+}
@@ -3,6 +3,7 @@ package mockrepo
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 
 	"main/internal/entity"
@@ -16,3 +17,8 @@ func (m *MockPresentMetaRepo) Upsert(ctx context.Context, meta entity.PresentMet
 	args := m.Called(ctx, meta)
 	return args.Error(0)
 }
+
+func (m *MockPresentMetaRepo) GetByPresentIDs(ctx context.Context, presentIDs []uuid.UUID) ([]entity.PresentMeta, error) {
+	args := m.Called(ctx, presentIDs)
+	return args.Get(0).([]entity.PresentMeta), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mockrepo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -33,7 +34,37 @@ func (m *MockPresentRepo) Update(ctx context.Context, present entity.Present) er
 	return args.Error(0)
 }
 
-func (m *MockPresentRepo) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockPresentRepo) Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error {
+	args := m.Called(ctx, id, version, now)
+	return args.Error(0)
+}
+
+func (m *MockPresentRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPresentRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Present, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Present), args.Error(1)
+}
+
+func (m *MockPresentRepo) GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Present, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Present), args.Error(1)
+}
+
+func (m *MockPresentRepo) GetDeletedByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.Present, error) {
+	args := m.Called(ctx, wishlistID)
+	return args.Get(0).([]entity.Present), args.Error(1)
+}
+
+func (m *MockPresentRepo) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Present, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]entity.Present), args.Error(1)
+}
+
+func (m *MockPresentRepo) Purge(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
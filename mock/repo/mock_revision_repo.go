@@ -0,0 +1,34 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockRevisionRepo struct {
+	mock.Mock
+}
+
+func (m *MockRevisionRepo) Create(ctx context.Context, rev entity.WishlistRevision, keep int) (entity.WishlistRevision, error) {
+	args := m.Called(ctx, rev, keep)
+	return args.Get(0).(entity.WishlistRevision), args.Error(1)
+}
+
+func (m *MockRevisionRepo) GetAllByWishlistID(ctx context.Context, wishlistID uuid.UUID) ([]entity.WishlistRevision, error) {
+	args := m.Called(ctx, wishlistID)
+	return args.Get(0).([]entity.WishlistRevision), args.Error(1)
+}
+
+func (m *MockRevisionRepo) GetByNumber(ctx context.Context, wishlistID uuid.UUID, number int) (entity.WishlistRevision, error) {
+	args := m.Called(ctx, wishlistID, number)
+	return args.Get(0).(entity.WishlistRevision), args.Error(1)
+}
+
+func (m *MockRevisionRepo) GetLatest(ctx context.Context, wishlistID uuid.UUID) (entity.WishlistRevision, error) {
+	args := m.Called(ctx, wishlistID)
+	return args.Get(0).(entity.WishlistRevision), args.Error(1)
+}
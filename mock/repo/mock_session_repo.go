@@ -0,0 +1,50 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockSessionRepo struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepo) Create(ctx context.Context, session entity.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Session, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepo) Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, oldHash, newHash, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) RevokeAllByUserID(ctx context.Context, userID, exceptID uuid.UUID) error {
+	args := m.Called(ctx, userID, exceptID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) Touch(ctx context.Context, id uuid.UUID, seenAt time.Time, ip, userAgent string) error {
+	args := m.Called(ctx, id, seenAt, ip, userAgent)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) GetActiveByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]entity.Session, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Get(0).([]entity.Session), args.Error(1)
+}
@@ -0,0 +1,64 @@
+package mockrepo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockTemplateRepo struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRepo) Create(ctx context.Context, template entity.Template) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTemplateRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Template, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Template), args.Error(1)
+}
+
+func (m *MockTemplateRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Template, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Template), args.Error(1)
+}
+
+func (m *MockTemplateRepo) GetPublic(ctx context.Context, limit, offset int, userID uuid.UUID) ([]entity.TemplateWithAuthor, error) {
+	args := m.Called(ctx, limit, offset, userID)
+	return args.Get(0).([]entity.TemplateWithAuthor), args.Error(1)
+}
+
+func (m *MockTemplateRepo) Update(ctx context.Context, template entity.Template) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+func (m *MockTemplateRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTemplateRepo) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTemplateRepo) Like(ctx context.Context, userID, templateID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID, templateID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTemplateRepo) Unlike(ctx context.Context, userID, templateID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID, templateID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTemplateRepo) GetLikedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Template, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Template), args.Error(1)
+}
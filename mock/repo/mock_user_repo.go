@@ -2,11 +2,13 @@ package mockrepo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 
 	"main/internal/entity"
+	"main/internal/repo"
 )
 
 type MockUserRepo struct {
@@ -23,12 +25,52 @@ func (m *MockUserRepo) GetByUsername(ctx context.Context, username string) (enti
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUserRepo) GetByVerifiedEmail(ctx context.Context, email string) (entity.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
 func (m *MockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.User, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
-func (m *MockUserRepo) Update(ctx context.Context, user entity.User) error {
-	args := m.Called(ctx, user)
+func (m *MockUserRepo) GetByHandle(ctx context.Context, handle string) (entity.User, error) {
+	args := m.Called(ctx, handle)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateColumns(ctx context.Context, user entity.User, columns ...repo.UserColumn) error {
+	args := m.Called(ctx, user, columns)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) ReplacePasswordHash(ctx context.Context, id uuid.UUID, oldHash, newHash string) error {
+	args := m.Called(ctx, id, oldHash, newHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) UseTOTPStep(ctx context.Context, id uuid.UUID, step int64) error {
+	args := m.Called(ctx, id, step)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) ChangeHandle(ctx context.Context, userID uuid.UUID, handle string, now time.Time) error {
+	args := m.Called(ctx, userID, handle, now)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) Search(ctx context.Context, query string, limit, offset int) ([]entity.User, int64, error) {
+	args := m.Called(ctx, query, limit, offset)
+	return args.Get(0).([]entity.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepo) GetDueForDeletion(ctx context.Context, now time.Time, limit int) ([]entity.User, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]entity.User), args.Error(1)
+}
+
+func (m *MockUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
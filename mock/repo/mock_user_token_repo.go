@@ -0,0 +1,30 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockUserTokenRepo struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepo) Create(ctx context.Context, token entity.UserToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepo) Consume(ctx context.Context, purpose, tokenHash string, now time.Time) (entity.UserToken, error) {
+	args := m.Called(ctx, purpose, tokenHash, now)
+	return args.Get(0).(entity.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepo) InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error {
+	args := m.Called(ctx, userID, purpose)
+	return args.Error(0)
+}
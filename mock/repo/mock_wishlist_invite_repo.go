@@ -0,0 +1,29 @@
+package mockrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"main/internal/entity"
+)
+
+type MockWishlistInviteRepo struct {
+	mock.Mock
+}
+
+func (m *MockWishlistInviteRepo) Create(ctx context.Context, invite entity.WishlistInvite) error {
+	args := m.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (m *MockWishlistInviteRepo) GetByTokenHash(ctx context.Context, tokenHash string) (entity.WishlistInvite, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(entity.WishlistInvite), args.Error(1)
+}
+
+func (m *MockWishlistInviteRepo) Consume(ctx context.Context, tokenHash string, now time.Time) (entity.WishlistInvite, error) {
+	args := m.Called(ctx, tokenHash, now)
+	return args.Get(0).(entity.WishlistInvite), args.Error(1)
+}
@@ -2,6 +2,7 @@ package mockrepo
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -18,6 +19,11 @@ func (m *MockWishlistRepo) Create(ctx context.Context, wishlist entity.Wishlist)
 	return args.Error(0)
 }
 
+func (m *MockWishlistRepo) CreateWithPresents(ctx context.Context, wishlist entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error {
+	args := m.Called(ctx, wishlist, presents, metas)
+	return args.Error(0)
+}
+
 func (m *MockWishlistRepo) GetByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(entity.Wishlist), args.Error(1)
@@ -28,17 +34,87 @@ func (m *MockWishlistRepo) GetByShortID(ctx context.Context, shortID string) (en
 	return args.Get(0).(entity.Wishlist), args.Error(1)
 }
 
+func (m *MockWishlistRepo) GetBySlug(ctx context.Context, scope uuid.UUID, slug string) (entity.Wishlist, error) {
+	args := m.Called(ctx, scope, slug)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) GetSlug(ctx context.Context, scope uuid.UUID, slug string) (entity.WishlistSlug, error) {
+	args := m.Called(ctx, scope, slug)
+	return args.Get(0).(entity.WishlistSlug), args.Error(1)
+}
+
+func (m *MockWishlistRepo) ChangeSlug(ctx context.Context, id uuid.UUID, version uint, scope uuid.UUID, slug string, now time.Time) error {
+	args := m.Called(ctx, id, version, scope, slug, now)
+	return args.Error(0)
+}
+
 func (m *MockWishlistRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]entity.Wishlist), args.Error(1)
 }
 
+func (m *MockWishlistRepo) GetListedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) GetSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) GetPendingSharedWithUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
 func (m *MockWishlistRepo) Update(ctx context.Context, wishlist entity.Wishlist) error {
 	args := m.Called(ctx, wishlist)
 	return args.Error(0)
 }
 
-func (m *MockWishlistRepo) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockWishlistRepo) GetEventsEndedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) Archive(ctx context.Context, id uuid.UUID, now time.Time, next *entity.Wishlist, presents []entity.Present, metas []entity.PresentMeta) error {
+	args := m.Called(ctx, id, now, next, presents, metas)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepo) Unarchive(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepo) Delete(ctx context.Context, id uuid.UUID, version uint, now time.Time) error {
+	args := m.Called(ctx, id, version, now)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepo) Restore(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepo) GetDeletedByID(ctx context.Context, id uuid.UUID) (entity.Wishlist, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) GetDeletedByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) GetDeletedBefore(ctx context.Context, before time.Time, limit int) ([]entity.Wishlist, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]entity.Wishlist), args.Error(1)
+}
+
+func (m *MockWishlistRepo) Purge(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
@@ -1,26 +1,37 @@
 package hasher
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMismatch = errors.New("hasher: password does not match")
+	// ErrUnknownAlgorithm — хеш не похож ни на Argon2id, ни на bcrypt
+	ErrUnknownAlgorithm = errors.New("hasher: unknown hash algorithm")
+)
 
 type PasswordHasher interface {
 	Hash(password string) (string, error)
+	// Compare проверяет пароль по хешу любого поддерживаемого алгоритма — алгоритм
+	// и параметры берутся из самого хеша, а не из настроек хешера
 	Compare(hashedPassword, password string) error
+	// NeedsRehash сообщает, что хеш сделан другим алгоритмом или с другими параметрами,
+	// чем новые хеши этого хешера, и его стоит пересчитать при следующем входе
+	NeedsRehash(hashedPassword string) bool
 }
 
-type bcryptHasher struct{}
-
+// New возвращает хешер Argon2id с параметрами по умолчанию
 func New() PasswordHasher {
-	return &bcryptHasher{}
+	return NewArgon2id(DefaultArgon2Params)
 }
 
-func (h *bcryptHasher) Hash(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
+func compare(hashedPassword, password string) error {
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		return compareArgon2id(hashedPassword, password)
+	case isBcrypt(hashedPassword):
+		return compareBcrypt(hashedPassword, password)
 	}
-	return string(bytes), nil
-}
-
-func (h *bcryptHasher) Compare(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return ErrUnknownAlgorithm
 }
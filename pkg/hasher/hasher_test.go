@@ -1,10 +1,12 @@
 package hasher_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"main/pkg/hasher"
 )
@@ -33,3 +35,70 @@ func TestCompare_WrongPassword(t *testing.T) {
 	err = h.Compare(hashed, "wrongpassword")
 	assert.Error(t, err)
 }
+
+func TestArgon2id_PHCFormat(t *testing.T) {
+	h := hasher.NewArgon2id(hasher.Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 2})
+	hashed, err := h.Hash("mypassword")
+	require.NoError(t, err)
+
+	parts := strings.Split(hashed, "$")
+	require.Len(t, parts, 6)
+	assert.Equal(t, "argon2id", parts[1])
+	assert.Equal(t, "v=19", parts[2])
+	assert.Equal(t, "m=8192,t=1,p=2", parts[3])
+
+	other, err := h.Hash("mypassword")
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, other, "salt must be random")
+}
+
+func TestCompare_DetectsAlgorithm(t *testing.T) {
+	bcryptHash, err := hasher.NewBcrypt(bcrypt.MinCost).Hash("mypassword")
+	require.NoError(t, err)
+	argonHash, err := hasher.NewArgon2id(hasher.Argon2Params{Memory: 8 * 1024, Iterations: 1}).Hash("mypassword")
+	require.NoError(t, err)
+
+	// Любой хешер проверяет хеши обоих алгоритмов с их собственными параметрами
+	for _, h := range []hasher.PasswordHasher{hasher.New(), hasher.NewBcrypt(bcrypt.DefaultCost)} {
+		for _, hashed := range []string{bcryptHash, argonHash} {
+			assert.NoError(t, h.Compare(hashed, "mypassword"))
+			assert.ErrorIs(t, h.Compare(hashed, "wrongpassword"), hasher.ErrMismatch)
+		}
+	}
+}
+
+func TestCompare_Malformed(t *testing.T) {
+	h := hasher.New()
+	for _, hashed := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=8192,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=8192,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=8192,t=0,p=0$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=8192,t=1,p=1$!!!$a2V5",
+	} {
+		assert.Error(t, h.Compare(hashed, "mypassword"), hashed)
+	}
+	assert.ErrorIs(t, h.Compare("plaintext", "plaintext"), hasher.ErrUnknownAlgorithm)
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current := hasher.Argon2Params{Memory: 8 * 1024, Iterations: 2, Parallelism: 1}
+	h := hasher.NewArgon2id(current)
+
+	upToDate, err := h.Hash("mypassword")
+	require.NoError(t, err)
+	weaker, err := hasher.NewArgon2id(hasher.Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}).Hash("mypassword")
+	require.NoError(t, err)
+	legacy, err := hasher.NewBcrypt(bcrypt.MinCost).Hash("mypassword")
+	require.NoError(t, err)
+
+	assert.False(t, h.NeedsRehash(upToDate))
+	assert.True(t, h.NeedsRehash(weaker))
+	assert.True(t, h.NeedsRehash(legacy))
+	assert.True(t, h.NeedsRehash("garbage"))
+
+	b := hasher.NewBcrypt(bcrypt.MinCost + 1)
+	assert.True(t, b.NeedsRehash(legacy))
+	assert.True(t, b.NeedsRehash(upToDate))
+}
@@ -0,0 +1,211 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"main/config"
+)
+
+const minRSABits = 2048
+
+// key — ключ проверки подписи; у ключей из приватных PEM есть и private
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	public  crypto.PublicKey
+	private crypto.PrivateKey
+}
+
+// KeySet подписывает access-токены активным ключом и проверяет их по kid.
+// Без каталога ключей работает как раньше — HS256 с общим секретом без kid.
+type KeySet struct {
+	signing *key
+	keys    map[string]*key
+	secret  []byte // HS256: подпись в режиме fallback, проверка токенов без kid
+}
+
+// JWK — публичный ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewHMAC — режим fallback: HS256 с общим секретом
+func NewHMAC(secret string) *KeySet {
+	return &KeySet{keys: map[string]*key{}, secret: []byte(secret)}
+}
+
+// New загружает ключи из cfg.JWTKeysDir: каждый *.pem — отдельный ключ, kid — имя файла.
+// Подписывает ключ cfg.JWTActiveKeyID (нужен приватный), остальные только проверяют токены,
+// выпущенные до ротации. Без каталога — NewHMAC(cfg.JWTSecret).
+func New(cfg config.AuthConfig) (*KeySet, error) {
+	if cfg.JWTKeysDir == "" {
+		return NewHMAC(cfg.JWTSecret), nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("jwtkeys: list %s: %w", cfg.JWTKeysDir, err)
+	}
+	ks := &KeySet{keys: make(map[string]*key, len(paths))}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwtkeys: %w", err)
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		k, err := parseKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("jwtkeys: %s: %w", path, err)
+		}
+		ks.keys[id] = k
+	}
+
+	active, ok := ks.keys[cfg.JWTActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("jwtkeys: active key %q not found in %s", cfg.JWTActiveKeyID, cfg.JWTKeysDir)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("jwtkeys: active key %q has no private part", cfg.JWTActiveKeyID)
+	}
+	ks.signing = active
+
+	if cfg.JWTAllowHS256 && cfg.JWTSecret != "" {
+		ks.secret = []byte(cfg.JWTSecret)
+	}
+	return ks, nil
+}
+
+// Sign подписывает claims активным ключом и проставляет kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.id
+	return token.SignedString(ks.signing.private)
+}
+
+// Keyfunc выбирает ключ проверки по kid. Алгоритм должен совпадать с алгоритмом ключа —
+// иначе публичный ключ можно было бы подсунуть как HMAC-секрет
+func (ks *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if ks.secret == nil {
+			return nil, errors.New("token has no kid")
+		}
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return ks.secret, nil
+	}
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", t.Header["alg"], kid)
+	}
+	return k.public, nil
+}
+
+// Parse проверяет подпись и срок действия токена
+func (ks *KeySet) Parse(token string) (*jwt.Token, error) {
+	return jwt.Parse(token, ks.Keyfunc)
+}
+
+// JWKS возвращает публичные ключи для проверки токенов другими сервисами
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		k := ks.keys[id]
+		jwk := JWK{Kid: id, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// parseKey принимает PKCS#8/PKCS#1 приватные и PKIX/PKCS#1 публичные ключи Ed25519 и RSA
+func parseKey(id string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{id: id}
+	switch v := parsed.(type) {
+	case ed25519.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodEdDSA, v, v.Public()
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, v
+	case *rsa.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, v
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if pub, ok := k.public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA key is shorter than %d bits", minRSABits)
+	}
+	return k, nil
+}
@@ -0,0 +1,181 @@
+package jwtkeys_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/config"
+	"main/pkg/jwtkeys"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), data, 0o600))
+}
+
+func writeEd25519(t *testing.T, dir, kid string) ed25519.PublicKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+	return pub
+}
+
+func writeRSA(t *testing.T, dir, kid string, bits int) *rsa.PrivateKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	writePEM(t, dir, kid, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(priv))
+	return priv
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"id": "user", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeySet_SignsWithActiveKeyAndKid(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519(t, dir, "2026-01")
+
+	ks, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "2026-01"})
+	require.NoError(t, err)
+
+	signed, err := ks.Sign(claims())
+	require.NoError(t, err)
+	token, err := ks.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01", token.Header["kid"])
+	assert.Equal(t, "EdDSA", token.Method.Alg())
+}
+
+func TestKeySet_RotationKeepsOldTokensValid(t *testing.T) {
+	dir := t.TempDir()
+	writeRSA(t, dir, "old", 2048)
+
+	oldKS, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "old"})
+	require.NoError(t, err)
+	oldToken, err := oldKS.Sign(claims())
+	require.NoError(t, err)
+
+	// Новый ключ становится активным, старый остаётся для проверки
+	writeEd25519(t, dir, "new")
+	ks, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "new"})
+	require.NoError(t, err)
+
+	_, err = ks.Parse(oldToken)
+	require.NoError(t, err)
+
+	newToken, err := ks.Sign(claims())
+	require.NoError(t, err)
+	_, err = oldKS.Parse(newToken)
+	assert.Error(t, err, "unknown kid must be rejected")
+}
+
+func TestKeySet_PublicOnlyKeyVerifies(t *testing.T) {
+	signDir, verifyDir := t.TempDir(), t.TempDir()
+	priv := writeRSA(t, signDir, "old", 2048)
+	signer, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: signDir, JWTActiveKeyID: "old"})
+	require.NoError(t, err)
+	signed, err := signer.Sign(claims())
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, verifyDir, "old", "PUBLIC KEY", der)
+	writeEd25519(t, verifyDir, "new")
+	ks, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: verifyDir, JWTActiveKeyID: "new"})
+	require.NoError(t, err)
+
+	_, err = ks.Parse(signed)
+	assert.NoError(t, err)
+
+	_, err = jwtkeys.New(config.AuthConfig{JWTKeysDir: verifyDir, JWTActiveKeyID: "old"})
+	assert.Error(t, err, "public-only key cannot be active")
+}
+
+func TestKeySet_HS256Fallback(t *testing.T) {
+	legacy, err := jwtkeys.NewHMAC("secret").Sign(claims())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeEd25519(t, dir, "k1")
+
+	allowing, err := jwtkeys.New(config.AuthConfig{JWTSecret: "secret", JWTKeysDir: dir, JWTActiveKeyID: "k1", JWTAllowHS256: true})
+	require.NoError(t, err)
+	_, err = allowing.Parse(legacy)
+	assert.NoError(t, err)
+
+	strict, err := jwtkeys.New(config.AuthConfig{JWTSecret: "secret", JWTKeysDir: dir, JWTActiveKeyID: "k1"})
+	require.NoError(t, err)
+	_, err = strict.Parse(legacy)
+	assert.Error(t, err)
+
+	// Без каталога ключей — прежнее поведение
+	ks, err := jwtkeys.New(config.AuthConfig{JWTSecret: "secret"})
+	require.NoError(t, err)
+	_, err = ks.Parse(legacy)
+	assert.NoError(t, err)
+	assert.Empty(t, ks.JWKS().Keys)
+}
+
+func TestKeySet_RejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	priv := writeRSA(t, dir, "rsa", 2048)
+	ks, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "rsa"})
+	require.NoError(t, err)
+
+	// HMAC с публичным ключом в роли секрета и kid RSA-ключа
+	pubDER := x509.MarshalPKCS1PublicKey(&priv.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = "rsa"
+	signed, err := forged.SignedString(pubDER)
+	require.NoError(t, err)
+
+	_, err = ks.Parse(signed)
+	assert.Error(t, err)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	edPub := writeEd25519(t, dir, "ed")
+	writeRSA(t, dir, "rsa", 2048)
+
+	ks, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "ed"})
+	require.NoError(t, err)
+
+	set := ks.JWKS()
+	require.Len(t, set.Keys, 2)
+	ed, rsaKey := set.Keys[0], set.Keys[1]
+	assert.Equal(t, jwtkeys.JWK{Kty: "OKP", Kid: "ed", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: encode(edPub)}, ed)
+	assert.Equal(t, "RSA", rsaKey.Kty)
+	assert.Equal(t, "RS256", rsaKey.Alg)
+	assert.Equal(t, "AQAB", rsaKey.E)
+	assert.NotEmpty(t, rsaKey.N)
+}
+
+func TestNew_RejectsShortRSAKey(t *testing.T) {
+	dir := t.TempDir()
+	writeRSA(t, dir, "weak", 1024)
+
+	_, err := jwtkeys.New(config.AuthConfig{JWTKeysDir: dir, JWTActiveKeyID: "weak"})
+	assert.Error(t, err)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"main/config"
+)
+
+// Message — простое текстовое письмо
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New возвращает SMTP-мейлер, если задан SMTP_HOST, иначе — мейлер, пишущий письма в лог
+func New(cfg config.MailConfig) Mailer {
+	if cfg.SMTPHost == "" {
+		return NewLog()
+	}
+	return NewSMTP(cfg)
+}
+
+// SMTP
+
+type smtpMailer struct {
+	addr     string
+	auth     smtp.Auth
+	from     string // заголовок From, может содержать имя отправителя
+	envelope string // голый адрес для MAIL FROM
+}
+
+func NewSMTP(cfg config.MailConfig) Mailer {
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	envelope := cfg.From
+	if addr, err := mail.ParseAddress(cfg.From); err == nil {
+		envelope = addr.Address
+	}
+	return &smtpMailer{
+		addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		auth:     auth,
+		from:     cfg.From,
+		envelope: envelope,
+	}
+}
+
+func (m *smtpMailer) Send(_ context.Context, msg Message) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, buildMessage(m.from, msg)); err != nil {
+		return fmt.Errorf("smtp send: %w", err)
+	}
+	return nil
+}
+
+// buildMessage собирает RFC 5322 письмо; тема кодируется по RFC 2047, т.к. обычно на кириллице
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// Log — для локальной разработки: письма со ссылками видны в логе сервера
+
+type logMailer struct{}
+
+func NewLog() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("mail to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// Memory — для тестов: сохраняет отправленные письма
+
+type Memory struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent возвращает копию всех отправленных писем
+func (m *Memory) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+// Last возвращает последнее письмо; ok = false, если писем не было
+func (m *Memory) Last() (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		return Message{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
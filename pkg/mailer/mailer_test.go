@@ -0,0 +1,31 @@
+package mailer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/config"
+	"main/pkg/mailer"
+)
+
+func TestMemory_RecordsMessages(t *testing.T) {
+	m := mailer.NewMemory()
+	_, ok := m.Last()
+	assert.False(t, ok)
+
+	require.NoError(t, m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "one"}))
+	require.NoError(t, m.Send(context.Background(), mailer.Message{To: "b@example.com", Subject: "two"}))
+
+	assert.Len(t, m.Sent(), 2)
+	last, ok := m.Last()
+	require.True(t, ok)
+	assert.Equal(t, "b@example.com", last.To)
+}
+
+func TestNew_WithoutSMTPHostLogs(t *testing.T) {
+	m := mailer.New(config.MailConfig{})
+	require.NoError(t, m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "dev"}))
+}
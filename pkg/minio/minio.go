@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"main/config"
@@ -17,7 +19,11 @@ import (
 
 type FileStorage interface {
 	Upload(name string, data []byte) (string, error)
+	// Get читает объект целиком
+	Get(objectID string) ([]byte, error)
 	Delete(objectID string) error
+	// ObjectID извлекает ID объекта из URL, который вернул Upload; ok=false — URL не из этого хранилища
+	ObjectID(url string) (string, bool)
 }
 
 type minioStorage struct {
@@ -85,7 +91,9 @@ func (s *minioStorage) Upload(name string, data []byte) (string, error) {
 		return "", errors.New("minio client not initialized")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Архивы выгрузок бывают большими: сверх базовых 10 секунд даём секунду на каждый мегабайт
+	timeout := 10*time.Second + time.Duration(len(data)>>20)*time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	objectID := uuid.New().String()
@@ -102,6 +110,34 @@ func (s *minioStorage) Upload(name string, data []byte) (string, error) {
 	return url, nil
 }
 
+func (s *minioStorage) Get(objectID string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	obj, err := s.mc.GetObject(ctx, s.bucketName, objectID, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("minio get %s: %w", objectID, err)
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, fmt.Errorf("minio read %s: %w", objectID, err)
+	}
+	return data, nil
+}
+
+func (s *minioStorage) ObjectID(url string) (string, bool) {
+	return objectIDFromURL(s.publicURL, s.bucketName, url)
+}
+
+func objectIDFromURL(publicURL, bucketName, url string) (string, bool) {
+	id, found := strings.CutPrefix(url, fmt.Sprintf("%s/%s/", publicURL, bucketName))
+	if !found || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
 func (s *minioStorage) Delete(objectID string) error {
 	return s.mc.RemoveObject(context.Background(), s.bucketName, objectID, minio.RemoveObjectOptions{})
 }
@@ -16,6 +16,14 @@ func (s *optimizingStorage) Upload(name string, data []byte) (string, error) {
 	return s.inner.Upload(name, imageconv.Convert(data))
 }
 
+func (s *optimizingStorage) Get(objectID string) ([]byte, error) {
+	return s.inner.Get(objectID)
+}
+
 func (s *optimizingStorage) Delete(objectID string) error {
 	return s.inner.Delete(objectID)
 }
+
+func (s *optimizingStorage) ObjectID(url string) (string, bool) {
+	return s.inner.ObjectID(url)
+}
@@ -0,0 +1,34 @@
+package randtoken_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/pkg/randtoken"
+)
+
+func TestGenerate_URLSafe(t *testing.T) {
+	tok, err := randtoken.Generate()
+	require.NoError(t, err)
+	assert.Len(t, tok, 43)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9_-]+$`), tok)
+}
+
+func TestGenerate_NoDuplicates(t *testing.T) {
+	seen := make(map[string]bool, 100)
+	for i := 0; i < 100; i++ {
+		tok, err := randtoken.Generate()
+		require.NoError(t, err)
+		assert.False(t, seen[tok], "duplicate token: %s", tok)
+		seen[tok] = true
+	}
+}
+
+func TestHash_Deterministic(t *testing.T) {
+	assert.Equal(t, randtoken.Hash("abc"), randtoken.Hash("abc"))
+	assert.NotEqual(t, randtoken.Hash("abc"), randtoken.Hash("abd"))
+	assert.Len(t, randtoken.Hash("abc"), 64)
+}
@@ -3,10 +3,13 @@ package shortid
 import (
 	"crypto/rand"
 	"math/big"
+	"regexp"
 )
 
 const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
 
+var pattern = regexp.MustCompile(`^[a-z0-9]{3}-[a-z0-9]{3}-[a-z0-9]{3}$`)
+
 // Generate returns a short ID in the format "abc-def-ghi"
 func Generate() (string, error) {
 	result := make([]byte, 11)
@@ -24,3 +27,8 @@ func Generate() (string, error) {
 
 	return string(result), nil
 }
+
+// Valid reports whether s has the format produced by Generate
+func Valid(s string) bool {
+	return pattern.MatchString(s)
+}
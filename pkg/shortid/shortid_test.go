@@ -34,3 +34,12 @@ func TestGenerate_NoDuplicates(t *testing.T) {
 		seen[id] = true
 	}
 }
+
+func TestValid(t *testing.T) {
+	id, err := shortid.Generate()
+	require.NoError(t, err)
+	assert.True(t, shortid.Valid(id))
+	for _, s := range []string{"", "abc-def", "abc-def-ghij", "ABC-DEF-GHI", "abc_def_ghi", "dr-mashi-2025"} {
+		assert.False(t, shortid.Valid(s), s)
+	}
+}
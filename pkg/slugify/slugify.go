@@ -0,0 +1,45 @@
+package slugify
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillic maps lowercase Russian, Ukrainian and Belarusian letters to Latin
+// the way people spell them in URLs and logins rather than by a strict standard
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "sch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// Make turns free text into a URL slug: lowercase Latin letters and digits
+// separated by single hyphens. Cyrillic is transliterated, other letters and
+// punctuation become separators, so "ДР Маши — 2025!" gives "dr-mashi-2025".
+// The result is empty when s has no letters or digits to keep.
+func Make(s string) string {
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(s) {
+		part, ok := cyrillic[r]
+		if !ok && r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part, ok = string(r), true
+		}
+		if !ok {
+			pendingDash = true
+			continue
+		}
+		if part == "" {
+			// hard and soft signs vanish without splitting the word
+			continue
+		}
+		if pendingDash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingDash = false
+		b.WriteString(part)
+	}
+	return b.String()
+}
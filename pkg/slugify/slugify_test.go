@@ -0,0 +1,30 @@
+package slugify_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"main/pkg/slugify"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"birthday-2025", "birthday-2025"},
+		{"  New Year  ", "new-year"},
+		{"ДР Маши — 2025!", "dr-mashi-2025"},
+		{"Щедрый подъезд", "schedryy-podezd"},
+		{"Ёлка_и_Юля", "elka-i-yulya"},
+		{"Їжак і ґанок", "yizhak-i-ganok"},
+		{"--a--b--", "a-b"},
+		{"café", "caf"},
+		{"!!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, slugify.Make(tt.in), "Make(%q)", tt.in)
+	}
+}
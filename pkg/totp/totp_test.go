@@ -0,0 +1,70 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"main/pkg/totp"
+)
+
+// Секрет из тестовых векторов RFC 6238 (SHA1); ожидаемые значения — младшие 6 цифр из Appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range vectors {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(v.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, v.code, code, "t=%d", v.unix)
+	}
+}
+
+func TestValidate_Skew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := totp.Code(rfcSecret, totp.Step(now))
+	require.NoError(t, err)
+
+	step, ok := totp.Validate(rfcSecret, code, now.Add(totp.Period))
+	assert.True(t, ok, "previous step accepted")
+	assert.Equal(t, totp.Step(now), step)
+
+	_, ok = totp.Validate(rfcSecret, code, now.Add(3*totp.Period))
+	assert.False(t, ok, "old code rejected")
+
+	_, ok = totp.Validate(rfcSecret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	b, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+	assert.Len(t, a, 32)
+}
+
+func TestURI(t *testing.T) {
+	uri := totp.URI("Wish List", "alice", "SECRET")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Wish%20List:alice?"))
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET", u.Query().Get("secret"))
+	assert.Equal(t, "Wish List", u.Query().Get("issuer"))
+}